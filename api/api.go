@@ -5,21 +5,59 @@ package api
 import (
 	"net/http"
 
+	"github.com/mdlayher/deltaiota/api/auth"
 	"github.com/mdlayher/deltaiota/api/util"
 	"github.com/mdlayher/deltaiota/api/v0"
+	"github.com/mdlayher/deltaiota/blob"
 	"github.com/mdlayher/deltaiota/data"
+	"github.com/mdlayher/deltaiota/metrics"
 
 	"github.com/gorilla/mux"
 )
 
 // NewServeMux returns a new http.Handler which contains the necessary HTTP routes
 // for all versions of the deltaiota HTTP server.
-func NewServeMux(db *data.DB) http.Handler {
+func NewServeMux(db *data.DB, store blob.Store) http.Handler {
 	// Create new mux to be configured
 	r := mux.NewRouter().StrictSlash(true)
 
-	// Create a handler for all v0 API routes
-	r.PathPrefix(v0.APIPrefix).Handler(util.LogHandler{v0.NewServeMux(db)})
+	// Create a handler for all v0 API routes; if the database is read-only,
+	// reject any requests which attempt to modify it.  Backups only read the
+	// database, so they remain available.
+	var h http.Handler = v0.NewServeMux(db, store)
+	if db.Readonly() {
+		h = util.ReadonlyHandler(h, v0.APIPrefix+"/admin/backup")
+	}
+	r.PathPrefix(v0.APIPrefix).Handler(util.LogHandler{Handler: h})
+
+	// Expose unauthenticated liveness and readiness checks
+	hc := &healthContext{db: db}
+	r.Handle("/healthz", util.JSONAPIHandler(hc.GetHealthz)).Methods("GET", "HEAD")
+	r.Handle("/readyz", util.JSONAPIHandler(hc.GetReadyz)).Methods("GET", "HEAD")
+
+	// Expose metrics for the API, database, and runtime in the Prometheus
+	// text format
+	r.Handle("/metrics", newRegistry(db)).Methods("GET", "HEAD")
 
 	return r
 }
+
+// newRegistry creates a metrics.Registry containing all metrics for a server
+// using the input database.
+func newRegistry(db *data.DB) *metrics.Registry {
+	reg := metrics.NewRegistry()
+
+	for _, ms := range [][]metrics.Metric{
+		util.Metrics(),
+		auth.Metrics(),
+		dbMetrics(db),
+		metrics.RuntimeMetrics(),
+	} {
+		// Metric names are fixed, so duplicates are a programming error
+		if err := reg.Register(ms...); err != nil {
+			panic(err)
+		}
+	}
+
+	return reg
+}
@@ -1,11 +1,15 @@
 package api
 
 import (
+	"bytes"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/mdlayher/deltaiota/api/v0"
+	"github.com/mdlayher/deltaiota/blob"
 	"github.com/mdlayher/deltaiota/data"
 	"github.com/mdlayher/deltaiota/ditest"
 )
@@ -33,26 +37,210 @@ func TestNewServeMuxGETAPIv1RootNotFound(t *testing.T) {
 func testNewServeMux(t *testing.T, method string, path string, code int) {
 	// Set up temporary database for test
 	ditest.WithTemporaryDBNew(t, func(t *testing.T, db *data.DB) {
-		// Set up HTTP test server
-		srv := httptest.NewServer(NewServeMux(db))
-		defer srv.Close()
+		err := ditest.WithTemporaryFileStore(func(store *blob.FileStore) error {
+			// Set up HTTP test server
+			srv := httptest.NewServer(NewServeMux(db, store))
+			defer srv.Close()
 
-		// Generate HTTP request, point at test server
-		path = srv.URL + path
-		req, err := http.NewRequest(method, path, nil)
+			// Generate HTTP request, point at test server
+			path = srv.URL + path
+			req, err := http.NewRequest(method, path, nil)
+			if err != nil {
+				return err
+			}
+
+			// Receive HTTP response
+			res, err := http.DefaultClient.Do(req)
+			if err != nil {
+				return err
+			}
+
+			// Check for expected status code
+			if res.StatusCode != code {
+				t.Errorf("unexpected code: %v != %v", res.StatusCode, code)
+			}
+
+			return nil
+		})
 		if err != nil {
 			t.Fatal(err)
 		}
+	})
+}
+
+// TestNewServeMuxGETMetrics verifies that metrics are served in the Prometheus
+// text format, including the duration of API requests.
+func TestNewServeMuxGETMetrics(t *testing.T) {
+	ditest.WithTemporaryDBNew(t, func(t *testing.T, db *data.DB) {
+		err := ditest.WithTemporaryFileStore(func(store *blob.FileStore) error {
+			srv := httptest.NewServer(NewServeMux(db, store))
+			defer srv.Close()
+
+			// Make an unauthenticated API request, which is recorded in metrics
+			res, err := http.Get(srv.URL + v0.APIPrefix + "/status")
+			if err != nil {
+				return err
+			}
+			res.Body.Close()
+
+			res, err = http.Get(srv.URL + "/metrics")
+			if err != nil {
+				return err
+			}
+			defer res.Body.Close()
+
+			if res.StatusCode != http.StatusOK {
+				t.Fatalf("unexpected code: %v != %v", res.StatusCode, http.StatusOK)
+			}
+
+			body, err := ioutil.ReadAll(res.Body)
+			if err != nil {
+				return err
+			}
 
-		// Receive HTTP response
-		res, err := http.DefaultClient.Do(req)
+			for _, s := range []string{
+				"deltaiota_auth_failures_total{reason=\"no HTTP Authorization header\"} ",
+				"deltaiota_db_prepared_statements ",
+				"deltaiota_db_open_connections ",
+				"go_goroutines ",
+				"go_memstats_alloc_bytes ",
+			} {
+				if !strings.Contains(string(body), "\n"+s) {
+					t.Fatalf("metric not found: %q", s)
+				}
+			}
+
+			return nil
+		})
 		if err != nil {
 			t.Fatal(err)
 		}
+	})
+}
+
+// TestNewServeMuxHealth verifies that the liveness check always succeeds, and
+// that the readiness check succeeds only while all migrations are applied.
+func TestNewServeMuxHealth(t *testing.T) {
+	ditest.WithTemporaryDBNew(t, func(t *testing.T, db *data.DB) {
+		err := ditest.WithTemporaryFileStore(func(store *blob.FileStore) error {
+			srv := httptest.NewServer(NewServeMux(db, store))
+			defer srv.Close()
+
+			var tests = []struct {
+				path    string
+				unapply bool
+				code    int
+				body    []byte
+			}{
+				{"/healthz", false, http.StatusOK, healthOK},
+				{"/readyz", false, http.StatusOK, healthOK},
+				// Pending migrations leave the server unready, but alive
+				{"/readyz", true, healthCode[migrationsPending], healthJSON[migrationsPending]},
+				{"/healthz", false, http.StatusOK, healthOK},
+			}
 
-		// Check for expected status code
-		if res.StatusCode != code {
-			t.Errorf("unexpected code: %v != %v", res.StatusCode, code)
+			for i, test := range tests {
+				if test.unapply {
+					if _, err := db.Exec(`DELETE FROM schema_migrations WHERE version = (SELECT MAX(version) FROM schema_migrations);`); err != nil {
+						return err
+					}
+				}
+
+				res, err := http.Get(srv.URL + test.path)
+				if err != nil {
+					return err
+				}
+
+				body, err := ioutil.ReadAll(res.Body)
+				res.Body.Close()
+				if err != nil {
+					return err
+				}
+
+				if res.StatusCode != test.code {
+					t.Fatalf("[%02d] unexpected code: %v != %v", i, res.StatusCode, test.code)
+				}
+				if !bytes.Equal(body, test.body) {
+					t.Fatalf("[%02d] unexpected body: %s != %s", i, body, test.body)
+				}
+			}
+
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+// TestNewServeMuxReadyzReadonly verifies that a server with a read-only database
+// which is behind its schema is not ready, since the database cannot be migrated.
+func TestNewServeMuxReadyzReadonly(t *testing.T) {
+	ditest.WithTemporaryUnmigratedReadonlyDB(t, func(t *testing.T, db *data.DB) {
+		err := ditest.WithTemporaryFileStore(func(store *blob.FileStore) error {
+			srv := httptest.NewServer(NewServeMux(db, store))
+			defer srv.Close()
+
+			res, err := http.Get(srv.URL + "/readyz")
+			if err != nil {
+				return err
+			}
+
+			body, err := ioutil.ReadAll(res.Body)
+			res.Body.Close()
+			if err != nil {
+				return err
+			}
+
+			if code := healthCode[migrationsPending]; res.StatusCode != code {
+				t.Fatalf("unexpected code: %v != %v", res.StatusCode, code)
+			}
+			if !bytes.Equal(body, healthJSON[migrationsPending]) {
+				t.Fatalf("unexpected body: %s != %s", body, healthJSON[migrationsPending])
+			}
+
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+// TestNewServeMuxReadonlyBackup verifies that a server with a read-only database
+// rejects changes, but still passes backup requests through to the Admin API.
+func TestNewServeMuxReadonlyBackup(t *testing.T) {
+	ditest.WithTemporaryReadonlyDB(t, nil, func(t *testing.T, db *data.DB) {
+		err := ditest.WithTemporaryFileStore(func(store *blob.FileStore) error {
+			srv := httptest.NewServer(NewServeMux(db, store))
+			defer srv.Close()
+
+			var tests = []struct {
+				path string
+				code int
+			}{
+				// Rejected by read-only mode
+				{"/users", http.StatusServiceUnavailable},
+				// Rejected by the Admin API, due to lack of credentials
+				{"/admin/backup", http.StatusUnauthorized},
+			}
+
+			for i, test := range tests {
+				res, err := http.Post(srv.URL+v0.APIPrefix+test.path, "application/json", nil)
+				if err != nil {
+					return err
+				}
+				res.Body.Close()
+
+				if res.StatusCode != test.code {
+					t.Fatalf("[%02d] unexpected code: %v != %v", i, res.StatusCode, test.code)
+				}
+			}
+
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
 		}
 	})
 }
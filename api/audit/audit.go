@@ -0,0 +1,328 @@
+// Package audit provides an audit log of changes made using the HTTP API for
+// the Phi Mu Alpha Sinfonia - Delta Iota chapter website.
+package audit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/mdlayher/deltaiota/api/auth"
+	"github.com/mdlayher/deltaiota/api/util"
+	"github.com/mdlayher/deltaiota/data"
+	"github.com/mdlayher/deltaiota/data/models"
+)
+
+// redacted is the value which replaces secret fields in an audit log entry.
+const redacted = "[redacted]"
+
+// redactedFields is the set of JSON fields which contain secrets, and are
+// never stored in the audit log.
+var redactedFields = map[string]struct{}{
+	"key":      {},
+	"password": {},
+	"token":    {},
+}
+
+// actions is a map of HTTP methods to the audit log action they perform.
+// Requests using any other method are not recorded.
+var actions = map[string]models.AuditAction{
+	"POST":   models.AuditCreate,
+	"PUT":    models.AuditUpdate,
+	"PATCH":  models.AuditUpdate,
+	"DELETE": models.AuditDelete,
+}
+
+// Context provides all shared members required for audit logging.
+type Context struct {
+	db *data.DB
+}
+
+// NewContext initializes a new Context with the input parameters.
+func NewContext(db *data.DB) *Context {
+	return &Context{
+		db: db,
+	}
+}
+
+// A Target retrieves the current state of the target of a request through the
+// data layer, or nil if it does not exist.  After a creation, id is the ID of
+// the created target, taken from the response; otherwise, it is zero, and the
+// target is identified by the request.
+type Target func(r *http.Request, vars util.Vars, id uint64) (interface{}, error)
+
+// errNotRecorded is returned within a transaction to roll back a request which
+// failed, and thus is not recorded.
+var errNotRecorded = errors.New("audit: request failed")
+
+// Handler wraps an input util.JSONAPIFunc, recording each successful change it
+// makes to a target of the input type in the audit log.  The action recorded
+// is determined by the HTTP method of each request.
+//
+// The target's state before and after the change is retrieved using the input
+// Target.  If target is nil, or the target cannot be identified, the state
+// after the change is taken from the response.  Only the changed fields are
+// recorded.
+//
+// Each request, and the audit log entry which records it, are performed in a
+// single transaction.  Requests which fail are rolled back, and are not
+// recorded.  If a change cannot be recorded, it is rolled back, and the request
+// fails with HTTP 500.
+func (c *Context) Handler(targetType string, target Target, fn util.JSONAPIFunc) util.JSONAPIFunc {
+	return c.ActionHandler(targetType, "", target, fn)
+}
+
+// ActionHandler is like Handler, but records the input action for all changes,
+// regardless of HTTP method.  If action is empty, it is determined by the HTTP
+// method of each request.
+func (c *Context) ActionHandler(targetType string, action models.AuditAction, target Target, fn util.JSONAPIFunc) util.JSONAPIFunc {
+	return func(r *http.Request, vars util.Vars) (int, []byte, error) {
+		// Only changes are recorded
+		a, ok := actions[r.Method]
+		if !ok {
+			return fn(r, vars)
+		}
+		if action != "" {
+			a = action
+		}
+
+		var (
+			code  int
+			body  []byte
+			fnErr error
+		)
+		err := c.db.WithTx(r.Context(), func(tx *data.Tx) error {
+			// All changes made by the request are made within the transaction
+			r := r.WithContext(data.NewTxContext(r.Context(), tx))
+
+			// Capture the target's state before it is changed
+			var before interface{}
+			if target != nil && a != models.AuditCreate {
+				var err error
+				if before, err = target(r, vars, 0); err != nil {
+					return err
+				}
+			}
+
+			code, body, fnErr = fn(r, vars)
+			if fnErr != nil || code < 200 || code >= 300 {
+				return errNotRecorded
+			}
+
+			// A successful creation which does not report HTTP 201 made no
+			// change
+			if a == models.AuditCreate && code != http.StatusCreated {
+				return nil
+			}
+
+			entry, err := newEntry(r, vars, a, targetType, target, before, body)
+			if err != nil {
+				return err
+			}
+
+			return tx.InsertAuditEntry(r.Context(), entry)
+		})
+		switch {
+		case err == errNotRecorded:
+			return code, body, fnErr
+		case err != nil:
+			return util.JSONAPIErr(fmt.Errorf("audit: %w", err))
+		}
+
+		return code, body, nil
+	}
+}
+
+// newEntry creates a models.AuditEntry for a successful request, using the
+// target's state before the change, and its state afterwards, which is
+// retrieved using target if possible, or taken from the response body.
+func newEntry(r *http.Request, vars util.Vars, a models.AuditAction, targetType string, target Target, before interface{}, body []byte) (*models.AuditEntry, error) {
+	b, err := encode(before)
+	if err != nil {
+		return nil, err
+	}
+
+	// Deleted targets have no state afterwards
+	after := body
+	if a == models.AuditDelete || a == models.AuditPurge {
+		after = nil
+	} else if target != nil {
+		// Identify a created target by the ID in the response
+		var id uint64
+		if a == models.AuditCreate {
+			obj, err := object(body)
+			if err != nil {
+				return nil, err
+			}
+			id = targetID(obj)
+		}
+
+		v, err := target(r, vars, id)
+		if err != nil {
+			return nil, err
+		}
+		if v != nil {
+			if after, err = encode(v); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	entry, err := NewEntry(a, targetType, b, after)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unauthenticated changes, such as accepting an invitation, are recorded
+	// without an actor
+	if user, ok := auth.User(r); ok {
+		entry.ActorID = user.ID
+	}
+	if entry.TargetID == 0 {
+		entry.TargetID, _ = strconv.ParseUint(vars["id"], 10, 64)
+	}
+
+	entry.RemoteAddr = r.RemoteAddr
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		entry.RemoteAddr = host
+	}
+
+	return entry, nil
+}
+
+// encode encodes the state of a target as JSON, or returns nil if there is
+// no target.
+func encode(v interface{}) ([]byte, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	return json.Marshal(v)
+}
+
+// NewEntry creates a new models.AuditEntry for the input action, from the JSON
+// representation of a target before and after it was changed.  Either may be
+// empty.
+//
+// API responses which wrap a single object in a list, such as {"users":[{...}]},
+// are unwrapped to the object.  Only fields which differ are kept, and secret
+// fields are redacted.  If the target has an "id" field, it is used as the
+// target ID.
+func NewEntry(action models.AuditAction, targetType string, before []byte, after []byte) (*models.AuditEntry, error) {
+	b, err := object(before)
+	if err != nil {
+		return nil, err
+	}
+	a, err := object(after)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &models.AuditEntry{
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID(a),
+		Created:    uint64(time.Now().Unix()),
+	}
+	if entry.TargetID == 0 {
+		entry.TargetID = targetID(b)
+	}
+
+	// Drop fields which are unchanged
+	for k, bv := range b {
+		if av, ok := a[k]; ok && reflect.DeepEqual(av, bv) {
+			delete(a, k)
+			delete(b, k)
+		}
+	}
+
+	if entry.Before, err = marshal(b); err != nil {
+		return nil, err
+	}
+	if entry.After, err = marshal(a); err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// object decodes a JSON API response into a redacted object, unwrapping any
+// single object which is wrapped in a list.
+func object(buf []byte) (map[string]interface{}, error) {
+	if len(buf) == 0 {
+		return nil, nil
+	}
+
+	var v interface{}
+	if err := json.Unmarshal(buf, &v); err != nil {
+		return nil, err
+	}
+	v = redact(v)
+
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		// Not an object, so keep the value as-is
+		return map[string]interface{}{"value": v}, nil
+	}
+
+	// Unwrap a single object in a list, such as {"users":[{...}]}
+	if len(obj) == 1 {
+		for _, vv := range obj {
+			if list, ok := vv.([]interface{}); ok && len(list) == 1 {
+				if inner, ok := list[0].(map[string]interface{}); ok {
+					return inner, nil
+				}
+			}
+		}
+	}
+
+	return obj, nil
+}
+
+// redact replaces the value of all secret fields in a decoded JSON value, at
+// any depth.
+func redact(v interface{}) interface{} {
+	switch vv := v.(type) {
+	case map[string]interface{}:
+		for k := range vv {
+			if _, ok := redactedFields[k]; ok {
+				vv[k] = redacted
+				continue
+			}
+
+			vv[k] = redact(vv[k])
+		}
+	case []interface{}:
+		for i := range vv {
+			vv[i] = redact(vv[i])
+		}
+	}
+
+	return v
+}
+
+// targetID returns the numeric "id" field of a decoded JSON object, or 0 if
+// none is present.
+func targetID(obj map[string]interface{}) uint64 {
+	id, ok := obj["id"].(float64)
+	if !ok || id < 0 {
+		return 0
+	}
+
+	return uint64(id)
+}
+
+// marshal encodes a decoded JSON object as models.JSONText, or returns nil if
+// the object is empty.
+func marshal(obj map[string]interface{}) (models.JSONText, error) {
+	if len(obj) == 0 {
+		return nil, nil
+	}
+
+	return json.Marshal(obj)
+}
@@ -0,0 +1,216 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/mdlayher/deltaiota/api/auth"
+	"github.com/mdlayher/deltaiota/api/util"
+	"github.com/mdlayher/deltaiota/data"
+	"github.com/mdlayher/deltaiota/data/models"
+	"github.com/mdlayher/deltaiota/ditest"
+)
+
+// TestNewEntry verifies that NewEntry records only changed fields, unwraps
+// API responses, and redacts secrets.
+func TestNewEntry(t *testing.T) {
+	var tests = []struct {
+		before string
+		after  string
+		id     uint64
+		b      string
+		a      string
+	}{
+		// Creation
+		{"", `{"users":[{"id":1,"username":"test"}]}`, 1, "", `{"id":1,"username":"test"}`},
+		// Deletion
+		{`{"users":[{"id":2,"username":"test"}]}`, "", 2, `{"id":2,"username":"test"}`, ""},
+		// Update, only changed fields
+		{`{"users":[{"id":3,"email":"a@example.com","username":"test"}]}`, `{"users":[{"id":3,"email":"b@example.com","username":"test"}]}`, 3, `{"email":"a@example.com"}`, `{"email":"b@example.com"}`},
+		// Secrets redacted, at any depth
+		{"", `{"user":{"id":4,"password":"hash"},"session":{"key":"secret"}}`, 0, "", `{"session":{"key":"[redacted]"},"user":{"id":4,"password":"[redacted]"}}`},
+		// Changed secrets are not recorded
+		{`{"token":"a"}`, `{"token":"b"}`, 0, "", ""},
+		// No change
+		{`{"id":5}`, `{"id":5}`, 5, "", ""},
+	}
+
+	for i, test := range tests {
+		entry, err := NewEntry(models.AuditUpdate, "user", []byte(test.before), []byte(test.after))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if entry.TargetID != test.id {
+			t.Fatalf("[%02d] unexpected target ID: %v != %v", i, entry.TargetID, test.id)
+		}
+		if b := string(entry.Before); b != test.b {
+			t.Fatalf("[%02d] unexpected before: %v != %v", i, b, test.b)
+		}
+		if a := string(entry.After); a != test.a {
+			t.Fatalf("[%02d] unexpected after: %v != %v", i, a, test.a)
+		}
+	}
+}
+
+// TestHandler verifies that Handler records successful changes in the audit
+// log, with the target's state before and after each change, and rolls back
+// changes made by requests which fail.
+func TestHandler(t *testing.T) {
+	ctx := context.Background()
+
+	ditest.WithTemporaryDBNew(t, func(t *testing.T, db *data.DB) {
+		c := NewContext(db)
+
+		user := ditest.MockUser()
+		if err := db.InsertUser(ctx, user); err != nil {
+			t.Fatal(err)
+		}
+
+		position := &models.Position{Name: "foo"}
+		if err := db.InsertPosition(ctx, position); err != nil {
+			t.Fatal(err)
+		}
+
+		// Target retrieves the position through the data layer
+		target := func(r *http.Request, vars util.Vars, id uint64) (interface{}, error) {
+			return db.SelectPositionByID(r.Context(), position.ID)
+		}
+
+		// Mock API which renames the position, but whose responses do not
+		// contain the position's state
+		fn := c.Handler("position", target, func(r *http.Request, vars util.Vars) (int, []byte, error) {
+			switch r.Method {
+			case "GET":
+				return http.StatusOK, []byte(`{}`), nil
+			case "PUT":
+				var v struct {
+					Name string `json:"name"`
+				}
+				if err := json.NewDecoder(r.Body).Decode(&v); err != nil || v.Name == "" {
+					return http.StatusBadRequest, []byte(`{}`), nil
+				}
+
+				p := *position
+				p.Name = v.Name
+				if err := db.UpdatePosition(r.Context(), &p); err != nil {
+					return util.JSONAPIErr(err)
+				}
+
+				// A change which fails after writing is rolled back
+				if v.Name == "fail" {
+					return http.StatusBadRequest, []byte(`{}`), nil
+				}
+
+				return http.StatusOK, []byte(`{}`), nil
+			case "POST":
+				// Dry run
+				return http.StatusOK, []byte(`{}`), nil
+			}
+
+			return util.MethodNotAllowed(r, vars)
+		})
+
+		var tests = []struct {
+			method string
+			body   string
+			code   int
+		}{
+			// Not a change
+			{"GET", "", http.StatusOK},
+			// Failed change
+			{"PUT", `{}`, http.StatusBadRequest},
+			{"PUT", `{"name":"fail"}`, http.StatusBadRequest},
+			// Successful creation which made no change
+			{"POST", `{}`, http.StatusOK},
+			// Successful change
+			{"PUT", `{"name":"bar"}`, http.StatusOK},
+		}
+
+		for i, test := range tests {
+			r, err := http.NewRequest(test.method, "/positions/1", bytes.NewReader([]byte(test.body)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			r.RemoteAddr = "192.0.2.1:1234"
+			r = auth.WithUser(r, user)
+
+			code, _, err := fn(r, util.Vars{"id": fmt.Sprintf("%d", position.ID)})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if code != test.code {
+				t.Fatalf("[%02d] unexpected code: %v != %v", i, code, test.code)
+			}
+		}
+
+		// Only the successful change is recorded
+		entries, err := db.SelectAuditEntriesByFilter(ctx, data.AuditFilter{}, 10, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(entries) != 1 {
+			t.Fatalf("unexpected number of entries: %v != %v", len(entries), 1)
+		}
+
+		e := entries[0]
+		if e.ActorID != user.ID || e.Action != models.AuditUpdate || e.TargetType != "position" || e.TargetID != position.ID {
+			t.Fatalf("unexpected entry: %+v", e)
+		}
+		if e.RemoteAddr != "192.0.2.1" {
+			t.Fatalf("unexpected remote address: %v", e.RemoteAddr)
+		}
+		if b, a := string(e.Before), string(e.After); b != `{"name":"foo"}` || a != `{"name":"bar"}` {
+			t.Fatalf("unexpected diff: %v -> %v", b, a)
+		}
+	})
+}
+
+// TestHandlerInsertError verifies that Handler fails a request, and rolls back
+// its change, when the change cannot be recorded in the audit log.
+func TestHandlerInsertError(t *testing.T) {
+	ctx := context.Background()
+
+	ditest.WithTemporaryDBNew(t, func(t *testing.T, db *data.DB) {
+		c := NewContext(db)
+
+		// Entries can no longer be stored
+		if _, err := db.Exec(`DROP TABLE audit_log;`); err != nil {
+			t.Fatal(err)
+		}
+
+		fn := c.Handler("position", nil, func(r *http.Request, vars util.Vars) (int, []byte, error) {
+			p := &models.Position{Name: "foo"}
+			if err := db.InsertPosition(r.Context(), p); err != nil {
+				return util.JSONAPIErr(err)
+			}
+
+			return http.StatusCreated, []byte(fmt.Sprintf(`{"positions":[{"id":%d,"name":"foo"}]}`, p.ID)), nil
+		})
+
+		r, err := http.NewRequest("POST", "/positions", bytes.NewReader([]byte(`{"name":"foo"}`)))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		code, _, err := fn(r, util.Vars{})
+		if err == nil {
+			t.Fatal("expected an error, but none occurred")
+		}
+		if code != http.StatusInternalServerError {
+			t.Fatalf("unexpected code: %v != %v", code, http.StatusInternalServerError)
+		}
+
+		positions, err := db.SelectAllPositions(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(positions) != 0 {
+			t.Fatalf("unexpected positions after rollback: %d", len(positions))
+		}
+	})
+}
@@ -0,0 +1,67 @@
+package auth
+
+import (
+	"net/http"
+
+	"github.com/mdlayher/deltaiota/data/models"
+)
+
+var (
+	// errNoAccess is returned when a user's membership status does not permit
+	// them to authenticate.
+	errNoAccess = &Error{
+		Reason: "membership status does not permit access",
+		Code:   http.StatusForbidden,
+	}
+
+	// errReadOnlyAccess is returned when a user's membership status permits
+	// read-only access, and they attempt to make changes or access a
+	// resource which requires full membership.
+	errReadOnlyAccess = &Error{
+		Reason: "membership status permits read-only access",
+		Code:   http.StatusForbidden,
+	}
+)
+
+// checkAccess returns a client error if the input user's membership status does
+// not grant the required level of access.
+func checkAccess(user *models.User, required models.Access) error {
+	access := user.Status.Access()
+	if access >= required {
+		return nil
+	}
+
+	if access == models.AccessNone {
+		return errNoAccess
+	}
+
+	return errReadOnlyAccess
+}
+
+// requiredAccess returns the level of access required to make the input HTTP
+// request.  Users with read-only access may only make safe requests.
+func requiredAccess(r *http.Request) models.Access {
+	switch r.Method {
+	case "GET", "HEAD", "OPTIONS":
+		return models.AccessRead
+	}
+
+	return models.AccessMember
+}
+
+// memberAuthenticate is a AuthenticateFunc which authenticates a user via API key,
+// and verifies that the user's membership status permits the request.
+func (a *Context) memberAuthenticate(r *http.Request) (*models.User, *models.Session, error, error) {
+	// Authenticate user by API key
+	user, session, cErr, sErr := a.keyAuthenticate(r)
+	if cErr != nil || sErr != nil {
+		return nil, nil, cErr, sErr
+	}
+
+	// Verify user may make this request
+	if err := checkAccess(user, requiredAccess(r)); err != nil {
+		return nil, nil, err, nil
+	}
+
+	return user, session, nil, nil
+}
@@ -0,0 +1,76 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/mdlayher/deltaiota/data"
+	"github.com/mdlayher/deltaiota/data/models"
+	"github.com/mdlayher/deltaiota/ditest"
+)
+
+// Test_memberAuthenticate verifies that memberAuthenticate restricts requests
+// according to the user's membership status.
+func Test_memberAuthenticate(t *testing.T) {
+	ctx := context.Background()
+
+	ditest.WithTemporaryDBNew(t, func(t *testing.T, db *data.DB) {
+		// Build context
+		ac := NewContext(db)
+
+		// Create and store mock user and session
+		user := ditest.MockUser()
+		if err := ac.db.InsertUser(ctx, user); err != nil {
+			t.Fatal(err)
+		}
+
+		session, err := user.NewSession(time.Now().Add(1 * time.Minute))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := ac.db.InsertSession(ctx, session); err != nil {
+			t.Fatal(err)
+		}
+
+		var tests = []struct {
+			status models.MemberStatus
+			method string
+			err    error
+		}{
+			{models.StatusPledge, "GET", nil},
+			{models.StatusPledge, "POST", nil},
+			{models.StatusActive, "PUT", nil},
+			{models.StatusAlumni, "GET", nil},
+			{models.StatusAlumni, "HEAD", nil},
+			{models.StatusAlumni, "POST", errReadOnlyAccess},
+			{models.StatusInactive, "DELETE", errReadOnlyAccess},
+			{models.StatusExpelled, "GET", errNoAccess},
+		}
+
+		for i, test := range tests {
+			// Set user's membership status
+			user.Status = test.status
+			if err := ac.db.UpdateUser(ctx, user); err != nil {
+				t.Fatal(err)
+			}
+
+			r, err := http.NewRequest(test.method, "/", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r.SetBasicAuth(user.Username, session.Key)
+
+			// Attempt authentication
+			_, _, cErr, sErr := ac.memberAuthenticate(r)
+			if sErr != nil {
+				t.Fatal(sErr)
+			}
+
+			if cErr != test.err {
+				t.Fatalf("[%02d] unexpected client err: %v != %v", i, cErr, test.err)
+			}
+		}
+	})
+}
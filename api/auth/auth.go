@@ -3,10 +3,10 @@
 package auth
 
 import (
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
-	"log"
 	"net/http"
 	"strings"
 	"time"
@@ -14,16 +14,19 @@ import (
 	"github.com/mdlayher/deltaiota/api/util"
 	"github.com/mdlayher/deltaiota/data"
 	"github.com/mdlayher/deltaiota/data/models"
-
-	"github.com/gorilla/context"
+	"github.com/mdlayher/deltaiota/metrics"
 )
 
+// ctxKey is the type of the keys used to store authentication values in the
+// context of a http.Request.
+type ctxKey int
+
 const (
-	// ctxSession is the named key used to fetch a Session from gorilla/context.
-	ctxSession = "session"
+	// ctxSession is the key used to fetch a Session from a request context.
+	ctxSession ctxKey = iota
 
-	// ctxUser is the named key used to fetch a User from gorilla/context.
-	ctxUser = "user"
+	// ctxUser is the key used to fetch a User from a request context.
+	ctxUser
 )
 
 var (
@@ -75,6 +78,25 @@ var (
 	SessionDuration = time.Duration(7 * 24 * time.Hour)
 )
 
+var (
+	// authSuccesses and authFailures count the outcomes of authentication
+	// attempts; failures are counted by the reason in their Error
+	authSuccesses = metrics.NewCounter(
+		"deltaiota_auth_successes_total",
+		"Number of successful authentication attempts.",
+	)
+	authFailures = metrics.NewCounter(
+		"deltaiota_auth_failures_total",
+		"Number of failed authentication attempts, by reason.",
+		"reason",
+	)
+)
+
+// Metrics returns the metrics collected by auth.
+func Metrics() []metrics.Metric {
+	return []metrics.Metric{authSuccesses, authFailures}
+}
+
 // AuthenticateFunc is a function which may be used to authenticate a user from an
 // input HTTP request.  On success, a user is returned.  On failure, either a client
 // or server error is returned.
@@ -92,9 +114,11 @@ func NewContext(db *data.DB) *Context {
 	}
 }
 
-// Error is an error returned on client authentication failure.
+// Error is an error returned on client authentication failure.  If Code is
+// not set, HTTP 401 is returned to the client.
 type Error struct {
 	Reason string
+	Code   int
 }
 
 // Error returns the string representation of an Error.
@@ -102,28 +126,31 @@ func (e *Error) Error() string {
 	return fmt.Sprintf("authentication failed: %s", e.Reason)
 }
 
-// SetSession sets a gorilla/context Session for the input http.Request.
-func SetSession(r *http.Request, s *models.Session) {
-	context.Set(r, ctxSession, s)
+// WithSession returns a shallow copy of the input http.Request, whose context
+// carries the input Session.
+func WithSession(r *http.Request, s *models.Session) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), ctxSession, s))
 }
 
-// Session returns the gorilla/context Session for the input http.Request.
-// This function will panic if the user is not properly authenticated, and
-// should only be used in handlers which are always authenticated.
-func Session(r *http.Request) *models.Session {
-	return context.Get(r, ctxSession).(*models.Session)
+// Session returns the Session which authenticated the input http.Request, and
+// whether or not one is present.  Requests authenticated without an API key,
+// and anonymous requests, carry no Session.
+func Session(r *http.Request) (*models.Session, bool) {
+	s, ok := r.Context().Value(ctxSession).(*models.Session)
+	return s, ok && s != nil
 }
 
-// SetUser sets a gorilla/context User for the input http.Request.
-func SetUser(r *http.Request, s *models.User) {
-	context.Set(r, ctxUser, s)
+// WithUser returns a shallow copy of the input http.Request, whose context
+// carries the input User.
+func WithUser(r *http.Request, u *models.User) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), ctxUser, u))
 }
 
-// User returns the gorilla/context User for the input http.Request.
-// This function will panic if the user is not properly authenticated, and
-// should only be used in handlers which are always authenticated.
-func User(r *http.Request) *models.User {
-	return context.Get(r, ctxUser).(*models.User)
+// User returns the authenticated User for the input http.Request, and whether
+// or not the request is authenticated.
+func User(r *http.Request) (*models.User, bool) {
+	u, ok := r.Context().Value(ctxUser).(*models.User)
+	return u, ok && u != nil
 }
 
 // makeAuthHandler generates a common authentication http.HandlerFunc using an input
@@ -136,32 +163,44 @@ func makeAuthHandler(fn AuthenticateFunc, h http.HandlerFunc) http.HandlerFunc {
 
 		// On server error, log error and return internal server error
 		if sErr != nil {
-			log.Println(sErr)
+			util.Logger(r).Error("authentication error", "err", sErr)
+			authFailures.Inc(util.InternalServerError)
 			w.WriteHeader(util.Code[util.InternalServerError])
-			w.Write(util.JSON[util.InternalServerError])
+			w.Write(util.AddRequestID(r, util.JSON[util.InternalServerError]))
 			return
 		}
 
 		// On client error, return details regarding failure
 		if cErr != nil {
-			code := util.Code[util.NotAuthorized]
-			w.WriteHeader(code)
-
 			// If not a specific authentication error, return generic error
 			authErr, ok := cErr.(*Error)
 			if !ok {
-				w.Write(util.JSON[util.NotAuthorized])
+				util.Logger(r).Info("authentication failed", "err", cErr)
+				authFailures.Inc(util.NotAuthorized)
+				w.WriteHeader(util.Code[util.NotAuthorized])
+				w.Write(util.AddRequestID(r, util.JSON[util.NotAuthorized]))
 				return
 			}
+			util.Logger(r).Info("authentication failed", "reason", authErr.Reason)
+			authFailures.Inc(authErr.Reason)
+
+			// Use error's specific code, if one is set
+			code := util.Code[util.NotAuthorized]
+			if authErr.Code != 0 {
+				code = authErr.Code
+			}
 
 			// Marshal specific error to JSON
-			body, err := json.Marshal(util.ErrRes(code, authErr.Error()))
+			res := util.ErrRes(code, authErr.Error())
+			res.Error.RequestID = util.RequestID(r)
+			body, err := json.Marshal(res)
 			if err != nil {
 				// On failed JSON marshal, return server error
-				log.Println(err)
+				util.Logger(r).Error("authentication error", "err", err)
 				w.WriteHeader(util.Code[util.InternalServerError])
 				return
 			}
+			w.WriteHeader(code)
 
 			// If not a HEAD request, write error body
 			if r.Method != "HEAD" {
@@ -170,15 +209,18 @@ func makeAuthHandler(fn AuthenticateFunc, h http.HandlerFunc) http.HandlerFunc {
 			return
 		}
 
-		// Authentication succeeded, store user and session for later use
-		SetUser(r, user)
-		SetSession(r, session)
+		// Authentication succeeded, store user and session for later use;
+		// anonymous requests carry neither
+		authSuccesses.Inc()
+		if user != nil {
+			r = WithUser(r, user)
+		}
+		if session != nil {
+			r = WithSession(r, session)
+		}
 
 		// Invoke input handler
 		h.ServeHTTP(w, r)
-
-		// Clear context after use
-		context.Clear(r)
 	})
 }
 
@@ -81,8 +81,14 @@ func Test_makeAuthHandlerUserSessionContext(t *testing.T) {
 
 	// Test handler which retrieves data from request context
 	contextHandlerFn := func(w http.ResponseWriter, r *http.Request) {
-		cUser := User(r)
-		cSession := Session(r)
+		cUser, ok := User(r)
+		if !ok {
+			t.Fatal("no user in request context")
+		}
+		cSession, ok := Session(r)
+		if !ok {
+			t.Fatal("no session in request context")
+		}
 
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte(cUser.Username + cSession.Key))
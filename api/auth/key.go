@@ -28,7 +28,16 @@ var (
 )
 
 // KeyAuthHandler is a http.HandlerFunc which performs API Key authentication.
+// Users whose membership status permits read-only access may only make GET and
+// HEAD requests.
 func (a *Context) KeyAuthHandler(h http.HandlerFunc) http.HandlerFunc {
+	return makeAuthHandler(a.memberAuthenticate, h)
+}
+
+// SessionAuthHandler is a http.HandlerFunc which performs API Key authentication,
+// permitting any request from a user whose membership status allows them to
+// authenticate.  It is intended for users to manage their own sessions.
+func (a *Context) SessionAuthHandler(h http.HandlerFunc) http.HandlerFunc {
 	return makeAuthHandler(a.keyAuthenticate, h)
 }
 
@@ -53,7 +62,7 @@ func (a *Context) keyAuthenticate(r *http.Request) (*models.User, *models.Sessio
 	}
 
 	// Attempt to select user for authentication by username
-	user, err := a.db.SelectUserByUsername(username)
+	user, err := a.db.SelectUserByUsername(r.Context(), username)
 	if err != nil {
 		// Check for unknown user
 		if err == sql.ErrNoRows {
@@ -64,7 +73,7 @@ func (a *Context) keyAuthenticate(r *http.Request) (*models.User, *models.Sessio
 	}
 
 	// Attempt to select session for authentication by key
-	session, err := a.db.SelectSessionByKey(key)
+	session, err := a.db.SelectSessionByKey(r.Context(), key)
 	if err != nil {
 		// Check for unknown session
 		if err == sql.ErrNoRows {
@@ -79,11 +88,19 @@ func (a *Context) keyAuthenticate(r *http.Request) (*models.User, *models.Sessio
 		return nil, nil, errInvalidKey, nil
 	}
 
+	// Verify user's membership status permits authentication
+	if err := checkAccess(user, models.AccessRead); err != nil {
+		return nil, nil, err, nil
+	}
+
 	// Verify key is not expired
 	if session.IsExpired() {
-		// Delete expired key
-		if err := a.db.DeleteSession(session); err != nil {
-			return nil, nil, nil, err
+		// Delete expired key, unless database is readonly, in which case the
+		// key is still rejected
+		if err := a.db.DeleteSession(r.Context(), session); err != nil {
+			if !a.db.IsReadonly(err) {
+				return nil, nil, nil, err
+			}
 		}
 
 		// Return expired key error
@@ -92,7 +109,7 @@ func (a *Context) keyAuthenticate(r *http.Request) (*models.User, *models.Sessio
 
 	// Update expire time, since authentication succeeded
 	session.SetExpire(time.Now().Add(SessionDuration))
-	if err := a.db.UpdateSession(session); err != nil {
+	if err := a.db.UpdateSession(r.Context(), session); err != nil {
 		// If database is readonly, ignore error
 		if !a.db.IsReadonly(err) {
 			return nil, nil, nil, err
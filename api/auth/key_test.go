@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"context"
 	"database/sql"
 	"net/http"
 	"testing"
@@ -56,10 +57,12 @@ func Test_keyAuthenticateInvalidKey(t *testing.T) {
 // Test_keyAuthenticateWrongKeyForUser verifies that keyAuthenticate returns a client
 // error when a valid user attempts to use another user's key.
 func Test_keyAuthenticateWrongKeyForUser(t *testing.T) {
+	ctx := context.Background()
+
 	test_keyAuthenticate(t, errInvalidKey, func(t *testing.T, ac *Context, user *models.User, session *models.Session) {
 		// Generate another mock user
 		user2 := ditest.MockUser()
-		if err := ac.db.InsertUser(user2); err != nil {
+		if err := ac.db.InsertUser(ctx, user2); err != nil {
 			t.Fatal(err)
 		}
 
@@ -73,10 +76,68 @@ func Test_keyAuthenticateWrongKeyForUser(t *testing.T) {
 // Test_keyAuthenticateExpiredSession verifies that keyAuthenticate returns a client
 // error when a valid user attempts to use an expired session.
 func Test_keyAuthenticateExpiredSession(t *testing.T) {
+	ctx := context.Background()
+
 	test_keyAuthenticate(t, errExpiredKey, func(t *testing.T, ac *Context, user *models.User, session *models.Session) {
 		// Expire session immediately
 		session.SetExpire(time.Now().Add(-1 * time.Hour))
-		if err := ac.db.UpdateSession(session); err != nil {
+		if err := ac.db.UpdateSession(ctx, session); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+// Test_keyAuthenticateExpiredSessionReadonly verifies that keyAuthenticate returns
+// a client error when a valid user attempts to use an expired session, even
+// though the session cannot be deleted from a read-only database.
+func Test_keyAuthenticateExpiredSessionReadonly(t *testing.T) {
+	ctx := context.Background()
+
+	user := ditest.MockUser()
+	var session *models.Session
+
+	ditest.WithTemporaryReadonlyDB(t, func(t *testing.T, db *data.DB) {
+		if err := db.InsertUser(ctx, user); err != nil {
+			t.Fatal(err)
+		}
+
+		// Generate an expired session
+		s, err := user.NewSession(time.Now().Add(-1 * time.Hour))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := db.InsertSession(ctx, s); err != nil {
+			t.Fatal(err)
+		}
+		session = s
+	}, func(t *testing.T, db *data.DB) {
+		ac := NewContext(db)
+
+		r, err := http.NewRequest("GET", "/", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.SetBasicAuth(user.Username, session.Key)
+
+		_, _, cErr, sErr := ac.keyAuthenticate(r)
+		if sErr != nil {
+			t.Fatal(sErr)
+		}
+		if cErr != errExpiredKey {
+			t.Fatalf("unexpected client err: %v != %v", cErr, errExpiredKey)
+		}
+	})
+}
+
+// Test_keyAuthenticateExpelled verifies that keyAuthenticate returns a client
+// error when a user's membership status does not permit access.
+func Test_keyAuthenticateExpelled(t *testing.T) {
+	ctx := context.Background()
+
+	test_keyAuthenticate(t, errNoAccess, func(t *testing.T, ac *Context, user *models.User, session *models.Session) {
+		// Expel user
+		user.Status = models.StatusExpelled
+		if err := ac.db.UpdateUser(ctx, user); err != nil {
 			t.Fatal(err)
 		}
 	})
@@ -86,13 +147,15 @@ func Test_keyAuthenticateExpiredSession(t *testing.T) {
 // handler.  It establishes test context, performs a setup function which can be used
 // to manipulate test data, and finally expects a certain error to occur on authentication.
 func test_keyAuthenticate(t *testing.T, expErr error, fn func(t *testing.T, ac *Context, user *models.User, session *models.Session)) {
+	ctx := context.Background()
+
 	ditest.WithTemporaryDBNew(t, func(t *testing.T, db *data.DB) {
 		// Build context
 		ac := NewContext(db)
 
 		// Create and store mock user in temporary database
 		user := ditest.MockUser()
-		if err := ac.db.InsertUser(user); err != nil {
+		if err := ac.db.InsertUser(ctx, user); err != nil {
 			t.Fatal(err)
 		}
 
@@ -103,7 +166,7 @@ func test_keyAuthenticate(t *testing.T, expErr error, fn func(t *testing.T, ac *
 		}
 
 		// Store session in temporary database
-		if err := ac.db.InsertSession(session); err != nil {
+		if err := ac.db.InsertSession(ctx, session); err != nil {
 			t.Fatal(err)
 		}
 
@@ -137,7 +200,7 @@ func test_keyAuthenticate(t *testing.T, expErr error, fn func(t *testing.T, ac *
 
 		// Ensure any expired sessions were deleted
 		if cErr == errExpiredKey {
-			if _, err := ac.db.SelectSessionByKey(session.Key); err != sql.ErrNoRows {
+			if _, err := ac.db.SelectSessionByKey(ctx, session.Key); err != sql.ErrNoRows {
 				t.Fatalf("session expired, but still in database")
 			}
 		}
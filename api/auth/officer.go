@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/mdlayher/deltaiota/data"
+	"github.com/mdlayher/deltaiota/data/models"
+)
+
+var (
+	// errNotOfficer is returned when an authenticated user attempts to access
+	// a resource which requires an officer.
+	errNotOfficer = &Error{
+		Reason: "user is not an officer",
+		Code:   http.StatusForbidden,
+	}
+)
+
+// OfficerAuthHandler is a http.HandlerFunc which performs API Key authentication,
+// and additionally requires that the authenticated user is an officer whose
+// membership status grants full member access.
+func (a *Context) OfficerAuthHandler(h http.HandlerFunc) http.HandlerFunc {
+	return makeAuthHandler(a.officerAuthenticate, h)
+}
+
+// officerAuthenticate is a AuthenticateFunc which authenticates a user via API key,
+// and verifies that the user is an officer.
+func (a *Context) officerAuthenticate(r *http.Request) (*models.User, *models.Session, error, error) {
+	// Authenticate user by API key
+	user, session, cErr, sErr := a.keyAuthenticate(r)
+	if cErr != nil || sErr != nil {
+		return nil, nil, cErr, sErr
+	}
+
+	// Officers must hold full member access, even for safe requests
+	if err := checkAccess(user, models.AccessMember); err != nil {
+		return nil, nil, err, nil
+	}
+
+	// Verify user is an officer
+	officer, err := IsOfficer(r.Context(), a.db, user)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if !officer {
+		return nil, nil, errNotOfficer, nil
+	}
+
+	return user, session, nil, nil
+}
+
+// IsOfficer returns whether or not the input user is currently an officer.
+//
+// A user is an officer only while they hold an active term in any position; the
+// role is never set directly.  When no user holds an active term, no user is an
+// officer, and the first officers must be appointed using the command line.
+func IsOfficer(ctx context.Context, db *data.DB, user *models.User) (bool, error) {
+	return db.IsOfficer(ctx, user.ID, time.Now())
+}
@@ -0,0 +1,214 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mdlayher/deltaiota/data"
+	"github.com/mdlayher/deltaiota/data/models"
+	"github.com/mdlayher/deltaiota/ditest"
+)
+
+// Test_officerAuthenticateNoOfficers verifies that officerAuthenticate returns a
+// client error for a user with no term, even when no officers exist.
+func Test_officerAuthenticateNoOfficers(t *testing.T) {
+	test_officerAuthenticate(t, errNotOfficer, nil)
+}
+
+// Test_officerAuthenticateNoOfficersForbidden verifies that a member with no
+// term is forbidden from an officer-only handler when no officers exist.
+func Test_officerAuthenticateNoOfficersForbidden(t *testing.T) {
+	ctx := context.Background()
+
+	ditest.WithTemporaryDBNew(t, func(t *testing.T, db *data.DB) {
+		ac := NewContext(db)
+
+		user := ditest.MockUser()
+		if err := db.InsertUser(ctx, user); err != nil {
+			t.Fatal(err)
+		}
+		session, err := user.NewSession(time.Now().Add(1 * time.Minute))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := db.InsertSession(ctx, session); err != nil {
+			t.Fatal(err)
+		}
+
+		r, err := http.NewRequest("GET", "/", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.SetBasicAuth(user.Username, session.Key)
+
+		w := httptest.NewRecorder()
+		ac.OfficerAuthHandler(okHandler()).ServeHTTP(w, r)
+
+		if w.Code != http.StatusForbidden {
+			t.Fatalf("unexpected code: %v != %v", w.Code, http.StatusForbidden)
+		}
+	})
+}
+
+// Test_officerAuthenticateActiveTerm verifies that officerAuthenticate allows a
+// user who holds an active term.
+func Test_officerAuthenticateActiveTerm(t *testing.T) {
+	ctx := context.Background()
+
+	test_officerAuthenticate(t, nil, func(t *testing.T, ac *Context, user *models.User, position *models.Position) {
+		// Appoint user to position, with no end date
+		term := &models.Term{
+			UserID:     user.ID,
+			PositionID: position.ID,
+			Start:      uint64(time.Now().Add(-1 * time.Hour).Unix()),
+		}
+		if err := ac.db.InsertTerm(ctx, term); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+// Test_officerAuthenticateNotOfficer verifies that officerAuthenticate returns a
+// client error when another user holds the only active term.
+func Test_officerAuthenticateNotOfficer(t *testing.T) {
+	ctx := context.Background()
+
+	test_officerAuthenticate(t, errNotOfficer, func(t *testing.T, ac *Context, user *models.User, position *models.Position) {
+		// Generate another mock user, who is an officer
+		user2 := ditest.MockUser()
+		if err := ac.db.InsertUser(ctx, user2); err != nil {
+			t.Fatal(err)
+		}
+
+		term := &models.Term{
+			UserID:     user2.ID,
+			PositionID: position.ID,
+			Start:      uint64(time.Now().Add(-1 * time.Hour).Unix()),
+		}
+		if err := ac.db.InsertTerm(ctx, term); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+// Test_officerAuthenticateEndedTerm verifies that officerAuthenticate returns a
+// client error when a user's term has ended, and another officer exists.
+func Test_officerAuthenticateEndedTerm(t *testing.T) {
+	ctx := context.Background()
+
+	test_officerAuthenticate(t, errNotOfficer, func(t *testing.T, ac *Context, user *models.User, position *models.Position) {
+		// Appoint user to a term which has already ended
+		term := &models.Term{
+			UserID:     user.ID,
+			PositionID: position.ID,
+			Start:      uint64(time.Now().Add(-2 * time.Hour).Unix()),
+			End:        uint64(time.Now().Add(-1 * time.Hour).Unix()),
+		}
+		if err := ac.db.InsertTerm(ctx, term); err != nil {
+			t.Fatal(err)
+		}
+
+		// Appoint another user to a current term
+		user2 := ditest.MockUser()
+		if err := ac.db.InsertUser(ctx, user2); err != nil {
+			t.Fatal(err)
+		}
+
+		term2 := &models.Term{
+			UserID:     user2.ID,
+			PositionID: position.ID,
+			Start:      term.End,
+		}
+		if err := ac.db.InsertTerm(ctx, term2); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+// Test_officerAuthenticateAlumni verifies that officerAuthenticate returns a
+// client error when an officer's membership status permits only read access.
+func Test_officerAuthenticateAlumni(t *testing.T) {
+	ctx := context.Background()
+
+	test_officerAuthenticate(t, errReadOnlyAccess, func(t *testing.T, ac *Context, user *models.User, position *models.Position) {
+		// Appoint user to position, with no end date
+		term := &models.Term{
+			UserID:     user.ID,
+			PositionID: position.ID,
+			Start:      uint64(time.Now().Add(-1 * time.Hour).Unix()),
+		}
+		if err := ac.db.InsertTerm(ctx, term); err != nil {
+			t.Fatal(err)
+		}
+
+		// Graduate user
+		user.Status = models.StatusAlumni
+		if err := ac.db.UpdateUser(ctx, user); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+// test_officerAuthenticate is a test helper which aids in testing the officerAuthenticate
+// handler.  It establishes test context, performs a setup function which can be used
+// to manipulate test data, and finally expects a certain error to occur on authentication.
+func test_officerAuthenticate(t *testing.T, expErr error, fn func(t *testing.T, ac *Context, user *models.User, position *models.Position)) {
+	ctx := context.Background()
+
+	ditest.WithTemporaryDBNew(t, func(t *testing.T, db *data.DB) {
+		// Build context
+		ac := NewContext(db)
+
+		// Create and store mock user in temporary database
+		user := ditest.MockUser()
+		if err := ac.db.InsertUser(ctx, user); err != nil {
+			t.Fatal(err)
+		}
+
+		// Generate and store a session for user
+		session, err := user.NewSession(time.Now().Add(1 * time.Minute))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := ac.db.InsertSession(ctx, session); err != nil {
+			t.Fatal(err)
+		}
+
+		// Create a position which users may hold
+		position := &models.Position{
+			Name: "President",
+		}
+		if err := ac.db.InsertPosition(ctx, position); err != nil {
+			t.Fatal(err)
+		}
+
+		// If set, perform test setup closure, to manipulate data and test
+		// for certain failure conditions
+		if fn != nil {
+			fn(t, ac, user, position)
+		}
+
+		// Create mock HTTP request with credentials for HTTP Basic
+		r, err := http.NewRequest("POST", "/", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.SetBasicAuth(user.Username, session.Key)
+
+		// Attempt authentication
+		_, _, cErr, sErr := ac.officerAuthenticate(r)
+
+		// Fail tests on any server error
+		if sErr != nil {
+			t.Fatal(sErr)
+		}
+
+		// Check for expected client error
+		if cErr != expErr {
+			t.Fatalf("unexpected client err: %v != %v", cErr, expErr)
+		}
+	})
+}
@@ -0,0 +1,26 @@
+package auth
+
+import (
+	"net/http"
+
+	"github.com/mdlayher/deltaiota/data/models"
+)
+
+// OptionalAuthHandler is a http.HandlerFunc which performs API Key authentication
+// when credentials are provided, and otherwise invokes the input handler as an
+// anonymous request.  Handlers should use User to check for an authenticated
+// user.  Invalid credentials are rejected, rather than treated as anonymous.
+func (a *Context) OptionalAuthHandler(h http.HandlerFunc) http.HandlerFunc {
+	return makeAuthHandler(a.optionalAuthenticate, h)
+}
+
+// optionalAuthenticate is a AuthenticateFunc which authenticates a user via API
+// key if an Authorization header is present.  If no header is present, no user,
+// session, or errors are returned.
+func (a *Context) optionalAuthenticate(r *http.Request) (*models.User, *models.Session, error, error) {
+	if r.Header.Get("Authorization") == "" {
+		return nil, nil, nil, nil
+	}
+
+	return a.memberAuthenticate(r)
+}
@@ -0,0 +1,73 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/mdlayher/deltaiota/data"
+	"github.com/mdlayher/deltaiota/ditest"
+)
+
+// Test_optionalAuthenticate verifies that optionalAuthenticate allows anonymous
+// requests, but rejects invalid credentials.
+func Test_optionalAuthenticate(t *testing.T) {
+	ctx := context.Background()
+
+	ditest.WithTemporaryDBNew(t, func(t *testing.T, db *data.DB) {
+		// Build context
+		ac := NewContext(db)
+
+		// Create and store mock user and session
+		user := ditest.MockUser()
+		if err := ac.db.InsertUser(ctx, user); err != nil {
+			t.Fatal(err)
+		}
+
+		session, err := user.NewSession(time.Now().Add(1 * time.Minute))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := ac.db.InsertSession(ctx, session); err != nil {
+			t.Fatal(err)
+		}
+
+		var tests = []struct {
+			username string
+			key      string
+			anon     bool
+			err      error
+		}{
+			// Anonymous
+			{"", "", true, nil},
+			// Invalid credentials
+			{user.Username, "foo", false, errInvalidKey},
+			// Valid credentials
+			{user.Username, session.Key, false, nil},
+		}
+
+		for i, test := range tests {
+			r, err := http.NewRequest("POST", "/", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !test.anon {
+				r.SetBasicAuth(test.username, test.key)
+			}
+
+			// Attempt authentication
+			authUser, _, cErr, sErr := ac.optionalAuthenticate(r)
+			if sErr != nil {
+				t.Fatal(sErr)
+			}
+
+			if cErr != test.err {
+				t.Fatalf("[%02d] unexpected client err: %v != %v", i, cErr, test.err)
+			}
+			if cErr == nil && test.anon != (authUser == nil) {
+				t.Fatalf("[%02d] unexpected user: %v", i, authUser)
+			}
+		}
+	})
+}
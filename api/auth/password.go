@@ -47,7 +47,7 @@ func (a *Context) passwordAuthenticate(r *http.Request) (*models.User, *models.S
 	}
 
 	// Attempt to select user for authentication by username
-	user, err := a.db.SelectUserByUsername(username)
+	user, err := a.db.SelectUserByUsername(r.Context(), username)
 	if err != nil {
 		// Check for unknown user
 		if err == sql.ErrNoRows {
@@ -67,6 +67,11 @@ func (a *Context) passwordAuthenticate(r *http.Request) (*models.User, *models.S
 		return nil, nil, nil, err
 	}
 
+	// Verify user's membership status permits authentication
+	if err := checkAccess(user, models.AccessRead); err != nil {
+		return nil, nil, err, nil
+	}
+
 	// Return authenticated user
 	return user, nil, nil, nil
 }
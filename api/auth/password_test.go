@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"context"
 	"net/http"
 	"testing"
 
@@ -55,6 +56,8 @@ func Test_passwordAuthenticateInvalidPassword(t *testing.T) {
 // handler.  It establishes text context, performs a setup function which can be used
 // to manipulate test data, and finally expects a certain error to occur on authentication.
 func test_passwordAuthenticate(t *testing.T, expErr error, fn func(t *testing.T, ac *Context, user *models.User)) {
+	ctx := context.Background()
+
 	ditest.WithTemporaryDBNew(t, func(t *testing.T, db *data.DB) {
 		// Build context
 		ac := NewContext(db)
@@ -65,7 +68,7 @@ func test_passwordAuthenticate(t *testing.T, expErr error, fn func(t *testing.T,
 		if err := user.SetPassword(plainPass); err != nil {
 			t.Fatal(err)
 		}
-		if err := ac.db.InsertUser(user); err != nil {
+		if err := ac.db.InsertUser(ctx, user); err != nil {
 			t.Fatal(err)
 		}
 
@@ -0,0 +1,70 @@
+package auth
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/mdlayher/deltaiota/data/models"
+)
+
+var (
+	// errNoFeedToken is returned when no feed token is provided for authentication.
+	errNoFeedToken = &Error{
+		Reason: "no feed token provided",
+	}
+
+	// errInvalidFeedToken is returned when an invalid feed token is provided for
+	// authentication.
+	errInvalidFeedToken = &Error{
+		Reason: "invalid feed token",
+	}
+)
+
+// FeedTokenAuthHandler is a http.HandlerFunc which performs feed token authentication.
+// It is intended for read-only feeds, such as calendars, which are fetched by
+// applications that cannot perform API key authentication.
+func (a *Context) FeedTokenAuthHandler(h http.HandlerFunc) http.HandlerFunc {
+	return makeAuthHandler(a.feedTokenAuthenticate, h)
+}
+
+// feedTokenAuthenticate is a AuthenticateFunc which authenticates a user via a
+// secret feed token, passed in the "token" query parameter.
+// On success, a user is returned, but no session.  On failure, either a
+// client or server error is returned.
+func (a *Context) feedTokenAuthenticate(r *http.Request) (*models.User, *models.Session, error, error) {
+	// Check for blank token
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		return nil, nil, errNoFeedToken, nil
+	}
+
+	// Attempt to select calendar token for authentication
+	ct, err := a.db.SelectCalendarTokenByToken(r.Context(), token)
+	if err != nil {
+		// Check for unknown token
+		if err == sql.ErrNoRows {
+			return nil, nil, errInvalidFeedToken, nil
+		}
+
+		return nil, nil, nil, err
+	}
+
+	// Select user who owns the token
+	user, err := a.db.SelectUserByID(r.Context(), ct.UserID)
+	if err != nil {
+		// Check for token which outlived its user
+		if err == sql.ErrNoRows {
+			return nil, nil, errInvalidFeedToken, nil
+		}
+
+		return nil, nil, nil, err
+	}
+
+	// Verify user's membership status permits authentication
+	if err := checkAccess(user, models.AccessRead); err != nil {
+		return nil, nil, err, nil
+	}
+
+	// Return authenticated user, with no session
+	return user, nil, nil, nil
+}
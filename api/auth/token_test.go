@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/mdlayher/deltaiota/data"
+	"github.com/mdlayher/deltaiota/data/models"
+	"github.com/mdlayher/deltaiota/ditest"
+)
+
+// Test_feedTokenAuthenticate verifies that feedTokenAuthenticate properly
+// authenticates users by their calendar feed token.
+func Test_feedTokenAuthenticate(t *testing.T) {
+	ctx := context.Background()
+
+	ditest.WithTemporaryDBNew(t, func(t *testing.T, db *data.DB) {
+		// Build context
+		ac := NewContext(db)
+
+		// Create and store mock user and calendar token
+		user := ditest.MockUser()
+		if err := ac.db.InsertUser(ctx, user); err != nil {
+			t.Fatal(err)
+		}
+
+		ct, err := models.NewCalendarToken(user.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := ac.db.SetCalendarToken(ctx, ct); err != nil {
+			t.Fatal(err)
+		}
+
+		var tests = []struct {
+			token string
+			err   error
+		}{
+			// No token
+			{"", errNoFeedToken},
+			// Unknown token
+			{"deadbeef", errInvalidFeedToken},
+			// Valid token
+			{ct.Token, nil},
+		}
+
+		for i, test := range tests {
+			r, err := http.NewRequest("GET", "/calendar.ics?token="+test.token, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Attempt authentication
+			authUser, _, cErr, sErr := ac.feedTokenAuthenticate(r)
+			if sErr != nil {
+				t.Fatal(sErr)
+			}
+
+			if cErr != test.err {
+				t.Fatalf("[%02d] unexpected client err: %v != %v", i, cErr, test.err)
+			}
+			if cErr == nil && authUser.ID != user.ID {
+				t.Fatalf("[%02d] unexpected user ID: %v != %v", i, authUser.ID, user.ID)
+			}
+		}
+	})
+}
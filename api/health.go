@@ -0,0 +1,91 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/mdlayher/deltaiota/api/util"
+	"github.com/mdlayher/deltaiota/data"
+)
+
+// JSON health API, human-readable client error responses.
+const (
+	dbUnavailable     = "database unavailable"
+	migrationsPending = "schema migrations pending"
+)
+
+// JSON health API, map of client errors to response codes.
+var healthCode = map[string]int{
+	dbUnavailable:     http.StatusServiceUnavailable,
+	migrationsPending: http.StatusServiceUnavailable,
+}
+
+// Generated JSON responses for various client-facing errors.
+var healthJSON = map[string][]byte{}
+
+// healthOK is the response body for a healthy server.
+var healthOK []byte
+
+// init initializes the stored JSON responses for client-facing errors.
+func init() {
+	// Iterate all error strings and code integers
+	for k, v := range healthCode {
+		// Generate error response with appropriate string and code
+		body, err := json.Marshal(util.ErrRes(v, k))
+		if err != nil {
+			panic(err)
+		}
+
+		// Store for later use
+		healthJSON[k] = body
+	}
+
+	body, err := json.Marshal(HealthResponse{Status: "ok"})
+	if err != nil {
+		panic(err)
+	}
+	healthOK = body
+}
+
+// HealthResponse is the output response for the health check endpoints.
+type HealthResponse struct {
+	Status string `json:"status"`
+}
+
+// healthContext provides the members required by the health check endpoints.
+type healthContext struct {
+	db *data.DB
+}
+
+// GetHealthz is a util.JSONAPIFunc which returns HTTP 200 as long as the server
+// is running and able to handle requests.  It is used as a liveness check, and
+// requires no authentication.
+func (c *healthContext) GetHealthz(r *http.Request, vars util.Vars) (int, []byte, error) {
+	return http.StatusOK, healthOK, nil
+}
+
+// GetReadyz is a util.JSONAPIFunc which returns HTTP 200 if the server is ready
+// to serve the API, or HTTP 503 if the database cannot be reached or its schema
+// has pending migrations.  It is used as a readiness check, and requires no
+// authentication.
+//
+// A read-only database cannot be migrated, so a server using a read-only
+// database which is behind its schema, such as a replica whose primary is being
+// migrated, remains unready until the database is migrated.
+func (c *healthContext) GetReadyz(r *http.Request, vars util.Vars) (int, []byte, error) {
+	if err := c.db.PingContext(r.Context()); err != nil {
+		util.Logger(r).Warn("readiness check failed", "err", err)
+		return healthCode[dbUnavailable], healthJSON[dbUnavailable], nil
+	}
+
+	pending, err := c.db.PendingMigrations(r.Context())
+	if err != nil {
+		util.Logger(r).Warn("readiness check failed", "err", err)
+		return healthCode[dbUnavailable], healthJSON[dbUnavailable], nil
+	}
+	if len(pending) > 0 {
+		return healthCode[migrationsPending], healthJSON[migrationsPending], nil
+	}
+
+	return http.StatusOK, healthOK, nil
+}
@@ -0,0 +1,56 @@
+package api
+
+import (
+	"database/sql"
+
+	"github.com/mdlayher/deltaiota/data"
+	"github.com/mdlayher/deltaiota/metrics"
+)
+
+// dbMetrics returns Metrics which report the state of the input database's
+// connection pool and prepared statement cache.
+func dbMetrics(db *data.DB) []metrics.Metric {
+	gauge := func(name string, help string, fn func(s sql.DBStats) float64) metrics.Metric {
+		return metrics.NewGaugeFunc(name, help, func() float64 {
+			return fn(db.Stats())
+		})
+	}
+	counter := func(name string, help string, fn func(s sql.DBStats) float64) metrics.Metric {
+		return metrics.NewCounterFunc(name, help, func() float64 {
+			return fn(db.Stats())
+		})
+	}
+
+	return []metrics.Metric{
+		metrics.NewGaugeFunc("deltaiota_db_prepared_statements", "Number of cached prepared statements.", func() float64 {
+			return float64(db.PreparedStatements())
+		}),
+		gauge("deltaiota_db_max_open_connections", "Maximum number of open connections to the database (0 for unlimited).", func(s sql.DBStats) float64 {
+			return float64(s.MaxOpenConnections)
+		}),
+		gauge("deltaiota_db_open_connections", "Number of established connections to the database.", func(s sql.DBStats) float64 {
+			return float64(s.OpenConnections)
+		}),
+		gauge("deltaiota_db_in_use_connections", "Number of connections currently in use.", func(s sql.DBStats) float64 {
+			return float64(s.InUse)
+		}),
+		gauge("deltaiota_db_idle_connections", "Number of idle connections.", func(s sql.DBStats) float64 {
+			return float64(s.Idle)
+		}),
+		counter("deltaiota_db_wait_count_total", "Number of connections waited for.", func(s sql.DBStats) float64 {
+			return float64(s.WaitCount)
+		}),
+		counter("deltaiota_db_wait_duration_seconds_total", "Total time spent waiting for new connections.", func(s sql.DBStats) float64 {
+			return s.WaitDuration.Seconds()
+		}),
+		counter("deltaiota_db_max_idle_closed_total", "Number of connections closed due to the maximum number of idle connections.", func(s sql.DBStats) float64 {
+			return float64(s.MaxIdleClosed)
+		}),
+		counter("deltaiota_db_max_idle_time_closed_total", "Number of connections closed due to the maximum idle time.", func(s sql.DBStats) float64 {
+			return float64(s.MaxIdleTimeClosed)
+		}),
+		counter("deltaiota_db_max_lifetime_closed_total", "Number of connections closed due to the maximum connection lifetime.", func(s sql.DBStats) float64 {
+			return float64(s.MaxLifetimeClosed)
+		}),
+	}
+}
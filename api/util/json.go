@@ -1,16 +1,26 @@
 package util
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"log"
 	"net/http"
 	"path/filepath"
 	"runtime"
 	"strconv"
+	"time"
 
 	"github.com/gorilla/mux"
 )
 
+var (
+	// QueryTimeout is the maximum duration which a JSONAPIFunc may spend handling
+	// a single request, including all of its database queries.  Queries which are
+	// still running when it elapses, or when the client disconnects, are canceled.
+	// A zero duration disables the timeout.
+	QueryTimeout = time.Duration(10 * time.Second)
+)
+
 // Vars is a map of route variables, typically injected by gorilla/mux; though they
 // can also be manually injected for testing handlers.
 type Vars map[string]string
@@ -20,32 +30,74 @@ type Vars map[string]string
 // or an error which is reported as an internal server error to the client.
 type JSONAPIFunc func(r *http.Request, vars Vars) (int, []byte, error)
 
-// JSONAPIHandler returns a http.HandlerFunc by invoking an input JSONAPIFunc.
+// JSONAPIHandler returns a http.HandlerFunc by invoking an input JSONAPIFunc,
+// whose requests are bounded by QueryTimeout.
 func JSONAPIHandler(fn JSONAPIFunc) http.HandlerFunc {
+	return jsonAPIHandler(fn, true)
+}
+
+// SlowJSONAPIHandler is like JSONAPIHandler, but does not bound the time spent
+// handling each request.  It is intended for JSONAPIFuncs which do slow work,
+// such as hashing passwords, before querying the database; they must bound
+// their own queries using QueryContext.
+func SlowJSONAPIHandler(fn JSONAPIFunc) http.HandlerFunc {
+	return jsonAPIHandler(fn, false)
+}
+
+// jsonAPIHandler implements JSONAPIHandler and SlowJSONAPIHandler.
+func jsonAPIHandler(fn JSONAPIFunc, timeout bool) http.HandlerFunc {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		// Bound the time spent handling this request
+		if timeout {
+			ctx, cancel := QueryContext(r.Context())
+			defer cancel()
+			r = r.WithContext(ctx)
+		}
+
 		// Invoke input closure to retrieve a HTTP status, a response body, and any
 		// possible errors which occurred.
 		code, body, err := fn(r, mux.Vars(r))
 		if err != nil {
-			// Generate string with request information
-			reqLog := fmt.Sprintf("[%s: %s %s]", r.RemoteAddr, r.Method, r.URL.Path)
+			logger := Logger(r).With(
+				"remote", r.RemoteAddr,
+				"method", r.Method,
+				"path", r.URL.Path,
+			)
 
 			// Check for internal error, with more debugging information
-			intErr, ok := err.(*InternalError)
-			if !ok {
+			var intErr *InternalError
+			if !errors.As(err, &intErr) {
 				// If not wrapped error, print basic information
-				log.Printf("%s %s", reqLog, err.Error())
+				logger.Error("request error", "err", err)
 			} else {
 				// If wrapped error, print advanced information
 				// In the future, additional error hooks could be added here
-				log.Printf("%s internal error: [file: %s:%d, err: %s]", reqLog, filepath.Base(intErr.File), intErr.Line, intErr.Err)
+				intErr.RequestID = RequestID(r)
+				logger.Error("internal error",
+					"file", fmt.Sprintf("%s:%d", filepath.Base(intErr.File), intErr.Line),
+					"err", intErr.Err,
+				)
 			}
+
+			// Report queries which ran out of time as a temporary failure
+			if errors.Is(err, context.DeadlineExceeded) {
+				code = Code[queryTimeout]
+				body = JSON[queryTimeout]
+			}
+		}
+
+		// Allow clients to report the request which produced an error
+		if code >= http.StatusBadRequest {
+			body = AddRequestID(r, body)
 		}
 
 		// Write HTTP status code
 		w.Header().Set(httpContentType, jsonContentType)
 		w.Header().Set(httpConnection, "close")
 		w.WriteHeader(code)
+		defer observeRequest(r, code, start)
 
 		// If HTTP HEAD request, write no body
 		if r.Method == "HEAD" {
@@ -59,6 +111,17 @@ func JSONAPIHandler(fn JSONAPIFunc) http.HandlerFunc {
 	})
 }
 
+// QueryContext returns a copy of the input context, which is canceled once
+// QueryTimeout elapses.  The returned function must be called to release
+// resources once the queries made using the context are complete.
+func QueryContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if QueryTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeout(ctx, QueryTimeout)
+}
+
 // JSONAPIErr accepts an internal error, wraps it in useful information for
 // debugging, and generates the appropriate JSONAPIFunc return signature,
 // for convenience and reduced code reptition.
@@ -8,6 +8,9 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
 )
 
 // TestJSONAPIHandlerNoBody verifies that JSONAPIHandler returns correct
@@ -60,6 +63,64 @@ func TestJSONAPIHandlerMethodNotAllowed(t *testing.T) {
 	testJSONAPIHandler(t, MethodNotAllowed, "CAT", http.StatusMethodNotAllowed, JSON[methodNotAllowed], nil)
 }
 
+// TestJSONAPIHandlerQueryTimeout verifies that JSONAPIHandler cancels requests
+// which exceed QueryTimeout, and reports them as a temporary failure.
+func TestJSONAPIHandlerQueryTimeout(t *testing.T) {
+	timeout := QueryTimeout
+	QueryTimeout = 10 * time.Millisecond
+	defer func() {
+		QueryTimeout = timeout
+	}()
+
+	// slowFn waits until its request is canceled
+	slowFn := func(r *http.Request, vars Vars) (int, []byte, error) {
+		<-r.Context().Done()
+		return JSONAPIErr(r.Context().Err())
+	}
+
+	testJSONAPIHandler(t, slowFn, "GET", http.StatusServiceUnavailable, JSON[queryTimeout], nil)
+}
+
+// TestSlowJSONAPIHandlerQueryContext verifies that SlowJSONAPIHandler does not
+// bound its requests, but that QueryContext bounds queries made within them.
+func TestSlowJSONAPIHandlerQueryContext(t *testing.T) {
+	timeout := QueryTimeout
+	QueryTimeout = 10 * time.Millisecond
+	defer func() {
+		QueryTimeout = timeout
+	}()
+
+	// slowFn outlasts QueryTimeout, then waits until its queries are canceled
+	slowFn := func(r *http.Request, vars Vars) (int, []byte, error) {
+		if _, ok := r.Context().Deadline(); ok {
+			return http.StatusOK, nil, nil
+		}
+
+		time.Sleep(2 * QueryTimeout)
+		if err := r.Context().Err(); err != nil {
+			return JSONAPIErr(err)
+		}
+
+		ctx, cancel := QueryContext(r.Context())
+		defer cancel()
+
+		<-ctx.Done()
+		return JSONAPIErr(ctx.Err())
+	}
+
+	r, err := http.NewRequest("GET", "/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	SlowJSONAPIHandler(slowFn).ServeHTTP(w, r)
+
+	if want := Code[queryTimeout]; w.Code != want {
+		t.Fatalf("unexpected code: %v != %v", w.Code, want)
+	}
+}
+
 // testJSONAPIHandler accepts input parameters and expected results for
 // JSONAPIHandler, and ensures it behaves as expected.
 func testJSONAPIHandler(t *testing.T, fn JSONAPIFunc, method string, code int, body []byte, expErr error) {
@@ -125,3 +186,29 @@ func testJSONAPIHandler(t *testing.T, fn JSONAPIFunc, method string, code int, b
 		t.Fatalf("error not logged: %v", expErr)
 	}
 }
+
+// TestJSONAPIHandlerMetrics verifies that JSONAPIHandler records the duration
+// of each request by route, method, and status code.
+func TestJSONAPIHandlerMetrics(t *testing.T) {
+	fn := func(r *http.Request, vars Vars) (int, []byte, error) {
+		return http.StatusTeapot, nil, nil
+	}
+
+	// Routes are identified by their template
+	m := mux.NewRouter()
+	m.Handle("/users/{id}", JSONAPIHandler(fn))
+
+	before := httpDuration.Count("/users/{id}", "GET", "418")
+	for _, path := range []string{"/users/1", "/users/2"} {
+		r, err := http.NewRequest("GET", path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		m.ServeHTTP(httptest.NewRecorder(), r)
+	}
+
+	if n := httpDuration.Count("/users/{id}", "GET", "418") - before; n != 2 {
+		t.Fatalf("unexpected number of observations: %d != 2", n)
+	}
+}
@@ -1,12 +1,29 @@
 package util
 
 import (
-	"log"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
 	"net/http"
+	"strings"
+	"time"
 )
 
-// LogHandler provides basic logging of actions which are passed through a
-// http.Handler.
+// httpRequestID is the name of the HTTP header which carries a request's ID.
+const httpRequestID = "X-Request-ID"
+
+// ctxKey is the type of keys for values stored in a request's context by util.
+type ctxKey int
+
+// ctxRequestID is the context key for a request's ID.
+const ctxRequestID ctxKey = iota
+
+// LogHandler provides structured logging of requests which are passed through
+// a http.Handler.  Each request is assigned a unique ID, which is stored in its
+// context, returned in the X-Request-ID header, and included in any errors
+// logged while handling the request.
 type LogHandler struct {
 	http.Handler
 }
@@ -14,26 +31,37 @@ type LogHandler struct {
 // ServeHTTP allows LogHandler to be used as a http.Handler, and captures
 // information regarding the client request and server response.
 func (l LogHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	// Wrap writer in custom logged writer
-	w = &logResponseWriter{
-		ResponseWriter: w,
-	}
+	start := time.Now()
 
-	// Call underlying handler
-	l.Handler.ServeHTTP(w, r)
+	id := newRequestID()
+	r = WithRequestID(r, id)
+	w.Header().Set(httpRequestID, id)
 
-	// Type-assert back to logged response writer
-	lw, ok := w.(*logResponseWriter)
-	if ok {
-		// Log information about the request and response
-		log.Printf("[%s] HTTP %d: %s %s", r.RemoteAddr, lw.Status, r.Method, r.URL)
+	// Wrap writer in custom logged writer, and call underlying handler
+	lw := &logResponseWriter{
+		ResponseWriter: w,
+		Status:         http.StatusOK,
 	}
+	l.Handler.ServeHTTP(lw, r)
+
+	// Log information about the request and response
+	slog.LogAttrs(r.Context(), slog.LevelInfo, "request",
+		slog.String("request_id", id),
+		slog.String("remote", r.RemoteAddr),
+		slog.String("method", r.Method),
+		slog.String("path", logPath(r)),
+		slog.Int("status", lw.Status),
+		slog.Int("bytes", lw.Bytes),
+		slog.Duration("duration", time.Since(start)),
+	)
 }
 
-// logResponseWriter captures the HTTP status code sent to a client.
+// logResponseWriter captures the HTTP status code and number of body bytes
+// sent to a client.
 type logResponseWriter struct {
 	http.ResponseWriter
 	Status int
+	Bytes  int
 }
 
 // WriteHeader captures the HTTP status code sent to a client.
@@ -41,3 +69,84 @@ func (w *logResponseWriter) WriteHeader(s int) {
 	w.ResponseWriter.WriteHeader(s)
 	w.Status = s
 }
+
+// Write captures the number of body bytes sent to a client.
+func (w *logResponseWriter) Write(b []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(b)
+	w.Bytes += n
+	return n, err
+}
+
+// redacted replaces secrets which are omitted from logged request paths.
+const redacted = "REDACTED"
+
+// logPath returns the path of the input http.Request for logging.  The query
+// is omitted, since it may carry a calendar feed token, and invitation tokens
+// in the path are redacted.
+func logPath(r *http.Request) string {
+	segments := strings.Split(r.URL.Path, "/")
+	for i := 0; i+2 < len(segments); i++ {
+		// Invitations are accepted at /invitations/{token}/accept
+		if segments[i] == "invitations" && segments[i+2] == "accept" {
+			segments[i+1] = redacted
+		}
+	}
+
+	return strings.Join(segments, "/")
+}
+
+// newRequestID generates a random request ID.
+func newRequestID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+
+	return hex.EncodeToString(b)
+}
+
+// WithRequestID returns a shallow copy of the input http.Request, whose
+// context carries the input request ID.
+func WithRequestID(r *http.Request, id string) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), ctxRequestID, id))
+}
+
+// RequestID returns the ID assigned to the input http.Request by LogHandler,
+// or empty if none was assigned.
+func RequestID(r *http.Request) string {
+	id, _ := r.Context().Value(ctxRequestID).(string)
+	return id
+}
+
+// Logger returns a slog.Logger which includes the ID of the input http.Request
+// in each log entry, if one was assigned.
+func Logger(r *http.Request) *slog.Logger {
+	if id := RequestID(r); id != "" {
+		return slog.With("request_id", id)
+	}
+
+	return slog.Default()
+}
+
+// AddRequestID adds the ID of the input http.Request to a JSON error response
+// body, so that clients may report it.  If the request has no ID, or the body
+// is not an ErrorResponse, the body is returned unmodified.
+func AddRequestID(r *http.Request, body []byte) []byte {
+	id := RequestID(r)
+	if id == "" || len(body) == 0 {
+		return body
+	}
+
+	var res ErrorResponse
+	if err := json.Unmarshal(body, &res); err != nil || res.Error == nil {
+		return body
+	}
+	res.Error.RequestID = id
+
+	b, err := json.Marshal(res)
+	if err != nil {
+		return body
+	}
+
+	return b
+}
@@ -0,0 +1,200 @@
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+// TestLogHandler verifies that LogHandler assigns each request an ID, and logs
+// the request and its response.
+func TestLogHandler(t *testing.T) {
+	buffer := bytes.NewBuffer(nil)
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewJSONHandler(buffer, nil)))
+
+	// h returns the request's ID as its body
+	var id string
+	h := LogHandler{http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id = RequestID(r)
+		w.WriteHeader(http.StatusTeapot)
+		w.Write([]byte(id))
+	})}
+
+	r, err := http.NewRequest("GET", "/api/v0/status", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if id == "" {
+		t.Fatal("no request ID assigned")
+	}
+	if h := w.Header().Get(httpRequestID); h != id {
+		t.Fatalf("unexpected %s header: %q != %q", httpRequestID, h, id)
+	}
+
+	var entry struct {
+		Message   string `json:"msg"`
+		RequestID string `json:"request_id"`
+		Method    string `json:"method"`
+		Path      string `json:"path"`
+		Status    int    `json:"status"`
+		Bytes     int    `json:"bytes"`
+	}
+	if err := json.Unmarshal(buffer.Bytes(), &entry); err != nil {
+		t.Fatal(err)
+	}
+
+	if entry.Message != "request" || entry.RequestID != id || entry.Method != "GET" || entry.Path != "/api/v0/status" {
+		t.Fatalf("unexpected log entry: %+v", entry)
+	}
+	if entry.Status != http.StatusTeapot || entry.Bytes != len(id) {
+		t.Fatalf("unexpected response in log entry: %+v", entry)
+	}
+}
+
+// TestLogHandlerRedactsTokens verifies that LogHandler does not log calendar
+// feed tokens or invitation tokens.
+func TestLogHandlerRedactsTokens(t *testing.T) {
+	const token = "abcdef0123456789"
+
+	var tests = []struct {
+		url  string
+		path string
+	}{
+		{
+			url:  "/api/v0/calendar.ics?token=" + token,
+			path: "/api/v0/calendar.ics",
+		},
+		{
+			url:  "/api/v0/invitations/" + token + "/accept",
+			path: "/api/v0/invitations/REDACTED/accept",
+		},
+		{
+			url:  "/api/v0/invitations/1",
+			path: "/api/v0/invitations/1",
+		},
+	}
+
+	defer slog.SetDefault(slog.Default())
+
+	for i, test := range tests {
+		buffer := bytes.NewBuffer(nil)
+		slog.SetDefault(slog.New(slog.NewJSONHandler(buffer, nil)))
+
+		h := LogHandler{http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})}
+
+		r, err := http.NewRequest("GET", test.url, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		h.ServeHTTP(httptest.NewRecorder(), r)
+
+		if bytes.Contains(buffer.Bytes(), []byte(token)) {
+			t.Fatalf("[%02d] token logged: %s", i, buffer.String())
+		}
+
+		var entry struct {
+			Path string `json:"path"`
+		}
+		if err := json.Unmarshal(buffer.Bytes(), &entry); err != nil {
+			t.Fatal(err)
+		}
+
+		if entry.Path != test.path {
+			t.Fatalf("[%02d] unexpected path: %q != %q", i, entry.Path, test.path)
+		}
+	}
+}
+
+// TestLogHandlerJSONAPIError verifies that errors from a JSONAPIHandler behind
+// LogHandler are logged and returned with the request's ID.
+func TestLogHandlerJSONAPIError(t *testing.T) {
+	buffer := bytes.NewBuffer(nil)
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewJSONHandler(buffer, nil)))
+
+	h := LogHandler{JSONAPIHandler(func(r *http.Request, vars Vars) (int, []byte, error) {
+		return JSONAPIErr(errors.New("a fake test error"))
+	})}
+
+	r, err := http.NewRequest("GET", "/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	id := w.Header().Get(httpRequestID)
+	if id == "" {
+		t.Fatal("no request ID assigned")
+	}
+
+	var errRes ErrorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &errRes); err != nil {
+		t.Fatal(err)
+	}
+	if errRes.Error.RequestID != id {
+		t.Fatalf("unexpected request ID in response: %q != %q", errRes.Error.RequestID, id)
+	}
+	if l := w.Header().Get(httpContentLength); l != "" && l != strconv.Itoa(w.Body.Len()) {
+		t.Fatalf("unexpected Content-Length: %s != %d", l, w.Body.Len())
+	}
+
+	// Both the error and the request are logged with the request's ID
+	var entries int
+	d := json.NewDecoder(buffer)
+	for d.More() {
+		var entry struct {
+			Message   string `json:"msg"`
+			RequestID string `json:"request_id"`
+		}
+		if err := d.Decode(&entry); err != nil {
+			t.Fatal(err)
+		}
+
+		if entry.RequestID != id {
+			t.Fatalf("unexpected request ID in %q log entry: %q != %q", entry.Message, entry.RequestID, id)
+		}
+		entries++
+	}
+	if entries != 2 {
+		t.Fatalf("unexpected number of log entries: %d != 2", entries)
+	}
+}
+
+// TestAddRequestID verifies that AddRequestID only modifies error responses
+// for requests with an ID.
+func TestAddRequestID(t *testing.T) {
+	r, err := http.NewRequest("GET", "/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = []struct {
+		r        *http.Request
+		body     []byte
+		expected []byte
+	}{
+		{r, JSON[NotAuthorized], JSON[NotAuthorized]},
+		{WithRequestID(r, "abcd"), nil, nil},
+		{WithRequestID(r, "abcd"), []byte(`{"users":[]}`), []byte(`{"users":[]}`)},
+		{WithRequestID(r, "abcd"), JSON[NotAuthorized], []byte(`{"error":{"code":401,"message":"not authorized","requestId":"abcd"}}`)},
+	}
+
+	for i, test := range tests {
+		if body := AddRequestID(test.r, test.body); !bytes.Equal(body, test.expected) {
+			t.Fatalf("[%02d] unexpected body: %s != %s", i, body, test.expected)
+		}
+	}
+}
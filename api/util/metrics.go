@@ -0,0 +1,40 @@
+package util
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/mdlayher/deltaiota/metrics"
+
+	"github.com/gorilla/mux"
+)
+
+// httpDuration measures the duration of each request handled by a
+// JSONAPIHandler.
+var httpDuration = metrics.NewHistogram(
+	"deltaiota_http_request_duration_seconds",
+	"Duration of API requests, by route, method, and status code.",
+	metrics.DefaultBuckets,
+	"route", "method", "code",
+)
+
+// Metrics returns the metrics collected by util.
+func Metrics() []metrics.Metric {
+	return []metrics.Metric{httpDuration}
+}
+
+// observeRequest records the duration of a request, handled since start, which
+// produced the input HTTP status code.  Requests are identified by the template
+// of their route, rather than their path, so that the number of series is
+// bounded.
+func observeRequest(r *http.Request, code int, start time.Time) {
+	route := "unknown"
+	if cr := mux.CurrentRoute(r); cr != nil {
+		if t, err := cr.GetPathTemplate(); err == nil {
+			route = t
+		}
+	}
+
+	httpDuration.Observe(time.Since(start).Seconds(), route, r.Method, strconv.Itoa(code))
+}
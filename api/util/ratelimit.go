@@ -0,0 +1,117 @@
+package util
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// rateLimitSweep is the interval at which idle clients are removed from a
+// RateLimiter.
+const rateLimitSweep = 1 * time.Minute
+
+// RateLimiter limits the rate of requests from each client, identified by its
+// remote IP address.  Each client may make requests at a sustained rate, and
+// may exceed that rate for a limited burst of requests.
+type RateLimiter struct {
+	rate  float64
+	burst float64
+
+	mu      sync.Mutex
+	clients map[string]*bucket
+	swept   time.Time
+
+	// now returns the current time, and may be replaced for testing
+	now func() time.Time
+}
+
+// bucket is a token bucket for a single client.
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter creates a RateLimiter which permits a sustained rate of
+// requests per second from each client, and bursts of up to burst requests.
+func NewRateLimiter(rate float64, burst int) *RateLimiter {
+	return &RateLimiter{
+		rate:    rate,
+		burst:   float64(burst),
+		clients: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+// Allow returns whether or not a request from the input client is permitted.
+// If not, it also returns how long the client must wait before its next
+// request is permitted.
+func (l *RateLimiter) Allow(client string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	b, ok := l.clients[client]
+	if !ok {
+		b = &bucket{
+			tokens: l.burst,
+			last:   now,
+		}
+		l.clients[client] = b
+	}
+
+	// Refill tokens for the time elapsed since the client's last request
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+	}
+
+	b.tokens--
+	return true, 0
+}
+
+// sweep periodically removes clients whose buckets would be full, since they
+// have been idle long enough that they are indistinguishable from new clients.
+func (l *RateLimiter) sweep(now time.Time) {
+	if now.Sub(l.swept) < rateLimitSweep {
+		return
+	}
+	l.swept = now
+
+	full := time.Duration(l.burst / l.rate * float64(time.Second))
+	for client, b := range l.clients {
+		if now.Sub(b.last) >= full {
+			delete(l.clients, client)
+		}
+	}
+}
+
+// Handler wraps a http.Handler, and rejects requests from clients which exceed
+// the rate limit with a HTTP 429 and a JSON error.
+func (l *RateLimiter) Handler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		client, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			client = r.RemoteAddr
+		}
+
+		ok, wait := l.Allow(client)
+		if ok {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		body := JSON[tooManyRequests]
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+		w.Header().Set(httpContentType, jsonContentType)
+		w.Header().Set(httpContentLength, strconv.Itoa(len(body)))
+		w.WriteHeader(Code[tooManyRequests])
+		w.Write(body)
+	})
+}
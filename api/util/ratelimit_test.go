@@ -0,0 +1,94 @@
+package util
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// TestRateLimiterAllow verifies that RateLimiter permits bursts of requests,
+// refills over time, and limits each client independently.
+func TestRateLimiterAllow(t *testing.T) {
+	now := time.Unix(0, 0)
+	l := NewRateLimiter(1, 2)
+	l.now = func() time.Time {
+		return now
+	}
+
+	var tests = []struct {
+		client  string
+		advance time.Duration
+		ok      bool
+		wait    time.Duration
+	}{
+		// Burst permitted, then limited
+		{"a", 0, true, 0},
+		{"a", 0, true, 0},
+		{"a", 0, false, 1 * time.Second},
+		// Other clients are unaffected
+		{"b", 0, true, 0},
+		// One token refilled
+		{"a", 500 * time.Millisecond, false, 500 * time.Millisecond},
+		{"a", 500 * time.Millisecond, true, 0},
+		{"a", 0, false, 1 * time.Second},
+		// Refill never exceeds burst
+		{"a", 1 * time.Hour, true, 0},
+		{"a", 0, true, 0},
+		{"a", 0, false, 1 * time.Second},
+	}
+
+	for i, test := range tests {
+		now = now.Add(test.advance)
+
+		ok, wait := l.Allow(test.client)
+		if ok != test.ok {
+			t.Fatalf("[%02d] unexpected result: %v != %v", i, ok, test.ok)
+		}
+		if wait != test.wait {
+			t.Fatalf("[%02d] unexpected wait: %v != %v", i, wait, test.wait)
+		}
+	}
+
+	// After idling, clients are removed
+	now = now.Add(1 * time.Hour)
+	l.Allow("c")
+	if _, ok := l.clients["a"]; ok {
+		t.Fatal("idle client was not removed")
+	}
+}
+
+// TestRateLimiterHandler verifies that RateLimiter.Handler rejects requests
+// which exceed the rate limit.
+func TestRateLimiterHandler(t *testing.T) {
+	okHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	h := NewRateLimiter(1, 1).Handler(okHandler)
+
+	for i, code := range []int{http.StatusOK, http.StatusTooManyRequests} {
+		r, err := http.NewRequest("GET", "/", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.RemoteAddr = "192.0.2.1:1234"
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		if w.Code != code {
+			t.Fatalf("[%02d] unexpected code: %v != %v", i, w.Code, code)
+		}
+		if code != http.StatusTooManyRequests {
+			continue
+		}
+
+		if !bytes.Equal(w.Body.Bytes(), JSON[tooManyRequests]) {
+			t.Fatalf("[%02d] unexpected body: %q", i, w.Body.String())
+		}
+		if retry := w.Header().Get("Retry-After"); retry != "1" {
+			t.Fatalf("[%02d] unexpected Retry-After header: %q", i, retry)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package util
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// ReadonlyHandler wraps a http.Handler for a server whose database is in
+// read-only mode.  Requests which may only read data, using the GET, HEAD, or
+// OPTIONS methods, are passed through to the handler.  All other requests are
+// rejected with a HTTP 503 and a JSON error, rather than failing partway
+// through an attempted change.  Requests for any of the input paths, which
+// must not modify the database, are passed through regardless of method.
+func ReadonlyHandler(h http.Handler, paths ...string) http.Handler {
+	allow := make(map[string]struct{}, len(paths))
+	for _, p := range paths {
+		allow[p] = struct{}{}
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET", "HEAD", "OPTIONS":
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		if _, ok := allow[r.URL.Path]; ok {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		body := AddRequestID(r, JSON[readonly])
+		w.Header().Set(httpContentType, jsonContentType)
+		w.Header().Set(httpContentLength, strconv.Itoa(len(body)))
+		w.WriteHeader(Code[readonly])
+		w.Write(body)
+	})
+}
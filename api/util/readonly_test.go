@@ -0,0 +1,57 @@
+package util
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestReadonlyHandler verifies that ReadonlyHandler passes through requests
+// which only read data, and rejects all others.
+func TestReadonlyHandler(t *testing.T) {
+	// okHandler returns HTTP OK and nothing else
+	okHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	var tests = []struct {
+		method string
+		path   string
+		code   int
+		body   []byte
+	}{
+		{"GET", "/", http.StatusOK, nil},
+		{"HEAD", "/", http.StatusOK, nil},
+		{"OPTIONS", "/", http.StatusOK, nil},
+		{"POST", "/", http.StatusServiceUnavailable, JSON[readonly]},
+		{"PUT", "/", http.StatusServiceUnavailable, JSON[readonly]},
+		{"PATCH", "/", http.StatusServiceUnavailable, JSON[readonly]},
+		{"DELETE", "/", http.StatusServiceUnavailable, JSON[readonly]},
+		// Allowed paths
+		{"POST", "/backup", http.StatusOK, nil},
+		{"POST", "/backup/foo", http.StatusServiceUnavailable, JSON[readonly]},
+	}
+
+	for i, test := range tests {
+		r, err := http.NewRequest(test.method, test.path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		w := httptest.NewRecorder()
+		ReadonlyHandler(okHandler, "/backup").ServeHTTP(w, r)
+
+		if w.Code != test.code {
+			t.Fatalf("[%02d] unexpected code: %v != %v", i, w.Code, test.code)
+		}
+		if !bytes.Equal(w.Body.Bytes(), test.body) {
+			t.Fatalf("[%02d] unexpected body: %q != %q", i, w.Body.String(), string(test.body))
+		}
+		if test.body != nil {
+			if contentType := w.Header().Get(httpContentType); contentType != jsonContentType {
+				t.Fatalf("[%02d] unexpected Content-Type header: %v != %v", i, contentType, jsonContentType)
+			}
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package util
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HSTSHandler wraps a http.Handler served over TLS, and sets the
+// Strict-Transport-Security header on each response, so that clients only
+// connect using HTTPS for the input duration.  If maxAge is 0, the header
+// is not set.
+func HSTSHandler(h http.Handler, maxAge time.Duration) http.Handler {
+	if maxAge <= 0 {
+		return h
+	}
+
+	hsts := "max-age=" + strconv.Itoa(int(maxAge/time.Second)) + "; includeSubDomains"
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Strict-Transport-Security", hsts)
+		h.ServeHTTP(w, r)
+	})
+}
+
+// RedirectHandler returns a http.Handler which permanently redirects each
+// request to the same URL using HTTPS, served on the port of the input TLS
+// server address.
+func RedirectHandler(tlsAddr string) http.Handler {
+	_, port, _ := net.SplitHostPort(tlsAddr)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host, _, err := net.SplitHostPort(r.Host)
+		if err != nil {
+			host = strings.Trim(r.Host, "[]")
+		}
+
+		// The default HTTPS port is omitted from the URL, but IPv6 hosts must
+		// still be bracketed
+		switch {
+		case port != "" && port != "443":
+			host = net.JoinHostPort(host, port)
+		case strings.Contains(host, ":"):
+			host = "[" + host + "]"
+		}
+
+		u := *r.URL
+		u.Scheme = "https"
+		u.Host = host
+
+		http.Redirect(w, r, u.String(), http.StatusMovedPermanently)
+	})
+}
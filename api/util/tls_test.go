@@ -0,0 +1,73 @@
+package util
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// TestHSTSHandler verifies that HSTSHandler sets the Strict-Transport-Security
+// header only when enabled.
+func TestHSTSHandler(t *testing.T) {
+	okHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	var tests = []struct {
+		maxAge time.Duration
+		header string
+	}{
+		{0, ""},
+		{24 * time.Hour, "max-age=86400; includeSubDomains"},
+	}
+
+	for i, test := range tests {
+		r, err := http.NewRequest("GET", "/", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		w := httptest.NewRecorder()
+		HSTSHandler(okHandler, test.maxAge).ServeHTTP(w, r)
+
+		if h := w.Header().Get("Strict-Transport-Security"); h != test.header {
+			t.Fatalf("[%02d] unexpected header: %q != %q", i, h, test.header)
+		}
+	}
+}
+
+// TestRedirectHandler verifies that RedirectHandler redirects requests to the
+// same URL using HTTPS.
+func TestRedirectHandler(t *testing.T) {
+	var tests = []struct {
+		tlsAddr  string
+		url      string
+		location string
+	}{
+		{":443", "http://example.com/api/v0/users?id=1", "https://example.com/api/v0/users?id=1"},
+		{":443", "http://example.com:80/", "https://example.com/"},
+		{":8443", "http://example.com:8080/login", "https://example.com:8443/login"},
+		{"127.0.0.1:8443", "http://[::1]/", "https://[::1]:8443/"},
+		{":443", "http://[::1]/", "https://[::1]/"},
+		{":443", "http://[2001:db8::1]:80/login", "https://[2001:db8::1]/login"},
+		{"[::]:8443", "http://[2001:db8::1]:8080/", "https://[2001:db8::1]:8443/"},
+	}
+
+	for i, test := range tests {
+		r, err := http.NewRequest("POST", test.url, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		w := httptest.NewRecorder()
+		RedirectHandler(test.tlsAddr).ServeHTTP(w, r)
+
+		if w.Code != http.StatusMovedPermanently {
+			t.Fatalf("[%02d] unexpected code: %v != %v", i, w.Code, http.StatusMovedPermanently)
+		}
+		if l := w.Header().Get("Location"); l != test.location {
+			t.Fatalf("[%02d] unexpected location: %q != %q", i, l, test.location)
+		}
+	}
+}
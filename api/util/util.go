@@ -29,6 +29,9 @@ const (
 	NotAuthorized       = "not authorized"
 
 	methodNotAllowed = "method not allowed"
+	queryTimeout     = "request timed out"
+	readonly         = "server is in read-only mode"
+	tooManyRequests  = "too many requests"
 )
 
 // JSON util, map of client errors to response codes.
@@ -37,6 +40,9 @@ var Code = map[string]int{
 	NotAuthorized:       http.StatusUnauthorized,
 
 	methodNotAllowed: http.StatusMethodNotAllowed,
+	queryTimeout:     http.StatusServiceUnavailable,
+	readonly:         http.StatusServiceUnavailable,
+	tooManyRequests:  http.StatusTooManyRequests,
 }
 
 // Generated JSON responses for various client-facing errors.
@@ -66,8 +72,9 @@ type ErrorResponse struct {
 // Error contains a status code and human-readable error message, and is generated for
 // client and server facing errors.
 type Error struct {
-	Code    int    `json:"code"`
-	Message string `json:"message"`
+	Code      int    `json:"code"`
+	Message   string `json:"message"`
+	RequestID string `json:"requestId,omitempty"`
 }
 
 // ErrRes generates and returns an ErrorResponse using the input parameters.
@@ -81,14 +88,25 @@ func ErrRes(code int, message string) *ErrorResponse {
 }
 
 // InternalError contains information which can be used to trace and debug internal
-// server errors, which should not occur during normal operation.
+// server errors, which should not occur during normal operation.  RequestID is
+// set by JSONAPIHandler to the ID of the request which caused the error.
 type InternalError struct {
-	File string
-	Line int
-	Err  error
+	File      string
+	Line      int
+	Err       error
+	RequestID string
 }
 
 // Error returns the string representation of an InternalError.
 func (e *InternalError) Error() string {
+	if e.RequestID != "" {
+		return fmt.Sprintf("[request: %s] %s:%d %s", e.RequestID, filepath.Base(e.File), e.Line, e.Err.Error())
+	}
+
 	return fmt.Sprintf("%s:%d %s", filepath.Base(e.File), e.Line, e.Err.Error())
 }
+
+// Unwrap returns the error wrapped by an InternalError.
+func (e *InternalError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,115 @@
+package v0
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/mdlayher/deltaiota/api/util"
+)
+
+var (
+	// BackupDir is the directory in which database backups created using the
+	// Admin API are stored.  If empty, backups are disabled.
+	BackupDir string
+
+	// BackupRetention is the number of database backups kept in BackupDir;
+	// older backups are removed as new ones are created.  If 0, no backups
+	// are removed.
+	BackupRetention = 7
+
+	// BackupTimeout is the maximum duration of a database backup created using
+	// the Admin API.  Backups may take far longer than other requests, so they
+	// are not bound by util.QueryTimeout.
+	BackupTimeout = 10 * time.Minute
+)
+
+// JSON Admin API, human-readable client error responses.
+const (
+	backupsDisabled = "database backups are not enabled"
+)
+
+// JSON Admin API, map of client errors to response codes.
+var adminCode = map[string]int{
+	backupsDisabled: http.StatusNotImplemented,
+}
+
+// Generated JSON responses for various client-facing errors.
+var adminJSON = map[string][]byte{}
+
+// init initializes the stored JSON responses for client-facing errors.
+func init() {
+	// Iterate all error strings and code integers
+	for k, v := range adminCode {
+		// Generate error response with appropriate string and code
+		body, err := json.Marshal(util.ErrRes(v, k))
+		if err != nil {
+			panic(err)
+		}
+
+		// Store for later use
+		adminJSON[k] = body
+	}
+}
+
+// BackupResponse is the output response for the Admin Backup API.
+type BackupResponse struct {
+	Backup *Backup `json:"backup"`
+}
+
+// Backup contains information about a database backup.
+type Backup struct {
+	Name    string `json:"name"`
+	Size    int64  `json:"size"`
+	Created uint64 `json:"created"`
+}
+
+// BackupAPI is a util.JSONAPIFunc, and is the single entry point for the Admin
+// Backup API.
+// This method delegates to other methods as appropriate to handle incoming requests.
+func (c *Context) BackupAPI(r *http.Request, vars util.Vars) (int, []byte, error) {
+	// Switch based on HTTP method
+	switch r.Method {
+	case "POST":
+		return c.PostBackup(r, vars)
+	default:
+		return util.MethodNotAllowed(r, vars)
+	}
+}
+
+// PostBackup is a util.JSONAPIFunc which creates a consistent backup of the
+// database in BackupDir, and returns HTTP 201 and information about the backup
+// on success, or a non-200 HTTP status code and an error response on failure.
+// Once the backup is complete, backups beyond BackupRetention are removed.
+// The backup runs until BackupTimeout elapses, regardless of the request's own
+// deadline or the client disconnecting.
+func (c *Context) PostBackup(r *http.Request, vars util.Vars) (int, []byte, error) {
+	if BackupDir == "" {
+		return adminCode[backupsDisabled], adminJSON[backupsDisabled], nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(r.Context()), BackupTimeout)
+	defer cancel()
+
+	path, err := c.db.BackupToDir(ctx, BackupDir, BackupRetention)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+
+	stat, err := os.Stat(path)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+
+	body, err := json.Marshal(BackupResponse{
+		Backup: &Backup{
+			Name:    filepath.Base(path),
+			Size:    stat.Size(),
+			Created: uint64(stat.ModTime().Unix()),
+		},
+	})
+	return http.StatusCreated, body, err
+}
@@ -0,0 +1,141 @@
+package v0
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mdlayher/deltaiota/api/util"
+	"github.com/mdlayher/deltaiota/data"
+	"github.com/mdlayher/deltaiota/ditest"
+)
+
+// TestBackupAPI verifies that BackupAPI correctly routes requests to
+// other Admin API handlers, using the input HTTP request.
+func TestBackupAPI(t *testing.T) {
+	withContext(t, func(c *Context) error {
+		var tests = []struct {
+			method string
+			code   int
+		}{
+			// PostBackup, with backups disabled
+			{"POST", http.StatusNotImplemented},
+			// Method not allowed
+			{"GET", http.StatusMethodNotAllowed},
+			{"PUT", http.StatusMethodNotAllowed},
+			{"DELETE", http.StatusMethodNotAllowed},
+		}
+
+		for i, test := range tests {
+			r, err := http.NewRequest(test.method, "/", nil)
+			if err != nil {
+				return err
+			}
+
+			code, _, err := c.BackupAPI(r, util.Vars{})
+			if err != nil {
+				return err
+			}
+
+			if code != test.code {
+				return fmt.Errorf("[%02d] unexpected code: %v != %v", i, code, test.code)
+			}
+		}
+
+		return nil
+	})
+}
+
+// TestPostBackup verifies that PostBackup creates a usable backup of the
+// database, and removes the oldest backups beyond the retention limit.
+func TestPostBackup(t *testing.T) {
+	ctx := context.Background()
+
+	withContext(t, func(c *Context) error {
+		dir, err := ioutil.TempDir("", "deltaiota")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(dir)
+
+		defer func(dir string, keep int) {
+			BackupDir = dir
+			BackupRetention = keep
+		}(BackupDir, BackupRetention)
+		BackupDir = dir
+		BackupRetention = 2
+
+		// Generate older backups, and an unrelated file which must be kept
+		for _, name := range []string{"deltaiota-20000101T000000Z.db", "deltaiota-20000102T000000Z.db", "notes.txt"} {
+			if err := ioutil.WriteFile(filepath.Join(dir, name), nil, 0600); err != nil {
+				return err
+			}
+		}
+
+		user := ditest.MockUser()
+		if err := c.db.InsertUser(ctx, user); err != nil {
+			return err
+		}
+
+		r, err := http.NewRequest("POST", "/", nil)
+		if err != nil {
+			return err
+		}
+
+		// The backup is not bound by the request's deadline, or canceled
+		// when the client disconnects
+		rctx, cancel := context.WithCancel(ctx)
+		cancel()
+		r = r.WithContext(rctx)
+
+		code, body, err := c.PostBackup(r, util.Vars{})
+		if err != nil {
+			return err
+		}
+		if code != http.StatusCreated {
+			return fmt.Errorf("unexpected code: %v != %v", code, http.StatusCreated)
+		}
+
+		var res BackupResponse
+		if err := json.Unmarshal(body, &res); err != nil {
+			return err
+		}
+
+		// Verify the oldest backup was removed
+		backups, err := data.Backups(dir)
+		if err != nil {
+			return err
+		}
+		expected := []string{
+			filepath.Join(dir, "deltaiota-20000102T000000Z.db"),
+			filepath.Join(dir, res.Backup.Name),
+		}
+		if fmt.Sprint(backups) != fmt.Sprint(expected) {
+			return fmt.Errorf("unexpected backups: %v != %v", backups, expected)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "notes.txt")); err != nil {
+			return err
+		}
+
+		// Verify the backup contains the user
+		backup := &data.DB{}
+		if err := backup.Open("sqlite3", filepath.Join(dir, res.Backup.Name), nil); err != nil {
+			return err
+		}
+		defer backup.Close()
+
+		if err := backup.IntegrityCheck(ctx); err != nil {
+			return err
+		}
+		if _, err := backup.SelectUserByID(ctx, user.ID); err != nil {
+			return err
+		}
+
+		return nil
+	})
+}
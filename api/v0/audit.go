@@ -0,0 +1,274 @@
+package v0
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/mdlayher/deltaiota/api/audit"
+	"github.com/mdlayher/deltaiota/api/auth"
+	"github.com/mdlayher/deltaiota/api/util"
+	"github.com/mdlayher/deltaiota/data"
+	"github.com/mdlayher/deltaiota/data/models"
+)
+
+const (
+	// auditDefaultLimit is the number of audit log entries returned per page,
+	// if no limit is specified.
+	auditDefaultLimit = 50
+
+	// auditMaxLimit is the maximum number of audit log entries which may be
+	// returned per page.
+	auditMaxLimit = 500
+)
+
+// JSON Audit API, human-readable client error responses.
+const (
+	// HTTP GET
+	auditInvalidAction = "invalid audit action"
+	auditInvalidFilter = "invalid audit filter"
+	auditInvalidPage   = "invalid audit page"
+)
+
+// JSON Audit API, map of client errors to response codes.
+var auditCode = map[string]int{
+	// HTTP GET
+	auditInvalidAction: http.StatusBadRequest,
+	auditInvalidFilter: http.StatusBadRequest,
+	auditInvalidPage:   http.StatusBadRequest,
+}
+
+// Generated JSON responses for various client-facing errors.
+var auditJSON = map[string][]byte{}
+
+// init initializes the stored JSON responses for client-facing errors.
+func init() {
+	// Iterate all error strings and code integers
+	for k, v := range auditCode {
+		// Generate error response with appropriate string and code
+		body, err := json.Marshal(util.ErrRes(v, k))
+		if err != nil {
+			panic(err)
+		}
+
+		// Store for later use
+		auditJSON[k] = body
+	}
+}
+
+// AuditResponse is the output response for the Audit API.  Total is the number
+// of entries which match the request's filters, across all pages.
+type AuditResponse struct {
+	Entries []*models.AuditEntry `json:"entries"`
+	Total   int                  `json:"total"`
+	Limit   int                  `json:"limit"`
+	Offset  int                  `json:"offset"`
+}
+
+// AuditAPI is a util.JSONAPIFunc, and is the single entry point for the Audit API.
+// This method delegates to other methods as appropriate to handle incoming requests.
+func (c *Context) AuditAPI(r *http.Request, vars util.Vars) (int, []byte, error) {
+	// Switch based on HTTP method
+	switch r.Method {
+	case "GET", "HEAD":
+		return c.ListAuditEntries(r, vars)
+	default:
+		return util.MethodNotAllowed(r, vars)
+	}
+}
+
+// ListAuditEntries is a util.JSONAPIFunc which returns HTTP 200 and a JSON page of
+// audit log entries, newest first, on success, or a non-200 HTTP status code and
+// an error response on failure.
+//
+// Entries may optionally be filtered using the "actorId", "action", "targetType",
+// "targetId", "since", and "until" query parameters, where "since" and "until"
+// are UNIX timestamps.  Pages are selected using the "limit" and "offset" query
+// parameters.
+func (c *Context) ListAuditEntries(r *http.Request, vars util.Vars) (int, []byte, error) {
+	query := r.URL.Query()
+
+	// Parse all numeric filters
+	var filter data.AuditFilter
+	for k, v := range map[string]*uint64{
+		"actorId":  &filter.ActorID,
+		"targetId": &filter.TargetID,
+		"since":    &filter.Since,
+		"until":    &filter.Until,
+	} {
+		s := query.Get(k)
+		if s == "" {
+			continue
+		}
+
+		n, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return auditCode[auditInvalidFilter], auditJSON[auditInvalidFilter], nil
+		}
+		*v = n
+	}
+
+	filter.Action = models.AuditAction(query.Get("action"))
+	filter.TargetType = query.Get("targetType")
+
+	// Verify action filter, if set
+	if filter.Action != "" && !filter.Action.Valid() {
+		return auditCode[auditInvalidAction], auditJSON[auditInvalidAction], nil
+	}
+
+	// Parse page, applying defaults
+	limit, offset := auditDefaultLimit, 0
+	for k, v := range map[string]*int{
+		"limit":  &limit,
+		"offset": &offset,
+	} {
+		s := query.Get(k)
+		if s == "" {
+			continue
+		}
+
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			return auditCode[auditInvalidPage], auditJSON[auditInvalidPage], nil
+		}
+		*v = n
+	}
+	if limit == 0 || limit > auditMaxLimit {
+		return auditCode[auditInvalidPage], auditJSON[auditInvalidPage], nil
+	}
+
+	entries, err := c.db.SelectAuditEntriesByFilter(r.Context(), filter, limit, offset)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+	total, err := c.db.CountAuditEntriesByFilter(r.Context(), filter)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+
+	// Wrap in response and return
+	body, err := json.Marshal(AuditResponse{
+		Entries: entries,
+		Total:   total,
+		Limit:   limit,
+		Offset:  offset,
+	})
+	return http.StatusOK, body, err
+}
+
+// auditID returns the ID of the target of an audited request: the input ID of a
+// created target, or otherwise the ID in the named path variable.
+func auditID(vars util.Vars, name string, id uint64) (uint64, bool) {
+	if id != 0 {
+		return id, true
+	}
+
+	id, err := strconv.ParseUint(vars[name], 10, 64)
+	return id, err == nil
+}
+
+// auditState returns the result of retrieving the target of an audited request,
+// or nil if the target does not exist.
+func auditState[T any](v *T, err error) (interface{}, error) {
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// auditTarget returns an audit.Target which retrieves a target by the ID in
+// the named path variable, using the input function.
+func auditTarget[T any](name string, fn func(ctx context.Context, id uint64) (*T, error)) audit.Target {
+	return func(r *http.Request, vars util.Vars, id uint64) (interface{}, error) {
+		id, ok := auditID(vars, name, id)
+		if !ok {
+			return nil, nil
+		}
+
+		return auditState(fn(r.Context(), id))
+	}
+}
+
+// auditUser is an audit.Target which retrieves a User, including a deleted
+// User, so that restored and purged users are recorded.
+func (c *Context) auditUser(r *http.Request, vars util.Vars, id uint64) (interface{}, error) {
+	id, ok := auditID(vars, "id", id)
+	if !ok {
+		return nil, nil
+	}
+
+	user, err := c.db.SelectUserByID(r.Context(), id)
+	if err == sql.ErrNoRows {
+		user, err = c.db.SelectDeletedUserByID(r.Context(), id)
+	}
+
+	return auditState(user, err)
+}
+
+// auditCalendarToken is an audit.Target which retrieves the requesting user's
+// calendar feed token.
+func (c *Context) auditCalendarToken(r *http.Request, vars util.Vars, id uint64) (interface{}, error) {
+	user, ok := auth.User(r)
+	if !ok {
+		return nil, nil
+	}
+
+	return auditState(c.db.SelectCalendarTokenByUserID(r.Context(), user.ID))
+}
+
+// auditCommitteeMember is an audit.Target which retrieves a user's membership
+// in a committee, identified by the "id" and "userId" path variables.
+func (c *Context) auditCommitteeMember(r *http.Request, vars util.Vars, id uint64) (interface{}, error) {
+	committeeID, ok := auditID(vars, "id", 0)
+	if !ok {
+		return nil, nil
+	}
+	userID, ok := auditID(vars, "userId", 0)
+	if !ok {
+		return nil, nil
+	}
+
+	return auditState(c.db.SelectCommitteeMember(r.Context(), committeeID, userID))
+}
+
+// auditRSVP is an audit.Target which retrieves the requesting user's RSVP to
+// the event identified by the "id" path variable.
+func (c *Context) auditRSVP(r *http.Request, vars util.Vars, id uint64) (interface{}, error) {
+	user, ok := auth.User(r)
+	if !ok {
+		return nil, nil
+	}
+	eventID, ok := auditID(vars, "id", 0)
+	if !ok {
+		return nil, nil
+	}
+
+	rsvps, err := c.db.SelectRSVPsByEventID(r.Context(), eventID)
+	if err != nil {
+		return nil, err
+	}
+	for _, rsvp := range rsvps {
+		if rsvp.UserID == user.ID {
+			return rsvp, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// auditInvitation is an audit.Target which retrieves an invitation by ID, or
+// by the "token" path variable when an invitation is accepted.
+func (c *Context) auditInvitation(r *http.Request, vars util.Vars, id uint64) (interface{}, error) {
+	if token, ok := vars["token"]; ok {
+		return auditState(c.db.SelectInvitationByToken(r.Context(), token))
+	}
+
+	return auditTarget("id", c.db.SelectInvitationByID)(r, vars, id)
+}
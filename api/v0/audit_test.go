@@ -0,0 +1,118 @@
+package v0
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/mdlayher/deltaiota/api/util"
+	"github.com/mdlayher/deltaiota/data/models"
+)
+
+// TestListAuditEntries verifies that ListAuditEntries filters and pages the
+// audit log.
+func TestListAuditEntries(t *testing.T) {
+	ctx := context.Background()
+
+	withContext(t, func(c *Context) error {
+		// Generate audit log entries, alternating between two actors
+		for i := 1; i <= 5; i++ {
+			if err := c.db.InsertAuditEntry(ctx, &models.AuditEntry{
+				ActorID:    uint64(i%2 + 1),
+				Action:     models.AuditUpdate,
+				TargetType: "user",
+				TargetID:   uint64(i),
+				Created:    uint64(1000 * i),
+			}); err != nil {
+				return err
+			}
+		}
+
+		// Table of tests to iterate
+		var tests = []struct {
+			query      string
+			code       int
+			errMessage string
+			ids        []uint64
+			total      int
+		}{
+			// Invalid filters
+			{"actorId=foo", http.StatusBadRequest, auditInvalidFilter, nil, 0},
+			{"action=foo", http.StatusBadRequest, auditInvalidAction, nil, 0},
+			{"limit=-1", http.StatusBadRequest, auditInvalidPage, nil, 0},
+			{"limit=0", http.StatusBadRequest, auditInvalidPage, nil, 0},
+			{"limit=501", http.StatusBadRequest, auditInvalidPage, nil, 0},
+			// All entries, newest first
+			{"", http.StatusOK, "", []uint64{5, 4, 3, 2, 1}, 5},
+			// Filter by actor
+			{"actorId=1", http.StatusOK, "", []uint64{4, 2}, 2},
+			// Filter by action
+			{"action=delete", http.StatusOK, "", nil, 0},
+			// Filter by target
+			{"targetType=user&targetId=3", http.StatusOK, "", []uint64{3}, 1},
+			// Filter by time
+			{"since=2000&until=4000", http.StatusOK, "", []uint64{3, 2}, 2},
+			// Page
+			{"limit=2&offset=1", http.StatusOK, "", []uint64{4, 3}, 5},
+		}
+
+		// Iterate and run tests
+		for i, test := range tests {
+			// Generate HTTP request
+			r, err := http.NewRequest("GET", "/?"+test.query, nil)
+			if err != nil {
+				return err
+			}
+
+			code, body, err := c.ListAuditEntries(r, util.Vars{})
+			if err != nil {
+				return err
+			}
+
+			// Ensure proper HTTP status code
+			if code != test.code {
+				return fmt.Errorf("[%02d] unexpected code: %v != %v", i, code, test.code)
+			}
+
+			// If code is in HTTP 400 or above, check error response
+			if code >= http.StatusBadRequest {
+				var errRes util.ErrorResponse
+				if err := json.Unmarshal(body, &errRes); err != nil {
+					return err
+				}
+
+				if errRes.Error.Message != test.errMessage {
+					return fmt.Errorf("[%02d] unexpected error message: %v != %v", i, errRes.Error.Message, test.errMessage)
+				}
+
+				continue
+			}
+
+			var aRes AuditResponse
+			if err := json.Unmarshal(body, &aRes); err != nil {
+				return err
+			}
+
+			if aRes.Total != test.total {
+				return fmt.Errorf("[%02d] unexpected total: %v != %v", i, aRes.Total, test.total)
+			}
+
+			var ids []uint64
+			for _, e := range aRes.Entries {
+				ids = append(ids, e.ID)
+			}
+			if fmt.Sprint(ids) != fmt.Sprint(test.ids) {
+				return fmt.Errorf("[%02d] unexpected entries: %v != %v", i, ids, test.ids)
+			}
+		}
+
+		// The audit log is append-only
+		if _, err := c.db.Exec("DELETE FROM audit_log;"); err == nil {
+			return fmt.Errorf("audit log entries were deleted")
+		}
+
+		return nil
+	})
+}
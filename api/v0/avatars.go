@@ -0,0 +1,377 @@
+package v0
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/mdlayher/deltaiota/api/util"
+	"github.com/mdlayher/deltaiota/blob"
+	"github.com/mdlayher/deltaiota/data/models"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	// avatarMaxBytes is the maximum size, in bytes, of an uploaded avatar image.
+	avatarMaxBytes = 5 << 20
+
+	// avatarMaxPixels is the maximum number of pixels in an uploaded avatar image,
+	// used to reject images which would consume excessive memory when decoded.
+	avatarMaxPixels = 4096 * 4096
+
+	// avatarDefaultSize is the size of avatar thumbnail returned when no size
+	// is specified.
+	avatarDefaultSize = 128
+
+	// avatarJPEGQuality is the quality used to encode JPEG avatar thumbnails.
+	avatarJPEGQuality = 85
+
+	// Content types of supported avatar images.
+	avatarJPEG = "image/jpeg"
+	avatarPNG  = "image/png"
+)
+
+// avatarSizes are the widths and heights, in pixels, of the square thumbnails
+// generated for each avatar, in descending order.
+var avatarSizes = []int{512, 128, 32}
+
+// JSON Avatars API, human-readable client error responses.
+const (
+	avatarInvalidImage = "invalid avatar image"
+	avatarInvalidSize  = "invalid avatar size"
+	avatarNotFound     = "avatar not found"
+	avatarTooLarge     = "avatar too large"
+	avatarUnsupported  = "unsupported avatar type, must be JPEG or PNG"
+)
+
+// JSON Avatars API, map of client errors to response codes.
+var avatarsCode = map[string]int{
+	avatarInvalidImage: http.StatusBadRequest,
+	avatarInvalidSize:  http.StatusBadRequest,
+	avatarNotFound:     http.StatusNotFound,
+	avatarTooLarge:     http.StatusRequestEntityTooLarge,
+	avatarUnsupported:  http.StatusUnsupportedMediaType,
+}
+
+// Generated JSON responses for various client-facing errors.
+var avatarsJSON = map[string][]byte{}
+
+// init initializes the stored JSON responses for client-facing errors.
+func init() {
+	// Iterate all error strings and code integers
+	for k, v := range avatarsCode {
+		// Generate error response with appropriate string and code
+		body, err := json.Marshal(util.ErrRes(v, k))
+		if err != nil {
+			panic(err)
+		}
+
+		// Store for later use
+		avatarsJSON[k] = body
+	}
+}
+
+// AvatarsAPI is a util.JSONAPIFunc, and is the single entry point for all
+// non-GET methods for the Avatars API.  The GET endpoint is separate because
+// it responds with image data, rather than JSON.
+// This method delegates to other methods as appropriate to handle incoming requests.
+func (c *Context) AvatarsAPI(r *http.Request, vars util.Vars) (int, []byte, error) {
+	// Switch based on HTTP method
+	switch r.Method {
+	case "PUT":
+		return c.PutAvatar(r, vars)
+	case "DELETE":
+		return c.DeleteAvatar(r, vars)
+	default:
+		return util.MethodNotAllowed(r, vars)
+	}
+}
+
+// GetAvatar is a http.HandlerFunc which writes HTTP 200 and a user's avatar
+// thumbnail on success, or a non-200 HTTP status code and a JSON error response
+// on failure.  The thumbnail size may be chosen using the "size" query parameter.
+func (c *Context) GetAvatar(w http.ResponseWriter, r *http.Request) {
+	// Write a JSON error response to the client
+	writeErr := func(code int, body []byte) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(code)
+		if r.Method != "HEAD" {
+			w.Write(util.AddRequestID(r, body))
+		}
+	}
+
+	// Fetch the user who owns the avatar
+	user, code, body, err := c.userFromVars(r.Context(), util.Vars(mux.Vars(r)))
+	if err != nil {
+		util.Logger(r).Error("request error", "err", err)
+		writeErr(util.Code[util.InternalServerError], util.JSON[util.InternalServerError])
+		return
+	}
+	if body != nil {
+		writeErr(code, body)
+		return
+	}
+
+	// Check if user has an avatar
+	if user.Avatar == "" {
+		writeErr(avatarsCode[avatarNotFound], avatarsJSON[avatarNotFound])
+		return
+	}
+
+	// Determine requested thumbnail size
+	size := avatarDefaultSize
+	if s := r.URL.Query().Get("size"); s != "" {
+		size, err = strconv.Atoi(s)
+		if err != nil || !validAvatarSize(size) {
+			writeErr(avatarsCode[avatarInvalidSize], avatarsJSON[avatarInvalidSize])
+			return
+		}
+	}
+
+	// Retrieve thumbnail from storage
+	rc, err := c.store.Get(avatarKey(user.ID, size))
+	if err != nil {
+		if err == blob.ErrNotExist {
+			writeErr(avatarsCode[avatarNotFound], avatarsJSON[avatarNotFound])
+			return
+		}
+
+		util.Logger(r).Error("request error", "err", err)
+		writeErr(util.Code[util.InternalServerError], util.JSON[util.InternalServerError])
+		return
+	}
+	defer rc.Close()
+
+	// Stream thumbnail to client
+	w.Header().Set("Content-Type", user.Avatar)
+	w.Header().Set("Cache-Control", "private, max-age=300")
+	w.WriteHeader(http.StatusOK)
+	if r.Method == "HEAD" {
+		return
+	}
+
+	if _, err := io.Copy(w, rc); err != nil {
+		util.Logger(r).Error("request error", "err", err)
+	}
+}
+
+// PutAvatar is a util.JSONAPIFunc which stores a new avatar for a User and returns
+// HTTP 200 and a JSON user object on success, or a non-200 HTTP status code and
+// an error response on failure.  The request body must contain a JPEG or PNG image.
+func (c *Context) PutAvatar(r *http.Request, vars util.Vars) (int, []byte, error) {
+	// Fetch the user who owns the avatar
+	user, code, body, err := c.userFromVars(r.Context(), vars)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+	if body != nil {
+		return code, body, nil
+	}
+
+	// Do not allow nil body
+	if r.Body == nil {
+		return avatarsCode[avatarInvalidImage], avatarsJSON[avatarInvalidImage], nil
+	}
+
+	// Read image, up to one byte more than the maximum, to detect overly large
+	// images without reading the entire request body
+	buf, err := ioutil.ReadAll(io.LimitReader(r.Body, avatarMaxBytes+1))
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+	if len(buf) > avatarMaxBytes {
+		return avatarsCode[avatarTooLarge], avatarsJSON[avatarTooLarge], nil
+	}
+
+	// Sniff content type of image, rather than trusting the client
+	contentType := http.DetectContentType(buf)
+	if contentType != avatarJPEG && contentType != avatarPNG {
+		return avatarsCode[avatarUnsupported], avatarsJSON[avatarUnsupported], nil
+	}
+
+	// Check image dimensions before decoding the entire image
+	config, _, err := image.DecodeConfig(bytes.NewReader(buf))
+	if err != nil || config.Width == 0 || config.Height == 0 || config.Width*config.Height > avatarMaxPixels {
+		return avatarsCode[avatarInvalidImage], avatarsJSON[avatarInvalidImage], nil
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(buf))
+	if err != nil {
+		return avatarsCode[avatarInvalidImage], avatarsJSON[avatarInvalidImage], nil
+	}
+
+	// Generate and store thumbnails, from largest to smallest, so that each
+	// smaller thumbnail can be scaled from the previous one
+	thumb := src
+	for _, size := range avatarSizes {
+		thumb = thumbnail(thumb, size)
+
+		tbuf := bytes.NewBuffer(nil)
+		if err := encodeAvatar(tbuf, thumb, contentType); err != nil {
+			return util.JSONAPIErr(err)
+		}
+
+		if err := c.store.Put(avatarKey(user.ID, size), tbuf); err != nil {
+			return util.JSONAPIErr(err)
+		}
+	}
+
+	// Record avatar content type for user
+	user.Avatar = contentType
+	if err := c.db.UpdateUser(r.Context(), user); err != nil {
+		return util.JSONAPIErr(err)
+	}
+
+	// Strip sensitive fields from output, and apply privacy settings
+	p, err := c.privacy(r)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+	sanitizeUser(user, p)
+
+	// Wrap in response and return
+	body, err = json.Marshal(UsersResponse{
+		Users: []*models.User{user},
+	})
+	return http.StatusOK, body, err
+}
+
+// DeleteAvatar is a util.JSONAPIFunc which deletes a User's avatar and returns
+// HTTP 204 on success, or a non-200 HTTP status code and an error response on failure.
+func (c *Context) DeleteAvatar(r *http.Request, vars util.Vars) (int, []byte, error) {
+	// Fetch the user who owns the avatar
+	user, code, body, err := c.userFromVars(r.Context(), vars)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+	if body != nil {
+		return code, body, nil
+	}
+
+	// Check if user has an avatar
+	if user.Avatar == "" {
+		return avatarsCode[avatarNotFound], avatarsJSON[avatarNotFound], nil
+	}
+
+	// Clear avatar for user, and remove its thumbnails
+	user.Avatar = ""
+	if err := c.db.UpdateUser(r.Context(), user); err != nil {
+		return util.JSONAPIErr(err)
+	}
+	if err := c.deleteAvatarBlobs(user.ID); err != nil {
+		return util.JSONAPIErr(err)
+	}
+
+	return http.StatusNoContent, nil, nil
+}
+
+// deleteAvatarBlobs removes all avatar thumbnails for the input user ID from
+// storage.  Thumbnails which do not exist are ignored.
+func (c *Context) deleteAvatarBlobs(userID uint64) error {
+	for _, size := range avatarSizes {
+		if err := c.store.Delete(avatarKey(userID, size)); err != nil && err != blob.ErrNotExist {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// avatarKey returns the blob.Store key for the avatar thumbnail of the input
+// user ID and size.
+func avatarKey(userID uint64, size int) string {
+	return fmt.Sprintf("avatars/%d/%d", userID, size)
+}
+
+// validAvatarSize returns whether or not the input size is one of the generated
+// avatar thumbnail sizes.
+func validAvatarSize(size int) bool {
+	for _, s := range avatarSizes {
+		if s == size {
+			return true
+		}
+	}
+
+	return false
+}
+
+// encodeAvatar encodes an avatar thumbnail into the input io.Writer, using the
+// image format which matches the input content type.
+func encodeAvatar(w io.Writer, img image.Image, contentType string) error {
+	if contentType == avatarPNG {
+		return png.Encode(w, img)
+	}
+
+	return jpeg.Encode(w, img, &jpeg.Options{Quality: avatarJPEGQuality})
+}
+
+// thumbnail crops the center square from an input image, and scales it to a
+// square image of the input size.  Each output pixel is the average of all
+// input pixels it covers, which produces smooth results when downscaling.
+func thumbnail(src image.Image, size int) *image.RGBA {
+	// Determine the largest centered square within the source image
+	b := src.Bounds()
+	side := b.Dx()
+	if b.Dy() < side {
+		side = b.Dy()
+	}
+	x0 := b.Min.X + (b.Dx()-side)/2
+	y0 := b.Min.Y + (b.Dy()-side)/2
+
+	// Copy the square into an RGBA image, for fast pixel access
+	square := image.NewRGBA(image.Rect(0, 0, side, side))
+	draw.Draw(square, square.Bounds(), src, image.Pt(x0, y0), draw.Src)
+
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+	for y := 0; y < size; y++ {
+		// Source rows covered by this destination row, always at least one
+		sy0 := y * side / size
+		sy1 := (y + 1) * side / size
+		if sy1 <= sy0 {
+			sy1 = sy0 + 1
+		}
+
+		for x := 0; x < size; x++ {
+			// Source columns covered by this destination column, always at
+			// least one
+			sx0 := x * side / size
+			sx1 := (x + 1) * side / size
+			if sx1 <= sx0 {
+				sx1 = sx0 + 1
+			}
+
+			// Average all covered source pixels
+			var r, g, bl, a, n uint32
+			for sy := sy0; sy < sy1; sy++ {
+				i := square.PixOffset(sx0, sy)
+				for sx := sx0; sx < sx1; sx++ {
+					r += uint32(square.Pix[i+0])
+					g += uint32(square.Pix[i+1])
+					bl += uint32(square.Pix[i+2])
+					a += uint32(square.Pix[i+3])
+					n++
+					i += 4
+				}
+			}
+
+			dst.SetRGBA(x, y, color.RGBA{
+				R: uint8(r / n),
+				G: uint8(g / n),
+				B: uint8(bl / n),
+				A: uint8(a / n),
+			})
+		}
+	}
+
+	return dst
+}
@@ -0,0 +1,378 @@
+package v0
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mdlayher/deltaiota/api/util"
+	"github.com/mdlayher/deltaiota/data/models"
+
+	"github.com/gorilla/mux"
+)
+
+// TestAvatarsAPI verifies that AvatarsAPI correctly routes requests to
+// other Avatars API handlers, using the input HTTP request.
+func TestAvatarsAPI(t *testing.T) {
+	withContext(t, func(c *Context) error {
+		var tests = []struct {
+			method string
+			code   int
+		}{
+			// PutAvatar
+			{"PUT", http.StatusBadRequest},
+			// DeleteAvatar
+			{"DELETE", http.StatusBadRequest},
+			// Unknown method
+			{"CAT", http.StatusMethodNotAllowed},
+		}
+
+		for _, test := range tests {
+			// Generate HTTP request
+			r, err := http.NewRequest(test.method, "/", nil)
+			if err != nil {
+				return err
+			}
+
+			// Delegate to appropriate handler
+			code, _, err := c.AvatarsAPI(r, util.Vars{})
+			if err != nil {
+				return err
+			}
+
+			// Ensure proper HTTP status code
+			if code != test.code {
+				return fmt.Errorf("unexpected code: %v != %v", code, test.code)
+			}
+		}
+
+		return nil
+	})
+}
+
+// TestPutAvatar verifies that PutAvatar returns the appropriate HTTP status
+// code, body, and any errors which occur.
+func TestPutAvatar(t *testing.T) {
+	ctx := context.Background()
+
+	withContextUser(t, func(c *Context, user *models.User) error {
+		// Generate valid PNG and JPEG images, which are not square
+		pngImage := bytes.NewBuffer(nil)
+		if err := png.Encode(pngImage, mockImage(640, 480)); err != nil {
+			return err
+		}
+		jpegImage := bytes.NewBuffer(nil)
+		if err := jpeg.Encode(jpegImage, mockImage(200, 300), nil); err != nil {
+			return err
+		}
+
+		// Table of tests to iterate
+		var tests = []struct {
+			id          string
+			code        int
+			errMessage  string
+			body        []byte
+			contentType string
+		}{
+			// ID not found
+			{"2", http.StatusNotFound, userNotFound, nil, ""},
+			// Empty body
+			{"1", http.StatusUnsupportedMediaType, avatarUnsupported, nil, ""},
+			// Not an image
+			{"1", http.StatusUnsupportedMediaType, avatarUnsupported, []byte("hello world"), ""},
+			// Corrupt PNG
+			{"1", http.StatusBadRequest, avatarInvalidImage, pngImage.Bytes()[:64], ""},
+			// Too large
+			{"1", http.StatusRequestEntityTooLarge, avatarTooLarge, make([]byte, avatarMaxBytes+1), ""},
+			// Valid PNG
+			{"1", http.StatusOK, "", pngImage.Bytes(), avatarPNG},
+			// Valid JPEG
+			{"1", http.StatusOK, "", jpegImage.Bytes(), avatarJPEG},
+		}
+
+		// Iterate and run tests
+		for _, test := range tests {
+			// Generate HTTP request
+			r, err := http.NewRequest("PUT", "/", bytes.NewReader(test.body))
+			if err != nil {
+				return err
+			}
+
+			// Invoke PutAvatar with HTTP request, manually injecting
+			// path variables from test
+			code, body, err := c.PutAvatar(r, util.Vars{"id": test.id})
+			if err != nil {
+				return err
+			}
+
+			// Ensure proper HTTP status code
+			if code != test.code {
+				return fmt.Errorf("unexpected code: %v != %v", code, test.code)
+			}
+
+			// If code is in HTTP 400 or above, check error response
+			if code >= http.StatusBadRequest {
+				var errRes util.ErrorResponse
+				if err := json.Unmarshal(body, &errRes); err != nil {
+					return err
+				}
+
+				if errRes.Error.Message != test.errMessage {
+					return fmt.Errorf("unexpected error message: %v != %v", errRes.Error.Message, test.errMessage)
+				}
+
+				continue
+			}
+
+			// Unmarshal response body, verify avatar URL is set
+			var res UsersResponse
+			if err := json.Unmarshal(body, &res); err != nil {
+				return err
+			}
+			if url := APIPrefix + "/users/1/avatar"; res.Users[0].AvatarURL != url {
+				return fmt.Errorf("unexpected avatar URL: %v != %v", res.Users[0].AvatarURL, url)
+			}
+
+			// Verify content type was stored for user
+			u, err := c.db.SelectUserByID(ctx, user.ID)
+			if err != nil {
+				return err
+			}
+			if u.Avatar != test.contentType {
+				return fmt.Errorf("unexpected avatar content type: %v != %v", u.Avatar, test.contentType)
+			}
+
+			// Verify all thumbnails were generated at the correct size
+			for _, size := range avatarSizes {
+				rc, err := c.store.Get(avatarKey(user.ID, size))
+				if err != nil {
+					return err
+				}
+
+				config, _, err := image.DecodeConfig(rc)
+				rc.Close()
+				if err != nil {
+					return err
+				}
+
+				if config.Width != size || config.Height != size {
+					return fmt.Errorf("unexpected thumbnail size: %dx%d != %dx%d", config.Width, config.Height, size, size)
+				}
+			}
+		}
+
+		return nil
+	})
+}
+
+// TestGetAvatar verifies that GetAvatar returns the appropriate HTTP status
+// code, headers, and body.
+func TestGetAvatar(t *testing.T) {
+	withContextUser(t, func(c *Context, user *models.User) error {
+		// Route requests so that path variables are set
+		m := mux.NewRouter()
+		m.HandleFunc("/users/{id}/avatar", c.GetAvatar)
+
+		// Avatar does not exist yet
+		if code := testGetAvatar(m, "/users/1/avatar"); code != http.StatusNotFound {
+			return fmt.Errorf("unexpected code: %v != %v", code, http.StatusNotFound)
+		}
+
+		// Upload an avatar
+		pngImage := bytes.NewBuffer(nil)
+		if err := png.Encode(pngImage, mockImage(64, 64)); err != nil {
+			return err
+		}
+		r, err := http.NewRequest("PUT", "/", pngImage)
+		if err != nil {
+			return err
+		}
+		if code, _, err := c.PutAvatar(r, util.Vars{"id": "1"}); err != nil || code != http.StatusOK {
+			return fmt.Errorf("failed to upload avatar: %v, %v", code, err)
+		}
+
+		// Table of tests to iterate
+		var tests = []struct {
+			path string
+			code int
+			size int
+		}{
+			// ID not found
+			{"/users/2/avatar", http.StatusNotFound, 0},
+			// Invalid size
+			{"/users/1/avatar?size=10", http.StatusBadRequest, 0},
+			{"/users/1/avatar?size=foo", http.StatusBadRequest, 0},
+			// Default size
+			{"/users/1/avatar", http.StatusOK, avatarDefaultSize},
+			// Explicit sizes
+			{"/users/1/avatar?size=32", http.StatusOK, 32},
+			{"/users/1/avatar?size=512", http.StatusOK, 512},
+		}
+
+		for _, test := range tests {
+			r, err := http.NewRequest("GET", test.path, nil)
+			if err != nil {
+				return err
+			}
+
+			w := httptest.NewRecorder()
+			m.ServeHTTP(w, r)
+
+			// Ensure proper HTTP status code
+			if w.Code != test.code {
+				return fmt.Errorf("%s: unexpected code: %v != %v", test.path, w.Code, test.code)
+			}
+			if w.Code != http.StatusOK {
+				continue
+			}
+
+			// Verify image type and size
+			if ct := w.Header().Get("Content-Type"); ct != avatarPNG {
+				return fmt.Errorf("unexpected Content-Type: %v != %v", ct, avatarPNG)
+			}
+			config, err := png.DecodeConfig(w.Body)
+			if err != nil {
+				return err
+			}
+			if config.Width != test.size {
+				return fmt.Errorf("unexpected thumbnail size: %v != %v", config.Width, test.size)
+			}
+		}
+
+		return nil
+	})
+}
+
+// TestDeleteAvatar verifies that DeleteAvatar removes a user's avatar and
+// all of its thumbnails.
+func TestDeleteAvatar(t *testing.T) {
+	ctx := context.Background()
+
+	withContextUser(t, func(c *Context, user *models.User) error {
+		// Avatar does not exist yet
+		code, _, err := c.DeleteAvatar(httptest.NewRequest("DELETE", "/", nil), util.Vars{"id": "1"})
+		if err != nil {
+			return err
+		}
+		if code != http.StatusNotFound {
+			return fmt.Errorf("unexpected code: %v != %v", code, http.StatusNotFound)
+		}
+
+		// Upload an avatar
+		jpegImage := bytes.NewBuffer(nil)
+		if err := jpeg.Encode(jpegImage, mockImage(64, 64), nil); err != nil {
+			return err
+		}
+		r, err := http.NewRequest("PUT", "/", jpegImage)
+		if err != nil {
+			return err
+		}
+		if code, _, err := c.PutAvatar(r, util.Vars{"id": "1"}); err != nil || code != http.StatusOK {
+			return fmt.Errorf("failed to upload avatar: %v, %v", code, err)
+		}
+
+		// Delete the avatar
+		code, _, err = c.DeleteAvatar(httptest.NewRequest("DELETE", "/", nil), util.Vars{"id": "1"})
+		if err != nil {
+			return err
+		}
+		if code != http.StatusNoContent {
+			return fmt.Errorf("unexpected code: %v != %v", code, http.StatusNoContent)
+		}
+
+		// Verify avatar cleared and thumbnails removed
+		u, err := c.db.SelectUserByID(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+		if u.Avatar != "" {
+			return fmt.Errorf("avatar not cleared: %v", u.Avatar)
+		}
+		for _, size := range avatarSizes {
+			if _, err := c.store.Get(avatarKey(user.ID, size)); err == nil {
+				return fmt.Errorf("thumbnail not removed: %d", size)
+			}
+		}
+
+		return nil
+	})
+}
+
+// Test_thumbnail verifies that thumbnail crops and scales images to the
+// appropriate size, averaging source pixels.
+func Test_thumbnail(t *testing.T) {
+	// Left half of a wide image is red, right half is blue, so the centered
+	// square is half red and half blue
+	src := image.NewRGBA(image.Rect(0, 0, 8, 4))
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 8; x++ {
+			c := color.RGBA{R: 255, A: 255}
+			if x >= 4 {
+				c = color.RGBA{B: 255, A: 255}
+			}
+			src.SetRGBA(x, y, c)
+		}
+	}
+
+	// Downscale to 2x2, each column is a single color
+	dst := thumbnail(src, 2)
+	if b := dst.Bounds(); b.Dx() != 2 || b.Dy() != 2 {
+		t.Fatalf("unexpected bounds: %v", b)
+	}
+	if c := dst.RGBAAt(0, 0); c != (color.RGBA{R: 255, A: 255}) {
+		t.Fatalf("unexpected left pixel: %v", c)
+	}
+	if c := dst.RGBAAt(1, 1); c != (color.RGBA{B: 255, A: 255}) {
+		t.Fatalf("unexpected right pixel: %v", c)
+	}
+
+	// Downscale to 1x1, colors are averaged
+	dst = thumbnail(src, 1)
+	if c := dst.RGBAAt(0, 0); c != (color.RGBA{R: 127, B: 127, A: 255}) {
+		t.Fatalf("unexpected averaged pixel: %v", c)
+	}
+
+	// Upscale to 8x8, no panic and correct bounds
+	dst = thumbnail(src, 8)
+	if b := dst.Bounds(); b.Dx() != 8 || b.Dy() != 8 {
+		t.Fatalf("unexpected bounds: %v", b)
+	}
+}
+
+// testGetAvatar performs a HTTP GET request against an input handler, and
+// returns the HTTP status code.
+func testGetAvatar(h http.Handler, path string) int {
+	r, err := http.NewRequest("GET", path, nil)
+	if err != nil {
+		panic(err)
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w.Code
+}
+
+// mockImage generates a gradient image of the input dimensions, used for testing.
+func mockImage(width int, height int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.SetRGBA(x, y, color.RGBA{
+				R: uint8(x),
+				G: uint8(y),
+				B: 128,
+				A: 255,
+			})
+		}
+	}
+
+	return img
+}
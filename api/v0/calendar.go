@@ -0,0 +1,270 @@
+package v0
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mdlayher/deltaiota/api/auth"
+	"github.com/mdlayher/deltaiota/api/util"
+	"github.com/mdlayher/deltaiota/data/models"
+	"github.com/mdlayher/deltaiota/ical"
+)
+
+const (
+	// calendarProdID is the iCalendar product identifier for calendar feeds.
+	calendarProdID = "-//Phi Mu Alpha Sinfonia - Delta Iota//deltaiota//EN"
+
+	// calendarName is the display name for calendar feeds.
+	calendarName = "Delta Iota"
+
+	// calendarUIDDomain is the domain used to generate globally unique
+	// identifiers for events in calendar feeds.
+	calendarUIDDomain = "deltaiota"
+)
+
+// JSON Calendar API, human-readable client error responses.
+const (
+	// HTTP GET
+	calendarInvalidFilter   = "invalid calendar filter"
+	calendarInvalidTimeZone = "invalid calendar time zone"
+)
+
+// JSON Calendar API, map of client errors to response codes.
+var calendarCode = map[string]int{
+	// HTTP GET
+	calendarInvalidFilter:   http.StatusBadRequest,
+	calendarInvalidTimeZone: http.StatusBadRequest,
+}
+
+// Generated JSON responses for various client-facing errors.
+var calendarJSON = map[string][]byte{}
+
+// init initializes the stored JSON responses for client-facing errors.
+func init() {
+	// Iterate all error strings and code integers
+	for k, v := range calendarCode {
+		// Generate error response with appropriate string and code
+		body, err := json.Marshal(util.ErrRes(v, k))
+		if err != nil {
+			panic(err)
+		}
+
+		// Store for later use
+		calendarJSON[k] = body
+	}
+}
+
+// CalendarTokenResponse is the output response for the Calendar Token API.
+type CalendarTokenResponse struct {
+	Token *models.CalendarToken `json:"token"`
+}
+
+// CalendarTokenAPI is a util.JSONAPIFunc, and is the single entry point for
+// managing the authenticated user's calendar feed token.
+// This method delegates to other methods as appropriate to handle incoming requests.
+func (c *Context) CalendarTokenAPI(r *http.Request, vars util.Vars) (int, []byte, error) {
+	// Switch based on HTTP method
+	switch r.Method {
+	case "GET", "HEAD":
+		return c.GetCalendarToken(r, vars)
+	case "POST":
+		return c.PostCalendarToken(r, vars)
+	default:
+		return util.MethodNotAllowed(r, vars)
+	}
+}
+
+// GetCalendarToken is a util.JSONAPIFunc which returns HTTP 200 and the authenticated
+// user's calendar feed token on success, or a non-200 HTTP status code and an error
+// response on failure.  If the user has no token, one is generated.
+func (c *Context) GetCalendarToken(r *http.Request, vars util.Vars) (int, []byte, error) {
+	user, code, body := authUser(r)
+	if body != nil {
+		return code, body, nil
+	}
+
+	// Fetch existing token, generating one if none exists
+	token, err := c.db.SelectCalendarTokenByUserID(r.Context(), user.ID)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			return util.JSONAPIErr(err)
+		}
+
+		token, err = c.newCalendarToken(r.Context(), user.ID)
+		if err != nil {
+			return util.JSONAPIErr(err)
+		}
+	}
+
+	// Wrap in response and return
+	body, err = json.Marshal(CalendarTokenResponse{
+		Token: token,
+	})
+	return http.StatusOK, body, err
+}
+
+// PostCalendarToken is a util.JSONAPIFunc which generates a new calendar feed token
+// for the authenticated user, revoking any existing token, and returns HTTP 201 and
+// the new token on success, or a non-200 HTTP status code and an error response on
+// failure.
+func (c *Context) PostCalendarToken(r *http.Request, vars util.Vars) (int, []byte, error) {
+	user, code, body := authUser(r)
+	if body != nil {
+		return code, body, nil
+	}
+
+	token, err := c.newCalendarToken(r.Context(), user.ID)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+
+	// Wrap in response and return
+	body, err = json.Marshal(CalendarTokenResponse{
+		Token: token,
+	})
+	return http.StatusCreated, body, err
+}
+
+// newCalendarToken generates and stores a new calendar feed token for the user
+// with the input ID, replacing any existing token.
+func (c *Context) newCalendarToken(ctx context.Context, userID uint64) (*models.CalendarToken, error) {
+	token, err := models.NewCalendarToken(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return token, c.db.SetCalendarToken(ctx, token)
+}
+
+// GetCalendar is a http.HandlerFunc which writes HTTP 200 and an iCalendar feed of
+// chapter events on success, or a non-200 HTTP status code and a JSON error response
+// on failure.
+//
+// By default, all events are included, with times in UTC.  The "filter" query
+// parameter may be set to "rsvp" to include only events which the user has
+// responded yes or maybe to, and the "tz" query parameter may be set to an IANA
+// time zone name, such as "America/Chicago", to express times in that zone.
+//
+// An ETag is generated from the feed's content, so that calendar applications
+// which poll the feed may use conditional requests.
+func (c *Context) GetCalendar(w http.ResponseWriter, r *http.Request) {
+	// Write a JSON error response to the client
+	writeErr := func(code int, body []byte) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(code)
+		if r.Method != "HEAD" {
+			w.Write(util.AddRequestID(r, body))
+		}
+	}
+
+	query := r.URL.Query()
+
+	// Determine time zone for event times
+	loc := time.UTC
+	if tz := query.Get("tz"); tz != "" {
+		var err error
+		loc, err = time.LoadLocation(tz)
+		if err != nil || loc.String() == "Local" {
+			writeErr(calendarCode[calendarInvalidTimeZone], calendarJSON[calendarInvalidTimeZone])
+			return
+		}
+	}
+
+	// Fetch all events, or only events the user has responded to
+	var events []*models.Event
+	var err error
+	switch query.Get("filter") {
+	case "":
+		events, err = c.db.SelectAllEvents(r.Context())
+	case "rsvp":
+		user, ok := auth.User(r)
+		if !ok {
+			writeErr(util.Code[util.NotAuthorized], util.JSON[util.NotAuthorized])
+			return
+		}
+
+		events, err = c.db.SelectEventsByRSVP(r.Context(), user.ID)
+	default:
+		writeErr(calendarCode[calendarInvalidFilter], calendarJSON[calendarInvalidFilter])
+		return
+	}
+	if err != nil {
+		util.Logger(r).Error("request error", "err", err)
+		writeErr(util.Code[util.InternalServerError], util.JSON[util.InternalServerError])
+		return
+	}
+
+	cal := &ical.Calendar{
+		ProdID:   calendarProdID,
+		Name:     calendarName,
+		Location: loc,
+		Events:   make([]*ical.Event, 0, len(events)),
+	}
+	for _, e := range events {
+		cal.Events = append(cal.Events, icalEvent(e))
+	}
+
+	buf := bytes.NewBuffer(nil)
+	if err := ical.NewEncoder(buf).Encode(cal); err != nil {
+		util.Logger(r).Error("request error", "err", err)
+		writeErr(util.Code[util.InternalServerError], util.JSON[util.InternalServerError])
+		return
+	}
+
+	// Event modification times are part of the feed, so its content only
+	// changes when events change
+	etag := fmt.Sprintf(`"%x"`, sha1.Sum(buf.Bytes()))
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "private, max-age=300")
+
+	if etagMatch(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", ical.ContentType)
+	w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+	w.WriteHeader(http.StatusOK)
+	if r.Method != "HEAD" {
+		w.Write(buf.Bytes())
+	}
+}
+
+// icalEvent converts an Event into an iCalendar event.
+func icalEvent(e *models.Event) *ical.Event {
+	return &ical.Event{
+		UID:         fmt.Sprintf("event-%d@%s", e.ID, calendarUIDDomain),
+		Stamp:       time.Unix(int64(e.Updated), 0),
+		Start:       e.StartTime(),
+		End:         e.EndTime(),
+		AllDay:      e.AllDay,
+		Summary:     e.Title,
+		Description: e.Description,
+		Location:    e.Location,
+	}
+}
+
+// etagMatch reports whether an If-None-Match header value matches the input ETag.
+// Weak comparison is used, as described in RFC 7232.
+func etagMatch(header string, etag string) bool {
+	if header == "" {
+		return false
+	}
+
+	for _, t := range strings.Split(header, ",") {
+		t = strings.TrimPrefix(strings.TrimSpace(t), "W/")
+		if t == "*" || t == etag {
+			return true
+		}
+	}
+
+	return false
+}
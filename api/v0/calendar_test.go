@@ -0,0 +1,121 @@
+package v0
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mdlayher/deltaiota/api/auth"
+	"github.com/mdlayher/deltaiota/data/models"
+)
+
+// TestGetCalendar verifies that GetCalendar writes an iCalendar feed of events,
+// filtered by the user's RSVPs if requested, and supports conditional requests.
+func TestGetCalendar(t *testing.T) {
+	ctx := context.Background()
+
+	withContextUser(t, func(c *Context, user *models.User) error {
+		// Create two events, one of which the user will attend
+		start := time.Date(2015, time.March, 1, 18, 0, 0, 0, time.UTC)
+		events := []*models.Event{
+			{Title: "Chapter meeting", Start: uint64(start.Unix()), End: uint64(start.Add(time.Hour).Unix())},
+			{Title: "Service day", Start: uint64(start.AddDate(0, 0, 7).Unix()), AllDay: true},
+		}
+		for _, e := range events {
+			e.Created = uint64(start.Unix())
+			e.Updated = e.Created
+			if err := c.db.InsertEvent(ctx, e); err != nil {
+				return err
+			}
+		}
+		if err := c.db.SetRSVP(ctx, &models.RSVP{
+			EventID:  events[1].ID,
+			UserID:   user.ID,
+			Response: models.RSVPYes,
+		}); err != nil {
+			return err
+		}
+
+		// Perform a request for the feed with the input query and ETag
+		get := func(query string, etag string) *httptest.ResponseRecorder {
+			r, err := http.NewRequest("GET", "/calendar.ics?"+query, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r = auth.WithUser(r, user)
+			if etag != "" {
+				r.Header.Set("If-None-Match", etag)
+			}
+
+			w := httptest.NewRecorder()
+			c.GetCalendar(w, r)
+			return w
+		}
+
+		// Table of tests to iterate
+		var tests = []struct {
+			query    string
+			code     int
+			contains []string
+			excludes []string
+		}{
+			// Invalid filter
+			{"filter=foo", http.StatusBadRequest, []string{calendarInvalidFilter}, nil},
+			// Invalid time zone
+			{"tz=Not/AZone", http.StatusBadRequest, []string{calendarInvalidTimeZone}, nil},
+			// All events, in UTC
+			{"", http.StatusOK, []string{
+				"UID:event-1@deltaiota\r\n",
+				"DTSTAMP:20150301T180000Z\r\n",
+				"DTSTART:20150301T180000Z\r\n",
+				"SUMMARY:Chapter meeting\r\n",
+				"DTSTART;VALUE=DATE:20150308\r\n",
+			}, nil},
+			// Only events the user will attend
+			{"filter=rsvp", http.StatusOK, []string{"SUMMARY:Service day\r\n"}, []string{"Chapter meeting"}},
+		}
+
+		// Iterate and run tests
+		for i, test := range tests {
+			w := get(test.query, "")
+			if w.Code != test.code {
+				t.Fatalf("[%02d] unexpected code: %v != %v", i, w.Code, test.code)
+			}
+
+			body := w.Body.String()
+			for _, s := range test.contains {
+				if !strings.Contains(body, s) {
+					t.Fatalf("[%02d] body missing %q:\n%s", i, s, body)
+				}
+			}
+			for _, s := range test.excludes {
+				if strings.Contains(body, s) {
+					t.Fatalf("[%02d] body unexpectedly contains %q:\n%s", i, s, body)
+				}
+			}
+		}
+
+		// Verify conditional requests are honored until an event changes
+		etag := get("", "").Header().Get("ETag")
+		if etag == "" {
+			t.Fatal("no ETag returned")
+		}
+		if w := get("", etag); w.Code != http.StatusNotModified {
+			t.Fatalf("unexpected code: %v != %v", w.Code, http.StatusNotModified)
+		}
+
+		events[0].Title = "Rescheduled meeting"
+		events[0].Updated++
+		if err := c.db.UpdateEvent(ctx, events[0]); err != nil {
+			return err
+		}
+		if w := get("", etag); w.Code != http.StatusOK {
+			t.Fatalf("unexpected code: %v != %v", w.Code, http.StatusOK)
+		}
+
+		return nil
+	})
+}
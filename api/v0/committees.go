@@ -0,0 +1,557 @@
+package v0
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/mdlayher/deltaiota/api/auth"
+	"github.com/mdlayher/deltaiota/api/util"
+	"github.com/mdlayher/deltaiota/data/models"
+)
+
+// JSON Committees API, human-readable client error responses.
+const (
+	// HTTP GET
+	committeeInvalidID       = "invalid committee ID"
+	committeeMissingID       = "missing committee ID"
+	committeeNotFound        = "committee not found"
+	committeeMemberInvalidID = "invalid member user ID"
+	committeeMemberMissingID = "missing member user ID"
+	committeeMemberNotFound  = "committee member not found"
+
+	// HTTP POST
+	committeeConflict          = "committee already exists"
+	committeeForbidden         = "only officers and committee chairs may manage this committee"
+	committeeJSONSyntax        = "invalid JSON request"
+	committeeMissingParameters = "missing required parameters"
+	committeeUserNotFound      = "user not found"
+)
+
+// JSON Committees API, map of client errors to response codes.
+var committeesCode = map[string]int{
+	// HTTP GET
+	committeeInvalidID:       http.StatusBadRequest,
+	committeeMissingID:       http.StatusBadRequest,
+	committeeNotFound:        http.StatusNotFound,
+	committeeMemberInvalidID: http.StatusBadRequest,
+	committeeMemberMissingID: http.StatusBadRequest,
+	committeeMemberNotFound:  http.StatusNotFound,
+
+	// HTTP POST
+	committeeConflict:          http.StatusConflict,
+	committeeForbidden:         http.StatusForbidden,
+	committeeJSONSyntax:        http.StatusBadRequest,
+	committeeMissingParameters: http.StatusBadRequest,
+	committeeUserNotFound:      http.StatusNotFound,
+}
+
+// Generated JSON responses for various client-facing errors.
+var committeesJSON = map[string][]byte{}
+
+// init initializes the stored JSON responses for client-facing errors.
+func init() {
+	// Iterate all error strings and code integers
+	for k, v := range committeesCode {
+		// Generate error response with appropriate string and code
+		body, err := json.Marshal(util.ErrRes(v, k))
+		if err != nil {
+			panic(err)
+		}
+
+		// Store for later use
+		committeesJSON[k] = body
+	}
+}
+
+// CommitteesResponse is the output response for the Committees API.
+type CommitteesResponse struct {
+	Committees []*models.Committee `json:"committees"`
+}
+
+// CommitteeMembersResponse is the output response for the Committee Members API.
+type CommitteeMembersResponse struct {
+	Members []*models.CommitteeMember `json:"members"`
+}
+
+// CommitteeMemberRequest is the input request for adding or updating a member
+// of a committee.
+type CommitteeMemberRequest struct {
+	Chair bool `json:"chair"`
+}
+
+// CommitteeNotificationRequest is the input request for sending a notification
+// to every member of a committee.
+type CommitteeNotificationRequest struct {
+	Text string `json:"text"`
+	URI  string `json:"uri"`
+}
+
+// CommitteesAPI is a util.JSONAPIFunc, and is the single entry point for the Committees API.
+// This method delegates to other methods as appropriate to handle incoming requests.
+func (c *Context) CommitteesAPI(r *http.Request, vars util.Vars) (int, []byte, error) {
+	// Switch based on HTTP method
+	switch r.Method {
+	case "GET", "HEAD":
+		// If ID present, request for single committee
+		if _, ok := vars["id"]; ok {
+			return c.GetCommittee(r, vars)
+		}
+
+		// No ID, request for list of committees
+		return c.ListCommittees(r, vars)
+	case "POST":
+		return c.PostCommittee(r, vars)
+	case "PUT":
+		return c.PutCommittee(r, vars)
+	case "DELETE":
+		return c.DeleteCommittee(r, vars)
+	default:
+		return util.MethodNotAllowed(r, vars)
+	}
+}
+
+// ListCommittees is a util.JSONAPIFunc which returns HTTP 200 and a JSON list of
+// committees on success, or a non-200 HTTP status code and an error response on failure.
+func (c *Context) ListCommittees(r *http.Request, vars util.Vars) (int, []byte, error) {
+	// Fetch a list of all committees from the database
+	committees, err := c.db.SelectAllCommittees(r.Context())
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+
+	// Wrap in response and return
+	body, err := json.Marshal(CommitteesResponse{
+		Committees: committees,
+	})
+	return http.StatusOK, body, err
+}
+
+// GetCommittee is a util.JSONAPIFunc which returns HTTP 200 and a JSON committee
+// object on success, or a non-200 HTTP status code and an error response on failure.
+func (c *Context) GetCommittee(r *http.Request, vars util.Vars) (int, []byte, error) {
+	// Fetch the committee
+	committee, code, body, err := c.committeeFromVars(r.Context(), vars)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+	if body != nil {
+		return code, body, nil
+	}
+
+	// Wrap in response and return
+	body, err = json.Marshal(CommitteesResponse{
+		Committees: []*models.Committee{committee},
+	})
+	return http.StatusOK, body, err
+}
+
+// PostCommittee is a util.JSONAPIFunc which creates a Committee and returns HTTP 201
+// and a JSON committee object on success, or a non-200 HTTP status code and an
+// error response on failure.
+func (c *Context) PostCommittee(r *http.Request, vars util.Vars) (int, []byte, error) {
+	// Read and validate request input into a Committee struct
+	committee := new(models.Committee)
+	code, body, err := decodeAndValidate(r, committee)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+	if body != nil {
+		return code, body, nil
+	}
+
+	// No body written, all checks passed, so insert new committee
+	if err := c.db.InsertCommittee(r.Context(), committee); err != nil {
+		// Check for constraint failure, meaning committee already exists
+		if c.db.IsConstraintFailure(err) {
+			return committeesCode[committeeConflict], committeesJSON[committeeConflict], nil
+		}
+
+		return util.JSONAPIErr(err)
+	}
+
+	// Wrap in response and return
+	body, err = json.Marshal(CommitteesResponse{
+		Committees: []*models.Committee{committee},
+	})
+	return http.StatusCreated, body, err
+}
+
+// PutCommittee is a util.JSONAPIFunc which updates a Committee and returns HTTP 200
+// and a JSON committee object on success, or a non-200 HTTP status code and an
+// error response on failure.
+func (c *Context) PutCommittee(r *http.Request, vars util.Vars) (int, []byte, error) {
+	// Fetch the committee
+	committee, code, body, err := c.committeeFromVars(r.Context(), vars)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+	if body != nil {
+		return code, body, nil
+	}
+
+	// Read and validate request input into a Committee struct
+	newCommittee := new(models.Committee)
+	code, body, err = decodeAndValidate(r, newCommittee)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+	if body != nil {
+		return code, body, nil
+	}
+
+	// Update existing committee with new fields
+	committee.CopyFrom(newCommittee)
+	if err := c.db.UpdateCommittee(r.Context(), committee); err != nil {
+		// Check for constraint failure, meaning a unique check failed
+		if c.db.IsConstraintFailure(err) {
+			return committeesCode[committeeConflict], committeesJSON[committeeConflict], nil
+		}
+
+		return util.JSONAPIErr(err)
+	}
+
+	// Wrap in response and return
+	body, err = json.Marshal(CommitteesResponse{
+		Committees: []*models.Committee{committee},
+	})
+	return http.StatusOK, body, err
+}
+
+// DeleteCommittee is a util.JSONAPIFunc which deletes a Committee and all of its
+// memberships, and returns HTTP 204 on success, or a non-200 HTTP status code and
+// an error response on failure.
+func (c *Context) DeleteCommittee(r *http.Request, vars util.Vars) (int, []byte, error) {
+	// Fetch the committee
+	committee, code, body, err := c.committeeFromVars(r.Context(), vars)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+	if body != nil {
+		return code, body, nil
+	}
+
+	if err := c.db.DeleteCommittee(r.Context(), committee); err != nil {
+		return util.JSONAPIErr(err)
+	}
+
+	return http.StatusNoContent, nil, nil
+}
+
+// CommitteeMembersAPI is a util.JSONAPIFunc, and is the single entry point for
+// managing the membership of a committee.
+// This method delegates to other methods as appropriate to handle incoming requests.
+func (c *Context) CommitteeMembersAPI(r *http.Request, vars util.Vars) (int, []byte, error) {
+	// Switch based on HTTP method
+	switch r.Method {
+	case "GET", "HEAD":
+		// If user ID present, request for single member
+		if _, ok := vars["userId"]; ok {
+			return c.GetCommitteeMember(r, vars)
+		}
+
+		// No user ID, request for list of members
+		return c.ListCommitteeMembers(r, vars)
+	case "PUT":
+		return c.PutCommitteeMember(r, vars)
+	case "DELETE":
+		return c.DeleteCommitteeMember(r, vars)
+	default:
+		return util.MethodNotAllowed(r, vars)
+	}
+}
+
+// ListCommitteeMembers is a util.JSONAPIFunc which returns HTTP 200 and a JSON list
+// of all members of a committee, chairs first, on success, or a non-200 HTTP status
+// code and an error response on failure.
+func (c *Context) ListCommitteeMembers(r *http.Request, vars util.Vars) (int, []byte, error) {
+	// Fetch the committee
+	committee, code, body, err := c.committeeFromVars(r.Context(), vars)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+	if body != nil {
+		return code, body, nil
+	}
+
+	members, err := c.db.SelectCommitteeMembersByCommitteeID(r.Context(), committee.ID)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+
+	// Wrap in response and return
+	body, err = json.Marshal(CommitteeMembersResponse{
+		Members: members,
+	})
+	return http.StatusOK, body, err
+}
+
+// GetCommitteeMember is a util.JSONAPIFunc which returns HTTP 200 and a JSON committee
+// member object on success, or a non-200 HTTP status code and an error response on failure.
+func (c *Context) GetCommitteeMember(r *http.Request, vars util.Vars) (int, []byte, error) {
+	// Fetch the committee
+	committee, code, body, err := c.committeeFromVars(r.Context(), vars)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+	if body != nil {
+		return code, body, nil
+	}
+
+	// Fetch the member
+	userID, code, body := memberIDFromVars(vars)
+	if body != nil {
+		return code, body, nil
+	}
+
+	member, err := c.db.SelectCommitteeMember(r.Context(), committee.ID, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return committeesCode[committeeMemberNotFound], committeesJSON[committeeMemberNotFound], nil
+		}
+
+		return util.JSONAPIErr(err)
+	}
+
+	// Wrap in response and return
+	body, err = json.Marshal(CommitteeMembersResponse{
+		Members: []*models.CommitteeMember{member},
+	})
+	return http.StatusOK, body, err
+}
+
+// PutCommitteeMember is a util.JSONAPIFunc which adds a user to a committee, or
+// updates their existing membership, and returns HTTP 200 and a JSON committee member
+// object on success, or a non-200 HTTP status code and an error response on failure.
+// Only officers and chairs of the committee may manage its membership.
+func (c *Context) PutCommitteeMember(r *http.Request, vars util.Vars) (int, []byte, error) {
+	// Fetch the committee, and verify the user may manage it
+	committee, code, body, err := c.committeeForManagement(r, vars)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+	if body != nil {
+		return code, body, nil
+	}
+
+	// Fetch the member's user ID, and verify the user exists
+	userID, code, body := memberIDFromVars(vars)
+	if body != nil {
+		return code, body, nil
+	}
+	if _, err := c.db.SelectUserByID(r.Context(), userID); err != nil {
+		if err == sql.ErrNoRows {
+			return committeesCode[committeeUserNotFound], committeesJSON[committeeUserNotFound], nil
+		}
+
+		return util.JSONAPIErr(err)
+	}
+
+	// Read membership details from request; an empty body adds a regular member
+	var req CommitteeMemberRequest
+	if r.Body != nil {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+			return committeesCode[committeeJSONSyntax], committeesJSON[committeeJSONSyntax], nil
+		}
+	}
+
+	member := &models.CommitteeMember{
+		CommitteeID: committee.ID,
+		UserID:      userID,
+		Chair:       req.Chair,
+	}
+	if err := c.db.SetCommitteeMember(r.Context(), member); err != nil {
+		return util.JSONAPIErr(err)
+	}
+
+	// Wrap in response and return
+	body, err = json.Marshal(CommitteeMembersResponse{
+		Members: []*models.CommitteeMember{member},
+	})
+	return http.StatusOK, body, err
+}
+
+// DeleteCommitteeMember is a util.JSONAPIFunc which removes a user from a committee,
+// and returns HTTP 204 on success, or a non-200 HTTP status code and an error response
+// on failure.  Only officers and chairs of the committee may manage its membership.
+func (c *Context) DeleteCommitteeMember(r *http.Request, vars util.Vars) (int, []byte, error) {
+	// Fetch the committee, and verify the user may manage it
+	committee, code, body, err := c.committeeForManagement(r, vars)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+	if body != nil {
+		return code, body, nil
+	}
+
+	// Fetch the member
+	userID, code, body := memberIDFromVars(vars)
+	if body != nil {
+		return code, body, nil
+	}
+
+	member, err := c.db.SelectCommitteeMember(r.Context(), committee.ID, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return committeesCode[committeeMemberNotFound], committeesJSON[committeeMemberNotFound], nil
+		}
+
+		return util.JSONAPIErr(err)
+	}
+
+	if err := c.db.DeleteCommitteeMember(r.Context(), member); err != nil {
+		return util.JSONAPIErr(err)
+	}
+
+	return http.StatusNoContent, nil, nil
+}
+
+// CommitteeNotificationsAPI is a util.JSONAPIFunc, and is the single entry point
+// for sending notifications to a committee.
+// This method delegates to other methods as appropriate to handle incoming requests.
+func (c *Context) CommitteeNotificationsAPI(r *http.Request, vars util.Vars) (int, []byte, error) {
+	// Switch based on HTTP method
+	switch r.Method {
+	case "POST":
+		return c.PostCommitteeNotification(r, vars)
+	default:
+		return util.MethodNotAllowed(r, vars)
+	}
+}
+
+// PostCommitteeNotification is a util.JSONAPIFunc which sends a notification to
+// every member of a committee, and returns HTTP 201 and a JSON list of the created
+// notifications on success, or a non-200 HTTP status code and an error response on
+// failure.  Either every member is notified, or none are.  Only officers and chairs
+// of the committee may send notifications to it.
+func (c *Context) PostCommitteeNotification(r *http.Request, vars util.Vars) (int, []byte, error) {
+	// Fetch the committee, and verify the user may manage it
+	committee, code, body, err := c.committeeForManagement(r, vars)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+	if body != nil {
+		return code, body, nil
+	}
+
+	// Do not allow nil body
+	if r.Body == nil {
+		return committeesCode[committeeJSONSyntax], committeesJSON[committeeJSONSyntax], nil
+	}
+
+	// Read notification from request
+	var req CommitteeNotificationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return committeesCode[committeeJSONSyntax], committeesJSON[committeeJSONSyntax], nil
+	}
+	if req.Text == "" {
+		code := committeesCode[committeeMissingParameters]
+		body, err := json.Marshal(util.ErrRes(code, (&models.EmptyFieldError{
+			Field: "text",
+		}).Error()))
+		return code, body, err
+	}
+
+	// Fan out notification to all members in a single transaction
+	notifications, err := c.db.NotifyCommittee(r.Context(), committee.ID, &models.Notification{
+		Timestamp: uint64(time.Now().Unix()),
+		Text:      req.Text,
+		URI:       req.URI,
+	})
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+
+	// Wrap in response and return
+	body, err = json.Marshal(NotificationsResponse{
+		Notifications: notifications,
+	})
+	return http.StatusCreated, body, err
+}
+
+// committeeFromVars fetches the Committee which is the target of a request, using
+// the "id" route variable.  On failure, it will return a message body or an error,
+// causing the caller to immediately send the result.
+func (c *Context) committeeFromVars(ctx context.Context, vars util.Vars) (*models.Committee, int, []byte, error) {
+	// Fetch input committee ID
+	strID, ok := vars["id"]
+	if !ok {
+		return nil, committeesCode[committeeMissingID], committeesJSON[committeeMissingID], nil
+	}
+
+	// Convert string to integer
+	id, err := strconv.ParseUint(strID, 10, 64)
+	if err != nil {
+		return nil, committeesCode[committeeInvalidID], committeesJSON[committeeInvalidID], nil
+	}
+
+	// Select single committee by ID from the database
+	committee, err := c.db.SelectCommitteeByID(ctx, id)
+	if err != nil {
+		// If no results found, return HTTP not found
+		if err == sql.ErrNoRows {
+			return nil, committeesCode[committeeNotFound], committeesJSON[committeeNotFound], nil
+		}
+
+		return nil, http.StatusInternalServerError, nil, err
+	}
+
+	return committee, http.StatusOK, nil, nil
+}
+
+// committeeForManagement fetches the Committee which is the target of a request,
+// and verifies that the authenticated user is an officer or a chair of the committee.
+// On failure, it will return a message body or an error, causing the caller to
+// immediately send the result.
+func (c *Context) committeeForManagement(r *http.Request, vars util.Vars) (*models.Committee, int, []byte, error) {
+	committee, code, body, err := c.committeeFromVars(r.Context(), vars)
+	if err != nil || body != nil {
+		return nil, code, body, err
+	}
+
+	user, code, body := authUser(r)
+	if body != nil {
+		return nil, code, body, nil
+	}
+
+	// Officers may manage any committee
+	officer, err := auth.IsOfficer(r.Context(), c.db, user)
+	if err != nil {
+		return nil, http.StatusInternalServerError, nil, err
+	}
+	if officer {
+		return committee, http.StatusOK, nil, nil
+	}
+
+	// Chairs may manage their own committee
+	member, err := c.db.SelectCommitteeMember(r.Context(), committee.ID, user.ID)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, http.StatusInternalServerError, nil, err
+	}
+	if member == nil || !member.Chair {
+		return nil, committeesCode[committeeForbidden], committeesJSON[committeeForbidden], nil
+	}
+
+	return committee, http.StatusOK, nil, nil
+}
+
+// memberIDFromVars parses the user ID of a committee member from the "userId"
+// route variable.  On failure, it will return a message body, causing the caller
+// to immediately send the result.
+func memberIDFromVars(vars util.Vars) (uint64, int, []byte) {
+	strID, ok := vars["userId"]
+	if !ok {
+		return 0, committeesCode[committeeMemberMissingID], committeesJSON[committeeMemberMissingID]
+	}
+
+	id, err := strconv.ParseUint(strID, 10, 64)
+	if err != nil {
+		return 0, committeesCode[committeeMemberInvalidID], committeesJSON[committeeMemberInvalidID]
+	}
+
+	return id, http.StatusOK, nil
+}
@@ -0,0 +1,314 @@
+package v0
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mdlayher/deltaiota/api/auth"
+	"github.com/mdlayher/deltaiota/api/util"
+	"github.com/mdlayher/deltaiota/data/models"
+	"github.com/mdlayher/deltaiota/ditest"
+)
+
+// TestPutCommitteeMember verifies that PutCommitteeMember only allows officers
+// and committee chairs to manage committee membership.
+func TestPutCommitteeMember(t *testing.T) {
+	ctx := context.Background()
+
+	withContextCommittee(t, func(c *Context, committee *models.Committee, officer *models.User, chair *models.User, member *models.User) error {
+		// Generate a user who is not yet a member
+		user := ditest.MockUser()
+		if err := c.db.InsertUser(ctx, user); err != nil {
+			return err
+		}
+
+		id := fmt.Sprintf("%d", committee.ID)
+
+		// Table of tests to iterate
+		var tests = []struct {
+			as         *models.User
+			vars       util.Vars
+			code       int
+			errMessage string
+			body       []byte
+		}{
+			// Committee not found
+			{officer, util.Vars{"id": "100", "userId": "1"}, http.StatusNotFound, committeeNotFound, nil},
+			// Regular member may not manage committee
+			{member, util.Vars{"id": id, "userId": fmt.Sprintf("%d", user.ID)}, http.StatusForbidden, committeeForbidden, nil},
+			// User not found
+			{officer, util.Vars{"id": id, "userId": "100"}, http.StatusNotFound, committeeUserNotFound, nil},
+			// Invalid user ID
+			{officer, util.Vars{"id": id, "userId": "foo"}, http.StatusBadRequest, committeeMemberInvalidID, nil},
+			// Bad JSON
+			{officer, util.Vars{"id": id, "userId": fmt.Sprintf("%d", user.ID)}, http.StatusBadRequest, committeeJSONSyntax, []byte(`{`)},
+			// Chair may add member
+			{chair, util.Vars{"id": id, "userId": fmt.Sprintf("%d", user.ID)}, http.StatusOK, "", nil},
+			// Officer may promote member to chair
+			{officer, util.Vars{"id": id, "userId": fmt.Sprintf("%d", user.ID)}, http.StatusOK, "", []byte(`{"chair":true}`)},
+		}
+
+		// Iterate and run tests
+		for _, test := range tests {
+			// Generate HTTP request
+			r, err := http.NewRequest("PUT", "/", bytes.NewReader(test.body))
+			if err != nil {
+				return err
+			}
+
+			// Store mock-authenticated user
+			r = auth.WithUser(r, test.as)
+
+			code, body, err := c.PutCommitteeMember(r, test.vars)
+			if err != nil {
+				return err
+			}
+
+			// Ensure proper HTTP status code
+			if code != test.code {
+				return fmt.Errorf("unexpected code: %v != %v", code, test.code)
+			}
+
+			// If code is in HTTP 400 or above, check error response
+			if code >= http.StatusBadRequest {
+				var errRes util.ErrorResponse
+				if err := json.Unmarshal(body, &errRes); err != nil {
+					return err
+				}
+
+				if errRes.Error.Message != test.errMessage {
+					return fmt.Errorf("unexpected error message: %v != %v", errRes.Error.Message, test.errMessage)
+				}
+			}
+		}
+
+		// Verify user is now a chair
+		m, err := c.db.SelectCommitteeMember(ctx, committee.ID, user.ID)
+		if err != nil {
+			return err
+		}
+		if !m.Chair {
+			return fmt.Errorf("member was not promoted to chair")
+		}
+
+		return nil
+	})
+}
+
+// TestPostCommitteeNotification verifies that PostCommitteeNotification sends a
+// notification to every member of a committee, and no other users.
+func TestPostCommitteeNotification(t *testing.T) {
+	ctx := context.Background()
+
+	withContextCommittee(t, func(c *Context, committee *models.Committee, officer *models.User, chair *models.User, member *models.User) error {
+		id := fmt.Sprintf("%d", committee.ID)
+
+		// Missing text
+		r, err := http.NewRequest("POST", "/", bytes.NewReader([]byte(`{"uri":"/foo"}`)))
+		if err != nil {
+			return err
+		}
+		r = auth.WithUser(r, chair)
+
+		code, _, err := c.PostCommitteeNotification(r, util.Vars{"id": id})
+		if err != nil {
+			return err
+		}
+		if code != http.StatusBadRequest {
+			return fmt.Errorf("unexpected code: %v != %v", code, http.StatusBadRequest)
+		}
+
+		// Valid notification
+		r, err = http.NewRequest("POST", "/", bytes.NewReader([]byte(`{"text":"Meeting at 8","uri":"/foo"}`)))
+		if err != nil {
+			return err
+		}
+		r = auth.WithUser(r, chair)
+
+		code, body, err := c.PostCommitteeNotification(r, util.Vars{"id": id})
+		if err != nil {
+			return err
+		}
+		if code != http.StatusCreated {
+			return fmt.Errorf("unexpected code: %v != %v", code, http.StatusCreated)
+		}
+
+		var res NotificationsResponse
+		if err := json.Unmarshal(body, &res); err != nil {
+			return err
+		}
+		if len(res.Notifications) != 2 {
+			return fmt.Errorf("unexpected number of notifications: %v != %v", len(res.Notifications), 2)
+		}
+
+		// Verify each member received exactly one notification, and the
+		// officer, who is not a member, received none
+		for _, test := range []struct {
+			user  *models.User
+			count int
+		}{
+			{chair, 1},
+			{member, 1},
+			{officer, 0},
+		} {
+			notifications, err := c.db.SelectNotificationsByUserID(ctx, test.user.ID)
+			if err != nil {
+				return err
+			}
+			if len(notifications) != test.count {
+				return fmt.Errorf("unexpected notifications for user %d: %v != %v", test.user.ID, len(notifications), test.count)
+			}
+			if test.count > 0 && notifications[0].Text != "Meeting at 8" {
+				return fmt.Errorf("unexpected notification text: %v", notifications[0].Text)
+			}
+		}
+
+		return nil
+	})
+}
+
+// TestCommitteeDeletedMember verifies that a deleted user is omitted from the
+// members of a committee, and receives no committee notifications.
+func TestCommitteeDeletedMember(t *testing.T) {
+	ctx := context.Background()
+
+	withContextCommittee(t, func(c *Context, committee *models.Committee, officer *models.User, chair *models.User, member *models.User) error {
+		id := fmt.Sprintf("%d", committee.ID)
+
+		member.DeletedAt = uint64(time.Now().Unix())
+		if err := c.db.UpdateUser(ctx, member); err != nil {
+			return err
+		}
+
+		code, body, err := c.ListCommitteeMembers(httptest.NewRequest("GET", "/", nil), util.Vars{"id": id})
+		if err != nil {
+			return err
+		}
+		if code != http.StatusOK {
+			return fmt.Errorf("unexpected code: %v != %v", code, http.StatusOK)
+		}
+
+		var mres CommitteeMembersResponse
+		if err := json.Unmarshal(body, &mres); err != nil {
+			return err
+		}
+		if len(mres.Members) != 1 || mres.Members[0].UserID != chair.ID {
+			return fmt.Errorf("unexpected members: %v", mres.Members)
+		}
+
+		r, err := http.NewRequest("POST", "/", bytes.NewReader([]byte(`{"text":"Meeting at 8"}`)))
+		if err != nil {
+			return err
+		}
+		r = auth.WithUser(r, chair)
+
+		code, body, err = c.PostCommitteeNotification(r, util.Vars{"id": id})
+		if err != nil {
+			return err
+		}
+		if code != http.StatusCreated {
+			return fmt.Errorf("unexpected code: %v != %v", code, http.StatusCreated)
+		}
+
+		var nres NotificationsResponse
+		if err := json.Unmarshal(body, &nres); err != nil {
+			return err
+		}
+		if len(nres.Notifications) != 1 || nres.Notifications[0].UserID != chair.ID {
+			return fmt.Errorf("unexpected notifications: %v", nres.Notifications)
+		}
+
+		notifications, err := c.db.SelectNotificationsByUserID(ctx, member.ID)
+		if err != nil {
+			return err
+		}
+		if len(notifications) != 0 {
+			return fmt.Errorf("unexpected notifications for deleted user: %v", notifications)
+		}
+
+		return nil
+	})
+}
+
+// TestDeleteCommittee verifies that DeleteCommittee removes a committee and all
+// of its memberships.
+func TestDeleteCommittee(t *testing.T) {
+	ctx := context.Background()
+
+	withContextCommittee(t, func(c *Context, committee *models.Committee, officer *models.User, chair *models.User, member *models.User) error {
+		code, _, err := c.DeleteCommittee(httptest.NewRequest("DELETE", "/", nil), util.Vars{"id": fmt.Sprintf("%d", committee.ID)})
+		if err != nil {
+			return err
+		}
+		if code != http.StatusNoContent {
+			return fmt.Errorf("unexpected code: %v != %v", code, http.StatusNoContent)
+		}
+
+		members, err := c.db.SelectCommitteeMembersByCommitteeID(ctx, committee.ID)
+		if err != nil {
+			return err
+		}
+		if len(members) != 0 {
+			return fmt.Errorf("unexpected members after delete: %v", members)
+		}
+
+		return nil
+	})
+}
+
+// withContextCommittee builds upon withContext, adding a committee with a chair
+// and a regular member, and an officer who is not a member of the committee.
+func withContextCommittee(t *testing.T, fn func(c *Context, committee *models.Committee, officer *models.User, chair *models.User, member *models.User) error) {
+	ctx := context.Background()
+
+	withContext(t, func(c *Context) error {
+		// Generate mock users
+		users := make([]*models.User, 3)
+		for i := range users {
+			users[i] = ditest.MockUser()
+			if err := c.db.InsertUser(ctx, users[i]); err != nil {
+				return err
+			}
+		}
+		officer, chair, member := users[0], users[1], users[2]
+
+		// Appoint officer
+		position := &models.Position{
+			Name: "President",
+		}
+		if err := c.db.InsertPosition(ctx, position); err != nil {
+			return err
+		}
+		if err := c.db.InsertTerm(ctx, &models.Term{
+			UserID:     officer.ID,
+			PositionID: position.ID,
+			Start:      uint64(time.Now().Add(-1 * time.Hour).Unix()),
+		}); err != nil {
+			return err
+		}
+
+		// Create committee with chair and member
+		committee := &models.Committee{
+			Name: "Social",
+		}
+		if err := c.db.InsertCommittee(ctx, committee); err != nil {
+			return err
+		}
+		for _, m := range []*models.CommitteeMember{
+			{CommitteeID: committee.ID, UserID: chair.ID, Chair: true},
+			{CommitteeID: committee.ID, UserID: member.ID},
+		} {
+			if err := c.db.SetCommitteeMember(ctx, m); err != nil {
+				return err
+			}
+		}
+
+		return fn(c, committee, officer, chair, member)
+	})
+}
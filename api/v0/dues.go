@@ -0,0 +1,556 @@
+package v0
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/mdlayher/deltaiota/api/auth"
+	"github.com/mdlayher/deltaiota/api/util"
+	"github.com/mdlayher/deltaiota/data/models"
+)
+
+// JSON Dues API, human-readable client error responses.
+const (
+	duesForbidden    = "only officers may access another user's ledger"
+	chargeInvalidID  = "invalid charge ID"
+	chargeMissingID  = "missing charge ID"
+	chargeNotFound   = "charge not found"
+	paymentInvalidID = "invalid payment ID"
+	paymentMissingID = "missing payment ID"
+	paymentNotFound  = "payment not found"
+)
+
+// JSON Dues API, map of client errors to response codes.
+var duesCode = map[string]int{
+	duesForbidden:    http.StatusForbidden,
+	chargeInvalidID:  http.StatusBadRequest,
+	chargeMissingID:  http.StatusBadRequest,
+	chargeNotFound:   http.StatusNotFound,
+	paymentInvalidID: http.StatusBadRequest,
+	paymentMissingID: http.StatusBadRequest,
+	paymentNotFound:  http.StatusNotFound,
+}
+
+// Generated JSON responses for various client-facing errors.
+var duesJSON = map[string][]byte{}
+
+// init initializes the stored JSON responses for client-facing errors.
+func init() {
+	// Iterate all error strings and code integers
+	for k, v := range duesCode {
+		// Generate error response with appropriate string and code
+		body, err := json.Marshal(util.ErrRes(v, k))
+		if err != nil {
+			panic(err)
+		}
+
+		// Store for later use
+		duesJSON[k] = body
+	}
+}
+
+// ChargesResponse is the output response for the Charges API.
+type ChargesResponse struct {
+	Charges []*models.Charge `json:"charges"`
+}
+
+// PaymentsResponse is the output response for the Payments API.
+type PaymentsResponse struct {
+	Payments []*models.Payment `json:"payments"`
+}
+
+// BalancesResponse is the output response for the Balance and Overdue APIs.
+type BalancesResponse struct {
+	Balances []*models.Balance `json:"balances"`
+}
+
+// ChargesAPI is a util.JSONAPIFunc, and is the single entry point for the Charges
+// API, which manages the charges assigned to a user.
+// This method delegates to other methods as appropriate to handle incoming requests.
+func (c *Context) ChargesAPI(r *http.Request, vars util.Vars) (int, []byte, error) {
+	// Switch based on HTTP method
+	switch r.Method {
+	case "GET", "HEAD":
+		// If charge ID present, request for single charge
+		if _, ok := vars["chargeId"]; ok {
+			return c.GetCharge(r, vars)
+		}
+
+		// No charge ID, request for list of charges
+		return c.ListCharges(r, vars)
+	case "POST":
+		return c.PostCharge(r, vars)
+	case "PUT":
+		return c.PutCharge(r, vars)
+	case "DELETE":
+		return c.DeleteCharge(r, vars)
+	default:
+		return util.MethodNotAllowed(r, vars)
+	}
+}
+
+// ListCharges is a util.JSONAPIFunc which returns HTTP 200 and a JSON list of all
+// charges for a user, oldest due first, on success, or a non-200 HTTP status code
+// and an error response on failure.
+func (c *Context) ListCharges(r *http.Request, vars util.Vars) (int, []byte, error) {
+	// Fetch the user who owns the ledger
+	user, code, body, err := c.ledgerUserFromVars(r, vars)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+	if body != nil {
+		return code, body, nil
+	}
+
+	charges, err := c.db.SelectChargesByUserID(r.Context(), user.ID)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+
+	// Wrap in response and return
+	body, err = json.Marshal(ChargesResponse{
+		Charges: charges,
+	})
+	return http.StatusOK, body, err
+}
+
+// GetCharge is a util.JSONAPIFunc which returns HTTP 200 and a JSON charge object
+// on success, or a non-200 HTTP status code and an error response on failure.
+func (c *Context) GetCharge(r *http.Request, vars util.Vars) (int, []byte, error) {
+	// Fetch the charge
+	charge, code, body, err := c.chargeFromVars(r, vars)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+	if body != nil {
+		return code, body, nil
+	}
+
+	// Wrap in response and return
+	body, err = json.Marshal(ChargesResponse{
+		Charges: []*models.Charge{charge},
+	})
+	return http.StatusOK, body, err
+}
+
+// PostCharge is a util.JSONAPIFunc which assigns a new Charge to a User, and returns
+// HTTP 201 and a JSON charge object on success, or a non-200 HTTP status code and
+// an error response on failure.
+func (c *Context) PostCharge(r *http.Request, vars util.Vars) (int, []byte, error) {
+	// Fetch the user who owns the ledger
+	user, code, body, err := c.ledgerUserFromVars(r, vars)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+	if body != nil {
+		return code, body, nil
+	}
+
+	// Read and validate request input into a Charge struct; charges always
+	// belong to the user in the route
+	charge := &models.Charge{
+		UserID: user.ID,
+	}
+	code, body, err = decodeAndValidate(r, charge)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+	if body != nil {
+		return code, body, nil
+	}
+	charge.UserID = user.ID
+	charge.Created = uint64(time.Now().Unix())
+
+	// Notification state is managed only by the server
+	charge.Reminded = false
+	charge.Overdue = false
+
+	if err := c.db.InsertCharge(r.Context(), charge); err != nil {
+		return util.JSONAPIErr(err)
+	}
+
+	// Wrap in response and return
+	body, err = json.Marshal(ChargesResponse{
+		Charges: []*models.Charge{charge},
+	})
+	return http.StatusCreated, body, err
+}
+
+// PutCharge is a util.JSONAPIFunc which updates a Charge and returns HTTP 200 and
+// a JSON charge object on success, or a non-200 HTTP status code and an error
+// response on failure.
+func (c *Context) PutCharge(r *http.Request, vars util.Vars) (int, []byte, error) {
+	// Fetch the charge
+	charge, code, body, err := c.chargeFromVars(r, vars)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+	if body != nil {
+		return code, body, nil
+	}
+
+	// Read and validate request input into a Charge struct
+	newCharge := &models.Charge{
+		UserID: charge.UserID,
+	}
+	code, body, err = decodeAndValidate(r, newCharge)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+	if body != nil {
+		return code, body, nil
+	}
+
+	// If the due date changes, the user should be notified again
+	if newCharge.Due != charge.Due {
+		charge.Reminded = false
+		charge.Overdue = false
+	}
+
+	charge.CopyFrom(newCharge)
+	if err := c.db.UpdateCharge(r.Context(), charge); err != nil {
+		return util.JSONAPIErr(err)
+	}
+
+	// Wrap in response and return
+	body, err = json.Marshal(ChargesResponse{
+		Charges: []*models.Charge{charge},
+	})
+	return http.StatusOK, body, err
+}
+
+// DeleteCharge is a util.JSONAPIFunc which deletes a Charge and returns HTTP 204
+// on success, or a non-200 HTTP status code and an error response on failure.
+func (c *Context) DeleteCharge(r *http.Request, vars util.Vars) (int, []byte, error) {
+	// Fetch the charge
+	charge, code, body, err := c.chargeFromVars(r, vars)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+	if body != nil {
+		return code, body, nil
+	}
+
+	if err := c.db.DeleteCharge(r.Context(), charge); err != nil {
+		return util.JSONAPIErr(err)
+	}
+
+	return http.StatusNoContent, nil, nil
+}
+
+// PaymentsAPI is a util.JSONAPIFunc, and is the single entry point for the Payments
+// API, which manages the payments made by a user.
+// This method delegates to other methods as appropriate to handle incoming requests.
+func (c *Context) PaymentsAPI(r *http.Request, vars util.Vars) (int, []byte, error) {
+	// Switch based on HTTP method
+	switch r.Method {
+	case "GET", "HEAD":
+		// If payment ID present, request for single payment
+		if _, ok := vars["paymentId"]; ok {
+			return c.GetPayment(r, vars)
+		}
+
+		// No payment ID, request for list of payments
+		return c.ListPayments(r, vars)
+	case "POST":
+		return c.PostPayment(r, vars)
+	case "DELETE":
+		return c.DeletePayment(r, vars)
+	default:
+		return util.MethodNotAllowed(r, vars)
+	}
+}
+
+// ListPayments is a util.JSONAPIFunc which returns HTTP 200 and a JSON list of all
+// payments made by a user, oldest first, on success, or a non-200 HTTP status code
+// and an error response on failure.
+func (c *Context) ListPayments(r *http.Request, vars util.Vars) (int, []byte, error) {
+	// Fetch the user who owns the ledger
+	user, code, body, err := c.ledgerUserFromVars(r, vars)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+	if body != nil {
+		return code, body, nil
+	}
+
+	payments, err := c.db.SelectPaymentsByUserID(r.Context(), user.ID)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+
+	// Wrap in response and return
+	body, err = json.Marshal(PaymentsResponse{
+		Payments: payments,
+	})
+	return http.StatusOK, body, err
+}
+
+// GetPayment is a util.JSONAPIFunc which returns HTTP 200 and a JSON payment object
+// on success, or a non-200 HTTP status code and an error response on failure.
+func (c *Context) GetPayment(r *http.Request, vars util.Vars) (int, []byte, error) {
+	// Fetch the payment
+	payment, code, body, err := c.paymentFromVars(r, vars)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+	if body != nil {
+		return code, body, nil
+	}
+
+	// Wrap in response and return
+	body, err = json.Marshal(PaymentsResponse{
+		Payments: []*models.Payment{payment},
+	})
+	return http.StatusOK, body, err
+}
+
+// PostPayment is a util.JSONAPIFunc which records a Payment made by a User, and
+// returns HTTP 201 and a JSON payment object on success, or a non-200 HTTP status
+// code and an error response on failure.  The authenticated officer is recorded
+// as the recipient of the payment.
+func (c *Context) PostPayment(r *http.Request, vars util.Vars) (int, []byte, error) {
+	// Fetch the user who owns the ledger
+	user, code, body, err := c.ledgerUserFromVars(r, vars)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+	if body != nil {
+		return code, body, nil
+	}
+
+	officer, code, body := authUser(r)
+	if body != nil {
+		return code, body, nil
+	}
+
+	// Read and validate request input into a Payment struct; payments always
+	// belong to the user in the route
+	payment := &models.Payment{
+		UserID: user.ID,
+	}
+	code, body, err = decodeAndValidate(r, payment)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+	if body != nil {
+		return code, body, nil
+	}
+	payment.UserID = user.ID
+	payment.RecordedBy = officer.ID
+
+	// Default to time of recording if no payment time is set
+	if payment.Timestamp == 0 {
+		payment.Timestamp = uint64(time.Now().Unix())
+	}
+
+	if err := c.db.InsertPayment(r.Context(), payment); err != nil {
+		return util.JSONAPIErr(err)
+	}
+
+	// Wrap in response and return
+	body, err = json.Marshal(PaymentsResponse{
+		Payments: []*models.Payment{payment},
+	})
+	return http.StatusCreated, body, err
+}
+
+// DeletePayment is a util.JSONAPIFunc which deletes a Payment recorded in error,
+// and returns HTTP 204 on success, or a non-200 HTTP status code and an error
+// response on failure.
+func (c *Context) DeletePayment(r *http.Request, vars util.Vars) (int, []byte, error) {
+	// Fetch the payment
+	payment, code, body, err := c.paymentFromVars(r, vars)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+	if body != nil {
+		return code, body, nil
+	}
+
+	if err := c.db.DeletePayment(r.Context(), payment); err != nil {
+		return util.JSONAPIErr(err)
+	}
+
+	return http.StatusNoContent, nil, nil
+}
+
+// BalanceAPI is a util.JSONAPIFunc, and is the single entry point for the Balance API.
+// This method delegates to other methods as appropriate to handle incoming requests.
+func (c *Context) BalanceAPI(r *http.Request, vars util.Vars) (int, []byte, error) {
+	// Switch based on HTTP method
+	switch r.Method {
+	case "GET", "HEAD":
+		return c.GetBalance(r, vars)
+	default:
+		return util.MethodNotAllowed(r, vars)
+	}
+}
+
+// GetBalance is a util.JSONAPIFunc which returns HTTP 200 and a JSON balance object
+// summarizing a user's ledger on success, or a non-200 HTTP status code and an error
+// response on failure.
+func (c *Context) GetBalance(r *http.Request, vars util.Vars) (int, []byte, error) {
+	// Fetch the user who owns the ledger
+	user, code, body, err := c.ledgerUserFromVars(r, vars)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+	if body != nil {
+		return code, body, nil
+	}
+
+	balance, err := c.db.SelectBalanceByUserID(r.Context(), user.ID, time.Now())
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+
+	// Wrap in response and return
+	body, err = json.Marshal(BalancesResponse{
+		Balances: []*models.Balance{balance},
+	})
+	return http.StatusOK, body, err
+}
+
+// OverdueAPI is a util.JSONAPIFunc, and is the single entry point for the Overdue API.
+// This method delegates to other methods as appropriate to handle incoming requests.
+func (c *Context) OverdueAPI(r *http.Request, vars util.Vars) (int, []byte, error) {
+	// Switch based on HTTP method
+	switch r.Method {
+	case "GET", "HEAD":
+		return c.ListOverdue(r, vars)
+	default:
+		return util.MethodNotAllowed(r, vars)
+	}
+}
+
+// ListOverdue is a util.JSONAPIFunc which returns HTTP 200 and a JSON list of balances
+// for all users with an overdue amount, largest first, on success, or a non-200 HTTP
+// status code and an error response on failure.
+func (c *Context) ListOverdue(r *http.Request, vars util.Vars) (int, []byte, error) {
+	balances, err := c.db.SelectOverdueBalances(r.Context(), time.Now())
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+
+	// Wrap in response and return
+	body, err := json.Marshal(BalancesResponse{
+		Balances: balances,
+	})
+	return http.StatusOK, body, err
+}
+
+// ledgerUserFromVars fetches the User whose ledger is the target of a request, using
+// the "id" route variable, and verifies that the authenticated user is either that
+// User or an officer.  On failure, it will return a message body or an error, causing
+// the caller to immediately send the result.
+func (c *Context) ledgerUserFromVars(r *http.Request, vars util.Vars) (*models.User, int, []byte, error) {
+	user, code, body, err := c.userFromVars(r.Context(), vars)
+	if err != nil || body != nil {
+		return nil, code, body, err
+	}
+
+	requester, code, body := authUser(r)
+	if body != nil {
+		return nil, code, body, nil
+	}
+
+	// Users may always access their own ledger
+	if requester.ID == user.ID {
+		return user, http.StatusOK, nil, nil
+	}
+
+	// Only officers may access another user's ledger
+	officer, err := auth.IsOfficer(r.Context(), c.db, requester)
+	if err != nil {
+		return nil, http.StatusInternalServerError, nil, err
+	}
+	if !officer {
+		return nil, duesCode[duesForbidden], duesJSON[duesForbidden], nil
+	}
+
+	return user, http.StatusOK, nil, nil
+}
+
+// chargeFromVars fetches the Charge which is the target of a request, using the
+// "id" and "chargeId" route variables.  On failure, it will return a message body
+// or an error, causing the caller to immediately send the result.
+func (c *Context) chargeFromVars(r *http.Request, vars util.Vars) (*models.Charge, int, []byte, error) {
+	// Fetch the user who owns the ledger
+	user, code, body, err := c.ledgerUserFromVars(r, vars)
+	if err != nil || body != nil {
+		return nil, code, body, err
+	}
+
+	// Fetch input charge ID
+	strID, ok := vars["chargeId"]
+	if !ok {
+		return nil, duesCode[chargeMissingID], duesJSON[chargeMissingID], nil
+	}
+
+	// Convert string to integer
+	id, err := strconv.ParseUint(strID, 10, 64)
+	if err != nil {
+		return nil, duesCode[chargeInvalidID], duesJSON[chargeInvalidID], nil
+	}
+
+	// Select single charge by ID from the database, and verify that it belongs
+	// to this user
+	charge, err := c.db.SelectChargeByID(r.Context(), id)
+	if err != nil {
+		// If no results found, return HTTP not found
+		if err == sql.ErrNoRows {
+			return nil, duesCode[chargeNotFound], duesJSON[chargeNotFound], nil
+		}
+
+		return nil, http.StatusInternalServerError, nil, err
+	}
+	if charge.UserID != user.ID {
+		return nil, duesCode[chargeNotFound], duesJSON[chargeNotFound], nil
+	}
+
+	return charge, http.StatusOK, nil, nil
+}
+
+// paymentFromVars fetches the Payment which is the target of a request, using the
+// "id" and "paymentId" route variables.  On failure, it will return a message body
+// or an error, causing the caller to immediately send the result.
+func (c *Context) paymentFromVars(r *http.Request, vars util.Vars) (*models.Payment, int, []byte, error) {
+	// Fetch the user who owns the ledger
+	user, code, body, err := c.ledgerUserFromVars(r, vars)
+	if err != nil || body != nil {
+		return nil, code, body, err
+	}
+
+	// Fetch input payment ID
+	strID, ok := vars["paymentId"]
+	if !ok {
+		return nil, duesCode[paymentMissingID], duesJSON[paymentMissingID], nil
+	}
+
+	// Convert string to integer
+	id, err := strconv.ParseUint(strID, 10, 64)
+	if err != nil {
+		return nil, duesCode[paymentInvalidID], duesJSON[paymentInvalidID], nil
+	}
+
+	// Select single payment by ID from the database, and verify that it belongs
+	// to this user
+	payment, err := c.db.SelectPaymentByID(r.Context(), id)
+	if err != nil {
+		// If no results found, return HTTP not found
+		if err == sql.ErrNoRows {
+			return nil, duesCode[paymentNotFound], duesJSON[paymentNotFound], nil
+		}
+
+		return nil, http.StatusInternalServerError, nil, err
+	}
+	if payment.UserID != user.ID {
+		return nil, duesCode[paymentNotFound], duesJSON[paymentNotFound], nil
+	}
+
+	return payment, http.StatusOK, nil, nil
+}
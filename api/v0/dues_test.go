@@ -0,0 +1,348 @@
+package v0
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mdlayher/deltaiota/api/auth"
+	"github.com/mdlayher/deltaiota/api/util"
+	"github.com/mdlayher/deltaiota/data/models"
+	"github.com/mdlayher/deltaiota/ditest"
+)
+
+// TestPostCharge verifies that PostCharge returns the appropriate HTTP status
+// code, body, and any errors which occur.
+func TestPostCharge(t *testing.T) {
+	withContextUser(t, func(c *Context, user *models.User) error {
+		// Table of tests to iterate
+		var tests = []struct {
+			id         string
+			code       int
+			errMessage string
+			body       []byte
+		}{
+			// User not found
+			{"100", http.StatusNotFound, userNotFound, nil},
+			// Empty body
+			{"1", http.StatusBadRequest, positionJSONSyntax, nil},
+			// Missing kind
+			{"1", http.StatusBadRequest, "empty field: kind", []byte(`{"amount":5000,"due":1000}`)},
+			// Missing due date
+			{"1", http.StatusBadRequest, "empty field: due", []byte(`{"kind":"dues","amount":5000}`)},
+			// Unknown kind
+			{"1", http.StatusBadRequest, "invalid field: kind (unknown kind of charge)", []byte(`{"kind":"foo","amount":5000,"due":1000}`)},
+			// Negative amount
+			{"1", http.StatusBadRequest, "invalid field: amount (amount must be a positive number of cents)", []byte(`{"kind":"fine","amount":-100,"due":1000}`)},
+			// Fractional amount
+			{"1", http.StatusBadRequest, positionJSONSyntax, []byte(`{"kind":"fine","amount":10.5,"due":1000}`)},
+			// Valid request
+			{"1", http.StatusCreated, "", []byte(`{"userId":100,"kind":"dues","description":"Spring dues","amount":5000,"due":1000}`)},
+		}
+
+		// Iterate and run tests
+		for _, test := range tests {
+			// Generate HTTP request
+			r, err := http.NewRequest("POST", "/", bytes.NewReader(test.body))
+			if err != nil {
+				return err
+			}
+			r = auth.WithUser(r, user)
+
+			code, body, err := c.PostCharge(r, util.Vars{"id": test.id})
+			if err != nil {
+				return err
+			}
+
+			// Ensure proper HTTP status code
+			if code != test.code {
+				return fmt.Errorf("unexpected code: %v != %v", code, test.code)
+			}
+
+			// If code is in HTTP 400 or above, check error response
+			if code >= http.StatusBadRequest {
+				var errRes util.ErrorResponse
+				if err := json.Unmarshal(body, &errRes); err != nil {
+					return err
+				}
+
+				if errRes.Error.Message != test.errMessage {
+					return fmt.Errorf("unexpected error message: %v != %v", errRes.Error.Message, test.errMessage)
+				}
+
+				continue
+			}
+
+			// Verify charge belongs to user in route
+			var res ChargesResponse
+			if err := json.Unmarshal(body, &res); err != nil {
+				return err
+			}
+			if len(res.Charges) != 1 || res.Charges[0].UserID != user.ID || res.Charges[0].Amount != 5000 {
+				return fmt.Errorf("unexpected charges: %v", res.Charges)
+			}
+		}
+
+		return nil
+	})
+}
+
+// TestGetBalance verifies that GetBalance applies payments to the oldest charges
+// first when computing a user's balance and overdue amount.
+func TestGetBalance(t *testing.T) {
+	ctx := context.Background()
+
+	withContextLedger(t, func(c *Context, officer *models.User, user *models.User) error {
+		var tests = []struct {
+			payment models.Cents
+			balance models.Cents
+			overdue models.Cents
+		}{
+			// No payments, entire past due charge is overdue
+			{0, 8000, 5000},
+			// Partial payment of past due charge
+			{2000, 6000, 3000},
+			// Past due charge paid, with credit toward upcoming charge
+			{4000, 2000, 0},
+		}
+
+		for _, test := range tests {
+			if test.payment > 0 {
+				if err := c.db.InsertPayment(ctx, &models.Payment{
+					UserID:     user.ID,
+					Amount:     test.payment,
+					Method:     models.PaymentCash,
+					Timestamp:  uint64(time.Now().Unix()),
+					RecordedBy: officer.ID,
+				}); err != nil {
+					return err
+				}
+			}
+
+			r, err := http.NewRequest("GET", "/", nil)
+			if err != nil {
+				return err
+			}
+			r = auth.WithUser(r, user)
+
+			code, body, err := c.GetBalance(r, util.Vars{"id": fmt.Sprintf("%d", user.ID)})
+			if err != nil {
+				return err
+			}
+			if code != http.StatusOK {
+				return fmt.Errorf("unexpected code: %v != %v", code, http.StatusOK)
+			}
+
+			var res BalancesResponse
+			if err := json.Unmarshal(body, &res); err != nil {
+				return err
+			}
+
+			b := res.Balances[0]
+			if b.Balance != test.balance {
+				return fmt.Errorf("unexpected balance: %v != %v", b.Balance, test.balance)
+			}
+			if b.Overdue != test.overdue {
+				return fmt.Errorf("unexpected overdue: %v != %v", b.Overdue, test.overdue)
+			}
+		}
+
+		return nil
+	})
+}
+
+// TestListChargesForbidden verifies that users may not view another user's ledger,
+// unless they are an officer.
+func TestListChargesForbidden(t *testing.T) {
+	ctx := context.Background()
+
+	withContextLedger(t, func(c *Context, officer *models.User, user *models.User) error {
+		other := ditest.MockUser()
+		if err := c.db.InsertUser(ctx, other); err != nil {
+			return err
+		}
+
+		var tests = []struct {
+			as   *models.User
+			code int
+		}{
+			{user, http.StatusOK},
+			{officer, http.StatusOK},
+			{other, http.StatusForbidden},
+		}
+
+		for _, test := range tests {
+			r, err := http.NewRequest("GET", "/", nil)
+			if err != nil {
+				return err
+			}
+			r = auth.WithUser(r, test.as)
+
+			code, _, err := c.ListCharges(r, util.Vars{"id": fmt.Sprintf("%d", user.ID)})
+			if err != nil {
+				return err
+			}
+			if code != test.code {
+				return fmt.Errorf("unexpected code: %v != %v", code, test.code)
+			}
+		}
+
+		return nil
+	})
+}
+
+// TestListOverdue verifies that ListOverdue only reports users with an overdue
+// amount.
+func TestListOverdue(t *testing.T) {
+	withContextLedger(t, func(c *Context, officer *models.User, user *models.User) error {
+		code, body, err := c.ListOverdue(httptest.NewRequest("GET", "/", nil), util.Vars{})
+		if err != nil {
+			return err
+		}
+		if code != http.StatusOK {
+			return fmt.Errorf("unexpected code: %v != %v", code, http.StatusOK)
+		}
+
+		var res BalancesResponse
+		if err := json.Unmarshal(body, &res); err != nil {
+			return err
+		}
+
+		if len(res.Balances) != 1 {
+			return fmt.Errorf("unexpected number of overdue balances: %v != %v", len(res.Balances), 1)
+		}
+		if b := res.Balances[0]; b.UserID != user.ID || b.Overdue != 5000 {
+			return fmt.Errorf("unexpected overdue balance: %v", b)
+		}
+
+		return nil
+	})
+}
+
+// TestNotifyCharges verifies that users are notified once of upcoming and
+// overdue charges, and never of charges which are already paid.
+func TestNotifyCharges(t *testing.T) {
+	ctx := context.Background()
+
+	withContextLedger(t, func(c *Context, officer *models.User, user *models.User) error {
+		// Upcoming charge is due in one day, so a two day window includes it
+		window := 48 * time.Hour
+
+		notifications, err := c.db.NotifyCharges(ctx, time.Now(), window)
+		if err != nil {
+			return err
+		}
+		if len(notifications) != 2 {
+			return fmt.Errorf("unexpected number of notifications: %v != %v", len(notifications), 2)
+		}
+		if !strings.Contains(notifications[0].Text, "Upcoming dues charge of $30.00") {
+			return fmt.Errorf("unexpected upcoming notification: %v", notifications[0].Text)
+		}
+		if !strings.Contains(notifications[1].Text, "Overdue dues charge of $50.00") {
+			return fmt.Errorf("unexpected overdue notification: %v", notifications[1].Text)
+		}
+
+		// Notifications are only sent once
+		notifications, err = c.db.NotifyCharges(ctx, time.Now(), window)
+		if err != nil {
+			return err
+		}
+		if len(notifications) != 0 {
+			return fmt.Errorf("unexpected repeat notifications: %v", notifications)
+		}
+
+		// A paid charge produces no overdue notice
+		if err := c.db.InsertPayment(ctx, &models.Payment{
+			UserID:     user.ID,
+			Amount:     8000,
+			Method:     models.PaymentCheck,
+			Timestamp:  uint64(time.Now().Unix()),
+			RecordedBy: officer.ID,
+		}); err != nil {
+			return err
+		}
+
+		notifications, err = c.db.NotifyCharges(ctx, time.Now().Add(window), window)
+		if err != nil {
+			return err
+		}
+		if len(notifications) != 0 {
+			return fmt.Errorf("unexpected notifications for paid charge: %v", notifications)
+		}
+
+		return nil
+	})
+}
+
+// TestDuesDeletedUser verifies that a deleted user is not reported as overdue,
+// and is not notified of their charges.
+func TestDuesDeletedUser(t *testing.T) {
+	ctx := context.Background()
+
+	withContextLedger(t, func(c *Context, officer *models.User, user *models.User) error {
+		user.DeletedAt = uint64(time.Now().Unix())
+		if err := c.db.UpdateUser(ctx, user); err != nil {
+			return err
+		}
+
+		code, body, err := c.ListOverdue(httptest.NewRequest("GET", "/", nil), util.Vars{})
+		if err != nil {
+			return err
+		}
+		if code != http.StatusOK {
+			return fmt.Errorf("unexpected code: %v != %v", code, http.StatusOK)
+		}
+
+		var res BalancesResponse
+		if err := json.Unmarshal(body, &res); err != nil {
+			return err
+		}
+		if len(res.Balances) != 0 {
+			return fmt.Errorf("unexpected overdue balances: %v", res.Balances)
+		}
+
+		notifications, err := c.db.NotifyCharges(ctx, time.Now(), 48*time.Hour)
+		if err != nil {
+			return err
+		}
+		if len(notifications) != 0 {
+			return fmt.Errorf("unexpected notifications for deleted user: %v", notifications)
+		}
+
+		return nil
+	})
+}
+
+// withContextLedger builds upon withContext, adding an officer, and a user with
+// a past due charge of $50.00 and an upcoming charge of $30.00.
+func withContextLedger(t *testing.T, fn func(c *Context, officer *models.User, user *models.User) error) {
+	ctx := context.Background()
+
+	withContextOfficer(t, func(c *Context, officer *models.User, position *models.Position, term *models.Term) error {
+		user := ditest.MockUser()
+		if err := c.db.InsertUser(ctx, user); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		for _, charge := range []*models.Charge{
+			{Amount: 5000, Due: uint64(now.Add(-24 * time.Hour).Unix())},
+			{Amount: 3000, Due: uint64(now.Add(24 * time.Hour).Unix())},
+		} {
+			charge.UserID = user.ID
+			charge.Kind = models.ChargeDues
+			charge.Created = uint64(now.Unix())
+			if err := c.db.InsertCharge(ctx, charge); err != nil {
+				return err
+			}
+		}
+
+		return fn(c, officer, user)
+	})
+}
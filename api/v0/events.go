@@ -0,0 +1,376 @@
+package v0
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/mdlayher/deltaiota/api/util"
+	"github.com/mdlayher/deltaiota/data/models"
+)
+
+// JSON Events API, human-readable client error responses.
+const (
+	// HTTP GET
+	eventInvalidID = "invalid event ID"
+	eventMissingID = "missing event ID"
+	eventNotFound  = "event not found"
+	rsvpNotFound   = "RSVP not found"
+)
+
+// JSON Events API, map of client errors to response codes.
+var eventsCode = map[string]int{
+	// HTTP GET
+	eventInvalidID: http.StatusBadRequest,
+	eventMissingID: http.StatusBadRequest,
+	eventNotFound:  http.StatusNotFound,
+	rsvpNotFound:   http.StatusNotFound,
+}
+
+// Generated JSON responses for various client-facing errors.
+var eventsJSON = map[string][]byte{}
+
+// init initializes the stored JSON responses for client-facing errors.
+func init() {
+	// Iterate all error strings and code integers
+	for k, v := range eventsCode {
+		// Generate error response with appropriate string and code
+		body, err := json.Marshal(util.ErrRes(v, k))
+		if err != nil {
+			panic(err)
+		}
+
+		// Store for later use
+		eventsJSON[k] = body
+	}
+}
+
+// EventsResponse is the output response for the Events API.
+type EventsResponse struct {
+	Events []*models.Event `json:"events"`
+}
+
+// RSVPsResponse is the output response for the RSVPs API.
+type RSVPsResponse struct {
+	RSVPs []*models.RSVP `json:"rsvps"`
+}
+
+// EventsAPI is a util.JSONAPIFunc, and is the single entry point for the Events API.
+// This method delegates to other methods as appropriate to handle incoming requests.
+func (c *Context) EventsAPI(r *http.Request, vars util.Vars) (int, []byte, error) {
+	// Switch based on HTTP method
+	switch r.Method {
+	case "GET", "HEAD":
+		// If ID present, request for single event
+		if _, ok := vars["id"]; ok {
+			return c.GetEvent(r, vars)
+		}
+
+		// No ID, request for list of events
+		return c.ListEvents(r, vars)
+	case "POST":
+		return c.PostEvent(r, vars)
+	case "PUT":
+		return c.PutEvent(r, vars)
+	case "DELETE":
+		return c.DeleteEvent(r, vars)
+	default:
+		return util.MethodNotAllowed(r, vars)
+	}
+}
+
+// ListEvents is a util.JSONAPIFunc which returns HTTP 200 and a JSON list of
+// events, ordered by start time, on success, or a non-200 HTTP status code and
+// an error response on failure.
+func (c *Context) ListEvents(r *http.Request, vars util.Vars) (int, []byte, error) {
+	// Fetch a list of all events from the database
+	events, err := c.db.SelectAllEvents(r.Context())
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+
+	// Wrap in response and return
+	body, err := json.Marshal(EventsResponse{
+		Events: events,
+	})
+	return http.StatusOK, body, err
+}
+
+// GetEvent is a util.JSONAPIFunc which returns HTTP 200 and a JSON event object
+// on success, or a non-200 HTTP status code and an error response on failure.
+func (c *Context) GetEvent(r *http.Request, vars util.Vars) (int, []byte, error) {
+	// Fetch the event
+	event, code, body, err := c.eventFromVars(r.Context(), vars)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+	if body != nil {
+		return code, body, nil
+	}
+
+	// Wrap in response and return
+	body, err = json.Marshal(EventsResponse{
+		Events: []*models.Event{event},
+	})
+	return http.StatusOK, body, err
+}
+
+// PostEvent is a util.JSONAPIFunc which creates an Event and returns HTTP 201
+// and a JSON event object on success, or a non-200 HTTP status code and an
+// error response on failure.
+func (c *Context) PostEvent(r *http.Request, vars util.Vars) (int, []byte, error) {
+	// Read and validate request input into an Event struct
+	event := new(models.Event)
+	code, body, err := decodeAndValidate(r, event)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+	if body != nil {
+		return code, body, nil
+	}
+
+	// Set creation and modification times
+	now := uint64(time.Now().Unix())
+	event.ID = 0
+	event.Created = now
+	event.Updated = now
+
+	// No body written, all checks passed, so insert new event
+	if err := c.db.InsertEvent(r.Context(), event); err != nil {
+		return util.JSONAPIErr(err)
+	}
+
+	// Wrap in response and return
+	body, err = json.Marshal(EventsResponse{
+		Events: []*models.Event{event},
+	})
+	return http.StatusCreated, body, err
+}
+
+// PutEvent is a util.JSONAPIFunc which updates an Event and returns HTTP 200
+// and a JSON event object on success, or a non-200 HTTP status code and an
+// error response on failure.
+func (c *Context) PutEvent(r *http.Request, vars util.Vars) (int, []byte, error) {
+	// Fetch the event
+	event, code, body, err := c.eventFromVars(r.Context(), vars)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+	if body != nil {
+		return code, body, nil
+	}
+
+	// Read and validate request input into an Event struct
+	newEvent := new(models.Event)
+	code, body, err = decodeAndValidate(r, newEvent)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+	if body != nil {
+		return code, body, nil
+	}
+
+	// Update existing event with new fields, bumping its modification time so
+	// that calendar applications pick up the change
+	event.CopyFrom(newEvent)
+	event.Updated = uint64(time.Now().Unix())
+	if err := c.db.UpdateEvent(r.Context(), event); err != nil {
+		return util.JSONAPIErr(err)
+	}
+
+	// Wrap in response and return
+	body, err = json.Marshal(EventsResponse{
+		Events: []*models.Event{event},
+	})
+	return http.StatusOK, body, err
+}
+
+// DeleteEvent is a util.JSONAPIFunc which deletes an Event and all of its RSVPs,
+// and returns HTTP 204 on success, or a non-200 HTTP status code and an error
+// response on failure.
+func (c *Context) DeleteEvent(r *http.Request, vars util.Vars) (int, []byte, error) {
+	// Fetch the event
+	event, code, body, err := c.eventFromVars(r.Context(), vars)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+	if body != nil {
+		return code, body, nil
+	}
+
+	if err := c.db.DeleteEvent(r.Context(), event); err != nil {
+		return util.JSONAPIErr(err)
+	}
+
+	return http.StatusNoContent, nil, nil
+}
+
+// RSVPsAPI is a util.JSONAPIFunc, and is the single entry point for listing the
+// RSVPs to an event.
+// This method delegates to other methods as appropriate to handle incoming requests.
+func (c *Context) RSVPsAPI(r *http.Request, vars util.Vars) (int, []byte, error) {
+	// Switch based on HTTP method
+	switch r.Method {
+	case "GET", "HEAD":
+		return c.ListRSVPs(r, vars)
+	default:
+		return util.MethodNotAllowed(r, vars)
+	}
+}
+
+// ListRSVPs is a util.JSONAPIFunc which returns HTTP 200 and a JSON list of all
+// RSVPs to an event on success, or a non-200 HTTP status code and an error
+// response on failure.
+func (c *Context) ListRSVPs(r *http.Request, vars util.Vars) (int, []byte, error) {
+	// Fetch the event
+	event, code, body, err := c.eventFromVars(r.Context(), vars)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+	if body != nil {
+		return code, body, nil
+	}
+
+	rsvps, err := c.db.SelectRSVPsByEventID(r.Context(), event.ID)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+
+	// Wrap in response and return
+	body, err = json.Marshal(RSVPsResponse{
+		RSVPs: rsvps,
+	})
+	return http.StatusOK, body, err
+}
+
+// RSVPAPI is a util.JSONAPIFunc, and is the single entry point for managing the
+// authenticated user's RSVP to an event.
+// This method delegates to other methods as appropriate to handle incoming requests.
+func (c *Context) RSVPAPI(r *http.Request, vars util.Vars) (int, []byte, error) {
+	// Switch based on HTTP method
+	switch r.Method {
+	case "PUT":
+		return c.PutRSVP(r, vars)
+	case "DELETE":
+		return c.DeleteRSVP(r, vars)
+	default:
+		return util.MethodNotAllowed(r, vars)
+	}
+}
+
+// PutRSVP is a util.JSONAPIFunc which sets the authenticated user's RSVP to an
+// event, and returns HTTP 200 and a JSON RSVP object on success, or a non-200
+// HTTP status code and an error response on failure.
+func (c *Context) PutRSVP(r *http.Request, vars util.Vars) (int, []byte, error) {
+	// Fetch the event
+	event, code, body, err := c.eventFromVars(r.Context(), vars)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+	if body != nil {
+		return code, body, nil
+	}
+
+	// Read and validate request input into an RSVP struct
+	rsvp := new(models.RSVP)
+	code, body, err = decodeAndValidate(r, rsvp)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+	if body != nil {
+		return code, body, nil
+	}
+
+	// Users may only respond for themselves
+	user, code, body := authUser(r)
+	if body != nil {
+		return code, body, nil
+	}
+	rsvp.EventID = event.ID
+	rsvp.UserID = user.ID
+	rsvp.Updated = uint64(time.Now().Unix())
+	if err := c.db.SetRSVP(r.Context(), rsvp); err != nil {
+		return util.JSONAPIErr(err)
+	}
+
+	// Wrap in response and return
+	body, err = json.Marshal(RSVPsResponse{
+		RSVPs: []*models.RSVP{rsvp},
+	})
+	return http.StatusOK, body, err
+}
+
+// DeleteRSVP is a util.JSONAPIFunc which removes the authenticated user's RSVP
+// to an event, and returns HTTP 204 on success, or a non-200 HTTP status code and
+// an error response on failure.
+func (c *Context) DeleteRSVP(r *http.Request, vars util.Vars) (int, []byte, error) {
+	// Fetch the event
+	event, code, body, err := c.eventFromVars(r.Context(), vars)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+	if body != nil {
+		return code, body, nil
+	}
+
+	// Verify the user has responded to this event
+	user, code, body := authUser(r)
+	if body != nil {
+		return code, body, nil
+	}
+	userID := user.ID
+	rsvps, err := c.db.SelectRSVPsByEventID(r.Context(), event.ID)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+
+	var rsvp *models.RSVP
+	for _, rv := range rsvps {
+		if rv.UserID == userID {
+			rsvp = rv
+			break
+		}
+	}
+	if rsvp == nil {
+		return eventsCode[rsvpNotFound], eventsJSON[rsvpNotFound], nil
+	}
+
+	if err := c.db.DeleteRSVP(r.Context(), rsvp); err != nil {
+		return util.JSONAPIErr(err)
+	}
+
+	return http.StatusNoContent, nil, nil
+}
+
+// eventFromVars fetches the Event which is the target of a request, using the
+// "id" route variable.  On failure, it will return a message body or an error,
+// causing the caller to immediately send the result.
+func (c *Context) eventFromVars(ctx context.Context, vars util.Vars) (*models.Event, int, []byte, error) {
+	// Fetch input event ID
+	strID, ok := vars["id"]
+	if !ok {
+		return nil, eventsCode[eventMissingID], eventsJSON[eventMissingID], nil
+	}
+
+	// Convert string to integer
+	id, err := strconv.ParseUint(strID, 10, 64)
+	if err != nil {
+		return nil, eventsCode[eventInvalidID], eventsJSON[eventInvalidID], nil
+	}
+
+	// Select single event by ID from the database
+	event, err := c.db.SelectEventByID(ctx, id)
+	if err != nil {
+		// If no results found, return HTTP not found
+		if err == sql.ErrNoRows {
+			return nil, eventsCode[eventNotFound], eventsJSON[eventNotFound], nil
+		}
+
+		return nil, http.StatusInternalServerError, nil, err
+	}
+
+	return event, http.StatusOK, nil, nil
+}
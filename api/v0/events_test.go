@@ -0,0 +1,234 @@
+package v0
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mdlayher/deltaiota/api/auth"
+	"github.com/mdlayher/deltaiota/api/util"
+	"github.com/mdlayher/deltaiota/data/models"
+	"github.com/mdlayher/deltaiota/ditest"
+)
+
+// TestPostEvent verifies that PostEvent validates input and creates events.
+func TestPostEvent(t *testing.T) {
+	withContext(t, func(c *Context) error {
+		// Table of tests to iterate
+		var tests = []struct {
+			body       []byte
+			code       int
+			errMessage string
+		}{
+			// Bad JSON
+			{[]byte(`{`), http.StatusBadRequest, positionJSONSyntax},
+			// Missing title
+			{[]byte(`{"start":1000}`), http.StatusBadRequest, "empty field: title"},
+			// Missing start
+			{[]byte(`{"title":"Meeting"}`), http.StatusBadRequest, "empty field: start"},
+			// Ends before it starts
+			{[]byte(`{"title":"Meeting","start":1000,"end":500}`), http.StatusBadRequest, "invalid field: end (event must end after it starts)"},
+			// Valid event
+			{[]byte(`{"title":"Meeting","start":1000,"end":2000}`), http.StatusCreated, ""},
+		}
+
+		// Iterate and run tests
+		for _, test := range tests {
+			// Generate HTTP request
+			r, err := http.NewRequest("POST", "/", bytes.NewReader(test.body))
+			if err != nil {
+				return err
+			}
+
+			code, body, err := c.PostEvent(r, util.Vars{})
+			if err != nil {
+				return err
+			}
+
+			// Ensure proper HTTP status code
+			if code != test.code {
+				return fmt.Errorf("unexpected code: %v != %v", code, test.code)
+			}
+
+			// If code is in HTTP 400 or above, check error response
+			if code >= http.StatusBadRequest {
+				var errRes util.ErrorResponse
+				if err := json.Unmarshal(body, &errRes); err != nil {
+					return err
+				}
+
+				if errRes.Error.Message != test.errMessage {
+					return fmt.Errorf("unexpected error message: %v != %v", errRes.Error.Message, test.errMessage)
+				}
+
+				continue
+			}
+
+			// Check event is stamped with creation time
+			var eRes EventsResponse
+			if err := json.Unmarshal(body, &eRes); err != nil {
+				return err
+			}
+
+			event := eRes.Events[0]
+			if event.Created == 0 || event.Updated != event.Created {
+				return fmt.Errorf("unexpected event times: %v, %v", event.Created, event.Updated)
+			}
+		}
+
+		return nil
+	})
+}
+
+// TestPutRSVP verifies that PutRSVP and DeleteRSVP manage the authenticated
+// user's response to an event.
+func TestPutRSVP(t *testing.T) {
+	ctx := context.Background()
+
+	withContextUser(t, func(c *Context, user *models.User) error {
+		event := &models.Event{
+			Title: "Meeting",
+			Start: uint64(time.Now().Unix()),
+		}
+		if err := c.db.InsertEvent(ctx, event); err != nil {
+			return err
+		}
+		id := fmt.Sprintf("%d", event.ID)
+
+		// Table of tests to iterate
+		var tests = []struct {
+			vars       util.Vars
+			body       []byte
+			code       int
+			errMessage string
+		}{
+			// Event not found
+			{util.Vars{"id": "100"}, []byte(`{"response":"yes"}`), http.StatusNotFound, eventNotFound},
+			// Missing response
+			{util.Vars{"id": id}, []byte(`{}`), http.StatusBadRequest, "empty field: response"},
+			// Unknown response
+			{util.Vars{"id": id}, []byte(`{"response":"perhaps"}`), http.StatusBadRequest, "invalid field: response (response must be one of: yes, no, maybe)"},
+			// Valid response
+			{util.Vars{"id": id}, []byte(`{"response":"maybe"}`), http.StatusOK, ""},
+		}
+
+		// Iterate and run tests
+		for _, test := range tests {
+			// Generate HTTP request
+			r, err := http.NewRequest("PUT", "/", bytes.NewReader(test.body))
+			if err != nil {
+				return err
+			}
+			r = auth.WithUser(r, user)
+
+			code, body, err := c.PutRSVP(r, test.vars)
+			if err != nil {
+				return err
+			}
+
+			// Ensure proper HTTP status code
+			if code != test.code {
+				return fmt.Errorf("unexpected code: %v != %v", code, test.code)
+			}
+
+			// If code is in HTTP 400 or above, check error response
+			if code >= http.StatusBadRequest {
+				var errRes util.ErrorResponse
+				if err := json.Unmarshal(body, &errRes); err != nil {
+					return err
+				}
+
+				if errRes.Error.Message != test.errMessage {
+					return fmt.Errorf("unexpected error message: %v != %v", errRes.Error.Message, test.errMessage)
+				}
+			}
+		}
+
+		// Verify RSVP is stored for authenticated user
+		rsvps, err := c.db.SelectRSVPsByEventID(ctx, event.ID)
+		if err != nil {
+			return err
+		}
+		if len(rsvps) != 1 || rsvps[0].UserID != user.ID || rsvps[0].Response != models.RSVPMaybe {
+			return fmt.Errorf("unexpected RSVPs: %v", rsvps)
+		}
+
+		// Remove RSVP, and verify it cannot be removed twice
+		for _, code := range []int{http.StatusNoContent, http.StatusNotFound} {
+			r, err := http.NewRequest("DELETE", "/", nil)
+			if err != nil {
+				return err
+			}
+			r = auth.WithUser(r, user)
+
+			got, _, err := c.DeleteRSVP(r, util.Vars{"id": id})
+			if err != nil {
+				return err
+			}
+			if got != code {
+				return fmt.Errorf("unexpected code: %v != %v", got, code)
+			}
+		}
+
+		return nil
+	})
+}
+
+// TestListRSVPsDeletedUser verifies that ListRSVPs omits the responses of
+// deleted users.
+func TestListRSVPsDeletedUser(t *testing.T) {
+	ctx := context.Background()
+
+	withContextUser(t, func(c *Context, user *models.User) error {
+		deleted := ditest.MockUser()
+		if err := c.db.InsertUser(ctx, deleted); err != nil {
+			return err
+		}
+
+		event := &models.Event{
+			Title: "Meeting",
+			Start: uint64(time.Now().Unix()),
+		}
+		if err := c.db.InsertEvent(ctx, event); err != nil {
+			return err
+		}
+
+		for _, u := range []*models.User{user, deleted} {
+			if err := c.db.SetRSVP(ctx, &models.RSVP{
+				EventID:  event.ID,
+				UserID:   u.ID,
+				Response: models.RSVPYes,
+			}); err != nil {
+				return err
+			}
+		}
+
+		deleted.DeletedAt = uint64(time.Now().Unix())
+		if err := c.db.UpdateUser(ctx, deleted); err != nil {
+			return err
+		}
+
+		code, body, err := c.ListRSVPs(httptest.NewRequest("GET", "/", nil), util.Vars{"id": fmt.Sprintf("%d", event.ID)})
+		if err != nil {
+			return err
+		}
+		if code != http.StatusOK {
+			return fmt.Errorf("unexpected code: %v != %v", code, http.StatusOK)
+		}
+
+		var res RSVPsResponse
+		if err := json.Unmarshal(body, &res); err != nil {
+			return err
+		}
+		if len(res.RSVPs) != 1 || res.RSVPs[0].UserID != user.ID {
+			return fmt.Errorf("unexpected RSVPs: %v", res.RSVPs)
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,450 @@
+package v0
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/mdlayher/deltaiota/api/util"
+	"github.com/mdlayher/deltaiota/data"
+	"github.com/mdlayher/deltaiota/data/models"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	// dotContentType is the HTTP Content-Type for Graphviz DOT documents.
+	dotContentType = "text/vnd.graphviz"
+)
+
+// JSON Family API, human-readable client error responses.
+const (
+	familyCycle        = "big brother would create a cycle in the family tree"
+	familyJSONSyntax   = "invalid JSON request"
+	familyNoBigBrother = "user has no big brother"
+	familyBigNotFound  = "big brother not found"
+)
+
+// JSON Family API, map of client errors to response codes.
+var familyCode = map[string]int{
+	familyCycle:        http.StatusConflict,
+	familyJSONSyntax:   http.StatusBadRequest,
+	familyNoBigBrother: http.StatusNotFound,
+	familyBigNotFound:  http.StatusBadRequest,
+}
+
+// Generated JSON responses for various client-facing errors.
+var familyJSON = map[string][]byte{}
+
+// init initializes the stored JSON responses for client-facing errors.
+func init() {
+	// Iterate all error strings and code integers
+	for k, v := range familyCode {
+		// Generate error response with appropriate string and code
+		body, err := json.Marshal(util.ErrRes(v, k))
+		if err != nil {
+			panic(err)
+		}
+
+		// Store for later use
+		familyJSON[k] = body
+	}
+}
+
+// BigBrotherRequest is the input request for setting a user's big brother.
+// A BigBrotherID of 0 removes the user's big brother.
+type BigBrotherRequest struct {
+	BigBrotherID uint64 `json:"bigBrotherId"`
+}
+
+// FamilyResponse is the output response for the Family API.
+type FamilyResponse struct {
+	Family *FamilyNode `json:"family"`
+}
+
+// FamilyNode is a single user within a family tree, along with the nodes of
+// their littles.
+type FamilyNode struct {
+	User    *models.User  `json:"user"`
+	Littles []*FamilyNode `json:"littles"`
+}
+
+// BigBrotherAPI is a util.JSONAPIFunc, and is the single entry point for managing
+// a user's big brother.
+// This method delegates to other methods as appropriate to handle incoming requests.
+func (c *Context) BigBrotherAPI(r *http.Request, vars util.Vars) (int, []byte, error) {
+	// Switch based on HTTP method
+	switch r.Method {
+	case "GET", "HEAD":
+		return c.GetBigBrother(r, vars)
+	case "PUT":
+		return c.PutBigBrother(r, vars)
+	case "DELETE":
+		return c.DeleteBigBrother(r, vars)
+	default:
+		return util.MethodNotAllowed(r, vars)
+	}
+}
+
+// GetBigBrother is a util.JSONAPIFunc which returns HTTP 200 and a JSON user
+// object for a user's big brother on success, or a non-200 HTTP status code and
+// an error response on failure.
+func (c *Context) GetBigBrother(r *http.Request, vars util.Vars) (int, []byte, error) {
+	// Fetch the little
+	user, code, body, err := c.userFromVars(r.Context(), vars)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+	if body != nil {
+		return code, body, nil
+	}
+
+	if user.BigBrotherID == 0 {
+		return familyCode[familyNoBigBrother], familyJSON[familyNoBigBrother], nil
+	}
+
+	// Fetch the big brother
+	big, err := c.db.SelectUserByID(r.Context(), user.BigBrotherID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return familyCode[familyNoBigBrother], familyJSON[familyNoBigBrother], nil
+		}
+
+		return util.JSONAPIErr(err)
+	}
+
+	// Strip sensitive fields from output, and apply privacy settings
+	p, err := c.privacy(r)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+	sanitizeUser(big, p)
+
+	// Wrap in response and return
+	body, err = json.Marshal(UsersResponse{
+		Users: []*models.User{big},
+	})
+	return http.StatusOK, body, err
+}
+
+// PutBigBrother is a util.JSONAPIFunc which sets a user's big brother and returns
+// HTTP 200 and a JSON user object on success, or a non-200 HTTP status code and
+// an error response on failure.
+func (c *Context) PutBigBrother(r *http.Request, vars util.Vars) (int, []byte, error) {
+	// Fetch the little
+	user, code, body, err := c.userFromVars(r.Context(), vars)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+	if body != nil {
+		return code, body, nil
+	}
+
+	// Do not allow nil body
+	if r.Body == nil {
+		return familyCode[familyJSONSyntax], familyJSON[familyJSONSyntax], nil
+	}
+
+	// Unmarshal body into a request
+	var req BigBrotherRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		// Check for bad input JSON
+		if _, ok := err.(*json.SyntaxError); ok || err == io.EOF || err == io.ErrUnexpectedEOF {
+			return familyCode[familyJSONSyntax], familyJSON[familyJSONSyntax], nil
+		}
+
+		return util.JSONAPIErr(err)
+	}
+
+	// Verify big brother exists
+	if req.BigBrotherID != 0 {
+		if _, err := c.db.SelectUserByID(r.Context(), req.BigBrotherID); err != nil {
+			if err == sql.ErrNoRows {
+				return familyCode[familyBigNotFound], familyJSON[familyBigNotFound], nil
+			}
+
+			return util.JSONAPIErr(err)
+		}
+	}
+
+	// Set big brother, checking for cycles in the family tree
+	if err := c.db.SetBigBrother(r.Context(), user.ID, req.BigBrotherID); err != nil {
+		if err == data.ErrFamilyCycle {
+			return familyCode[familyCycle], familyJSON[familyCycle], nil
+		}
+
+		return util.JSONAPIErr(err)
+	}
+	user.BigBrotherID = req.BigBrotherID
+
+	// Strip sensitive fields from output, and apply privacy settings
+	p, err := c.privacy(r)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+	sanitizeUser(user, p)
+
+	// Wrap in response and return
+	body, err = json.Marshal(UsersResponse{
+		Users: []*models.User{user},
+	})
+	return http.StatusOK, body, err
+}
+
+// DeleteBigBrother is a util.JSONAPIFunc which removes a user's big brother and
+// returns HTTP 204 on success, or a non-200 HTTP status code and an error response
+// on failure.
+func (c *Context) DeleteBigBrother(r *http.Request, vars util.Vars) (int, []byte, error) {
+	// Fetch the little
+	user, code, body, err := c.userFromVars(r.Context(), vars)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+	if body != nil {
+		return code, body, nil
+	}
+
+	if user.BigBrotherID == 0 {
+		return familyCode[familyNoBigBrother], familyJSON[familyNoBigBrother], nil
+	}
+
+	if err := c.db.SetBigBrother(r.Context(), user.ID, 0); err != nil {
+		return util.JSONAPIErr(err)
+	}
+
+	return http.StatusNoContent, nil, nil
+}
+
+// LittlesAPI is a util.JSONAPIFunc, and is the single entry point for listing
+// a user's littles.
+// This method delegates to other methods as appropriate to handle incoming requests.
+func (c *Context) LittlesAPI(r *http.Request, vars util.Vars) (int, []byte, error) {
+	// Switch based on HTTP method
+	switch r.Method {
+	case "GET", "HEAD":
+		return c.ListLittles(r, vars)
+	default:
+		return util.MethodNotAllowed(r, vars)
+	}
+}
+
+// ListLittles is a util.JSONAPIFunc which returns HTTP 200 and a JSON list of
+// a user's littles on success, or a non-200 HTTP status code and an error response
+// on failure.
+func (c *Context) ListLittles(r *http.Request, vars util.Vars) (int, []byte, error) {
+	// Fetch the big brother
+	user, code, body, err := c.userFromVars(r.Context(), vars)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+	if body != nil {
+		return code, body, nil
+	}
+
+	littles, err := c.db.SelectLittlesByUserID(r.Context(), user.ID)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+
+	// Strip sensitive fields from output, and apply privacy settings
+	p, err := c.privacy(r)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+	for i := range littles {
+		sanitizeUser(littles[i], p)
+	}
+
+	// Wrap in response and return
+	body, err = json.Marshal(UsersResponse{
+		Users: littles,
+	})
+	return http.StatusOK, body, err
+}
+
+// FamilyAPI is a util.JSONAPIFunc, and is the single entry point for retrieving
+// a user's family tree as JSON.
+// This method delegates to other methods as appropriate to handle incoming requests.
+func (c *Context) FamilyAPI(r *http.Request, vars util.Vars) (int, []byte, error) {
+	// Switch based on HTTP method
+	switch r.Method {
+	case "GET", "HEAD":
+		return c.GetFamily(r, vars)
+	default:
+		return util.MethodNotAllowed(r, vars)
+	}
+}
+
+// GetFamily is a util.JSONAPIFunc which returns HTTP 200 and a user's family tree
+// as nested JSON on success, or a non-200 HTTP status code and an error response
+// on failure.  The tree is rooted at the user's most distant big brother, and
+// contains the user's line of big brothers, the user, and all of the user's
+// descendants.
+func (c *Context) GetFamily(r *http.Request, vars util.Vars) (int, []byte, error) {
+	p, err := c.privacy(r)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+
+	root, _, code, body, err := c.familyTree(r.Context(), vars, p)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+	if body != nil {
+		return code, body, nil
+	}
+
+	// Wrap in response and return
+	body, err = json.Marshal(FamilyResponse{
+		Family: root,
+	})
+	return http.StatusOK, body, err
+}
+
+// GetFamilyDOT is a http.HandlerFunc which writes HTTP 200 and a user's family
+// tree as a Graphviz DOT document on success, or a non-200 HTTP status code and
+// a JSON error response on failure.
+func (c *Context) GetFamilyDOT(w http.ResponseWriter, r *http.Request) {
+	// Write a JSON error response to the client
+	writeErr := func(code int, body []byte) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(code)
+		if r.Method != "HEAD" {
+			w.Write(util.AddRequestID(r, body))
+		}
+	}
+
+	p, err := c.privacy(r)
+	if err != nil {
+		util.Logger(r).Error("request error", "err", err)
+		writeErr(util.Code[util.InternalServerError], util.JSON[util.InternalServerError])
+		return
+	}
+
+	root, user, code, body, err := c.familyTree(r.Context(), util.Vars(mux.Vars(r)), p)
+	if err != nil {
+		util.Logger(r).Error("request error", "err", err)
+		writeErr(util.Code[util.InternalServerError], util.JSON[util.InternalServerError])
+		return
+	}
+	if body != nil {
+		writeErr(code, body)
+		return
+	}
+
+	buf := bytes.NewBuffer(nil)
+	writeFamilyDOT(buf, root, user.ID)
+
+	w.Header().Set("Content-Type", dotContentType)
+	w.WriteHeader(http.StatusOK)
+	if r.Method != "HEAD" {
+		w.Write(buf.Bytes())
+	}
+}
+
+// familyTree builds the family tree for the user which is the target of a request,
+// returning the root of the tree and the target user.  On failure, it will return
+// a message body or an error, causing the caller to immediately send the result.
+// Users in the tree are sanitized using the input privacy settings.
+func (c *Context) familyTree(ctx context.Context, vars util.Vars, p privacy) (*FamilyNode, *models.User, int, []byte, error) {
+	user, code, body, err := c.userFromVars(ctx, vars)
+	if err != nil || body != nil {
+		return nil, nil, code, body, err
+	}
+
+	ancestors, err := c.db.SelectAncestorsByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, nil, http.StatusInternalServerError, nil, err
+	}
+	descendants, err := c.db.SelectDescendantsByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, nil, http.StatusInternalServerError, nil, err
+	}
+
+	// Group descendants by their big brother
+	littles := make(map[uint64][]*models.User)
+	for _, d := range descendants {
+		sanitizeUser(d, p)
+		littles[d.BigBrotherID] = append(littles[d.BigBrotherID], d)
+	}
+
+	// Build the user's subtree, tracking visited users so that each user appears
+	// in the tree only once
+	sanitizeUser(user, p)
+	visited := map[uint64]bool{user.ID: true}
+	var build func(u *models.User) *FamilyNode
+	build = func(u *models.User) *FamilyNode {
+		n := &FamilyNode{
+			User:    u,
+			Littles: []*FamilyNode{},
+		}
+		for _, l := range littles[u.ID] {
+			if visited[l.ID] {
+				continue
+			}
+			visited[l.ID] = true
+
+			n.Littles = append(n.Littles, build(l))
+		}
+
+		return n
+	}
+	root := build(user)
+
+	// Wrap the subtree in the user's line of big brothers, nearest first
+	for _, a := range ancestors {
+		if visited[a.ID] {
+			break
+		}
+		visited[a.ID] = true
+
+		sanitizeUser(a, p)
+		root = &FamilyNode{
+			User:    a,
+			Littles: []*FamilyNode{root},
+		}
+	}
+
+	return root, user, http.StatusOK, nil, nil
+}
+
+// writeFamilyDOT writes a family tree rooted at the input node as a Graphviz
+// DOT directed graph, with edges pointing from big brother to little.  The user
+// with the input ID is highlighted.
+func writeFamilyDOT(w io.Writer, root *FamilyNode, highlightID uint64) {
+	fmt.Fprintln(w, "digraph family {")
+	fmt.Fprintln(w, "\tnode [shape=box];")
+
+	var walk func(n *FamilyNode)
+	walk = func(n *FamilyNode) {
+		style := ""
+		if n.User.ID == highlightID {
+			style = ", style=bold"
+		}
+
+		label := strings.TrimSpace(n.User.FirstName + " " + n.User.LastName)
+		if label == "" {
+			label = n.User.Username
+		}
+		fmt.Fprintf(w, "\t%d [label=%s%s];\n", n.User.ID, dotQuote(label), style)
+
+		for _, l := range n.Littles {
+			fmt.Fprintf(w, "\t%d -> %d;\n", n.User.ID, l.User.ID)
+			walk(l)
+		}
+	}
+	walk(root)
+
+	fmt.Fprintln(w, "}")
+}
+
+// dotQuote returns the input string as a quoted Graphviz DOT string.
+func dotQuote(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", "")
+	return `"` + r.Replace(s) + `"`
+}
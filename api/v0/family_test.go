@@ -0,0 +1,295 @@
+package v0
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mdlayher/deltaiota/api/util"
+	"github.com/mdlayher/deltaiota/data/models"
+	"github.com/mdlayher/deltaiota/ditest"
+
+	"github.com/gorilla/mux"
+)
+
+// TestPutBigBrother verifies that PutBigBrother returns the appropriate HTTP
+// status code, body, and any errors which occur.
+func TestPutBigBrother(t *testing.T) {
+	ctx := context.Background()
+
+	withContextFamily(t, func(c *Context, users []*models.User) error {
+		// Table of tests to iterate
+		var tests = []struct {
+			id         string
+			code       int
+			errMessage string
+			body       []byte
+		}{
+			// ID not found
+			{"100", http.StatusNotFound, userNotFound, nil},
+			// Empty body
+			{"5", http.StatusBadRequest, familyJSONSyntax, nil},
+			// Bad JSON
+			{"5", http.StatusBadRequest, familyJSONSyntax, []byte(`{`)},
+			// Big brother not found
+			{"5", http.StatusBadRequest, familyBigNotFound, []byte(`{"bigBrotherId":100}`)},
+			// Own big brother
+			{"5", http.StatusConflict, familyCycle, []byte(`{"bigBrotherId":5}`)},
+			// Descendant as big brother
+			{"1", http.StatusConflict, familyCycle, []byte(`{"bigBrotherId":3}`)},
+			// Valid request
+			{"5", http.StatusOK, "", []byte(`{"bigBrotherId":3}`)},
+		}
+
+		// Iterate and run tests
+		for _, test := range tests {
+			// Generate HTTP request
+			r, err := http.NewRequest("PUT", "/", bytes.NewReader(test.body))
+			if err != nil {
+				return err
+			}
+
+			// Invoke PutBigBrother with HTTP request, manually injecting
+			// path variables from test
+			code, body, err := c.PutBigBrother(r, util.Vars{"id": test.id})
+			if err != nil {
+				return err
+			}
+
+			// Ensure proper HTTP status code
+			if code != test.code {
+				return fmt.Errorf("unexpected code: %v != %v", code, test.code)
+			}
+
+			// If code is in HTTP 400 or above, check error response
+			if code >= http.StatusBadRequest {
+				var errRes util.ErrorResponse
+				if err := json.Unmarshal(body, &errRes); err != nil {
+					return err
+				}
+
+				if errRes.Error.Message != test.errMessage {
+					return fmt.Errorf("unexpected error message: %v != %v", errRes.Error.Message, test.errMessage)
+				}
+
+				continue
+			}
+
+			// Verify big brother was stored
+			u, err := c.db.SelectUserByID(ctx, 5)
+			if err != nil {
+				return err
+			}
+			if u.BigBrotherID != 3 {
+				return fmt.Errorf("unexpected big brother: %v != %v", u.BigBrotherID, 3)
+			}
+		}
+
+		return nil
+	})
+}
+
+// TestListLittles verifies that ListLittles returns only a user's direct littles.
+func TestListLittles(t *testing.T) {
+	withContextFamily(t, func(c *Context, users []*models.User) error {
+		code, body, err := c.ListLittles(httptest.NewRequest("GET", "/", nil), util.Vars{"id": "2"})
+		if err != nil {
+			return err
+		}
+		if code != http.StatusOK {
+			return fmt.Errorf("unexpected code: %v != %v", code, http.StatusOK)
+		}
+
+		var res UsersResponse
+		if err := json.Unmarshal(body, &res); err != nil {
+			return err
+		}
+
+		if len(res.Users) != 2 || res.Users[0].ID != 3 || res.Users[1].ID != 4 {
+			return fmt.Errorf("unexpected littles: %v", res.Users)
+		}
+
+		return nil
+	})
+}
+
+// TestGetFamily verifies that GetFamily returns a nested family tree, rooted at
+// a user's most distant big brother, and containing all of their descendants.
+func TestGetFamily(t *testing.T) {
+	withContextFamily(t, func(c *Context, users []*models.User) error {
+		code, body, err := c.GetFamily(httptest.NewRequest("GET", "/", nil), util.Vars{"id": "2"})
+		if err != nil {
+			return err
+		}
+		if code != http.StatusOK {
+			return fmt.Errorf("unexpected code: %v != %v", code, http.StatusOK)
+		}
+
+		var res FamilyResponse
+		if err := json.Unmarshal(body, &res); err != nil {
+			return err
+		}
+
+		// Tree should be: 1 -> 2 -> (3, 4)
+		root := res.Family
+		if root.User.ID != 1 || len(root.Littles) != 1 {
+			return fmt.Errorf("unexpected root: %v", root.User)
+		}
+		if root.User.Password != "" {
+			return fmt.Errorf("password not stripped from family tree")
+		}
+
+		n := root.Littles[0]
+		if n.User.ID != 2 || len(n.Littles) != 2 {
+			return fmt.Errorf("unexpected node: %v", n.User)
+		}
+		if n.Littles[0].User.ID != 3 || n.Littles[1].User.ID != 4 {
+			return fmt.Errorf("unexpected littles: %v, %v", n.Littles[0].User, n.Littles[1].User)
+		}
+
+		// User 5 is not in this family
+		for _, l := range n.Littles {
+			if len(l.Littles) != 0 {
+				return fmt.Errorf("unexpected descendants: %v", l.Littles)
+			}
+		}
+
+		return nil
+	})
+}
+
+// TestGetFamilyCycle verifies that GetFamily terminates and returns each user
+// once, even if a cycle exists in the family tree.
+func TestGetFamilyCycle(t *testing.T) {
+	ctx := context.Background()
+
+	withContextFamily(t, func(c *Context, users []*models.User) error {
+		// Bypass cycle checking to force a cycle: 1 -> 2 -> 3 -> 1
+		users[0].BigBrotherID = 3
+		if err := c.db.UpdateUser(ctx, users[0]); err != nil {
+			return err
+		}
+
+		code, body, err := c.GetFamily(httptest.NewRequest("GET", "/", nil), util.Vars{"id": "1"})
+		if err != nil {
+			return err
+		}
+		if code != http.StatusOK {
+			return fmt.Errorf("unexpected code: %v != %v", code, http.StatusOK)
+		}
+
+		var res FamilyResponse
+		if err := json.Unmarshal(body, &res); err != nil {
+			return err
+		}
+
+		// Count each user in the tree
+		seen := make(map[uint64]int)
+		var walk func(n *FamilyNode)
+		walk = func(n *FamilyNode) {
+			seen[n.User.ID]++
+			for _, l := range n.Littles {
+				walk(l)
+			}
+		}
+		walk(res.Family)
+
+		for id, n := range seen {
+			if n != 1 {
+				return fmt.Errorf("user %d appears %d times in tree", id, n)
+			}
+		}
+
+		return nil
+	})
+}
+
+// TestGetFamilyDOT verifies that GetFamilyDOT returns a Graphviz DOT document
+// containing all edges in a user's family tree.
+func TestGetFamilyDOT(t *testing.T) {
+	withContextFamily(t, func(c *Context, users []*models.User) error {
+		// Route requests so that path variables are set
+		m := mux.NewRouter()
+		m.HandleFunc("/users/{id}/family.dot", c.GetFamilyDOT)
+
+		r, err := http.NewRequest("GET", "/users/3/family.dot", nil)
+		if err != nil {
+			return err
+		}
+
+		w := httptest.NewRecorder()
+		m.ServeHTTP(w, r)
+
+		if w.Code != http.StatusOK {
+			return fmt.Errorf("unexpected code: %v != %v", w.Code, http.StatusOK)
+		}
+		if ct := w.Header().Get("Content-Type"); ct != dotContentType {
+			return fmt.Errorf("unexpected Content-Type: %v != %v", ct, dotContentType)
+		}
+
+		dot := w.Body.String()
+		for _, s := range []string{
+			"digraph family {",
+			"\t1 -> 2;",
+			"\t2 -> 3;",
+			fmt.Sprintf("\t3 [label=\"%s %s\", style=bold];", users[2].FirstName, users[2].LastName),
+		} {
+			if !strings.Contains(dot, s) {
+				return fmt.Errorf("DOT document missing %q:\n%s", s, dot)
+			}
+		}
+
+		// Siblings of ancestors are not part of a user's family tree
+		if strings.Contains(dot, "2 -> 4") {
+			return fmt.Errorf("DOT document contains sibling:\n%s", dot)
+		}
+
+		return nil
+	})
+}
+
+// Test_dotQuote verifies that dotQuote properly escapes DOT strings.
+func Test_dotQuote(t *testing.T) {
+	var tests = []struct {
+		in  string
+		out string
+	}{
+		{"foo", `"foo"`},
+		{`foo "bar"`, `"foo \"bar\""`},
+		{`foo\bar`, `"foo\\bar"`},
+		{"foo\nbar", `"foo\nbar"`},
+	}
+
+	for _, test := range tests {
+		if out := dotQuote(test.in); out != test.out {
+			t.Fatalf("unexpected output: %v != %v", out, test.out)
+		}
+	}
+}
+
+// withContextFamily builds upon withContext, adding five mock users, where
+// users 1 -> 2 -> (3, 4) form a family, and user 5 has no big brother.
+func withContextFamily(t *testing.T, fn func(c *Context, users []*models.User) error) {
+	ctx := context.Background()
+
+	withContext(t, func(c *Context) error {
+		bigs := []uint64{0, 1, 2, 2, 0}
+		users := make([]*models.User, len(bigs))
+		for i, big := range bigs {
+			user := ditest.MockUser()
+			user.BigBrotherID = big
+			if err := c.db.InsertUser(ctx, user); err != nil {
+				return err
+			}
+
+			users[i] = user
+		}
+
+		return fn(c, users)
+	})
+}
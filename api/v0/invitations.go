@@ -0,0 +1,377 @@
+package v0
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/mdlayher/deltaiota/api/auth"
+	"github.com/mdlayher/deltaiota/api/util"
+	"github.com/mdlayher/deltaiota/data"
+	"github.com/mdlayher/deltaiota/data/models"
+)
+
+var (
+	// InvitationDuration is the duration which an invitation may exist before
+	// it expires, and can no longer be accepted.
+	InvitationDuration = time.Duration(7 * 24 * time.Hour)
+)
+
+// JSON Invitations API, human-readable client error responses.
+const (
+	// HTTP GET
+	invitationInvalidID = "invalid invitation ID"
+	invitationMissingID = "missing invitation ID"
+	invitationNotFound  = "invitation not found"
+
+	// HTTP POST
+	invitationConflict     = "user already exists with this email"
+	invitationExpired      = "invitation expired"
+	invitationJSONSyntax   = "invalid JSON request"
+	invitationMissingToken = "missing invitation token"
+)
+
+// JSON Invitations API, map of client errors to response codes.
+var invitationsCode = map[string]int{
+	// HTTP GET
+	invitationInvalidID: http.StatusBadRequest,
+	invitationMissingID: http.StatusBadRequest,
+	invitationNotFound:  http.StatusNotFound,
+
+	// HTTP POST
+	invitationConflict:     http.StatusConflict,
+	invitationExpired:      http.StatusGone,
+	invitationJSONSyntax:   http.StatusBadRequest,
+	invitationMissingToken: http.StatusBadRequest,
+}
+
+// Generated JSON responses for various client-facing errors.
+var invitationsJSON = map[string][]byte{}
+
+// init initializes the stored JSON responses for client-facing errors.
+func init() {
+	// Iterate all error strings and code integers
+	for k, v := range invitationsCode {
+		// Generate error response with appropriate string and code
+		body, err := json.Marshal(util.ErrRes(v, k))
+		if err != nil {
+			panic(err)
+		}
+
+		// Store for later use
+		invitationsJSON[k] = body
+	}
+}
+
+// InvitationsResponse is the output response for the Invitations API.
+type InvitationsResponse struct {
+	Invitations []*models.Invitation `json:"invitations"`
+}
+
+// InvitationAcceptRequest is the input request for accepting an invitation.
+// For an invitation to an existing user, only the password is required, and
+// any other fields which are set replace the user's existing values.
+type InvitationAcceptRequest struct {
+	Username  string `json:"username"`
+	Password  string `json:"password"`
+	FirstName string `json:"firstName"`
+	LastName  string `json:"lastName"`
+	Phone     string `json:"phone"`
+}
+
+// InvitationAcceptResponse is the output response for accepting an invitation,
+// containing the invitee's user and their first session.
+type InvitationAcceptResponse struct {
+	User    *models.User    `json:"user"`
+	Session *models.Session `json:"session"`
+}
+
+// InvitationsAPI is a util.JSONAPIFunc, and is the single entry point for the
+// Invitations API.
+// This method delegates to other methods as appropriate to handle incoming requests.
+func (c *Context) InvitationsAPI(r *http.Request, vars util.Vars) (int, []byte, error) {
+	// Switch based on HTTP method
+	switch r.Method {
+	case "GET", "HEAD":
+		// Check for an ID to fetch a single invitation
+		if _, ok := vars["id"]; ok {
+			return c.GetInvitation(r, vars)
+		}
+
+		return c.ListInvitations(r, vars)
+	case "POST":
+		return c.PostInvitation(r, vars)
+	case "DELETE":
+		return c.DeleteInvitation(r, vars)
+	default:
+		return util.MethodNotAllowed(r, vars)
+	}
+}
+
+// ListInvitations is a util.JSONAPIFunc which returns HTTP 200 and a JSON list
+// of invitations which have not yet been accepted on success, or a non-200 HTTP
+// status code and an error response on failure.
+func (c *Context) ListInvitations(r *http.Request, vars util.Vars) (int, []byte, error) {
+	invitations, err := c.db.SelectAllInvitations(r.Context())
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+
+	// Wrap in response and return
+	body, err := json.Marshal(InvitationsResponse{
+		Invitations: invitations,
+	})
+	return http.StatusOK, body, err
+}
+
+// GetInvitation is a util.JSONAPIFunc which returns HTTP 200 and a JSON invitation
+// object on success, or a non-200 HTTP status code and an error response on failure.
+func (c *Context) GetInvitation(r *http.Request, vars util.Vars) (int, []byte, error) {
+	// Fetch the invitation
+	invitation, code, body, err := c.invitationFromVars(r.Context(), vars)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+	if body != nil {
+		return code, body, nil
+	}
+
+	// Wrap in response and return
+	body, err = json.Marshal(InvitationsResponse{
+		Invitations: []*models.Invitation{invitation},
+	})
+	return http.StatusOK, body, err
+}
+
+// PostInvitation is a util.JSONAPIFunc which invites an email address to register
+// an account, and returns HTTP 201 and a JSON invitation object on success, or a
+// non-200 HTTP status code and an error response on failure.  The invitation is
+// queued, and mailed to the invitee in the background.
+func (c *Context) PostInvitation(r *http.Request, vars util.Vars) (int, []byte, error) {
+	// Read and validate request input into an Invitation struct
+	req := new(models.Invitation)
+	code, body, err := decodeAndValidate(r, req)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+	if body != nil {
+		return code, body, nil
+	}
+
+	// Do not invite an email address which already has an account, even if
+	// the account is deleted
+	inUse, err := c.db.EmailInUse(r.Context(), req.Email)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+	if inUse {
+		return invitationsCode[invitationConflict], invitationsJSON[invitationConflict], nil
+	}
+
+	// Generate a new invitation; only the email is taken from the request
+	invitation, err := models.NewInvitation(req.Email, 0, time.Now().Add(InvitationDuration))
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+	if err := c.db.InsertInvitation(r.Context(), invitation); err != nil {
+		return util.JSONAPIErr(err)
+	}
+
+	// Wrap in response and return
+	body, err = json.Marshal(InvitationsResponse{
+		Invitations: []*models.Invitation{invitation},
+	})
+	return http.StatusCreated, body, err
+}
+
+// DeleteInvitation is a util.JSONAPIFunc which revokes an invitation, and returns
+// HTTP 204 on success, or a non-200 HTTP status code and an error response on failure.
+func (c *Context) DeleteInvitation(r *http.Request, vars util.Vars) (int, []byte, error) {
+	// Fetch the invitation
+	invitation, code, body, err := c.invitationFromVars(r.Context(), vars)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+	if body != nil {
+		return code, body, nil
+	}
+
+	if err := c.db.DeleteInvitation(r.Context(), invitation); err != nil {
+		return util.JSONAPIErr(err)
+	}
+
+	return http.StatusNoContent, nil, nil
+}
+
+// AcceptInvitationAPI is a util.JSONAPIFunc, and is the single entry point for
+// accepting invitations.  It requires no authentication; the invitation token
+// identifies the invitee.
+// This method delegates to other methods as appropriate to handle incoming requests.
+func (c *Context) AcceptInvitationAPI(r *http.Request, vars util.Vars) (int, []byte, error) {
+	// Switch based on HTTP method
+	switch r.Method {
+	case "POST":
+		return c.AcceptInvitation(r, vars)
+	default:
+		return util.MethodNotAllowed(r, vars)
+	}
+}
+
+// AcceptInvitation is a util.JSONAPIFunc which accepts an invitation, and returns
+// HTTP 201 and the invitee's user and first session on success, or a non-200 HTTP
+// status code and an error response on failure.
+//
+// For an invitation to register, a new user is created with the invited email
+// address.  For an invitation to an existing user, the user's password is set.
+// Either the user is stored, a session is created, and the invitation is consumed,
+// or none of these occur.
+func (c *Context) AcceptInvitation(r *http.Request, vars util.Vars) (int, []byte, error) {
+	// Fetch the invitation by its token
+	token, ok := vars["token"]
+	if !ok {
+		return invitationsCode[invitationMissingToken], invitationsJSON[invitationMissingToken], nil
+	}
+
+	invitation, err := c.db.SelectInvitationByToken(r.Context(), token)
+	if err != nil {
+		// If no results found, return HTTP not found
+		if err == sql.ErrNoRows {
+			return invitationsCode[invitationNotFound], invitationsJSON[invitationNotFound], nil
+		}
+
+		return util.JSONAPIErr(err)
+	}
+	if invitation.IsExpired() {
+		return invitationsCode[invitationExpired], invitationsJSON[invitationExpired], nil
+	}
+
+	// Do not allow nil body
+	if r.Body == nil {
+		return invitationsCode[invitationJSONSyntax], invitationsJSON[invitationJSONSyntax], nil
+	}
+
+	var req InvitationAcceptRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return invitationsCode[invitationJSONSyntax], invitationsJSON[invitationJSONSyntax], nil
+	}
+
+	// Start with the invited user, or a new user with the invited email
+	user := &models.User{
+		Email: invitation.Email,
+	}
+	if invitation.UserID != 0 {
+		user, err = c.db.SelectUserByID(r.Context(), invitation.UserID)
+		if err != nil {
+			// User was deleted after being invited
+			if err == sql.ErrNoRows {
+				return invitationsCode[invitationNotFound], invitationsJSON[invitationNotFound], nil
+			}
+
+			return util.JSONAPIErr(err)
+		}
+	}
+
+	// Apply any fields chosen by the invitee
+	if req.Username != "" {
+		user.Username = req.Username
+	}
+	if req.FirstName != "" {
+		user.FirstName = req.FirstName
+	}
+	if req.LastName != "" {
+		user.LastName = req.LastName
+	}
+	if req.Phone != "" {
+		user.Phone = req.Phone
+	}
+
+	// Hash the chosen password, and validate the user
+	if err := user.SetPassword(req.Password); err != nil {
+		return validationErr(err)
+	}
+	if err := user.Validate(); err != nil {
+		return validationErr(err)
+	}
+
+	// Store the user, create their first session, and consume the invitation,
+	// all at once
+	var session *models.Session
+	err = c.db.WithTx(r.Context(), func(tx *data.Tx) error {
+		if user.ID == 0 {
+			if err := tx.InsertUser(r.Context(), user); err != nil {
+				return err
+			}
+		} else {
+			if err := tx.UpdateUser(r.Context(), user); err != nil {
+				return err
+			}
+		}
+
+		var err error
+		session, err = user.NewSession(time.Now().Add(auth.SessionDuration))
+		if err != nil {
+			return err
+		}
+		if err := tx.InsertSession(r.Context(), session); err != nil {
+			return err
+		}
+
+		// An existing user may have been invited more than once
+		if invitation.UserID != 0 {
+			return tx.DeleteInvitationsByUserID(r.Context(), invitation.UserID)
+		}
+
+		return tx.DeleteInvitation(r.Context(), invitation)
+	})
+	if err != nil {
+		// Check for constraint failure, meaning username or email is taken
+		if c.db.IsConstraintFailure(err) {
+			return usersCode[userConflict], usersJSON[userConflict], nil
+		}
+
+		return util.JSONAPIErr(err)
+	}
+
+	// Strip sensitive fields from output, showing the new user their own
+	// private contact details
+	sanitizeUser(user, privacy{requesterID: user.ID})
+
+	// Wrap in response and return
+	body, err := json.Marshal(InvitationAcceptResponse{
+		User:    user,
+		Session: session,
+	})
+	return http.StatusCreated, body, err
+}
+
+// invitationFromVars fetches the Invitation identified by the "id" variable.
+// On failure, it will return a message body or an error, causing the caller
+// to immediately send the result.
+func (c *Context) invitationFromVars(ctx context.Context, vars util.Vars) (*models.Invitation, int, []byte, error) {
+	// Fetch input invitation ID
+	strID, ok := vars["id"]
+	if !ok {
+		return nil, invitationsCode[invitationMissingID], invitationsJSON[invitationMissingID], nil
+	}
+
+	// Convert string to integer
+	id, err := strconv.ParseUint(strID, 10, 64)
+	if err != nil {
+		return nil, invitationsCode[invitationInvalidID], invitationsJSON[invitationInvalidID], nil
+	}
+
+	invitation, err := c.db.SelectInvitationByID(ctx, id)
+	if err != nil {
+		// If no results found, return HTTP not found
+		if err == sql.ErrNoRows {
+			return nil, invitationsCode[invitationNotFound], invitationsJSON[invitationNotFound], nil
+		}
+
+		return nil, http.StatusInternalServerError, nil, err
+	}
+
+	return invitation, http.StatusOK, nil, nil
+}
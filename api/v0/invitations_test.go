@@ -0,0 +1,265 @@
+package v0
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/mdlayher/deltaiota/api/util"
+	"github.com/mdlayher/deltaiota/data/models"
+)
+
+// TestPostInvitation verifies that PostInvitation validates input and queues
+// invitations.
+func TestPostInvitation(t *testing.T) {
+	ctx := context.Background()
+
+	withContextUser(t, func(c *Context, user *models.User) error {
+		// Table of tests to iterate
+		var tests = []struct {
+			body       []byte
+			code       int
+			errMessage string
+		}{
+			// Bad JSON
+			{[]byte(`{`), http.StatusBadRequest, positionJSONSyntax},
+			// Missing email
+			{[]byte(`{}`), http.StatusBadRequest, "empty field: email"},
+			// Invalid email
+			{[]byte(`{"email":"test"}`), http.StatusBadRequest, "invalid field: email (could not parse valid email address)"},
+			// Email belongs to an existing user
+			{[]byte(fmt.Sprintf(`{"email":%q}`, user.Email)), http.StatusConflict, invitationConflict},
+			// Valid invitation; user ID may not be set by client
+			{[]byte(fmt.Sprintf(`{"email":"invitee@example.com","userId":%d}`, user.ID)), http.StatusCreated, ""},
+		}
+
+		// Iterate and run tests
+		for _, test := range tests {
+			// Generate HTTP request
+			r, err := http.NewRequest("POST", "/", bytes.NewReader(test.body))
+			if err != nil {
+				return err
+			}
+
+			code, body, err := c.PostInvitation(r, util.Vars{})
+			if err != nil {
+				return err
+			}
+
+			// Ensure proper HTTP status code
+			if code != test.code {
+				return fmt.Errorf("unexpected code: %v != %v", code, test.code)
+			}
+
+			// If code is in HTTP 400 or above, check error response
+			if code >= http.StatusBadRequest {
+				var errRes util.ErrorResponse
+				if err := json.Unmarshal(body, &errRes); err != nil {
+					return err
+				}
+
+				if errRes.Error.Message != test.errMessage {
+					return fmt.Errorf("unexpected error message: %v != %v", errRes.Error.Message, test.errMessage)
+				}
+
+				continue
+			}
+
+			// Check invitation is queued, expires, and is not for an existing user
+			var iRes InvitationsResponse
+			if err := json.Unmarshal(body, &iRes); err != nil {
+				return err
+			}
+
+			invitation := iRes.Invitations[0]
+			if invitation.UserID != 0 || invitation.Sent != 0 || invitation.Expire <= invitation.Created {
+				return fmt.Errorf("unexpected invitation: %+v", invitation)
+			}
+
+			// Token must be stored, but not returned to the client
+			if bytes.Contains(body, []byte("token")) {
+				return fmt.Errorf("invitation token returned to client: %s", string(body))
+			}
+			stored, err := c.db.SelectInvitationByID(ctx, invitation.ID)
+			if err != nil {
+				return err
+			}
+			if stored.Token == "" {
+				return fmt.Errorf("invitation stored without token")
+			}
+		}
+
+		return nil
+	})
+}
+
+// TestAcceptInvitation verifies that AcceptInvitation creates a user and
+// their first session, and consumes the invitation.
+func TestAcceptInvitation(t *testing.T) {
+	ctx := context.Background()
+
+	withContext(t, func(c *Context) error {
+		invitation, err := models.NewInvitation("invitee@example.com", 0, time.Now().Add(InvitationDuration))
+		if err != nil {
+			return err
+		}
+		if err := c.db.InsertInvitation(ctx, invitation); err != nil {
+			return err
+		}
+
+		expired, err := models.NewInvitation("expired@example.com", 0, time.Now().Add(-1*time.Hour))
+		if err != nil {
+			return err
+		}
+		if err := c.db.InsertInvitation(ctx, expired); err != nil {
+			return err
+		}
+
+		const valid = `{"username":"invitee","password":"secret","firstName":"Test","lastName":"User"}`
+
+		// Table of tests to iterate
+		var tests = []struct {
+			token      string
+			body       []byte
+			code       int
+			errMessage string
+		}{
+			// Unknown token
+			{"foo", []byte(valid), http.StatusNotFound, invitationNotFound},
+			// Expired invitation
+			{expired.Token, []byte(valid), http.StatusGone, invitationExpired},
+			// Bad JSON
+			{invitation.Token, []byte(`{`), http.StatusBadRequest, invitationJSONSyntax},
+			// Missing password
+			{invitation.Token, []byte(`{"username":"invitee","firstName":"Test","lastName":"User"}`), http.StatusBadRequest, "empty field: password"},
+			// Missing username
+			{invitation.Token, []byte(`{"password":"secret","firstName":"Test","lastName":"User"}`), http.StatusBadRequest, "empty field: username"},
+			// Valid acceptance
+			{invitation.Token, []byte(valid), http.StatusCreated, ""},
+			// Invitation may only be accepted once
+			{invitation.Token, []byte(valid), http.StatusNotFound, invitationNotFound},
+		}
+
+		// Iterate and run tests
+		for i, test := range tests {
+			// Generate HTTP request
+			r, err := http.NewRequest("POST", "/", bytes.NewReader(test.body))
+			if err != nil {
+				return err
+			}
+
+			code, body, err := c.AcceptInvitation(r, util.Vars{"token": test.token})
+			if err != nil {
+				return err
+			}
+
+			// Ensure proper HTTP status code
+			if code != test.code {
+				return fmt.Errorf("[%02d] unexpected code: %v != %v", i, code, test.code)
+			}
+
+			// If code is in HTTP 400 or above, check error response
+			if code >= http.StatusBadRequest {
+				var errRes util.ErrorResponse
+				if err := json.Unmarshal(body, &errRes); err != nil {
+					return err
+				}
+
+				if errRes.Error.Message != test.errMessage {
+					return fmt.Errorf("[%02d] unexpected error message: %v != %v", i, errRes.Error.Message, test.errMessage)
+				}
+
+				continue
+			}
+
+			var aRes InvitationAcceptResponse
+			if err := json.Unmarshal(body, &aRes); err != nil {
+				return err
+			}
+
+			// User must have the invited email, and their chosen password
+			user, err := c.db.SelectUserByID(ctx, aRes.User.ID)
+			if err != nil {
+				return err
+			}
+			if user.Username != "invitee" || user.Email != invitation.Email {
+				return fmt.Errorf("unexpected user: %v, %v", user.Username, user.Email)
+			}
+			if err := user.TryPassword("secret"); err != nil {
+				return err
+			}
+
+			// Session must belong to the new user
+			session, err := c.db.SelectSessionByKey(ctx, aRes.Session.Key)
+			if err != nil {
+				return err
+			}
+			if session.UserID != user.ID {
+				return fmt.Errorf("unexpected session user ID: %v != %v", session.UserID, user.ID)
+			}
+		}
+
+		return nil
+	})
+}
+
+// TestAcceptInvitationExistingUser verifies that AcceptInvitation allows an
+// existing user to choose their password, and consumes all of their invitations.
+func TestAcceptInvitationExistingUser(t *testing.T) {
+	ctx := context.Background()
+
+	withContextUser(t, func(c *Context, user *models.User) error {
+		var invitations []*models.Invitation
+		for i := 0; i < 2; i++ {
+			invitation, err := models.NewInvitation(user.Email, user.ID, time.Now().Add(InvitationDuration))
+			if err != nil {
+				return err
+			}
+			if err := c.db.InsertInvitation(ctx, invitation); err != nil {
+				return err
+			}
+
+			invitations = append(invitations, invitation)
+		}
+
+		r, err := http.NewRequest("POST", "/", bytes.NewReader([]byte(`{"password":"newpassword"}`)))
+		if err != nil {
+			return err
+		}
+
+		code, body, err := c.AcceptInvitation(r, util.Vars{"token": invitations[1].Token})
+		if err != nil {
+			return err
+		}
+		if code != http.StatusCreated {
+			return fmt.Errorf("unexpected code: %v != %v: %s", code, http.StatusCreated, string(body))
+		}
+
+		// User keeps their username, and has a new password
+		user2, err := c.db.SelectUserByID(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+		if user2.Username != user.Username {
+			return fmt.Errorf("unexpected username: %v != %v", user2.Username, user.Username)
+		}
+		if err := user2.TryPassword("newpassword"); err != nil {
+			return err
+		}
+
+		// All invitations for the user are consumed
+		remaining, err := c.db.SelectInvitationsByUserID(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+		if len(remaining) != 0 {
+			return fmt.Errorf("unexpected remaining invitations: %d", len(remaining))
+		}
+
+		return nil
+	})
+}
@@ -4,7 +4,6 @@ import (
 	"encoding/json"
 	"net/http"
 
-	"github.com/mdlayher/deltaiota/api/auth"
 	"github.com/mdlayher/deltaiota/api/util"
 	"github.com/mdlayher/deltaiota/data/models"
 )
@@ -30,14 +29,19 @@ func (c *Context) NotificationsAPI(r *http.Request, vars util.Vars) (int, []byte
 // JSON list of notifications for the authenticated user on success, or a non-200
 // HTTP status code and an error response on failure.
 func (c *Context) ListNotificationsForUser(r *http.Request, vars util.Vars) (int, []byte, error) {
+	user, code, body := authUser(r)
+	if body != nil {
+		return code, body, nil
+	}
+
 	// Fetch a list of notifications for this user from the database
-	notifications, err := c.db.SelectNotificationsByUserID(auth.User(r).ID)
+	notifications, err := c.db.SelectNotificationsByUserID(r.Context(), user.ID)
 	if err != nil {
 		return util.JSONAPIErr(err)
 	}
 
 	// Wrap in response
-	body, err := json.Marshal(NotificationsResponse{
+	body, err = json.Marshal(NotificationsResponse{
 		Notifications: notifications,
 	})
 	return http.StatusOK, body, err
@@ -1,6 +1,7 @@
 package v0
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -39,7 +40,7 @@ func TestNotificationsAPI(t *testing.T) {
 			}
 
 			// Store mock-authenticated user
-			auth.SetUser(r, user)
+			r = auth.WithUser(r, user)
 
 			// Delegate to appropriate handler
 			code, _, err := c.NotificationsAPI(r, util.Vars{})
@@ -60,16 +61,18 @@ func TestNotificationsAPI(t *testing.T) {
 // TestListNotificationsForUserNoNotifications verifies that ListNotificationsForUser
 // returns no notifications when no notifications exist for a user in the database.
 func TestListNotificationsForUserNoNotifications(t *testing.T) {
+	ctx := context.Background()
+
 	withContextUser(t, func(c *Context, user *models.User) error {
 		// Generate another mock user
 		user2 := ditest.MockUser()
-		if err := c.db.InsertUser(user2); err != nil {
+		if err := c.db.InsertUser(ctx, user2); err != nil {
 			return err
 		}
 
 		// Add notification for second user, which should not
 		// appear in results
-		if err := c.db.InsertNotification(&models.Notification{
+		if err := c.db.InsertNotification(ctx, &models.Notification{
 			UserID: user2.ID,
 		}); err != nil {
 			return err
@@ -82,7 +85,7 @@ func TestListNotificationsForUserNoNotifications(t *testing.T) {
 		}
 
 		// Store mock-authenticated user
-		auth.SetUser(r, user)
+		r = auth.WithUser(r, user)
 
 		// Fetch list of current notifications for user
 		code, body, err := c.ListNotificationsForUser(r, util.Vars{})
@@ -113,16 +116,18 @@ func TestListNotificationsForUserNoNotifications(t *testing.T) {
 // TestListNotificationsForUserManyNotifications verifies that ListNotificationsForUser
 // returns many notifications when many notifications for the user exist in the database.
 func TestListNotificationsManyNotifications(t *testing.T) {
+	ctx := context.Background()
+
 	withContextUser(t, func(c *Context, user *models.User) error {
 		// Generate another mock user
 		user2 := ditest.MockUser()
-		if err := c.db.InsertUser(user2); err != nil {
+		if err := c.db.InsertUser(ctx, user2); err != nil {
 			return err
 		}
 
 		// Add notification for second user, which should not
 		// appear in results
-		if err := c.db.InsertNotification(&models.Notification{
+		if err := c.db.InsertNotification(ctx, &models.Notification{
 			UserID: user2.ID,
 		}); err != nil {
 			return err
@@ -135,7 +140,7 @@ func TestListNotificationsManyNotifications(t *testing.T) {
 		}
 
 		// Store mock-authenticated user
-		auth.SetUser(r, user)
+		r = auth.WithUser(r, user)
 
 		// Generate and save a mock notifications in the database
 		notifications := make([]*models.Notification, 100)
@@ -143,7 +148,7 @@ func TestListNotificationsManyNotifications(t *testing.T) {
 			notification := &models.Notification{
 				UserID: user.ID,
 			}
-			if err := c.db.InsertNotification(notification); err != nil {
+			if err := c.db.InsertNotification(ctx, notification); err != nil {
 				return err
 			}
 
@@ -0,0 +1,640 @@
+package v0
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/mdlayher/deltaiota/api/util"
+	"github.com/mdlayher/deltaiota/data/models"
+)
+
+// JSON Positions API, human-readable client error responses.
+const (
+	// HTTP GET
+	positionInvalidID = "invalid position ID"
+	positionMissingID = "missing position ID"
+	positionNotFound  = "position not found"
+	termInvalidID     = "invalid term ID"
+	termMissingID     = "missing term ID"
+	termNotFound      = "term not found"
+
+	// HTTP POST
+	positionConflict          = "position already exists"
+	positionHasTerms          = "position has term history"
+	positionInvalidParameters = "invalid parameters"
+	positionJSONSyntax        = "invalid JSON request"
+	positionMissingParameters = "missing required parameters"
+)
+
+// JSON Positions API, map of client errors to response codes.
+var positionsCode = map[string]int{
+	// HTTP GET
+	positionInvalidID: http.StatusBadRequest,
+	positionMissingID: http.StatusBadRequest,
+	positionNotFound:  http.StatusNotFound,
+	termInvalidID:     http.StatusBadRequest,
+	termMissingID:     http.StatusBadRequest,
+	termNotFound:      http.StatusNotFound,
+
+	// HTTP POST
+	positionConflict:          http.StatusConflict,
+	positionHasTerms:          http.StatusConflict,
+	positionInvalidParameters: http.StatusBadRequest,
+	positionJSONSyntax:        http.StatusBadRequest,
+	positionMissingParameters: http.StatusBadRequest,
+}
+
+// Generated JSON responses for various client-facing errors.
+var positionsJSON = map[string][]byte{}
+
+// init initializes the stored JSON responses for client-facing errors.
+func init() {
+	// Iterate all error strings and code integers
+	for k, v := range positionsCode {
+		// Generate error response with appropriate string and code
+		body, err := json.Marshal(util.ErrRes(v, k))
+		if err != nil {
+			panic(err)
+		}
+
+		// Store for later use
+		positionsJSON[k] = body
+	}
+}
+
+// PositionsResponse is the output response for the Positions API.
+type PositionsResponse struct {
+	Positions []*models.Position `json:"positions"`
+}
+
+// TermsResponse is the output response for the Terms API.
+type TermsResponse struct {
+	Terms []*models.Term `json:"terms"`
+}
+
+// OfficersResponse is the output response for the Officers API.
+type OfficersResponse struct {
+	Officers []*Officer `json:"officers"`
+}
+
+// Officer is a user who currently holds a position, along with their term.
+type Officer struct {
+	Position *models.Position `json:"position"`
+	User     *models.User     `json:"user"`
+	Term     *models.Term     `json:"term"`
+}
+
+// PositionsAPI is a util.JSONAPIFunc, and is the single entry point for the Positions API.
+// This method delegates to other methods as appropriate to handle incoming requests.
+func (c *Context) PositionsAPI(r *http.Request, vars util.Vars) (int, []byte, error) {
+	// Switch based on HTTP method
+	switch r.Method {
+	case "GET", "HEAD":
+		// If ID present, request for single position
+		if _, ok := vars["id"]; ok {
+			return c.GetPosition(r, vars)
+		}
+
+		// No ID, request for list of positions
+		return c.ListPositions(r, vars)
+	case "POST":
+		return c.PostPosition(r, vars)
+	case "PUT":
+		return c.PutPosition(r, vars)
+	case "DELETE":
+		return c.DeletePosition(r, vars)
+	default:
+		return util.MethodNotAllowed(r, vars)
+	}
+}
+
+// ListPositions is a util.JSONAPIFunc which returns HTTP 200 and a JSON list of
+// positions on success, or a non-200 HTTP status code and an error response on failure.
+func (c *Context) ListPositions(r *http.Request, vars util.Vars) (int, []byte, error) {
+	// Fetch a list of all positions from the database
+	positions, err := c.db.SelectAllPositions(r.Context())
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+
+	// Wrap in response and return
+	body, err := json.Marshal(PositionsResponse{
+		Positions: positions,
+	})
+	return http.StatusOK, body, err
+}
+
+// GetPosition is a util.JSONAPIFunc which returns HTTP 200 and a JSON position
+// object on success, or a non-200 HTTP status code and an error response on failure.
+func (c *Context) GetPosition(r *http.Request, vars util.Vars) (int, []byte, error) {
+	// Fetch the position
+	position, code, body, err := c.positionFromVars(r.Context(), vars)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+	if body != nil {
+		return code, body, nil
+	}
+
+	// Wrap in response and return
+	body, err = json.Marshal(PositionsResponse{
+		Positions: []*models.Position{position},
+	})
+	return http.StatusOK, body, err
+}
+
+// PostPosition is a util.JSONAPIFunc which creates a Position and returns HTTP 201
+// and a JSON position object on success, or a non-200 HTTP status code and an
+// error response on failure.
+func (c *Context) PostPosition(r *http.Request, vars util.Vars) (int, []byte, error) {
+	// Read and validate request input into a Position struct
+	position := new(models.Position)
+	code, body, err := decodeAndValidate(r, position)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+	if body != nil {
+		return code, body, nil
+	}
+
+	// No body written, all checks passed, so insert new position
+	if err := c.db.InsertPosition(r.Context(), position); err != nil {
+		// Check for constraint failure, meaning position already exists
+		if c.db.IsConstraintFailure(err) {
+			return positionsCode[positionConflict], positionsJSON[positionConflict], nil
+		}
+
+		return util.JSONAPIErr(err)
+	}
+
+	// Wrap in response and return
+	body, err = json.Marshal(PositionsResponse{
+		Positions: []*models.Position{position},
+	})
+	return http.StatusCreated, body, err
+}
+
+// PutPosition is a util.JSONAPIFunc which updates a Position and returns HTTP 200
+// and a JSON position object on success, or a non-200 HTTP status code and an
+// error response on failure.
+func (c *Context) PutPosition(r *http.Request, vars util.Vars) (int, []byte, error) {
+	// Fetch the position
+	position, code, body, err := c.positionFromVars(r.Context(), vars)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+	if body != nil {
+		return code, body, nil
+	}
+
+	// Read and validate request input into a Position struct
+	newPosition := new(models.Position)
+	code, body, err = decodeAndValidate(r, newPosition)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+	if body != nil {
+		return code, body, nil
+	}
+
+	// Update existing position with new fields
+	position.CopyFrom(newPosition)
+	if err := c.db.UpdatePosition(r.Context(), position); err != nil {
+		// Check for constraint failure, meaning a unique check failed
+		if c.db.IsConstraintFailure(err) {
+			return positionsCode[positionConflict], positionsJSON[positionConflict], nil
+		}
+
+		return util.JSONAPIErr(err)
+	}
+
+	// Wrap in response and return
+	body, err = json.Marshal(PositionsResponse{
+		Positions: []*models.Position{position},
+	})
+	return http.StatusOK, body, err
+}
+
+// DeletePosition is a util.JSONAPIFunc which deletes a Position and returns HTTP 204
+// on success, or a non-200 HTTP status code and an error response on failure.
+// Positions which have any term history may not be deleted.
+func (c *Context) DeletePosition(r *http.Request, vars util.Vars) (int, []byte, error) {
+	// Fetch the position
+	position, code, body, err := c.positionFromVars(r.Context(), vars)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+	if body != nil {
+		return code, body, nil
+	}
+
+	// Preserve term history by refusing to delete positions with terms
+	hasTerms, err := c.db.HasTerms(r.Context(), position.ID)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+	if hasTerms {
+		return positionsCode[positionHasTerms], positionsJSON[positionHasTerms], nil
+	}
+
+	if err := c.db.DeletePosition(r.Context(), position); err != nil {
+		return util.JSONAPIErr(err)
+	}
+
+	return http.StatusNoContent, nil, nil
+}
+
+// TermsAPI is a util.JSONAPIFunc, and is the single entry point for the Terms API,
+// which manages the term history of a position.
+// This method delegates to other methods as appropriate to handle incoming requests.
+func (c *Context) TermsAPI(r *http.Request, vars util.Vars) (int, []byte, error) {
+	// Switch based on HTTP method
+	switch r.Method {
+	case "GET", "HEAD":
+		// If term ID present, request for single term
+		if _, ok := vars["termId"]; ok {
+			return c.GetTerm(r, vars)
+		}
+
+		// No term ID, request for list of terms
+		return c.ListTerms(r, vars)
+	case "POST":
+		return c.PostTerm(r, vars)
+	case "PUT":
+		return c.PutTerm(r, vars)
+	case "DELETE":
+		return c.DeleteTerm(r, vars)
+	default:
+		return util.MethodNotAllowed(r, vars)
+	}
+}
+
+// ListTerms is a util.JSONAPIFunc which returns HTTP 200 and a JSON list of all
+// terms for a position, most recent first, on success, or a non-200 HTTP status
+// code and an error response on failure.
+func (c *Context) ListTerms(r *http.Request, vars util.Vars) (int, []byte, error) {
+	// Fetch the position
+	position, code, body, err := c.positionFromVars(r.Context(), vars)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+	if body != nil {
+		return code, body, nil
+	}
+
+	// Fetch term history for position
+	terms, err := c.db.SelectTermsByPositionID(r.Context(), position.ID)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+
+	// Wrap in response and return
+	body, err = json.Marshal(TermsResponse{
+		Terms: terms,
+	})
+	return http.StatusOK, body, err
+}
+
+// GetTerm is a util.JSONAPIFunc which returns HTTP 200 and a JSON term object
+// on success, or a non-200 HTTP status code and an error response on failure.
+func (c *Context) GetTerm(r *http.Request, vars util.Vars) (int, []byte, error) {
+	// Fetch the term
+	term, code, body, err := c.termFromVars(r.Context(), vars)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+	if body != nil {
+		return code, body, nil
+	}
+
+	// Wrap in response and return
+	body, err = json.Marshal(TermsResponse{
+		Terms: []*models.Term{term},
+	})
+	return http.StatusOK, body, err
+}
+
+// PostTerm is a util.JSONAPIFunc which appoints a User to a Position for a Term,
+// and returns HTTP 201 and a JSON term object on success, or a non-200 HTTP status
+// code and an error response on failure.
+func (c *Context) PostTerm(r *http.Request, vars util.Vars) (int, []byte, error) {
+	// Fetch the position
+	position, code, body, err := c.positionFromVars(r.Context(), vars)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+	if body != nil {
+		return code, body, nil
+	}
+
+	// Read and validate request input into a Term struct
+	term, code, body, err := c.jsonToTerm(r, position)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+	if body != nil {
+		return code, body, nil
+	}
+
+	if err := c.db.InsertTerm(r.Context(), term); err != nil {
+		return util.JSONAPIErr(err)
+	}
+
+	// Wrap in response and return
+	body, err = json.Marshal(TermsResponse{
+		Terms: []*models.Term{term},
+	})
+	return http.StatusCreated, body, err
+}
+
+// PutTerm is a util.JSONAPIFunc which updates a Term and returns HTTP 200 and a
+// JSON term object on success, or a non-200 HTTP status code and an error response
+// on failure.
+func (c *Context) PutTerm(r *http.Request, vars util.Vars) (int, []byte, error) {
+	// Fetch the term
+	term, code, body, err := c.termFromVars(r.Context(), vars)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+	if body != nil {
+		return code, body, nil
+	}
+
+	// Terms may not be moved between positions
+	position, err := c.db.SelectPositionByID(r.Context(), term.PositionID)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+
+	// Read and validate request input into a Term struct
+	newTerm, code, body, err := c.jsonToTerm(r, position)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+	if body != nil {
+		return code, body, nil
+	}
+
+	// Update existing term with new fields
+	term.CopyFrom(newTerm)
+	if err := c.db.UpdateTerm(r.Context(), term); err != nil {
+		return util.JSONAPIErr(err)
+	}
+
+	// Wrap in response and return
+	body, err = json.Marshal(TermsResponse{
+		Terms: []*models.Term{term},
+	})
+	return http.StatusOK, body, err
+}
+
+// DeleteTerm is a util.JSONAPIFunc which deletes a Term and returns HTTP 204
+// on success, or a non-200 HTTP status code and an error response on failure.
+func (c *Context) DeleteTerm(r *http.Request, vars util.Vars) (int, []byte, error) {
+	// Fetch the term
+	term, code, body, err := c.termFromVars(r.Context(), vars)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+	if body != nil {
+		return code, body, nil
+	}
+
+	if err := c.db.DeleteTerm(r.Context(), term); err != nil {
+		return util.JSONAPIErr(err)
+	}
+
+	return http.StatusNoContent, nil, nil
+}
+
+// OfficersAPI is a util.JSONAPIFunc, and is the single entry point for the Officers API.
+// This method delegates to other methods as appropriate to handle incoming requests.
+func (c *Context) OfficersAPI(r *http.Request, vars util.Vars) (int, []byte, error) {
+	// Switch based on HTTP method
+	switch r.Method {
+	case "GET", "HEAD":
+		return c.ListOfficers(r, vars)
+	default:
+		return util.MethodNotAllowed(r, vars)
+	}
+}
+
+// ListOfficers is a util.JSONAPIFunc which returns HTTP 200 and a JSON list of
+// all current officers, in order of position rank, on success, or a non-200 HTTP
+// status code and an error response on failure.
+func (c *Context) ListOfficers(r *http.Request, vars util.Vars) (int, []byte, error) {
+	// Fetch all currently active terms
+	terms, err := c.db.SelectActiveTerms(r.Context(), time.Now())
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+
+	// Cache positions, since a position may be held by more than one user
+	positions := make(map[uint64]*models.Position)
+
+	// Apply privacy settings to each officer's contact details
+	p, err := c.privacy(r)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+
+	officers := make([]*Officer, 0, len(terms))
+	for _, t := range terms {
+		// Fetch position for term
+		position, ok := positions[t.PositionID]
+		if !ok {
+			position, err = c.db.SelectPositionByID(r.Context(), t.PositionID)
+			if err != nil {
+				return util.JSONAPIErr(err)
+			}
+			positions[t.PositionID] = position
+		}
+
+		// Fetch user who holds the term, skipping deleted users
+		user, err := c.db.SelectUserByID(r.Context(), t.UserID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				continue
+			}
+
+			return util.JSONAPIErr(err)
+		}
+
+		// Strip sensitive fields from output
+		sanitizeUser(user, p)
+
+		officers = append(officers, &Officer{
+			Position: position,
+			User:     user,
+			Term:     t,
+		})
+	}
+
+	// Wrap in response and return
+	body, err := json.Marshal(OfficersResponse{
+		Officers: officers,
+	})
+	return http.StatusOK, body, err
+}
+
+// positionFromVars fetches the Position which is the target of a request, using
+// the "id" route variable.  On failure, it will return a message body or an error,
+// causing the caller to immediately send the result.
+func (c *Context) positionFromVars(ctx context.Context, vars util.Vars) (*models.Position, int, []byte, error) {
+	// Fetch input position ID
+	strID, ok := vars["id"]
+	if !ok {
+		return nil, positionsCode[positionMissingID], positionsJSON[positionMissingID], nil
+	}
+
+	// Convert string to integer
+	id, err := strconv.ParseUint(strID, 10, 64)
+	if err != nil {
+		return nil, positionsCode[positionInvalidID], positionsJSON[positionInvalidID], nil
+	}
+
+	// Select single position by ID from the database
+	position, err := c.db.SelectPositionByID(ctx, id)
+	if err != nil {
+		// If no results found, return HTTP not found
+		if err == sql.ErrNoRows {
+			return nil, positionsCode[positionNotFound], positionsJSON[positionNotFound], nil
+		}
+
+		return nil, http.StatusInternalServerError, nil, err
+	}
+
+	return position, http.StatusOK, nil, nil
+}
+
+// termFromVars fetches the Term which is the target of a request, using the "id"
+// and "termId" route variables.  On failure, it will return a message body or an
+// error, causing the caller to immediately send the result.
+func (c *Context) termFromVars(ctx context.Context, vars util.Vars) (*models.Term, int, []byte, error) {
+	// Fetch the position which holds the term
+	position, code, body, err := c.positionFromVars(ctx, vars)
+	if err != nil || body != nil {
+		return nil, code, body, err
+	}
+
+	// Fetch input term ID
+	strID, ok := vars["termId"]
+	if !ok {
+		return nil, positionsCode[termMissingID], positionsJSON[termMissingID], nil
+	}
+
+	// Convert string to integer
+	id, err := strconv.ParseUint(strID, 10, 64)
+	if err != nil {
+		return nil, positionsCode[termInvalidID], positionsJSON[termInvalidID], nil
+	}
+
+	// Select single term by ID from the database, and verify that it belongs
+	// to this position
+	term, err := c.db.SelectTermByID(ctx, id)
+	if err != nil {
+		// If no results found, return HTTP not found
+		if err == sql.ErrNoRows {
+			return nil, positionsCode[termNotFound], positionsJSON[termNotFound], nil
+		}
+
+		return nil, http.StatusInternalServerError, nil, err
+	}
+	if term.PositionID != position.ID {
+		return nil, positionsCode[termNotFound], positionsJSON[termNotFound], nil
+	}
+
+	return term, http.StatusOK, nil, nil
+}
+
+// jsonToTerm reads the JSON body of an incoming HTTP request, validates that all
+// required fields are set, and returns a Term for the input Position on success.
+// On failure, it will return a message body or an error, causing the caller to
+// immediately send the result.
+func (c *Context) jsonToTerm(r *http.Request, position *models.Position) (*models.Term, int, []byte, error) {
+	// Terms always belong to the position in the route
+	term := &models.Term{
+		PositionID: position.ID,
+	}
+	code, body, err := decodeAndValidate(r, term)
+	if err != nil || body != nil {
+		return nil, code, body, err
+	}
+	term.PositionID = position.ID
+
+	// Verify that the user holding the term exists
+	if _, err := c.db.SelectUserByID(r.Context(), term.UserID); err != nil {
+		if err != sql.ErrNoRows {
+			return nil, http.StatusInternalServerError, nil, err
+		}
+
+		// Set code for invalid parameter
+		code := positionsCode[positionInvalidParameters]
+
+		// Return customized error object
+		body, err := json.Marshal(util.ErrRes(code, (&models.InvalidFieldError{
+			Field:   "userId",
+			Details: "user not found",
+		}).Error()))
+		return nil, code, body, err
+	}
+
+	return term, http.StatusOK, nil, nil
+}
+
+// decodeAndValidate reads the JSON body of an incoming HTTP request into the
+// input models.Validator, and validates its fields.  On failure, it will return a
+// message body or an error, causing the caller to immediately send the result.
+func decodeAndValidate(r *http.Request, v models.Validator) (int, []byte, error) {
+	// Do not allow nil body
+	if r.Body == nil {
+		return positionsCode[positionJSONSyntax], positionsJSON[positionJSONSyntax], nil
+	}
+
+	// Unmarshal body into the input value
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		// Check for bad input JSON
+		if _, ok := err.(*json.SyntaxError); ok || err == io.EOF || err == io.ErrUnexpectedEOF {
+			return positionsCode[positionJSONSyntax], positionsJSON[positionJSONSyntax], nil
+		}
+		if _, ok := err.(*json.UnmarshalTypeError); ok {
+			return positionsCode[positionJSONSyntax], positionsJSON[positionJSONSyntax], nil
+		}
+
+		return http.StatusInternalServerError, nil, err
+	}
+
+	// Validate input
+	if err := v.Validate(); err != nil {
+		return validationErr(err)
+	}
+
+	return http.StatusOK, nil, nil
+}
+
+// validationErr converts an error returned by models.Validator.Validate into a
+// client error response.  Errors which do not describe a field are reported as
+// server errors.
+func validationErr(err error) (int, []byte, error) {
+	// If a required field was empty, report missing parameters
+	if emptyErr, ok := err.(*models.EmptyFieldError); ok {
+		code := positionsCode[positionMissingParameters]
+		body, err := json.Marshal(util.ErrRes(code, emptyErr.Error()))
+		return code, body, err
+	}
+
+	// If a field was invalid, report invalid input
+	if invalidErr, ok := err.(*models.InvalidFieldError); ok {
+		code := positionsCode[positionInvalidParameters]
+		body, err := json.Marshal(util.ErrRes(code, invalidErr.Error()))
+		return code, body, err
+	}
+
+	// For any other errors, report a server error
+	return http.StatusInternalServerError, nil, err
+}
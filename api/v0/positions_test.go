@@ -0,0 +1,301 @@
+package v0
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mdlayher/deltaiota/api/util"
+	"github.com/mdlayher/deltaiota/data/models"
+)
+
+// TestPostPosition verifies that PostPosition returns the appropriate HTTP
+// status code, body, and any errors which occur.
+func TestPostPosition(t *testing.T) {
+	withContext(t, func(c *Context) error {
+		// Table of tests to iterate
+		var tests = []struct {
+			code       int
+			errMessage string
+			body       []byte
+		}{
+			// Empty body
+			{http.StatusBadRequest, positionJSONSyntax, nil},
+			// Bad JSON
+			{http.StatusBadRequest, positionJSONSyntax, []byte(`{`)},
+			// Missing name
+			{http.StatusBadRequest, "empty field: name", []byte(`{"description":"Runs meetings"}`)},
+			// Valid request
+			{http.StatusCreated, "", []byte(`{"name":"President","rank":1}`)},
+			// Duplicate name
+			{http.StatusConflict, positionConflict, []byte(`{"name":"President"}`)},
+		}
+
+		// Iterate and run tests
+		for _, test := range tests {
+			// Generate HTTP request
+			r, err := http.NewRequest("POST", "/", bytes.NewReader(test.body))
+			if err != nil {
+				return err
+			}
+
+			code, body, err := c.PostPosition(r, util.Vars{})
+			if err != nil {
+				return err
+			}
+
+			// Ensure proper HTTP status code
+			if code != test.code {
+				return fmt.Errorf("unexpected code: %v != %v", code, test.code)
+			}
+
+			// If code is in HTTP 400 or above, check error response
+			if code >= http.StatusBadRequest {
+				var errRes util.ErrorResponse
+				if err := json.Unmarshal(body, &errRes); err != nil {
+					return err
+				}
+
+				if errRes.Error.Message != test.errMessage {
+					return fmt.Errorf("unexpected error message: %v != %v", errRes.Error.Message, test.errMessage)
+				}
+
+				continue
+			}
+
+			// Verify position was returned
+			var res PositionsResponse
+			if err := json.Unmarshal(body, &res); err != nil {
+				return err
+			}
+			if len(res.Positions) != 1 || res.Positions[0].ID == 0 || res.Positions[0].Name != "President" {
+				return fmt.Errorf("unexpected positions: %v", res.Positions)
+			}
+		}
+
+		return nil
+	})
+}
+
+// TestDeletePositionHasTerms verifies that DeletePosition refuses to delete a
+// position which has term history.
+func TestDeletePositionHasTerms(t *testing.T) {
+	ctx := context.Background()
+
+	withContextOfficer(t, func(c *Context, user *models.User, position *models.Position, term *models.Term) error {
+		code, _, err := c.DeletePosition(httptest.NewRequest("DELETE", "/", nil), util.Vars{"id": fmt.Sprintf("%d", position.ID)})
+		if err != nil {
+			return err
+		}
+		if code != http.StatusConflict {
+			return fmt.Errorf("unexpected code: %v != %v", code, http.StatusConflict)
+		}
+
+		// Remove term history, and try again
+		if err := c.db.DeleteTerm(ctx, term); err != nil {
+			return err
+		}
+
+		code, _, err = c.DeletePosition(httptest.NewRequest("DELETE", "/", nil), util.Vars{"id": fmt.Sprintf("%d", position.ID)})
+		if err != nil {
+			return err
+		}
+		if code != http.StatusNoContent {
+			return fmt.Errorf("unexpected code: %v != %v", code, http.StatusNoContent)
+		}
+
+		return nil
+	})
+}
+
+// TestPostTerm verifies that PostTerm returns the appropriate HTTP status code,
+// body, and any errors which occur.
+func TestPostTerm(t *testing.T) {
+	ctx := context.Background()
+
+	withContextOfficer(t, func(c *Context, user *models.User, position *models.Position, term *models.Term) error {
+		// Table of tests to iterate
+		var tests = []struct {
+			id         string
+			code       int
+			errMessage string
+			body       []byte
+		}{
+			// Invalid position ID
+			{"foo", http.StatusBadRequest, positionInvalidID, nil},
+			// Position not found
+			{"100", http.StatusNotFound, positionNotFound, nil},
+			// Empty body
+			{"1", http.StatusBadRequest, positionJSONSyntax, nil},
+			// Missing user
+			{"1", http.StatusBadRequest, "empty field: userId", []byte(`{"start":1000}`)},
+			// Missing start
+			{"1", http.StatusBadRequest, "empty field: start", []byte(`{"userId":1}`)},
+			// Ends before start
+			{"1", http.StatusBadRequest, "invalid field: end (term must end after it starts)", []byte(`{"userId":1,"start":1000,"end":500}`)},
+			// User not found
+			{"1", http.StatusBadRequest, "invalid field: userId (user not found)", []byte(`{"userId":100,"start":1000}`)},
+			// Valid request, position ID from route takes precedence
+			{"1", http.StatusCreated, "", []byte(`{"userId":1,"positionId":100,"start":1000,"end":2000}`)},
+		}
+
+		// Iterate and run tests
+		for _, test := range tests {
+			// Generate HTTP request
+			r, err := http.NewRequest("POST", "/", bytes.NewReader(test.body))
+			if err != nil {
+				return err
+			}
+
+			code, body, err := c.PostTerm(r, util.Vars{"id": test.id})
+			if err != nil {
+				return err
+			}
+
+			// Ensure proper HTTP status code
+			if code != test.code {
+				return fmt.Errorf("unexpected code: %v != %v", code, test.code)
+			}
+
+			// If code is in HTTP 400 or above, check error response
+			if code >= http.StatusBadRequest {
+				var errRes util.ErrorResponse
+				if err := json.Unmarshal(body, &errRes); err != nil {
+					return err
+				}
+
+				if errRes.Error.Message != test.errMessage {
+					return fmt.Errorf("unexpected error message: %v != %v", errRes.Error.Message, test.errMessage)
+				}
+
+				continue
+			}
+
+			// Verify term was stored for position in route
+			var res TermsResponse
+			if err := json.Unmarshal(body, &res); err != nil {
+				return err
+			}
+			if len(res.Terms) != 1 || res.Terms[0].PositionID != position.ID {
+				return fmt.Errorf("unexpected terms: %v", res.Terms)
+			}
+		}
+
+		// Verify term history, most recent first
+		terms, err := c.db.SelectTermsByPositionID(ctx, position.ID)
+		if err != nil {
+			return err
+		}
+		if len(terms) != 2 || terms[0].ID != term.ID {
+			return fmt.Errorf("unexpected term history: %v", terms)
+		}
+
+		return nil
+	})
+}
+
+// TestGetTermWrongPosition verifies that GetTerm does not return a term through
+// a position which does not hold it.
+func TestGetTermWrongPosition(t *testing.T) {
+	ctx := context.Background()
+
+	withContextOfficer(t, func(c *Context, user *models.User, position *models.Position, term *models.Term) error {
+		other := &models.Position{
+			Name: "Secretary",
+		}
+		if err := c.db.InsertPosition(ctx, other); err != nil {
+			return err
+		}
+
+		code, _, err := c.GetTerm(httptest.NewRequest("GET", "/", nil), util.Vars{
+			"id":     fmt.Sprintf("%d", other.ID),
+			"termId": fmt.Sprintf("%d", term.ID),
+		})
+		if err != nil {
+			return err
+		}
+		if code != http.StatusNotFound {
+			return fmt.Errorf("unexpected code: %v != %v", code, http.StatusNotFound)
+		}
+
+		return nil
+	})
+}
+
+// TestListOfficers verifies that ListOfficers returns only users who hold an
+// active term.
+func TestListOfficers(t *testing.T) {
+	ctx := context.Background()
+
+	withContextOfficer(t, func(c *Context, user *models.User, position *models.Position, term *models.Term) error {
+		// Add a term which has already ended
+		past := &models.Term{
+			UserID:     user.ID,
+			PositionID: position.ID,
+			Start:      uint64(time.Now().Add(-48 * time.Hour).Unix()),
+			End:        uint64(time.Now().Add(-24 * time.Hour).Unix()),
+		}
+		if err := c.db.InsertTerm(ctx, past); err != nil {
+			return err
+		}
+
+		code, body, err := c.ListOfficers(httptest.NewRequest("GET", "/", nil), util.Vars{})
+		if err != nil {
+			return err
+		}
+		if code != http.StatusOK {
+			return fmt.Errorf("unexpected code: %v != %v", code, http.StatusOK)
+		}
+
+		var res OfficersResponse
+		if err := json.Unmarshal(body, &res); err != nil {
+			return err
+		}
+
+		if len(res.Officers) != 1 {
+			return fmt.Errorf("unexpected number of officers: %v != %v", len(res.Officers), 1)
+		}
+
+		o := res.Officers[0]
+		if o.Term.ID != term.ID || o.User.ID != user.ID || o.Position.ID != position.ID {
+			return fmt.Errorf("unexpected officer: %v", o)
+		}
+		if o.User.Password != "" {
+			return fmt.Errorf("password not stripped from officer")
+		}
+
+		return nil
+	})
+}
+
+// withContextOfficer builds upon withContextUser, adding a position which the
+// mock user currently holds.
+func withContextOfficer(t *testing.T, fn func(c *Context, user *models.User, position *models.Position, term *models.Term) error) {
+	ctx := context.Background()
+
+	withContextUser(t, func(c *Context, user *models.User) error {
+		position := &models.Position{
+			Name: "President",
+			Rank: 1,
+		}
+		if err := c.db.InsertPosition(ctx, position); err != nil {
+			return err
+		}
+
+		term := &models.Term{
+			UserID:     user.ID,
+			PositionID: position.ID,
+			Start:      uint64(time.Now().Add(-1 * time.Hour).Unix()),
+		}
+		if err := c.db.InsertTerm(ctx, term); err != nil {
+			return err
+		}
+
+		return fn(c, user, position, term)
+	})
+}
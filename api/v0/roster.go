@@ -0,0 +1,672 @@
+package v0
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/mdlayher/deltaiota/api/util"
+	"github.com/mdlayher/deltaiota/data"
+	"github.com/mdlayher/deltaiota/data/models"
+)
+
+const (
+	// csvContentType is the HTTP Content-Type for CSV documents.
+	csvContentType = "text/csv; charset=utf-8"
+
+	// rosterMaxBytes is the maximum size, in bytes, of an imported roster.
+	rosterMaxBytes = 1 << 20
+)
+
+// JSON Roster API, human-readable client error responses.
+const (
+	// HTTP GET
+	rosterInvalidDryRun = "invalid dry run parameter"
+
+	// HTTP POST
+	rosterDuplicateColumn = "duplicate column in roster"
+	rosterInvalidCSV      = "invalid CSV document"
+	rosterInvalidMapping  = "invalid column mapping"
+	rosterInvalidRows     = "invalid rows in roster"
+	rosterNoRows          = "roster contains no users"
+	rosterTooLarge        = "roster too large"
+)
+
+// JSON Roster API, map of client errors to response codes.
+var rosterCode = map[string]int{
+	// HTTP GET
+	rosterInvalidDryRun: http.StatusBadRequest,
+
+	// HTTP POST
+	rosterDuplicateColumn: http.StatusBadRequest,
+	rosterInvalidCSV:      http.StatusBadRequest,
+	rosterInvalidMapping:  http.StatusBadRequest,
+	rosterInvalidRows:     http.StatusBadRequest,
+	rosterNoRows:          http.StatusBadRequest,
+	rosterTooLarge:        http.StatusRequestEntityTooLarge,
+}
+
+// Generated JSON responses for various client-facing errors.
+var rosterJSON = map[string][]byte{}
+
+// init initializes the stored JSON responses for client-facing errors.
+func init() {
+	// Iterate all error strings and code integers
+	for k, v := range rosterCode {
+		// Generate error response with appropriate string and code
+		body, err := json.Marshal(util.ErrRes(v, k))
+		if err != nil {
+			panic(err)
+		}
+
+		// Store for later use
+		rosterJSON[k] = body
+	}
+}
+
+// RosterImportResponse is the output response for the Roster Import API.
+type RosterImportResponse struct {
+	DryRun         bool               `json:"dryRun"`
+	Created        int                `json:"created"`
+	IgnoredColumns []string           `json:"ignoredColumns"`
+	Rows           []*RosterImportRow `json:"rows"`
+}
+
+// RosterImportRow is the result of importing a single row of a roster.  Line is
+// the line number of the row in the CSV document, including its header.
+type RosterImportRow struct {
+	Line     int    `json:"line"`
+	Username string `json:"username"`
+	UserID   uint64 `json:"userId,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// rosterColumn is a single CSV column of the roster, named after the JSON field
+// of the User it contains.  Columns with no set function are exported, but are
+// ignored on import.
+type rosterColumn struct {
+	name string
+	get  func(u *models.User) string
+	set  func(u *models.User, v string) error
+}
+
+// rosterColumns are the CSV columns of the roster, in export order.
+var rosterColumns = []*rosterColumn{
+	{"id", func(u *models.User) string { return strconv.FormatUint(u.ID, 10) }, nil},
+	{"username", func(u *models.User) string { return u.Username }, func(u *models.User, v string) error {
+		u.Username = v
+		return nil
+	}},
+	{"firstName", func(u *models.User) string { return u.FirstName }, func(u *models.User, v string) error {
+		u.FirstName = v
+		return nil
+	}},
+	{"lastName", func(u *models.User) string { return u.LastName }, func(u *models.User, v string) error {
+		u.LastName = v
+		return nil
+	}},
+	{"email", func(u *models.User) string { return u.Email }, func(u *models.User, v string) error {
+		u.Email = v
+		return nil
+	}},
+	{"phone", func(u *models.User) string { return u.Phone }, func(u *models.User, v string) error {
+		u.Phone = v
+		return nil
+	}},
+	{"initiationDate", func(u *models.User) string { return formatRosterUint(u.InitiationDate) }, func(u *models.User, v string) error {
+		return parseRosterUint("initiationDate", v, &u.InitiationDate)
+	}},
+	{"pledgeClass", func(u *models.User) string { return u.PledgeClass }, func(u *models.User, v string) error {
+		u.PledgeClass = v
+		return nil
+	}},
+	{"bigBrotherId", func(u *models.User) string { return formatRosterUint(u.BigBrotherID) }, func(u *models.User, v string) error {
+		return parseRosterUint("bigBrotherId", v, &u.BigBrotherID)
+	}},
+	{"instruments", func(u *models.User) string { return strings.Join(u.Instruments, "; ") }, func(u *models.User, v string) error {
+		u.Instruments = nil
+		for _, s := range strings.Split(v, ";") {
+			if s = strings.TrimSpace(s); s != "" {
+				u.Instruments = append(u.Instruments, s)
+			}
+		}
+		return nil
+	}},
+	{"graduationYear", func(u *models.User) string { return formatRosterUint(uint64(u.GraduationYear)) }, func(u *models.User, v string) error {
+		var year uint64
+		err := parseRosterUint("graduationYear", v, &year)
+		u.GraduationYear = int(year)
+		return err
+	}},
+	{"status", func(u *models.User) string { return string(u.Status) }, func(u *models.User, v string) error {
+		u.Status = models.MemberStatus(strings.ToLower(v))
+		return nil
+	}},
+	{"address", func(u *models.User) string { return u.Address }, func(u *models.User, v string) error {
+		u.Address = v
+		return nil
+	}},
+	{"bio", func(u *models.User) string { return u.Bio }, func(u *models.User, v string) error {
+		u.Bio = v
+		return nil
+	}},
+	{"privateEmail", func(u *models.User) string { return strconv.FormatBool(u.PrivateEmail) }, func(u *models.User, v string) error {
+		return parseRosterBool("privateEmail", v, &u.PrivateEmail)
+	}},
+	{"privatePhone", func(u *models.User) string { return strconv.FormatBool(u.PrivatePhone) }, func(u *models.User, v string) error {
+		return parseRosterBool("privatePhone", v, &u.PrivatePhone)
+	}},
+}
+
+// ExportUsersCSV is a http.HandlerFunc which writes HTTP 200 and the roster of
+// users as a CSV document on success, or a non-200 HTTP status code and a JSON
+// error response on failure.  Users may optionally be filtered using the "status"
+// and "pledgeClass" query parameters.  The document may be edited and imported
+// using ImportUsers.
+func (c *Context) ExportUsersCSV(w http.ResponseWriter, r *http.Request) {
+	// Build filter from query parameters
+	query := r.URL.Query()
+	filter := data.UserFilter{
+		Status:      models.MemberStatus(query.Get("status")),
+		PledgeClass: query.Get("pledgeClass"),
+	}
+
+	// Verify status filter, if set
+	if filter.Status != "" && !filter.Status.Valid() {
+		writeJSONErr(w, r, usersCode[userInvalidStatus], usersJSON[userInvalidStatus])
+		return
+	}
+
+	users, err := c.db.SelectUsersByFilter(r.Context(), filter)
+	if err != nil {
+		util.Logger(r).Error("request error", "err", err)
+		writeJSONErr(w, r, util.Code[util.InternalServerError], util.JSON[util.InternalServerError])
+		return
+	}
+
+	// Write header, followed by one row per user
+	buf := bytes.NewBuffer(nil)
+	cw := csv.NewWriter(buf)
+
+	record := make([]string, len(rosterColumns))
+	for i, col := range rosterColumns {
+		record[i] = col.name
+	}
+	cw.Write(record)
+
+	for _, u := range users {
+		for i, col := range rosterColumns {
+			record[i] = col.get(u)
+		}
+		cw.Write(record)
+	}
+
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		util.Logger(r).Error("request error", "err", err)
+		writeJSONErr(w, r, util.Code[util.InternalServerError], util.JSON[util.InternalServerError])
+		return
+	}
+
+	w.Header().Set("Content-Type", csvContentType)
+	w.Header().Set("Content-Disposition", `attachment; filename="roster.csv"`)
+	w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+	w.WriteHeader(http.StatusOK)
+	if r.Method != "HEAD" {
+		w.Write(buf.Bytes())
+	}
+}
+
+// UsersImportAPI is a util.JSONAPIFunc, and is the single entry point for the
+// Roster Import API.
+// This method delegates to other methods as appropriate to handle incoming requests.
+func (c *Context) UsersImportAPI(r *http.Request, vars util.Vars) (int, []byte, error) {
+	// Switch based on HTTP method
+	switch r.Method {
+	case "POST":
+		return c.ImportUsers(r, vars)
+	default:
+		return util.MethodNotAllowed(r, vars)
+	}
+}
+
+// ImportUsers is a util.JSONAPIFunc which creates users from a CSV document in
+// the request body, and returns HTTP 201 and a JSON list of per-row results on
+// success, or a non-200 HTTP status code and an error response on failure.
+//
+// The first row of the document is a header, which names the User field stored
+// in each column.  Header names are matched to fields ignoring case, spaces, and
+// punctuation, so "First Name" matches "firstName".  Other names may be mapped
+// to fields using one or more "map" query parameters, in the form "Header:field".
+// Unknown columns are ignored.
+//
+// If the "dryRun" query parameter is set, each row is validated and HTTP 200 is
+// returned with the results, but no users are created.  Otherwise, either every
+// user is created, or none are.  Each created user is assigned a random password,
+// and an invitation to choose their own password is queued.
+func (c *Context) ImportUsers(r *http.Request, vars util.Vars) (int, []byte, error) {
+	query := r.URL.Query()
+
+	// Check for dry run
+	var dryRun bool
+	if s := query.Get("dryRun"); s != "" {
+		var err error
+		dryRun, err = strconv.ParseBool(s)
+		if err != nil {
+			return rosterCode[rosterInvalidDryRun], rosterJSON[rosterInvalidDryRun], nil
+		}
+	}
+
+	// Parse explicit header mappings
+	mapping := make(map[string]*rosterColumn)
+	for _, m := range query["map"] {
+		kv := strings.SplitN(m, ":", 2)
+		if len(kv) != 2 {
+			return rosterCode[rosterInvalidMapping], rosterJSON[rosterInvalidMapping], nil
+		}
+
+		col := findRosterColumn(kv[1])
+		if col == nil || col.set == nil {
+			return rosterCode[rosterInvalidMapping], rosterJSON[rosterInvalidMapping], nil
+		}
+		mapping[normalizeRosterHeader(kv[0])] = col
+	}
+
+	// Do not allow nil body
+	if r.Body == nil {
+		return rosterCode[rosterNoRows], rosterJSON[rosterNoRows], nil
+	}
+
+	// Read document, up to one byte more than the maximum, to detect overly
+	// large documents without reading the entire request body
+	buf, err := ioutil.ReadAll(io.LimitReader(r.Body, rosterMaxBytes+1))
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+	if len(buf) > rosterMaxBytes {
+		return rosterCode[rosterTooLarge], rosterJSON[rosterTooLarge], nil
+	}
+
+	// Spreadsheet applications may prefix CSV documents with a byte order mark
+	buf = bytes.TrimPrefix(buf, []byte("\ufeff"))
+
+	cr := csv.NewReader(bytes.NewReader(buf))
+	cr.TrimLeadingSpace = true
+	records, err := cr.ReadAll()
+	if err != nil {
+		return rosterCode[rosterInvalidCSV], rosterJSON[rosterInvalidCSV], nil
+	}
+	if len(records) < 2 {
+		return rosterCode[rosterNoRows], rosterJSON[rosterNoRows], nil
+	}
+
+	// Map each column in the header to a User field
+	res := &RosterImportResponse{
+		DryRun:         dryRun,
+		IgnoredColumns: []string{},
+	}
+	columns := make([]*rosterColumn, len(records[0]))
+	seen := make(map[*rosterColumn]bool)
+	for i, h := range records[0] {
+		col, ok := mapping[normalizeRosterHeader(h)]
+		if !ok {
+			col = findRosterColumn(h)
+		}
+		if col == nil || col.set == nil {
+			res.IgnoredColumns = append(res.IgnoredColumns, h)
+			continue
+		}
+
+		if seen[col] {
+			return rosterCode[rosterDuplicateColumn], rosterJSON[rosterDuplicateColumn], nil
+		}
+		seen[col] = true
+		columns[i] = col
+	}
+
+	// Validate each row, recording the result
+	ctx, cancel := util.QueryContext(r.Context())
+	users, err := c.rosterUsers(ctx, columns, records[1:], res)
+	cancel()
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+
+	// On dry run, report results without creating any users
+	if dryRun {
+		body, err := json.Marshal(res)
+		return http.StatusOK, body, err
+	}
+
+	// Report the first invalid row, if any
+	for _, row := range res.Rows {
+		if row.Error != "" {
+			code := rosterCode[rosterInvalidRows]
+			body, err := json.Marshal(util.ErrRes(code, fmt.Sprintf("%s: line %d: %s", rosterInvalidRows, row.Line, row.Error)))
+			return code, body, err
+		}
+	}
+
+	// Hash each user's random password before querying the database again,
+	// since hashing is deliberately slow, and would otherwise consume much of
+	// the time allowed for the queries which follow
+	if err := hashPasswords(r.Context(), users); err != nil {
+		return util.JSONAPIErr(err)
+	}
+
+	// Create all users, and record them in the audit log
+	ctx, cancel = util.QueryContext(r.Context())
+	defer cancel()
+
+	return c.audit.Handler("user", nil, func(r *http.Request, vars util.Vars) (int, []byte, error) {
+		return c.createRosterUsers(r, users, res)
+	})(r.WithContext(ctx), vars)
+}
+
+// createRosterUsers creates each input User, and an invitation for each, in a
+// single transaction.  Like a util.JSONAPIFunc, it returns HTTP 201 and the
+// input response on success, or a non-200 HTTP status code and an error
+// response on failure.
+func (c *Context) createRosterUsers(r *http.Request, users []*models.User, res *RosterImportResponse) (int, []byte, error) {
+	var failed int
+	expire := time.Now().Add(InvitationDuration)
+	err := c.db.WithTx(r.Context(), func(tx *data.Tx) error {
+		for i, u := range users {
+			failed = i
+			if err := tx.InsertUser(r.Context(), u); err != nil {
+				return err
+			}
+
+			invite, err := models.NewInvitation(u.Email, u.ID, expire)
+			if err != nil {
+				return err
+			}
+			if err := tx.InsertInvitation(r.Context(), invite); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		// Check for constraint failure, meaning a user already exists
+		if c.db.IsConstraintFailure(err) {
+			code := usersCode[userConflict]
+			body, err := json.Marshal(util.ErrRes(code, fmt.Sprintf("%s: line %d: %s", rosterInvalidRows, res.Rows[failed].Line, userConflict)))
+			return code, body, err
+		}
+
+		return util.JSONAPIErr(err)
+	}
+
+	for i, u := range users {
+		res.Rows[i].UserID = u.ID
+	}
+	res.Created = len(users)
+
+	body, err := json.Marshal(res)
+	return http.StatusCreated, body, err
+}
+
+// rosterUsers converts each input record into a User, using the input columns,
+// and validates it, appending a result row to the input response for each record.
+// Each User is assigned a random password, which is not yet hashed.
+func (c *Context) rosterUsers(ctx context.Context, columns []*rosterColumn, records [][]string, res *RosterImportResponse) ([]*models.User, error) {
+	users := make([]*models.User, 0, len(records))
+	usernames := make(map[string]bool)
+	emails := make(map[string]bool)
+
+	for i, record := range records {
+		u := new(models.User)
+		row := &RosterImportRow{
+			// Records are numbered after the header line
+			Line: i + 2,
+		}
+		res.Rows = append(res.Rows, row)
+		users = append(users, u)
+
+		err := c.rosterUser(ctx, u, columns, record, usernames, emails)
+		row.Username = u.Username
+		if err == nil {
+			continue
+		}
+
+		// Record client errors for this row, but fail on server errors
+		switch err.(type) {
+		case *models.EmptyFieldError, *models.InvalidFieldError:
+			row.Error = err.Error()
+		default:
+			return nil, err
+		}
+	}
+
+	return users, nil
+}
+
+// hashPasswords hashes the random password assigned to each input User, using
+// one goroutine per CPU.  Hashing stops early if ctx is canceled.
+func hashPasswords(ctx context.Context, users []*models.User) error {
+	userC := make(chan *models.User)
+	errC := make(chan error, runtime.NumCPU())
+
+	var wg sync.WaitGroup
+	for i := 0; i < runtime.NumCPU(); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for u := range userC {
+				if err := u.SetPassword(u.Password); err != nil {
+					errC <- err
+					return
+				}
+			}
+		}()
+	}
+
+	// Feed users to the workers until all are hashed, a worker fails, or
+	// the context is canceled
+	var err error
+feed:
+	for _, u := range users {
+		select {
+		case userC <- u:
+		case err = <-errC:
+			break feed
+		case <-ctx.Done():
+			err = ctx.Err()
+			break feed
+		}
+	}
+	close(userC)
+	wg.Wait()
+
+	if err != nil {
+		return err
+	}
+
+	select {
+	case err := <-errC:
+		return err
+	default:
+		return nil
+	}
+}
+
+// rosterUser populates and validates a single User from an input record.  The
+// input maps track usernames and email addresses seen earlier in the roster.
+func (c *Context) rosterUser(ctx context.Context, u *models.User, columns []*rosterColumn, record []string, usernames map[string]bool, emails map[string]bool) error {
+	for i, col := range columns {
+		if col == nil {
+			continue
+		}
+
+		if err := col.set(u, strings.TrimSpace(record[i])); err != nil {
+			return err
+		}
+	}
+
+	// Assign a random password, which the user will replace by accepting
+	// their invitation
+	password, err := models.RandomPassword()
+	if err != nil {
+		return err
+	}
+	u.Password = password
+
+	if err := u.Validate(); err != nil {
+		return err
+	}
+
+	// Check for duplicates within the roster, and for existing users
+	if usernames[u.Username] {
+		return &models.InvalidFieldError{
+			Field:   "username",
+			Details: "duplicate username in roster",
+		}
+	}
+	usernames[u.Username] = true
+
+	if emails[u.Email] {
+		return &models.InvalidFieldError{
+			Field:   "email",
+			Details: "duplicate email in roster",
+		}
+	}
+	emails[u.Email] = true
+
+	// Usernames and emails of deleted users may not be reused until the users
+	// are purged
+	inUse, err := c.db.UsernameInUse(ctx, u.Username)
+	if err != nil {
+		return err
+	}
+	if inUse {
+		return &models.InvalidFieldError{
+			Field:   "username",
+			Details: "user already exists",
+		}
+	}
+
+	inUse, err = c.db.EmailInUse(ctx, u.Email)
+	if err != nil {
+		return err
+	}
+	if inUse {
+		return &models.InvalidFieldError{
+			Field:   "email",
+			Details: "user already exists",
+		}
+	}
+
+	// If a big brother is specified, verify that he exists
+	if u.BigBrotherID != 0 {
+		exists, err := userExists(c.db.SelectUserByID(ctx, u.BigBrotherID))
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return &models.InvalidFieldError{
+				Field:   "bigBrotherId",
+				Details: "big brother not found",
+			}
+		}
+	}
+
+	return nil
+}
+
+// userExists converts the result of selecting a single User into whether or not
+// the User exists.
+func userExists(u *models.User, err error) (bool, error) {
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+// findRosterColumn returns the roster column matching the input header name,
+// or nil if none matches.
+func findRosterColumn(header string) *rosterColumn {
+	h := normalizeRosterHeader(header)
+	for _, col := range rosterColumns {
+		if normalizeRosterHeader(col.name) == h {
+			return col
+		}
+	}
+
+	return nil
+}
+
+// normalizeRosterHeader normalizes a header name for comparison, by removing
+// case, spaces, and punctuation.
+func normalizeRosterHeader(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+
+		return -1
+	}, s)
+}
+
+// formatRosterUint formats an optional number for export, leaving zero blank.
+func formatRosterUint(n uint64) string {
+	if n == 0 {
+		return ""
+	}
+
+	return strconv.FormatUint(n, 10)
+}
+
+// parseRosterUint parses an optional number for the named field on import.
+func parseRosterUint(field string, v string, n *uint64) error {
+	if v == "" {
+		*n = 0
+		return nil
+	}
+
+	var err error
+	*n, err = strconv.ParseUint(v, 10, 64)
+	if err != nil {
+		return &models.InvalidFieldError{
+			Field:   field,
+			Err:     err,
+			Details: "must be a number",
+		}
+	}
+
+	return nil
+}
+
+// parseRosterBool parses an optional boolean for the named field on import.
+func parseRosterBool(field string, v string, b *bool) error {
+	if v == "" {
+		*b = false
+		return nil
+	}
+
+	var err error
+	*b, err = strconv.ParseBool(v)
+	if err != nil {
+		return &models.InvalidFieldError{
+			Field:   field,
+			Err:     err,
+			Details: "must be true or false",
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,299 @@
+package v0
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mdlayher/deltaiota/api/auth"
+	"github.com/mdlayher/deltaiota/api/util"
+	"github.com/mdlayher/deltaiota/data"
+	"github.com/mdlayher/deltaiota/data/models"
+)
+
+// TestImportUsers verifies that ImportUsers validates rosters, reports per-row
+// results on dry run, and creates either every user or none.
+func TestImportUsers(t *testing.T) {
+	ctx := context.Background()
+
+	withContextUser(t, func(c *Context, user *models.User) error {
+		const valid = "Username,First Name,Last Name,E-mail,Class,Notes\n" +
+			"alice,Alice,Adams,alice@example.com,Alpha,first\n" +
+			"bob,Bob,Brown,bob@example.com,Alpha,second\n"
+
+		invalid := "username,firstName,lastName,email,graduationYear\n" +
+			"carol,Carol,Clark,carol@example.com,2016\n" +
+			"dave,Dave,Davis,,2016\n" +
+			"erin,Erin,Evans,erin@example.com,soon\n" +
+			"carol,Carol,Clark,carol2@example.com,\n" +
+			user.Username + ",Frank,Fisher,frank@example.com,\n"
+
+		// Table of tests to iterate
+		var tests = []struct {
+			query      string
+			body       string
+			code       int
+			errMessage string
+		}{
+			// Invalid dry run parameter
+			{"dryRun=foo", valid, http.StatusBadRequest, rosterInvalidDryRun},
+			// Mapping to unknown field
+			{"map=Class:foo", valid, http.StatusBadRequest, rosterInvalidMapping},
+			// Mapping to export-only field
+			{"map=Class:id", valid, http.StatusBadRequest, rosterInvalidMapping},
+			// Header only
+			{"", "username\n", http.StatusBadRequest, rosterNoRows},
+			// Ragged rows
+			{"", "username,email\nalice\n", http.StatusBadRequest, rosterInvalidCSV},
+			// Two columns map to the same field
+			{"", "username,user name\nalice,alice\n", http.StatusBadRequest, rosterDuplicateColumn},
+			// Invalid rows, reporting the first
+			{"", invalid, http.StatusBadRequest, rosterInvalidRows + ": line 3: empty field: email"},
+		}
+
+		for _, test := range tests {
+			code, body, err := c.ImportUsers(mockImportRequest(test.query, test.body), util.Vars{})
+			if err != nil {
+				return err
+			}
+
+			// Ensure proper HTTP status code
+			if code != test.code {
+				return fmt.Errorf("unexpected code: %v != %v", code, test.code)
+			}
+
+			var errRes util.ErrorResponse
+			if err := json.Unmarshal(body, &errRes); err != nil {
+				return err
+			}
+			if errRes.Error.Message != test.errMessage {
+				return fmt.Errorf("unexpected error message: %v != %v", errRes.Error.Message, test.errMessage)
+			}
+		}
+
+		// Dry run reports the result of every row
+		code, body, err := c.ImportUsers(mockImportRequest("dryRun=true", invalid), util.Vars{})
+		if err != nil {
+			return err
+		}
+		if code != http.StatusOK {
+			return fmt.Errorf("unexpected code: %v != %v", code, http.StatusOK)
+		}
+
+		var res RosterImportResponse
+		if err := json.Unmarshal(body, &res); err != nil {
+			return err
+		}
+
+		wantErrors := []string{
+			"",
+			"empty field: email",
+			"invalid field: graduationYear (must be a number)",
+			"invalid field: username (duplicate username in roster)",
+			"invalid field: username (user already exists)",
+		}
+		if len(res.Rows) != len(wantErrors) {
+			return fmt.Errorf("unexpected number of rows: %v != %v", len(res.Rows), len(wantErrors))
+		}
+		for i, row := range res.Rows {
+			if row.Line != i+2 {
+				return fmt.Errorf("unexpected line: %v != %v", row.Line, i+2)
+			}
+			if row.Error != wantErrors[i] {
+				return fmt.Errorf("line %d: unexpected error: %v != %v", row.Line, row.Error, wantErrors[i])
+			}
+		}
+
+		// No users were created by dry run or failed import
+		if _, err := c.db.SelectUserByUsername(ctx, "carol"); err == nil {
+			return fmt.Errorf("user created by failed import")
+		}
+
+		// Import valid roster, mapping an unrecognized column
+		code, body, err = c.ImportUsers(mockImportRequest("map=Class:pledgeClass", valid), util.Vars{})
+		if err != nil {
+			return err
+		}
+		if code != http.StatusCreated {
+			return fmt.Errorf("unexpected code: %v != %v: %s", code, http.StatusCreated, body)
+		}
+
+		res = RosterImportResponse{}
+		if err := json.Unmarshal(body, &res); err != nil {
+			return err
+		}
+		if res.Created != 2 {
+			return fmt.Errorf("unexpected number created: %v != %v", res.Created, 2)
+		}
+		if len(res.IgnoredColumns) != 1 || res.IgnoredColumns[0] != "Notes" {
+			return fmt.Errorf("unexpected ignored columns: %v", res.IgnoredColumns)
+		}
+
+		// Verify each user was created with a random password and a queued
+		// invitation
+		for _, row := range res.Rows {
+			u, err := c.db.SelectUserByID(ctx, row.UserID)
+			if err != nil {
+				return err
+			}
+			if u.PledgeClass != "Alpha" {
+				return fmt.Errorf("unexpected pledge class: %v != %v", u.PledgeClass, "Alpha")
+			}
+			if u.Password == "" {
+				return fmt.Errorf("user %q has no password", u.Username)
+			}
+
+			invites, err := c.db.SelectInvitationsByUserID(ctx, u.ID)
+			if err != nil {
+				return err
+			}
+			if len(invites) != 1 || invites[0].Email != u.Email || invites[0].Sent != 0 {
+				return fmt.Errorf("unexpected invitations for %q: %v", u.Username, invites)
+			}
+		}
+
+		return nil
+	})
+}
+
+// TestImportUsersLargeRoster verifies that importing a roster of realistic
+// size is not canceled by QueryTimeout while hashing passwords, and that the
+// import is recorded in the audit log.
+func TestImportUsersLargeRoster(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping large roster import in short mode")
+	}
+
+	ctx := context.Background()
+
+	// Hashing every password takes far longer than the time allowed for the
+	// import's queries
+	timeout := util.QueryTimeout
+	util.QueryTimeout = 1 * time.Second
+	defer func() {
+		util.QueryTimeout = timeout
+	}()
+
+	const n = 100
+
+	withContextUser(t, func(c *Context, user *models.User) error {
+		roster := "username,firstName,lastName,email\n"
+		for i := 0; i < n; i++ {
+			roster += fmt.Sprintf("user%03d,First,Last,user%03d@example.com\n", i, i)
+		}
+
+		w := httptest.NewRecorder()
+		r := auth.WithUser(mockImportRequest("", roster), user)
+		util.SlowJSONAPIHandler(c.UsersImportAPI).ServeHTTP(w, r)
+
+		if w.Code != http.StatusCreated {
+			return fmt.Errorf("unexpected code: %v != %v: %s", w.Code, http.StatusCreated, w.Body.String())
+		}
+
+		var res RosterImportResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+			return err
+		}
+		if res.Created != n {
+			return fmt.Errorf("unexpected number created: %v != %v", res.Created, n)
+		}
+
+		// Verify each user can be retrieved, and the import was audited
+		for _, row := range res.Rows {
+			if _, err := c.db.SelectUserByID(ctx, row.UserID); err != nil {
+				return err
+			}
+		}
+
+		entries, err := c.db.SelectAuditEntriesByFilter(ctx, data.AuditFilter{
+			ActorID:    user.ID,
+			Action:     models.AuditCreate,
+			TargetType: "user",
+		}, 10, 0)
+		if err != nil {
+			return err
+		}
+		if len(entries) != 1 {
+			return fmt.Errorf("unexpected number of audit entries: %v != %v", len(entries), 1)
+		}
+
+		return nil
+	})
+}
+
+// TestExportUsersCSV verifies that ExportUsersCSV writes the roster as a CSV
+// document which may be imported again.
+func TestExportUsersCSV(t *testing.T) {
+	ctx := context.Background()
+
+	withContextUser(t, func(c *Context, user *models.User) error {
+		user.Instruments = models.StringList{"trumpet", "piano"}
+		user.GraduationYear = 2016
+		if err := c.db.UpdateUser(ctx, user); err != nil {
+			return err
+		}
+
+		r, err := http.NewRequest("GET", "/users/export.csv", nil)
+		if err != nil {
+			return err
+		}
+
+		w := httptest.NewRecorder()
+		c.ExportUsersCSV(w, r)
+		if w.Code != http.StatusOK {
+			return fmt.Errorf("unexpected code: %v != %v", w.Code, http.StatusOK)
+		}
+		if ct := w.Header().Get("Content-Type"); ct != csvContentType {
+			return fmt.Errorf("unexpected Content-Type: %v != %v", ct, csvContentType)
+		}
+
+		records, err := csv.NewReader(w.Body).ReadAll()
+		if err != nil {
+			return err
+		}
+		if len(records) != 2 {
+			return fmt.Errorf("unexpected number of records: %v != %v", len(records), 2)
+		}
+
+		// Verify each column is exported, and may be found again on import
+		record := make(map[string]string)
+		for i, h := range records[0] {
+			if findRosterColumn(h) != rosterColumns[i] {
+				return fmt.Errorf("unexpected column: %v", h)
+			}
+			record[h] = records[1][i]
+		}
+
+		for k, v := range map[string]string{
+			"username":       user.Username,
+			"email":          user.Email,
+			"instruments":    "trumpet; piano",
+			"graduationYear": "2016",
+			"status":         "active",
+			"privateEmail":   "false",
+		} {
+			if record[k] != v {
+				return fmt.Errorf("unexpected %s: %v != %v", k, record[k], v)
+			}
+		}
+
+		return nil
+	})
+}
+
+// mockImportRequest generates a HTTP request to import the input CSV document,
+// with the input query string.
+func mockImportRequest(query string, body string) *http.Request {
+	r, err := http.NewRequest("POST", "/users/import?"+query, strings.NewReader(body))
+	if err != nil {
+		panic(err)
+	}
+
+	return r
+}
@@ -34,8 +34,13 @@ func (c *Context) SessionsAPI(r *http.Request, vars util.Vars) (int, []byte, err
 // GetSession is a util.JSONAPIFunc which returns the current Session and a HTTP 200
 // on success, or a non-200 HTTP status code and an error response on failure.
 func (c *Context) GetSession(r *http.Request, vars util.Vars) (int, []byte, error) {
+	session, ok := auth.Session(r)
+	if !ok {
+		return util.Code[util.NotAuthorized], util.JSON[util.NotAuthorized], nil
+	}
+
 	body, err := json.Marshal(SessionsResponse{
-		Session: auth.Session(r),
+		Session: session,
 	})
 	return http.StatusOK, body, err
 }
@@ -45,7 +50,10 @@ func (c *Context) GetSession(r *http.Request, vars util.Vars) (int, []byte, erro
 // error response on failure.
 func (c *Context) PostSession(r *http.Request, vars util.Vars) (int, []byte, error) {
 	// Retrieve authenticated user
-	user := auth.User(r)
+	user, code, body := authUser(r)
+	if body != nil {
+		return code, body, nil
+	}
 
 	// Generate a new session for the user
 	session, err := user.NewSession(time.Now().Add(auth.SessionDuration))
@@ -54,12 +62,12 @@ func (c *Context) PostSession(r *http.Request, vars util.Vars) (int, []byte, err
 	}
 
 	// Store session for later use
-	if err := c.db.InsertSession(session); err != nil {
+	if err := c.db.InsertSession(r.Context(), session); err != nil {
 		return util.JSONAPIErr(err)
 	}
 
 	// Wrap in response and return
-	body, err := json.Marshal(SessionsResponse{
+	body, err = json.Marshal(SessionsResponse{
 		Session: session,
 	})
 	return http.StatusOK, body, err
@@ -69,10 +77,13 @@ func (c *Context) PostSession(r *http.Request, vars util.Vars) (int, []byte, err
 // HTTP 204 on success, or a non-200 HTTP status code and an error response on failure.
 func (c *Context) DeleteSession(r *http.Request, vars util.Vars) (int, []byte, error) {
 	// Retrieve authenticated session
-	session := auth.Session(r)
+	session, ok := auth.Session(r)
+	if !ok {
+		return util.Code[util.NotAuthorized], util.JSON[util.NotAuthorized], nil
+	}
 
 	// Delete session now
-	if err := c.db.DeleteSession(session); err != nil {
+	if err := c.db.DeleteSession(r.Context(), session); err != nil {
 		return util.JSONAPIErr(err)
 	}
 
@@ -1,6 +1,7 @@
 package v0
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
@@ -17,6 +18,8 @@ import (
 // TestSessionsAPI verifies that SessionsAPI correctly routes requests to
 // other Sessions API handlers, using the input HTTP request.
 func TestSessionsAPI(t *testing.T) {
+	ctx := context.Background()
+
 	withContextUser(t, func(c *Context, user *models.User) error {
 		// Generate and store mock session
 		session := &models.Session{
@@ -24,7 +27,7 @@ func TestSessionsAPI(t *testing.T) {
 			Key:    ditest.RandomString(32),
 			Expire: uint64(time.Now().Unix()),
 		}
-		if err := c.db.InsertSession(session); err != nil {
+		if err := c.db.InsertSession(ctx, session); err != nil {
 			return err
 		}
 
@@ -54,7 +57,7 @@ func TestSessionsAPI(t *testing.T) {
 			}
 
 			// Store mock-authenticated session
-			auth.SetSession(r, session)
+			r = auth.WithSession(r, session)
 
 			// Delegate to appropriate handler
 			code, _, err := c.SessionsAPI(r, util.Vars{})
@@ -75,6 +78,8 @@ func TestSessionsAPI(t *testing.T) {
 // TestGetSession verifies that GetSession returns the appropriate HTTP status
 // code, body, and any errors which occur.
 func TestGetSession(t *testing.T) {
+	ctx := context.Background()
+
 	withContextUser(t, func(c *Context, user *models.User) error {
 		// Generate and store mock session
 		session := &models.Session{
@@ -82,7 +87,7 @@ func TestGetSession(t *testing.T) {
 			Key:    ditest.RandomString(32),
 			Expire: uint64(time.Now().Unix()),
 		}
-		if err := c.db.InsertSession(session); err != nil {
+		if err := c.db.InsertSession(ctx, session); err != nil {
 			return err
 		}
 
@@ -93,7 +98,7 @@ func TestGetSession(t *testing.T) {
 		}
 
 		// Store mock-authenticated session
-		auth.SetSession(r, session)
+		r = auth.WithSession(r, session)
 
 		// Invoke GetSession with HTTP request
 		code, body, err := c.GetSession(r, util.Vars{})
@@ -131,7 +136,7 @@ func TestPostSession(t *testing.T) {
 		}
 
 		// Store mock-authenticated user
-		auth.SetUser(r, user)
+		r = auth.WithUser(r, user)
 
 		// Invoke PostSession with HTTP request
 		code, body, err := c.PostSession(r, util.Vars{})
@@ -162,6 +167,8 @@ func TestPostSession(t *testing.T) {
 // TestDeleteSession verifies that DeleteSession returns the appropriate HTTP status
 // code, body, and any errors which occur.
 func TestDeleteSession(t *testing.T) {
+	ctx := context.Background()
+
 	withContextUser(t, func(c *Context, user *models.User) error {
 		// Generate and store mock session
 		session := &models.Session{
@@ -169,7 +176,7 @@ func TestDeleteSession(t *testing.T) {
 			Key:    ditest.RandomString(32),
 			Expire: uint64(time.Now().Unix()),
 		}
-		if err := c.db.InsertSession(session); err != nil {
+		if err := c.db.InsertSession(ctx, session); err != nil {
 			return err
 		}
 
@@ -180,7 +187,7 @@ func TestDeleteSession(t *testing.T) {
 		}
 
 		// Store mock-authenticated session
-		auth.SetSession(r, session)
+		r = auth.WithSession(r, session)
 
 		// Invoke DeleteSession with HTTP request
 		code, _, err := c.DeleteSession(r, util.Vars{})
@@ -194,7 +201,7 @@ func TestDeleteSession(t *testing.T) {
 		}
 
 		// Ensure session was deleted
-		if _, err := c.db.SelectSessionByKey(session.Key); err != sql.ErrNoRows {
+		if _, err := c.db.SelectSessionByKey(ctx, session.Key); err != sql.ErrNoRows {
 			return fmt.Errorf("called DeleteSession, but session still exists: %v", session)
 		}
 
@@ -5,10 +5,20 @@ import (
 	"net/http"
 	"os"
 	"runtime"
+	"time"
 
 	"github.com/mdlayher/deltaiota/api/util"
 )
 
+var (
+	// Version is the version of the running server, reported by the Status
+	// API.
+	Version string
+
+	// startTime is the time at which the server started.
+	startTime = time.Now()
+)
+
 // StatusResponse is the output response for the Status API
 type StatusResponse struct {
 	Status *Status `json:"status"`
@@ -16,12 +26,41 @@ type StatusResponse struct {
 
 // Status contains information about the running server process.
 type Status struct {
-	Architecture string `json:"architecture"`
-	Hostname     string `json:"hostname"`
-	NumCPU       int    `json:"numCpu"`
-	NumGoroutine int    `json:"numGoroutine"`
-	PID          int    `json:"pid"`
-	Platform     string `json:"platform"`
+	Architecture string          `json:"architecture"`
+	Hostname     string          `json:"hostname"`
+	NumCPU       int             `json:"numCpu"`
+	NumGoroutine int             `json:"numGoroutine"`
+	PID          int             `json:"pid"`
+	Platform     string          `json:"platform"`
+	Readonly     bool            `json:"readonly"`
+	Version      string          `json:"version"`
+	StartTime    uint64          `json:"startTime"`
+	Uptime       uint64          `json:"uptime"`
+	Database     *DatabaseStatus `json:"database"`
+	Memory       *MemoryStatus   `json:"memory"`
+}
+
+// DatabaseStatus contains information about the server's database, including
+// its schema and connection pool.  WaitDuration is in milliseconds.
+type DatabaseStatus struct {
+	SchemaVersion      int   `json:"schemaVersion"`
+	PendingMigrations  int   `json:"pendingMigrations"`
+	PreparedStatements int   `json:"preparedStatements"`
+	OpenConnections    int   `json:"openConnections"`
+	InUse              int   `json:"inUse"`
+	Idle               int   `json:"idle"`
+	WaitCount          int64 `json:"waitCount"`
+	WaitDuration       int64 `json:"waitDuration"`
+}
+
+// MemoryStatus contains memory statistics of the server process, in bytes.
+type MemoryStatus struct {
+	Alloc       uint64 `json:"alloc"`
+	TotalAlloc  uint64 `json:"totalAlloc"`
+	Sys         uint64 `json:"sys"`
+	HeapInuse   uint64 `json:"heapInuse"`
+	HeapObjects uint64 `json:"heapObjects"`
+	NumGC       uint32 `json:"numGc"`
 }
 
 // StatusAPI is a util.JSONAPIFunc, and is the single entry point for the Status API.
@@ -45,6 +84,20 @@ func (c *Context) GetStatus(r *http.Request, vars util.Vars) (int, []byte, error
 		return util.JSONAPIErr(err)
 	}
 
+	// Fetch database schema and connection information
+	version, err := c.db.SchemaVersion(r.Context())
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+	pending, err := c.db.PendingMigrations(r.Context())
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+	stats := c.db.Stats()
+
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
 	// Wrap in response
 	body, err := json.Marshal(StatusResponse{
 		Status: &Status{
@@ -54,6 +107,28 @@ func (c *Context) GetStatus(r *http.Request, vars util.Vars) (int, []byte, error
 			NumGoroutine: runtime.NumGoroutine(),
 			PID:          os.Getpid(),
 			Platform:     runtime.GOOS,
+			Readonly:     c.db.Readonly(),
+			Version:      Version,
+			StartTime:    uint64(startTime.Unix()),
+			Uptime:       uint64(time.Since(startTime) / time.Second),
+			Database: &DatabaseStatus{
+				SchemaVersion:      version,
+				PendingMigrations:  len(pending),
+				PreparedStatements: c.db.PreparedStatements(),
+				OpenConnections:    stats.OpenConnections,
+				InUse:              stats.InUse,
+				Idle:               stats.Idle,
+				WaitCount:          stats.WaitCount,
+				WaitDuration:       int64(stats.WaitDuration / time.Millisecond),
+			},
+			Memory: &MemoryStatus{
+				Alloc:       mem.Alloc,
+				TotalAlloc:  mem.TotalAlloc,
+				Sys:         mem.Sys,
+				HeapInuse:   mem.HeapInuse,
+				HeapObjects: mem.HeapObjects,
+				NumGC:       mem.NumGC,
+			},
 		},
 	})
 	return http.StatusOK, body, err
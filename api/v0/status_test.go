@@ -55,8 +55,17 @@ func TestStatusAPI(t *testing.T) {
 // TestGetStatus verifies that GetStatus returns accurate server status values.
 func TestGetStatus(t *testing.T) {
 	withContext(t, func(c *Context) error {
+		Version = "test"
+		defer func() {
+			Version = ""
+		}()
+
 		// Fetch server status
-		code, body, err := c.GetStatus(nil, util.Vars{})
+		r, err := http.NewRequest("GET", "/", nil)
+		if err != nil {
+			return err
+		}
+		code, body, err := c.GetStatus(r, util.Vars{})
 		if err != nil {
 			return err
 		}
@@ -87,6 +96,39 @@ func TestGetStatus(t *testing.T) {
 		if res.Status.Platform != runtime.GOOS {
 			return fmt.Errorf("unexpected Platform: %v != %v", res.Status.Platform, runtime.GOOS)
 		}
+		if res.Status.Readonly {
+			return fmt.Errorf("unexpected Readonly status for writable database")
+		}
+		if res.Status.Version != Version {
+			return fmt.Errorf("unexpected Version: %v != %v", res.Status.Version, Version)
+		}
+		if res.Status.StartTime != uint64(startTime.Unix()) {
+			return fmt.Errorf("unexpected StartTime: %v != %v", res.Status.StartTime, startTime.Unix())
+		}
+
+		// Verify database status matches the database
+		if res.Status.Database == nil {
+			return fmt.Errorf("empty Database object in response")
+		}
+		version, err := c.db.SchemaVersion(r.Context())
+		if err != nil {
+			return err
+		}
+		if res.Status.Database.SchemaVersion != version {
+			return fmt.Errorf("unexpected SchemaVersion: %v != %v", res.Status.Database.SchemaVersion, version)
+		}
+		pending, err := c.db.PendingMigrations(r.Context())
+		if err != nil {
+			return err
+		}
+		if res.Status.Database.PendingMigrations != len(pending) {
+			return fmt.Errorf("unexpected PendingMigrations: %v != %v", res.Status.Database.PendingMigrations, len(pending))
+		}
+
+		// Verify memory statistics are present
+		if res.Status.Memory == nil || res.Status.Memory.Sys == 0 {
+			return fmt.Errorf("empty Memory object in response")
+		}
 
 		return nil
 	})
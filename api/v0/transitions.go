@@ -0,0 +1,174 @@
+package v0
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/mdlayher/deltaiota/api/auth"
+	"github.com/mdlayher/deltaiota/api/util"
+	"github.com/mdlayher/deltaiota/data"
+	"github.com/mdlayher/deltaiota/data/models"
+)
+
+// JSON Status Transitions API, human-readable client error responses.
+const (
+	transitionForbidden  = "only officers may view another user's membership history"
+	transitionJSONSyntax = "invalid JSON request"
+)
+
+// JSON Status Transitions API, map of client errors to response codes.
+var transitionsCode = map[string]int{
+	transitionForbidden:  http.StatusForbidden,
+	transitionJSONSyntax: http.StatusBadRequest,
+}
+
+// Generated JSON responses for various client-facing errors.
+var transitionsJSON = map[string][]byte{}
+
+// init initializes the stored JSON responses for client-facing errors.
+func init() {
+	// Iterate all error strings and code integers
+	for k, v := range transitionsCode {
+		// Generate error response with appropriate string and code
+		body, err := json.Marshal(util.ErrRes(v, k))
+		if err != nil {
+			panic(err)
+		}
+
+		// Store for later use
+		transitionsJSON[k] = body
+	}
+}
+
+// TransitionsResponse is the output response for the Status Transitions API.
+type TransitionsResponse struct {
+	Transitions []*models.StatusTransition `json:"transitions"`
+}
+
+// TransitionRequest is the input request for changing a user's membership status.
+type TransitionRequest struct {
+	Status models.MemberStatus `json:"status"`
+	Reason string              `json:"reason"`
+}
+
+// TransitionsAPI is a util.JSONAPIFunc, and is the single entry point for the
+// Status Transitions API.
+// This method delegates to other methods as appropriate to handle incoming requests.
+func (c *Context) TransitionsAPI(r *http.Request, vars util.Vars) (int, []byte, error) {
+	// Switch based on HTTP method
+	switch r.Method {
+	case "GET", "HEAD":
+		return c.ListTransitions(r, vars)
+	case "POST":
+		return c.PostTransition(r, vars)
+	default:
+		return util.MethodNotAllowed(r, vars)
+	}
+}
+
+// ListTransitions is a util.JSONAPIFunc which returns HTTP 200 and a JSON list
+// of a user's membership status transitions, oldest first, on success, or a
+// non-200 HTTP status code and an error response on failure.  Users may view
+// their own history, and officers may view any user's history.
+func (c *Context) ListTransitions(r *http.Request, vars util.Vars) (int, []byte, error) {
+	user, code, body, err := c.userFromVars(r.Context(), vars)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+	if body != nil {
+		return code, body, nil
+	}
+
+	requester, code, body := authUser(r)
+	if body != nil {
+		return code, body, nil
+	}
+
+	// Only officers may view another user's history
+	if requester.ID != user.ID {
+		officer, err := auth.IsOfficer(r.Context(), c.db, requester)
+		if err != nil {
+			return util.JSONAPIErr(err)
+		}
+		if !officer {
+			return transitionsCode[transitionForbidden], transitionsJSON[transitionForbidden], nil
+		}
+	}
+
+	transitions, err := c.db.SelectStatusTransitionsByUserID(r.Context(), user.ID)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+
+	body, err = json.Marshal(TransitionsResponse{
+		Transitions: transitions,
+	})
+	return http.StatusOK, body, err
+}
+
+// PostTransition is a util.JSONAPIFunc which changes a user's membership status
+// and records the change in their history, returning HTTP 201 and a JSON status
+// transition object on success, or a non-200 HTTP status code and an error
+// response on failure.  If the user's new status does not permit them to
+// authenticate, all of their sessions are revoked.
+func (c *Context) PostTransition(r *http.Request, vars util.Vars) (int, []byte, error) {
+	user, code, body, err := c.userFromVars(r.Context(), vars)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+	if body != nil {
+		return code, body, nil
+	}
+
+	officer, code, body := authUser(r)
+	if body != nil {
+		return code, body, nil
+	}
+
+	// Read the requested status and reason
+	var req TransitionRequest
+	if r.Body == nil {
+		return transitionsCode[transitionJSONSyntax], transitionsJSON[transitionJSONSyntax], nil
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return transitionsCode[transitionJSONSyntax], transitionsJSON[transitionJSONSyntax], nil
+	}
+
+	// Verify the transition is permitted from the user's current status
+	transition := &models.StatusTransition{
+		UserID:  user.ID,
+		From:    user.Status,
+		To:      req.Status,
+		Reason:  req.Reason,
+		ActorID: officer.ID,
+		Created: uint64(time.Now().Unix()),
+	}
+	if err := transition.Validate(); err != nil {
+		return validationErr(err)
+	}
+
+	// Update the user and record the transition together
+	user.Status = transition.To
+	err = c.db.WithTx(r.Context(), func(tx *data.Tx) error {
+		if err := tx.UpdateUser(r.Context(), user); err != nil {
+			return err
+		}
+
+		if user.Status.Access() == models.AccessNone {
+			if err := tx.DeleteSessionsByUserID(r.Context(), user.ID); err != nil {
+				return err
+			}
+		}
+
+		return tx.InsertStatusTransition(r.Context(), transition)
+	})
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+
+	body, err = json.Marshal(TransitionsResponse{
+		Transitions: []*models.StatusTransition{transition},
+	})
+	return http.StatusCreated, body, err
+}
@@ -0,0 +1,195 @@
+package v0
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/mdlayher/deltaiota/api/auth"
+	"github.com/mdlayher/deltaiota/api/util"
+	"github.com/mdlayher/deltaiota/data/models"
+	"github.com/mdlayher/deltaiota/ditest"
+)
+
+// TestPostTransition verifies that PostTransition only permits allowed membership
+// status transitions, records them, and revokes sessions of expelled users.
+func TestPostTransition(t *testing.T) {
+	ctx := context.Background()
+
+	withContextOfficer(t, func(c *Context, officer *models.User, position *models.Position, term *models.Term) error {
+		// Generate a member, with a session
+		member := ditest.MockUser()
+		if err := c.db.InsertUser(ctx, member); err != nil {
+			return err
+		}
+		session, err := member.NewSession(time.Now().Add(1 * time.Minute))
+		if err != nil {
+			return err
+		}
+		if err := c.db.InsertSession(ctx, session); err != nil {
+			return err
+		}
+
+		vars := util.Vars{"id": fmt.Sprintf("%d", member.ID)}
+
+		// Table of tests to iterate
+		var tests = []struct {
+			vars       util.Vars
+			body       []byte
+			code       int
+			errMessage string
+		}{
+			// User not found
+			{util.Vars{"id": "100"}, []byte(`{"status":"alumni","reason":"graduated"}`), http.StatusNotFound, userNotFound},
+			// Bad JSON
+			{vars, []byte(`{`), http.StatusBadRequest, transitionJSONSyntax},
+			// Missing reason
+			{vars, []byte(`{"status":"alumni"}`), http.StatusBadRequest, "empty field: reason"},
+			// Unknown status
+			{vars, []byte(`{"status":"foo","reason":"graduated"}`), http.StatusBadRequest, "invalid field: status (unknown membership status)"},
+			// Disallowed transition
+			{vars, []byte(`{"status":"pledge","reason":"graduated"}`), http.StatusBadRequest, "invalid field: status (membership status may not be changed from active to pledge)"},
+			// Valid transitions
+			{vars, []byte(`{"status":"alumni","reason":"graduated"}`), http.StatusCreated, ""},
+			{vars, []byte(`{"status":"expelled","reason":"conduct"}`), http.StatusCreated, ""},
+			// Expulsion is final
+			{vars, []byte(`{"status":"active","reason":"appeal"}`), http.StatusBadRequest, "invalid field: status (membership status may not be changed from expelled to active)"},
+		}
+
+		// Iterate and run tests
+		for i, test := range tests {
+			// Generate HTTP request
+			r, err := http.NewRequest("POST", "/", bytes.NewReader(test.body))
+			if err != nil {
+				return err
+			}
+
+			// Store mock-authenticated user
+			r = auth.WithUser(r, officer)
+
+			code, body, err := c.PostTransition(r, test.vars)
+			if err != nil {
+				return err
+			}
+
+			// Ensure proper HTTP status code
+			if code != test.code {
+				return fmt.Errorf("[%02d] unexpected code: %v != %v", i, code, test.code)
+			}
+
+			// If code is in HTTP 400 or above, check error response
+			if code >= http.StatusBadRequest {
+				var errRes util.ErrorResponse
+				if err := json.Unmarshal(body, &errRes); err != nil {
+					return err
+				}
+
+				if errRes.Error.Message != test.errMessage {
+					return fmt.Errorf("[%02d] unexpected error message: %v != %v", i, errRes.Error.Message, test.errMessage)
+				}
+			}
+		}
+
+		// Verify status and history were updated together
+		user, err := c.db.SelectUserByID(ctx, member.ID)
+		if err != nil {
+			return err
+		}
+		if user.Status != models.StatusExpelled {
+			return fmt.Errorf("unexpected status: %v != %v", user.Status, models.StatusExpelled)
+		}
+
+		transitions, err := c.db.SelectStatusTransitionsByUserID(ctx, member.ID)
+		if err != nil {
+			return err
+		}
+		if len(transitions) != 2 {
+			return fmt.Errorf("unexpected number of transitions: %v != %v", len(transitions), 2)
+		}
+		if tr := transitions[0]; tr.From != models.StatusActive || tr.To != models.StatusAlumni || tr.Reason != "graduated" || tr.ActorID != officer.ID {
+			return fmt.Errorf("unexpected transition: %+v", tr)
+		}
+
+		// Expelled user's sessions must be revoked
+		if _, err := c.db.SelectSessionByKey(ctx, session.Key); err == nil {
+			return fmt.Errorf("session for expelled user was not revoked")
+		}
+
+		return nil
+	})
+}
+
+// TestListTransitions verifies that ListTransitions returns a user's history
+// only to that user and to officers.
+func TestListTransitions(t *testing.T) {
+	ctx := context.Background()
+
+	withContextOfficer(t, func(c *Context, officer *models.User, position *models.Position, term *models.Term) error {
+		// Generate a member with a history, and another member
+		member := ditest.MockUser()
+		if err := c.db.InsertUser(ctx, member); err != nil {
+			return err
+		}
+		other := ditest.MockUser()
+		if err := c.db.InsertUser(ctx, other); err != nil {
+			return err
+		}
+
+		r, err := http.NewRequest("POST", "/", bytes.NewReader([]byte(`{"status":"inactive","reason":"studying abroad"}`)))
+		if err != nil {
+			return err
+		}
+		r = auth.WithUser(r, officer)
+
+		vars := util.Vars{"id": fmt.Sprintf("%d", member.ID)}
+		if code, _, err := c.PostTransition(r, vars); err != nil || code != http.StatusCreated {
+			return fmt.Errorf("unexpected transition result: %v, %v", code, err)
+		}
+
+		// Table of tests to iterate
+		var tests = []struct {
+			as   *models.User
+			code int
+		}{
+			{member, http.StatusOK},
+			{officer, http.StatusOK},
+			{other, http.StatusForbidden},
+		}
+
+		// Iterate and run tests
+		for i, test := range tests {
+			r, err := http.NewRequest("GET", "/", nil)
+			if err != nil {
+				return err
+			}
+			r = auth.WithUser(r, test.as)
+
+			code, body, err := c.ListTransitions(r, vars)
+			if err != nil {
+				return err
+			}
+
+			// Ensure proper HTTP status code
+			if code != test.code {
+				return fmt.Errorf("[%02d] unexpected code: %v != %v", i, code, test.code)
+			}
+			if code != http.StatusOK {
+				continue
+			}
+
+			var tRes TransitionsResponse
+			if err := json.Unmarshal(body, &tRes); err != nil {
+				return err
+			}
+			if len(tRes.Transitions) != 1 || tRes.Transitions[0].To != models.StatusInactive {
+				return fmt.Errorf("[%02d] unexpected transitions: %s", i, string(body))
+			}
+		}
+
+		return nil
+	})
+}
@@ -1,12 +1,16 @@
 package v0
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
 	"strconv"
+	"time"
 
+	"github.com/mdlayher/deltaiota/api/auth"
 	"github.com/mdlayher/deltaiota/api/util"
 	"github.com/mdlayher/deltaiota/data"
 	"github.com/mdlayher/deltaiota/data/models"
@@ -15,9 +19,11 @@ import (
 // JSON Users API, human-readable client error responses.
 const (
 	// HTTP GET
-	userInvalidID = "invalid user ID"
-	userMissingID = "missing user ID"
-	userNotFound  = "user not found"
+	userDeletedNotFound = "deleted user not found"
+	userInvalidID       = "invalid user ID"
+	userInvalidStatus   = "invalid membership status"
+	userMissingID       = "missing user ID"
+	userNotFound        = "user not found"
 
 	// HTTP POST
 	userConflict          = "user already exists"
@@ -29,9 +35,11 @@ const (
 // JSON Users API, map of client errors to response codes.
 var usersCode = map[string]int{
 	// HTTP GET
-	userInvalidID: http.StatusBadRequest,
-	userMissingID: http.StatusBadRequest,
-	userNotFound:  http.StatusNotFound,
+	userDeletedNotFound: http.StatusNotFound,
+	userInvalidID:       http.StatusBadRequest,
+	userInvalidStatus:   http.StatusBadRequest,
+	userMissingID:       http.StatusBadRequest,
+	userNotFound:        http.StatusNotFound,
 
 	// HTTP POST
 	userConflict:          http.StatusConflict,
@@ -89,16 +97,34 @@ func (c *Context) UsersAPI(r *http.Request, vars util.Vars) (int, []byte, error)
 
 // ListUsers is a util.JSONAPIFunc which returns HTTP 200 and a JSON list of users
 // on success, or a non-200 HTTP status code and an error response on failure.
+// Users may optionally be filtered using the "status" and "pledgeClass" query
+// parameters.
 func (c *Context) ListUsers(r *http.Request, vars util.Vars) (int, []byte, error) {
-	// Fetch a list of all users from the database
-	users, err := c.db.SelectAllUsers()
+	// Build filter from query parameters
+	query := r.URL.Query()
+	filter := data.UserFilter{
+		Status:      models.MemberStatus(query.Get("status")),
+		PledgeClass: query.Get("pledgeClass"),
+	}
+
+	// Verify status filter, if set
+	if filter.Status != "" && !filter.Status.Valid() {
+		return usersCode[userInvalidStatus], usersJSON[userInvalidStatus], nil
+	}
+
+	// Fetch a list of all matching users from the database
+	users, err := c.db.SelectUsersByFilter(r.Context(), filter)
 	if err != nil {
 		return util.JSONAPIErr(err)
 	}
 
-	// Strip all passwords from output
+	// Strip sensitive fields from output, and apply privacy settings
+	p, err := c.privacy(r)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
 	for i := range users {
-		users[i].Password = ""
+		sanitizeUser(users[i], p)
 	}
 
 	// Wrap in response and return
@@ -124,7 +150,7 @@ func (c *Context) GetUser(r *http.Request, vars util.Vars) (int, []byte, error)
 	}
 
 	// Select single user by ID from the database
-	user, err := c.db.SelectUserByID(id)
+	user, err := c.db.SelectUserByID(r.Context(), id)
 	if err != nil {
 		// If no results found, return HTTP not found
 		if err == sql.ErrNoRows {
@@ -134,8 +160,12 @@ func (c *Context) GetUser(r *http.Request, vars util.Vars) (int, []byte, error)
 		return util.JSONAPIErr(err)
 	}
 
-	// Strip password from output
-	user.Password = ""
+	// Strip sensitive fields from output, and apply privacy settings
+	p, err := c.privacy(r)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+	sanitizeUser(user, p)
 
 	// Wrap in response and return
 	body, err := json.Marshal(UsersResponse{
@@ -160,7 +190,7 @@ func (c *Context) PostUser(r *http.Request, vars util.Vars) (int, []byte, error)
 	}
 
 	// No body written, all checks passed, so insert new user
-	if err := c.db.InsertUser(user); err != nil {
+	if err := c.db.InsertUser(r.Context(), user); err != nil {
 		// Check for constraint failure, meaning user already exists
 		if c.db.IsConstraintFailure(err) {
 			return usersCode[userConflict], usersJSON[userConflict], nil
@@ -169,8 +199,12 @@ func (c *Context) PostUser(r *http.Request, vars util.Vars) (int, []byte, error)
 		return util.JSONAPIErr(err)
 	}
 
-	// Strip password from output
-	user.Password = ""
+	// Strip sensitive fields from output, and apply privacy settings
+	p, err := c.privacy(r)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+	sanitizeUser(user, p)
 
 	// Wrap in response and return
 	body, err = json.Marshal(UsersResponse{
@@ -196,7 +230,7 @@ func (c *Context) PutUser(r *http.Request, vars util.Vars) (int, []byte, error)
 	}
 
 	// Select single user by ID from the database
-	user, err := c.db.SelectUserByID(id)
+	user, err := c.db.SelectUserByID(r.Context(), id)
 	if err != nil {
 		// If no results found, return HTTP not found
 		if err == sql.ErrNoRows {
@@ -217,12 +251,34 @@ func (c *Context) PutUser(r *http.Request, vars util.Vars) (int, []byte, error)
 		return code, body, nil
 	}
 
+	// A user cannot be their own big brother
+	if newUser.BigBrotherID == user.ID {
+		code := usersCode[userInvalidParameters]
+		body, err := json.Marshal(util.ErrRes(code, (&models.InvalidFieldError{
+			Field:   "bigBrotherId",
+			Details: "user cannot be their own big brother",
+		}).Error()))
+		return code, body, err
+	}
+
+	// A user's big brother cannot be one of their descendants
+	if newUser.BigBrotherID != 0 {
+		cycle, err := c.db.IsDescendant(r.Context(), user.ID, newUser.BigBrotherID)
+		if err != nil {
+			return util.JSONAPIErr(err)
+		}
+		if cycle {
+			return familyCode[familyCycle], familyJSON[familyCycle], nil
+		}
+	}
+
 	// No body written, all checks passed, so update existing user with
 	// new fields
 	//  - Email already validated in jsonToUser
 	//  - Password already hashed in jsonToUser
+	//  - Membership status is only changed using PostTransition
 	user.CopyFrom(newUser)
-	if err := c.db.UpdateUser(user); err != nil {
+	if err := c.db.UpdateUser(r.Context(), user); err != nil {
 		// Check for constraint failure, meaning a unique check failed
 		if c.db.IsConstraintFailure(err) {
 			return usersCode[userConflict], usersJSON[userConflict], nil
@@ -231,8 +287,12 @@ func (c *Context) PutUser(r *http.Request, vars util.Vars) (int, []byte, error)
 		return util.JSONAPIErr(err)
 	}
 
-	// Strip password from output
-	user.Password = ""
+	// Strip sensitive fields from output, and apply privacy settings
+	p, err := c.privacy(r)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+	sanitizeUser(user, p)
 
 	// Wrap in response and return
 	body, err = json.Marshal(UsersResponse{
@@ -243,44 +303,161 @@ func (c *Context) PutUser(r *http.Request, vars util.Vars) (int, []byte, error)
 
 // DeleteUser is a util.JSONAPIFunc which deletes a User and returns HTTP 204
 // on success, or a non-200 HTTP status code and an error response on failure.
+// Deleted users may no longer authenticate, and are hidden from all other
+// APIs, but their data is kept so that they may be restored.  A deleted user's
+// username and email may not be reused until the user is purged.
 func (c *Context) DeleteUser(r *http.Request, vars util.Vars) (int, []byte, error) {
-	// Fetch input user ID
-	strID, ok := vars["id"]
-	if !ok {
-		return usersCode[userMissingID], usersJSON[userMissingID], nil
+	// Fetch the user
+	user, code, body, err := c.userFromVars(r.Context(), vars)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+	if body != nil {
+		return code, body, nil
 	}
 
-	// Convert string to integer
-	id, err := strconv.ParseUint(strID, 10, 64)
+	// Mark user deleted and revoke their sessions within a transaction
+	user.DeletedAt = uint64(time.Now().Unix())
+	err = c.db.WithTx(r.Context(), func(tx *data.Tx) error {
+		if err := tx.DeleteSessionsByUserID(r.Context(), user.ID); err != nil {
+			return err
+		}
+
+		return tx.UpdateUser(r.Context(), user)
+	})
 	if err != nil {
-		return usersCode[userInvalidID], usersJSON[userInvalidID], nil
+		return util.JSONAPIErr(err)
 	}
 
-	// Select single user by ID from the database
-	user, err := c.db.SelectUserByID(id)
+	return http.StatusNoContent, nil, nil
+}
+
+// ListDeletedUsers is a util.JSONAPIFunc which returns HTTP 200 and a JSON list
+// of deleted users which have not been purged, most recently deleted first, on
+// success, or a non-200 HTTP status code and an error response on failure.
+func (c *Context) ListDeletedUsers(r *http.Request, vars util.Vars) (int, []byte, error) {
+	users, err := c.db.SelectDeletedUsers(r.Context())
 	if err != nil {
-		// If no results found, return HTTP not found
-		if err == sql.ErrNoRows {
-			return usersCode[userNotFound], usersJSON[userNotFound], nil
-		}
+		return util.JSONAPIErr(err)
+	}
+
+	// Strip sensitive fields from output, and apply privacy settings
+	p, err := c.privacy(r)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+	for i := range users {
+		sanitizeUser(users[i], p)
+	}
+
+	// Wrap in response and return
+	body, err := json.Marshal(UsersResponse{
+		Users: users,
+	})
+	return http.StatusOK, body, err
+}
+
+// RestoreUser is a util.JSONAPIFunc which restores a deleted User and returns
+// HTTP 200 and a JSON user object on success, or a non-200 HTTP status code and
+// an error response on failure.  The restored user must log in again.
+func (c *Context) RestoreUser(r *http.Request, vars util.Vars) (int, []byte, error) {
+	// Fetch the deleted user
+	user, code, body, err := c.deletedUserFromVars(r.Context(), vars)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+	if body != nil {
+		return code, body, nil
+	}
 
+	user.DeletedAt = 0
+	if err := c.db.UpdateUser(r.Context(), user); err != nil {
 		return util.JSONAPIErr(err)
 	}
 
+	// Strip sensitive fields from output, and apply privacy settings
+	p, err := c.privacy(r)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+	sanitizeUser(user, p)
+
+	// Wrap in response and return
+	body, err = json.Marshal(UsersResponse{
+		Users: []*models.User{user},
+	})
+	return http.StatusOK, body, err
+}
+
+// PurgeUser is a util.JSONAPIFunc which permanently deletes a deleted User,
+// together with all of their data, and returns HTTP 204 on success, or a
+// non-200 HTTP status code and an error response on failure.  Once purged,
+// the user's username and email may be reused.
+func (c *Context) PurgeUser(r *http.Request, vars util.Vars) (int, []byte, error) {
+	// Fetch the deleted user
+	user, code, body, err := c.deletedUserFromVars(r.Context(), vars)
+	if err != nil {
+		return util.JSONAPIErr(err)
+	}
+	if body != nil {
+		return code, body, nil
+	}
+
 	// Clear user data within a transaction
-	err = c.db.WithTx(func(tx *data.Tx) error {
+	err = c.db.WithTx(r.Context(), func(tx *data.Tx) error {
 		// Delete all sessions for user
-		if err := tx.DeleteSessionsByUserID(user.ID); err != nil {
+		if err := tx.DeleteSessionsByUserID(r.Context(), user.ID); err != nil {
 			return err
 		}
 
 		// Delete all notifications for user
-		if err := tx.DeleteNotificationsByUserID(user.ID); err != nil {
+		if err := tx.DeleteNotificationsByUserID(r.Context(), user.ID); err != nil {
+			return err
+		}
+
+		// Remove user as big brother of any littles
+		if err := tx.OrphanLittles(r.Context(), user.ID); err != nil {
+			return err
+		}
+
+		// Delete all officer terms held by user
+		if err := tx.DeleteTermsByUserID(r.Context(), user.ID); err != nil {
+			return err
+		}
+
+		// Remove user from all committees
+		if err := tx.DeleteCommitteeMembersByUserID(r.Context(), user.ID); err != nil {
+			return err
+		}
+
+		// Delete dues ledger for user
+		if err := tx.DeleteChargesByUserID(r.Context(), user.ID); err != nil {
+			return err
+		}
+		if err := tx.DeletePaymentsByUserID(r.Context(), user.ID); err != nil {
+			return err
+		}
+
+		// Delete event RSVPs and calendar feed token for user
+		if err := tx.DeleteRSVPsByUserID(r.Context(), user.ID); err != nil {
+			return err
+		}
+		if err := tx.DeleteCalendarTokenByUserID(r.Context(), user.ID); err != nil {
+			return err
+		}
+
+		// Delete pending invitations for user
+		if err := tx.DeleteInvitationsByUserID(r.Context(), user.ID); err != nil {
+			return err
+		}
+
+		// Delete membership status history for user
+		if err := tx.DeleteStatusTransitionsByUserID(r.Context(), user.ID); err != nil {
 			return err
 		}
 
 		// Delete user
-		return tx.DeleteUser(user)
+		return tx.DeleteUser(r.Context(), user)
 	})
 
 	// Check for transaction errors
@@ -288,9 +465,76 @@ func (c *Context) DeleteUser(r *http.Request, vars util.Vars) (int, []byte, erro
 		return util.JSONAPIErr(err)
 	}
 
+	// Remove avatar thumbnails for user, if any
+	if user.Avatar != "" {
+		if err := c.deleteAvatarBlobs(user.ID); err != nil {
+			return util.JSONAPIErr(err)
+		}
+	}
+
 	return http.StatusNoContent, nil, nil
 }
 
+// deletedUserFromVars fetches the deleted User which is the target of a request,
+// using the "id" route variable.  On failure, it will return a message body or an
+// error, causing the caller to immediately send the result.
+func (c *Context) deletedUserFromVars(ctx context.Context, vars util.Vars) (*models.User, int, []byte, error) {
+	// Fetch input user ID
+	strID, ok := vars["id"]
+	if !ok {
+		return nil, usersCode[userMissingID], usersJSON[userMissingID], nil
+	}
+
+	// Convert string to integer
+	id, err := strconv.ParseUint(strID, 10, 64)
+	if err != nil {
+		return nil, usersCode[userInvalidID], usersJSON[userInvalidID], nil
+	}
+
+	// Select single deleted user by ID from the database
+	user, err := c.db.SelectDeletedUserByID(ctx, id)
+	if err != nil {
+		// If no results found, return HTTP not found
+		if err == sql.ErrNoRows {
+			return nil, usersCode[userDeletedNotFound], usersJSON[userDeletedNotFound], nil
+		}
+
+		return nil, http.StatusInternalServerError, nil, err
+	}
+
+	return user, http.StatusOK, nil, nil
+}
+
+// userFromVars fetches the User which is the target of a request, using the
+// "id" route variable.  On failure, it will return a message body or an error,
+// causing the caller to immediately send the result.
+func (c *Context) userFromVars(ctx context.Context, vars util.Vars) (*models.User, int, []byte, error) {
+	// Fetch input user ID
+	strID, ok := vars["id"]
+	if !ok {
+		return nil, usersCode[userMissingID], usersJSON[userMissingID], nil
+	}
+
+	// Convert string to integer
+	id, err := strconv.ParseUint(strID, 10, 64)
+	if err != nil {
+		return nil, usersCode[userInvalidID], usersJSON[userInvalidID], nil
+	}
+
+	// Select single user by ID from the database
+	user, err := c.db.SelectUserByID(ctx, id)
+	if err != nil {
+		// If no results found, return HTTP not found
+		if err == sql.ErrNoRows {
+			return nil, usersCode[userNotFound], usersJSON[userNotFound], nil
+		}
+
+		return nil, http.StatusInternalServerError, nil, err
+	}
+
+	return user, http.StatusOK, nil, nil
+}
+
 // jsonToUser reads the JSON body of an incoming HTTP request, validates that
 // all required fields are set, and returns a User on success.
 // On failure, it will return a message body or an error, causing the caller to
@@ -312,6 +556,9 @@ func (c *Context) jsonToUser(r *http.Request) (*models.User, int, []byte, error)
 		return nil, http.StatusInternalServerError, nil, err
 	}
 
+	// Users may only be deleted using DeleteUser
+	user.DeletedAt = 0
+
 	// Attempt to set password from input
 	if err := user.SetPassword(user.Password); err != nil {
 		// If empty password was passed, we are missing a parameter
@@ -353,7 +600,80 @@ func (c *Context) jsonToUser(r *http.Request) (*models.User, int, []byte, error)
 		return nil, http.StatusInternalServerError, nil, err
 	}
 
+	// If a big brother is specified, verify that he exists
+	if user.BigBrotherID != 0 {
+		if _, err := c.db.SelectUserByID(r.Context(), user.BigBrotherID); err != nil {
+			if err != sql.ErrNoRows {
+				return nil, http.StatusInternalServerError, nil, err
+			}
+
+			// Set code for invalid parameter
+			code := usersCode[userInvalidParameters]
+
+			// Return customized error object
+			body, err := json.Marshal(util.ErrRes(code, (&models.InvalidFieldError{
+				Field:   "bigBrotherId",
+				Details: "big brother not found",
+			}).Error()))
+			return nil, code, body, err
+		}
+	}
+
 	// All validations passed, return User with no body so processing
 	// can continue in caller
 	return user, http.StatusOK, nil, nil
 }
+
+// privacy determines whether the user making a request may see other users'
+// private contact details.
+type privacy struct {
+	requesterID uint64
+	officer     bool
+}
+
+// privacy returns the privacy settings for the user making a request.  Users
+// may see their own private contact details, and officers may see any user's.
+func (c *Context) privacy(r *http.Request) (privacy, error) {
+	requester, ok := auth.User(r)
+	if !ok {
+		return privacy{}, nil
+	}
+
+	officer, err := auth.IsOfficer(r.Context(), c.db, requester)
+	if err != nil {
+		return privacy{}, err
+	}
+
+	return privacy{
+		requesterID: requester.ID,
+		officer:     officer,
+	}, nil
+}
+
+// showPrivate returns whether the input user's private contact details may be
+// shown.
+func (p privacy) showPrivate(u *models.User) bool {
+	return p.officer || (p.requesterID != 0 && p.requesterID == u.ID)
+}
+
+// sanitizeUser strips sensitive fields from a User, hides contact details which
+// the User has made private unless permitted by the input privacy settings, and
+// populates fields which are computed for clients, before the User is sent in a
+// response.
+func sanitizeUser(u *models.User, p privacy) {
+	u.Password = ""
+
+	if !p.showPrivate(u) {
+		if u.PrivateEmail {
+			u.Email = ""
+		}
+		if u.PrivatePhone {
+			u.Phone = ""
+		}
+	}
+
+	// Link to avatar, if one is set
+	if u.Avatar != "" {
+		u.AvatarURL = fmt.Sprintf("%s/users/%d/avatar", APIPrefix, u.ID)
+	}
+}
@@ -2,6 +2,7 @@ package v0
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
@@ -9,6 +10,7 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/mdlayher/deltaiota/api/auth"
 	"github.com/mdlayher/deltaiota/api/util"
 	"github.com/mdlayher/deltaiota/data/models"
 	"github.com/mdlayher/deltaiota/ditest"
@@ -66,8 +68,14 @@ func TestUsersAPI(t *testing.T) {
 // users exist in the database.
 func TestListUsersNoUsers(t *testing.T) {
 	withContext(t, func(c *Context) error {
+		// Generate HTTP request
+		r, err := http.NewRequest("GET", "/", nil)
+		if err != nil {
+			return err
+		}
+
 		// Fetch list of current users
-		code, body, err := c.ListUsers(nil, util.Vars{})
+		code, body, err := c.ListUsers(r, util.Vars{})
 		if err != nil {
 			return err
 		}
@@ -95,20 +103,28 @@ func TestListUsersNoUsers(t *testing.T) {
 // TestListUserManyUsers verifies that ListUsers returns many users when many users
 // users exist in the database.
 func TestListUsersManyUsers(t *testing.T) {
+	ctx := context.Background()
+
 	withContext(t, func(c *Context) error {
 		// Generate and save a mock users in the database
 		users := make([]*models.User, 100)
 		for i := range users {
 			user := ditest.MockUser()
-			if err := c.db.InsertUser(user); err != nil {
+			if err := c.db.InsertUser(ctx, user); err != nil {
 				return err
 			}
 
 			users[i] = user
 		}
 
+		// Generate HTTP request
+		r, err := http.NewRequest("GET", "/", nil)
+		if err != nil {
+			return err
+		}
+
 		// Fetch list of current users
-		code, body, err := c.ListUsers(nil, util.Vars{})
+		code, body, err := c.ListUsers(r, util.Vars{})
 		if err != nil {
 			return err
 		}
@@ -152,6 +168,100 @@ func TestListUsersManyUsers(t *testing.T) {
 	})
 }
 
+// TestListUsersFilter verifies that ListUsers returns only users which match
+// the status and pledge class query parameters.
+func TestListUsersFilter(t *testing.T) {
+	ctx := context.Background()
+
+	withContext(t, func(c *Context) error {
+		// Generate and save mock users with varying status and pledge class
+		var mocks = []struct {
+			status      models.MemberStatus
+			pledgeClass string
+		}{
+			{models.StatusActive, "Alpha"},
+			{models.StatusActive, "Beta"},
+			{models.StatusAlumni, "Alpha"},
+			{models.StatusInactive, "Gamma"},
+		}
+		for _, m := range mocks {
+			user := ditest.MockUser()
+			user.Status = m.status
+			user.PledgeClass = m.pledgeClass
+			if err := c.db.InsertUser(ctx, user); err != nil {
+				return err
+			}
+		}
+
+		// Table of tests to iterate
+		var tests = []struct {
+			query string
+			code  int
+			count int
+		}{
+			// No filter
+			{"", http.StatusOK, 4},
+			// Status filter
+			{"status=active", http.StatusOK, 2},
+			{"status=alumni", http.StatusOK, 1},
+			// Pledge class filter
+			{"pledgeClass=Alpha", http.StatusOK, 2},
+			{"pledgeClass=Delta", http.StatusOK, 0},
+			// Both filters
+			{"status=active&pledgeClass=Alpha", http.StatusOK, 1},
+			// Invalid status
+			{"status=foo", http.StatusBadRequest, 0},
+		}
+
+		// Iterate and run tests
+		for _, test := range tests {
+			// Generate HTTP request with query parameters
+			r, err := http.NewRequest("GET", "/?"+test.query, nil)
+			if err != nil {
+				return err
+			}
+
+			// Invoke ListUsers with HTTP request
+			code, body, err := c.ListUsers(r, util.Vars{})
+			if err != nil {
+				return err
+			}
+
+			// Ensure proper HTTP status code
+			if code != test.code {
+				return fmt.Errorf("unexpected code: %v != %v", code, test.code)
+			}
+
+			// If error, verify error message
+			if code != http.StatusOK {
+				var errRes util.ErrorResponse
+				if err := json.Unmarshal(body, &errRes); err != nil {
+					return err
+				}
+
+				if errRes.Error.Message != userInvalidStatus {
+					return fmt.Errorf("unexpected error message: %v != %v", errRes.Error.Message, userInvalidStatus)
+				}
+
+				continue
+			}
+
+			// Unmarshal response body
+			var res UsersResponse
+			if err := json.Unmarshal(body, &res); err != nil {
+				return err
+			}
+
+			// Verify number of matching users
+			if len(res.Users) != test.count {
+				return fmt.Errorf("%q: unexpected number of users: %v != %v", test.query, len(res.Users), test.count)
+			}
+		}
+
+		return nil
+	})
+}
+
 // TestGetUser verifies that GetUser returns the appropriate HTTP status
 // code, body, and any errors which occur.
 func TestGetUser(t *testing.T) {
@@ -252,12 +362,83 @@ func TestGetUser(t *testing.T) {
 	})
 }
 
+// TestGetUserPrivacy verifies that GetUser hides a user's private contact
+// details, unless the requester is that user or an officer.
+func TestGetUserPrivacy(t *testing.T) {
+	ctx := context.Background()
+
+	withContextOfficer(t, func(c *Context, officer *models.User, position *models.Position, term *models.Term) error {
+		// Generate a member who keeps their contact details private, and
+		// another member who does not hold a term
+		private := ditest.MockUser()
+		private.Phone = "555-0100"
+		private.PrivateEmail = true
+		private.PrivatePhone = true
+		if err := c.db.InsertUser(ctx, private); err != nil {
+			return err
+		}
+		member := ditest.MockUser()
+		if err := c.db.InsertUser(ctx, member); err != nil {
+			return err
+		}
+
+		// Table of tests to iterate
+		var tests = []struct {
+			as   *models.User
+			show bool
+		}{
+			// Unauthenticated
+			{nil, false},
+			// Another member
+			{member, false},
+			// The user themself
+			{private, true},
+			// An officer
+			{officer, true},
+		}
+
+		for i, test := range tests {
+			r, err := http.NewRequest("GET", "/", nil)
+			if err != nil {
+				return err
+			}
+			if test.as != nil {
+				r = auth.WithUser(r, test.as)
+			}
+
+			code, body, err := c.GetUser(r, util.Vars{"id": fmt.Sprintf("%d", private.ID)})
+			if err != nil {
+				return err
+			}
+			if code != http.StatusOK {
+				return fmt.Errorf("[%02d] unexpected code: %v != %v", i, code, http.StatusOK)
+			}
+
+			var res UsersResponse
+			if err := json.Unmarshal(body, &res); err != nil {
+				return err
+			}
+			u := res.Users[0]
+
+			email, phone := "", ""
+			if test.show {
+				email, phone = private.Email, private.Phone
+			}
+			if u.Email != email || u.Phone != phone {
+				return fmt.Errorf("[%02d] unexpected contact details: %q, %q != %q, %q", i, u.Email, u.Phone, email, phone)
+			}
+		}
+
+		return nil
+	})
+}
+
 // TestPostUser verifies that PostUser returns the appropriate HTTP status
 // code, body, and any errors which occur.
 func TestPostUser(t *testing.T) {
 	withContext(t, func(c *Context) error {
 		// JSON used to generate a temporary user
-		mockUserJSON := []byte(`{"id": 1, "password":"test","firstName":"test","lastName":"test","username":"test","email":"test@test.com"}`)
+		mockUserJSON := []byte(`{"id": 1, "password":"test","firstName":"test","lastName":"test","username":"test","email":"test@test.com","status":"active","pledgeClass":"Alpha","instruments":["trumpet"],"graduationYear":2016}`)
 
 		// Unmarshal into mock user
 		user := new(models.User)
@@ -289,6 +470,12 @@ func TestPostUser(t *testing.T) {
 			{http.StatusBadRequest, "empty field: email", []byte(`{"password":"test","firstName":"test","lastName":"test","username":"test"}`)},
 			// Invalid email
 			{http.StatusBadRequest, "invalid field: email (could not parse valid email address)", []byte(`{"password":"test","firstName":"test","lastName":"test","username":"test","email":"test"}`)},
+			// Invalid status
+			{http.StatusBadRequest, "invalid field: status (unknown membership status)", []byte(`{"password":"test","firstName":"test","lastName":"test","username":"test","email":"test@test.com","status":"foo"}`)},
+			// Invalid graduation year
+			{http.StatusBadRequest, "invalid field: graduationYear (graduation year out of range)", []byte(`{"password":"test","firstName":"test","lastName":"test","username":"test","email":"test@test.com","graduationYear":1776}`)},
+			// Unknown big brother
+			{http.StatusBadRequest, "invalid field: bigBrotherId (big brother not found)", []byte(`{"password":"test","firstName":"test","lastName":"test","username":"test","email":"test@test.com","bigBrotherId":100}`)},
 			// Valid request
 			{http.StatusCreated, "", mockUserJSON},
 			// Duplicate username
@@ -363,9 +550,11 @@ func TestPostUser(t *testing.T) {
 // TestPutUser verifies that PutUser returns the appropriate HTTP status
 // code, body, and any errors which occur.
 func TestPutUser(t *testing.T) {
+	ctx := context.Background()
+
 	withContext(t, func(c *Context) error {
 		// JSON used to generate a temporary user
-		mockUserJSON := []byte(`{"id": 1, "password":"test","firstName":"test","lastName":"test","username":"test","email":"test@test.com"}`)
+		mockUserJSON := []byte(`{"id": 1, "password":"test","firstName":"test","lastName":"test","username":"test","email":"test@test.com","status":"active","pledgeClass":"Alpha","instruments":["trumpet"],"graduationYear":2016}`)
 
 		// Unmarshal into mock user
 		user := new(models.User)
@@ -374,7 +563,7 @@ func TestPutUser(t *testing.T) {
 		}
 
 		// Save user in database, to be updated later
-		if err := c.db.InsertUser(user); err != nil {
+		if err := c.db.InsertUser(ctx, user); err != nil {
 			return err
 		}
 
@@ -384,7 +573,7 @@ func TestPutUser(t *testing.T) {
 			Email:    "conflict@conflict.com",
 		}
 		conflictUser.SetPassword("conflict")
-		if err := c.db.InsertUser(conflictUser); err != nil {
+		if err := c.db.InsertUser(ctx, conflictUser); err != nil {
 			return err
 		}
 
@@ -420,6 +609,10 @@ func TestPutUser(t *testing.T) {
 			{"1", http.StatusBadRequest, "empty field: email", []byte(`{"password":"test","firstName":"test","lastName":"test","username":"test"}`)},
 			// Invalid email
 			{"1", http.StatusBadRequest, "invalid field: email (could not parse valid email address)", []byte(`{"password":"test","firstName":"test","lastName":"test","username":"test","email":"test"}`)},
+			// Invalid status
+			{"1", http.StatusBadRequest, "invalid field: status (unknown membership status)", []byte(`{"password":"test","firstName":"test","lastName":"test","username":"test","email":"test@test.com","status":"foo"}`)},
+			// Own big brother
+			{"1", http.StatusBadRequest, "invalid field: bigBrotherId (user cannot be their own big brother)", []byte(`{"password":"test","firstName":"test","lastName":"test","username":"test","email":"test@test.com","bigBrotherId":1}`)},
 			// Valid request
 			{"1", http.StatusOK, "", mockUserJSON},
 			// Duplicate username
@@ -501,6 +694,8 @@ func TestPutUser(t *testing.T) {
 // TestDeleteUser verifies that DeleteUser returns the appropriate HTTP status
 // code, body, and any errors which occur.
 func TestDeleteUser(t *testing.T) {
+	ctx := context.Background()
+
 	withContextUser(t, func(c *Context, user *models.User) error {
 		// Table of tests to iterate
 		var tests = []struct {
@@ -547,7 +742,7 @@ func TestDeleteUser(t *testing.T) {
 
 			// If code is HTTP No Content, ensure user was deleted
 			if code == http.StatusNoContent {
-				if _, err := c.db.SelectUserByID(user.ID); err != sql.ErrNoRows {
+				if _, err := c.db.SelectUserByID(ctx, user.ID); err != sql.ErrNoRows {
 					return fmt.Errorf("called DeleteUser, but user still exists: %v", user)
 				}
 
@@ -572,3 +767,105 @@ func TestDeleteUser(t *testing.T) {
 		return nil
 	})
 }
+
+// TestRestorePurgeUser verifies that a deleted user is hidden and keeps their
+// username until purged, and may be restored.
+func TestRestorePurgeUser(t *testing.T) {
+	ctx := context.Background()
+
+	withContextUser(t, func(c *Context, user *models.User) error {
+		id := util.Vars{"id": fmt.Sprintf("%d", user.ID)}
+
+		// invoke calls a util.JSONAPIFunc as the target user, and checks
+		// its HTTP status code
+		invoke := func(fn util.JSONAPIFunc, method string, vars util.Vars, expected int) ([]byte, error) {
+			r, err := http.NewRequest(method, "/", nil)
+			if err != nil {
+				return nil, err
+			}
+
+			code, body, err := fn(r, vars)
+			if err != nil {
+				return nil, err
+			}
+			if code != expected {
+				return nil, fmt.Errorf("unexpected code: %v != %v: %s", code, expected, string(body))
+			}
+
+			return body, nil
+		}
+
+		// A user which is not deleted may not be restored or purged
+		if _, err := invoke(c.RestoreUser, "POST", id, http.StatusNotFound); err != nil {
+			return err
+		}
+		if _, err := invoke(c.PurgeUser, "POST", id, http.StatusNotFound); err != nil {
+			return err
+		}
+
+		// Delete user, and verify they are hidden
+		if _, err := invoke(c.DeleteUser, "DELETE", id, http.StatusNoContent); err != nil {
+			return err
+		}
+		if _, err := invoke(c.GetUser, "GET", id, http.StatusNotFound); err != nil {
+			return err
+		}
+		if _, err := c.db.SelectUserByUsername(ctx, user.Username); err != sql.ErrNoRows {
+			return fmt.Errorf("deleted user found by username: %v", err)
+		}
+
+		body, err := invoke(c.ListDeletedUsers, "GET", util.Vars{}, http.StatusOK)
+		if err != nil {
+			return err
+		}
+		var uRes UsersResponse
+		if err := json.Unmarshal(body, &uRes); err != nil {
+			return err
+		}
+		if len(uRes.Users) != 1 || uRes.Users[0].ID != user.ID || uRes.Users[0].DeletedAt == 0 {
+			return fmt.Errorf("unexpected deleted users: %v", uRes.Users)
+		}
+
+		// Username and email remain in use
+		usernameInUse, err := c.db.UsernameInUse(ctx, user.Username)
+		if err != nil {
+			return err
+		}
+		emailInUse, err := c.db.EmailInUse(ctx, user.Email)
+		if err != nil {
+			return err
+		}
+		if !usernameInUse || !emailInUse {
+			return fmt.Errorf("deleted user's username or email freed before purge")
+		}
+
+		// Restore user, and verify they are visible once more
+		if _, err := invoke(c.RestoreUser, "POST", id, http.StatusOK); err != nil {
+			return err
+		}
+		if _, err := invoke(c.GetUser, "GET", id, http.StatusOK); err != nil {
+			return err
+		}
+
+		// Delete and purge user, freeing their username
+		if _, err := invoke(c.DeleteUser, "DELETE", id, http.StatusNoContent); err != nil {
+			return err
+		}
+		if _, err := invoke(c.PurgeUser, "POST", id, http.StatusNoContent); err != nil {
+			return err
+		}
+		if _, err := invoke(c.RestoreUser, "POST", id, http.StatusNotFound); err != nil {
+			return err
+		}
+
+		inUse, err := c.db.UsernameInUse(ctx, user.Username)
+		if err != nil {
+			return err
+		}
+		if inUse {
+			return fmt.Errorf("purged user's username still in use")
+		}
+
+		return nil
+	})
+}
@@ -5,9 +5,12 @@ package v0
 import (
 	"net/http"
 
+	"github.com/mdlayher/deltaiota/api/audit"
 	"github.com/mdlayher/deltaiota/api/auth"
 	"github.com/mdlayher/deltaiota/api/util"
+	"github.com/mdlayher/deltaiota/blob"
 	"github.com/mdlayher/deltaiota/data"
+	"github.com/mdlayher/deltaiota/data/models"
 
 	"github.com/gorilla/mux"
 )
@@ -18,14 +21,21 @@ const (
 )
 
 // NewServeMux returns a new http.Handler which contains the necessary HTTP routes
-// for the development deltaiota HTTP server.
-func NewServeMux(db *data.DB) http.Handler {
+// for the development deltaiota HTTP server.  Uploaded files, such as avatars,
+// are kept in the input blob.Store.
+func NewServeMux(db *data.DB, store blob.Store) http.Handler {
 	// Create new mux to be configured
 	r := mux.NewRouter().StrictSlash(true).PathPrefix(APIPrefix).Subrouter()
 
+	// Set up audit logging context; all changes made using the API, other
+	// than authentication, are recorded
+	au := audit.NewContext(db)
+
 	// Create a context which stores any shared members
 	c := &Context{
-		db: db,
+		db:    db,
+		store: store,
+		audit: au,
 	}
 
 	// Set up authentication context
@@ -33,24 +43,130 @@ func NewServeMux(db *data.DB) http.Handler {
 
 	// Set up HTTP routes
 
+	// Admin API, which may only be used by officers
+	r.Handle("/admin/backup", ac.OfficerAuthHandler(util.JSONAPIHandler(c.BackupAPI)))
+
+	// Audit API, which may only be viewed by officers
+	r.Handle("/audit", ac.OfficerAuthHandler(util.JSONAPIHandler(c.AuditAPI)))
+
+	// Calendar API; the feed is authenticated by a secret token, so that
+	// calendar applications may subscribe to it
+	r.Handle("/calendar.ics", ac.FeedTokenAuthHandler(c.GetCalendar)).Methods("GET", "HEAD")
+	r.Handle("/calendar/token", ac.KeyAuthHandler(util.JSONAPIHandler(au.Handler("calendarToken", c.auditCalendarToken, c.CalendarTokenAPI))))
+
+	// Committees API, which may only be created and modified by officers;
+	// membership is managed by officers and committee chairs
+	r.Handle("/committees", ac.KeyAuthHandler(util.JSONAPIHandler(c.CommitteesAPI))).Methods("GET", "HEAD")
+	r.Handle("/committees", ac.OfficerAuthHandler(util.JSONAPIHandler(au.Handler("committee", auditTarget("id", c.db.SelectCommitteeByID), c.CommitteesAPI)))).Methods("POST", "PUT", "PATCH", "DELETE")
+	r.Handle("/committees/{id}", ac.KeyAuthHandler(util.JSONAPIHandler(c.CommitteesAPI))).Methods("GET", "HEAD")
+	r.Handle("/committees/{id}", ac.OfficerAuthHandler(util.JSONAPIHandler(au.Handler("committee", auditTarget("id", c.db.SelectCommitteeByID), c.CommitteesAPI)))).Methods("POST", "PUT", "PATCH", "DELETE")
+	r.Handle("/committees/{id}/members", ac.KeyAuthHandler(util.JSONAPIHandler(au.Handler("committeeMember", c.auditCommitteeMember, c.CommitteeMembersAPI))))
+	r.Handle("/committees/{id}/members/{userId}", ac.KeyAuthHandler(util.JSONAPIHandler(au.Handler("committeeMember", c.auditCommitteeMember, c.CommitteeMembersAPI))))
+	r.Handle("/committees/{id}/notifications", ac.KeyAuthHandler(util.JSONAPIHandler(au.Handler("notification", nil, c.CommitteeNotificationsAPI))))
+
+	// Dues API, which may only be modified by officers; users may view
+	// their own ledger
+	r.Handle("/dues/overdue", ac.OfficerAuthHandler(util.JSONAPIHandler(c.OverdueAPI)))
+	r.Handle("/users/{id}/balance", ac.KeyAuthHandler(util.JSONAPIHandler(c.BalanceAPI)))
+	r.Handle("/users/{id}/charges", ac.KeyAuthHandler(util.JSONAPIHandler(c.ChargesAPI))).Methods("GET", "HEAD")
+	r.Handle("/users/{id}/charges", ac.OfficerAuthHandler(util.JSONAPIHandler(au.Handler("charge", auditTarget("chargeId", c.db.SelectChargeByID), c.ChargesAPI)))).Methods("POST", "PUT", "PATCH", "DELETE")
+	r.Handle("/users/{id}/charges/{chargeId}", ac.KeyAuthHandler(util.JSONAPIHandler(c.ChargesAPI))).Methods("GET", "HEAD")
+	r.Handle("/users/{id}/charges/{chargeId}", ac.OfficerAuthHandler(util.JSONAPIHandler(au.Handler("charge", auditTarget("chargeId", c.db.SelectChargeByID), c.ChargesAPI)))).Methods("POST", "PUT", "PATCH", "DELETE")
+	r.Handle("/users/{id}/payments", ac.KeyAuthHandler(util.JSONAPIHandler(c.PaymentsAPI))).Methods("GET", "HEAD")
+	r.Handle("/users/{id}/payments", ac.OfficerAuthHandler(util.JSONAPIHandler(au.Handler("payment", auditTarget("paymentId", c.db.SelectPaymentByID), c.PaymentsAPI)))).Methods("POST", "PUT", "PATCH", "DELETE")
+	r.Handle("/users/{id}/payments/{paymentId}", ac.KeyAuthHandler(util.JSONAPIHandler(c.PaymentsAPI))).Methods("GET", "HEAD")
+	r.Handle("/users/{id}/payments/{paymentId}", ac.OfficerAuthHandler(util.JSONAPIHandler(au.Handler("payment", auditTarget("paymentId", c.db.SelectPaymentByID), c.PaymentsAPI)))).Methods("POST", "PUT", "PATCH", "DELETE")
+
+	// Events API, which may only be modified by officers; users manage
+	// their own RSVPs
+	r.Handle("/events", ac.KeyAuthHandler(util.JSONAPIHandler(c.EventsAPI))).Methods("GET", "HEAD")
+	r.Handle("/events", ac.OfficerAuthHandler(util.JSONAPIHandler(au.Handler("event", auditTarget("id", c.db.SelectEventByID), c.EventsAPI)))).Methods("POST", "PUT", "PATCH", "DELETE")
+	r.Handle("/events/{id}", ac.KeyAuthHandler(util.JSONAPIHandler(c.EventsAPI))).Methods("GET", "HEAD")
+	r.Handle("/events/{id}", ac.OfficerAuthHandler(util.JSONAPIHandler(au.Handler("event", auditTarget("id", c.db.SelectEventByID), c.EventsAPI)))).Methods("POST", "PUT", "PATCH", "DELETE")
+	r.Handle("/events/{id}/rsvps", ac.KeyAuthHandler(util.JSONAPIHandler(c.RSVPsAPI)))
+	r.Handle("/events/{id}/rsvp", ac.KeyAuthHandler(util.JSONAPIHandler(au.Handler("rsvp", c.auditRSVP, c.RSVPAPI))))
+
+	// Invitations API, which may only be managed by officers; invitations are
+	// accepted using the invitation's secret token, with or without an
+	// existing session, such as an officer's, to which the change is attributed
+	r.Handle("/invitations", ac.OfficerAuthHandler(util.JSONAPIHandler(au.Handler("invitation", c.auditInvitation, c.InvitationsAPI))))
+	r.Handle("/invitations/{id}", ac.OfficerAuthHandler(util.JSONAPIHandler(au.Handler("invitation", c.auditInvitation, c.InvitationsAPI))))
+	r.Handle("/invitations/{token}/accept", ac.OptionalAuthHandler(util.JSONAPIHandler(au.Handler("invitation", c.auditInvitation, c.AcceptInvitationAPI))))
+
 	// Notifications API
 	r.Handle("/notifications", ac.KeyAuthHandler(util.JSONAPIHandler(c.NotificationsAPI)))
 
+	// Officers API
+	r.Handle("/officers", ac.KeyAuthHandler(util.JSONAPIHandler(c.OfficersAPI)))
+
+	// Positions API, which may only be modified by officers
+	r.Handle("/positions", ac.KeyAuthHandler(util.JSONAPIHandler(c.PositionsAPI))).Methods("GET", "HEAD")
+	r.Handle("/positions", ac.OfficerAuthHandler(util.JSONAPIHandler(au.Handler("position", auditTarget("id", c.db.SelectPositionByID), c.PositionsAPI)))).Methods("POST", "PUT", "PATCH", "DELETE")
+	r.Handle("/positions/{id}", ac.KeyAuthHandler(util.JSONAPIHandler(c.PositionsAPI))).Methods("GET", "HEAD")
+	r.Handle("/positions/{id}", ac.OfficerAuthHandler(util.JSONAPIHandler(au.Handler("position", auditTarget("id", c.db.SelectPositionByID), c.PositionsAPI)))).Methods("POST", "PUT", "PATCH", "DELETE")
+	r.Handle("/positions/{id}/terms", ac.KeyAuthHandler(util.JSONAPIHandler(c.TermsAPI))).Methods("GET", "HEAD")
+	r.Handle("/positions/{id}/terms", ac.OfficerAuthHandler(util.JSONAPIHandler(au.Handler("term", auditTarget("termId", c.db.SelectTermByID), c.TermsAPI)))).Methods("POST", "PUT", "PATCH", "DELETE")
+	r.Handle("/positions/{id}/terms/{termId}", ac.KeyAuthHandler(util.JSONAPIHandler(c.TermsAPI))).Methods("GET", "HEAD")
+	r.Handle("/positions/{id}/terms/{termId}", ac.OfficerAuthHandler(util.JSONAPIHandler(au.Handler("term", auditTarget("termId", c.db.SelectTermByID), c.TermsAPI)))).Methods("POST", "PUT", "PATCH", "DELETE")
+
 	// Sessions API
 	r.Handle("/sessions", ac.PasswordAuthHandler(util.JSONAPIHandler(c.PostSession))).Methods("POST")
-	r.Handle("/sessions", ac.KeyAuthHandler(util.JSONAPIHandler(c.SessionsAPI))).Methods("GET", "HEAD", "PUT", "PATCH", "DELETE")
+	r.Handle("/sessions", ac.SessionAuthHandler(util.JSONAPIHandler(c.SessionsAPI))).Methods("GET", "HEAD", "PUT", "PATCH", "DELETE")
 
 	// Status API
 	r.Handle("/status", ac.KeyAuthHandler(util.JSONAPIHandler(c.StatusAPI)))
 
+	// Directory and roster export, and roster import, which must be routed
+	// before the Users API so that their paths are not mistaken for user IDs.
+	// Roster import hashes many passwords, so it records its own changes, and
+	// bounds its own queries
+	r.Handle("/users/export.csv", ac.OfficerAuthHandler(c.ExportUsersCSV)).Methods("GET", "HEAD")
+	r.Handle("/users/import", ac.OfficerAuthHandler(util.SlowJSONAPIHandler(c.UsersImportAPI)))
+	r.Handle("/users.vcf", ac.KeyAuthHandler(c.ListUsersVCard)).Methods("GET", "HEAD")
+	r.Handle("/users/{id}.vcf", ac.KeyAuthHandler(c.GetUserVCard)).Methods("GET", "HEAD")
+
+	// Deleted users, which may only be viewed, restored, or purged by officers
+	r.Handle("/users/deleted", ac.OfficerAuthHandler(util.JSONAPIHandler(c.ListDeletedUsers))).Methods("GET", "HEAD")
+	r.Handle("/users/{id}/restore", ac.OfficerAuthHandler(util.JSONAPIHandler(au.ActionHandler("user", models.AuditRestore, c.auditUser, c.RestoreUser)))).Methods("POST")
+	r.Handle("/users/{id}/purge", ac.OfficerAuthHandler(util.JSONAPIHandler(au.ActionHandler("user", models.AuditPurge, c.auditUser, c.PurgeUser)))).Methods("POST")
+
 	// Users API
-	r.Handle("/users", ac.KeyAuthHandler(util.JSONAPIHandler(c.UsersAPI)))
-	r.Handle("/users/{id}", ac.KeyAuthHandler(util.JSONAPIHandler(c.UsersAPI)))
+	r.Handle("/users", ac.KeyAuthHandler(util.JSONAPIHandler(au.Handler("user", c.auditUser, c.UsersAPI))))
+	r.Handle("/users/{id}", ac.KeyAuthHandler(util.JSONAPIHandler(au.Handler("user", c.auditUser, c.UsersAPI))))
+
+	// Membership status API; a user's status may only be changed by officers,
+	// and every change is recorded in the user's history
+	r.Handle("/users/{id}/status", ac.KeyAuthHandler(util.JSONAPIHandler(c.TransitionsAPI))).Methods("GET", "HEAD")
+	r.Handle("/users/{id}/status", ac.OfficerAuthHandler(util.JSONAPIHandler(au.Handler("statusTransition", nil, c.TransitionsAPI)))).Methods("POST")
+
+	// Family API
+	r.Handle("/users/{id}/big", ac.KeyAuthHandler(util.JSONAPIHandler(au.Handler("bigBrother", c.auditUser, c.BigBrotherAPI))))
+	r.Handle("/users/{id}/littles", ac.KeyAuthHandler(util.JSONAPIHandler(c.LittlesAPI)))
+	r.Handle("/users/{id}/family", ac.KeyAuthHandler(util.JSONAPIHandler(c.FamilyAPI)))
+	r.Handle("/users/{id}/family.dot", ac.KeyAuthHandler(c.GetFamilyDOT)).Methods("GET", "HEAD")
+
+	// Avatars API
+	r.Handle("/users/{id}/avatar", ac.KeyAuthHandler(c.GetAvatar)).Methods("GET", "HEAD")
+	r.Handle("/users/{id}/avatar", ac.KeyAuthHandler(util.JSONAPIHandler(au.Handler("avatar", c.auditUser, c.AvatarsAPI)))).Methods("PUT", "PATCH", "POST", "DELETE")
 
 	return r
 }
 
 // Context stores shared members for API v0 HTTP handlers.
 type Context struct {
-	db *data.DB
+	db    *data.DB
+	store blob.Store
+	audit *audit.Context
+}
+
+// authUser returns the authenticated user for the input request.  If the request
+// is not authenticated, it returns a message body, causing the caller to
+// immediately send the result.
+func authUser(r *http.Request) (*models.User, int, []byte) {
+	user, ok := auth.User(r)
+	if !ok {
+		return nil, util.Code[util.NotAuthorized], util.JSON[util.NotAuthorized]
+	}
+
+	return user, http.StatusOK, nil
 }
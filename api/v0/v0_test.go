@@ -1,12 +1,16 @@
 package v0
 
 import (
+	"context"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"testing"
 	"time"
 
+	"github.com/mdlayher/deltaiota/api/audit"
+	"github.com/mdlayher/deltaiota/blob"
 	"github.com/mdlayher/deltaiota/data"
 	"github.com/mdlayher/deltaiota/data/models"
 	"github.com/mdlayher/deltaiota/ditest"
@@ -127,14 +131,28 @@ func TestNewServeMuxDELETEUsersWithIDNoContent(t *testing.T) {
 // testNewServeMux is a helper which verifies that an HTTP request with the
 // given path returns the expected HTTP status code.
 func testNewServeMux(t *testing.T, method string, path string, code int) {
+	ctx := context.Background()
+
 	ditest.WithTemporaryDBNew(t, func(t *testing.T, db *data.DB) {
+		// Set up temporary storage for uploads
+		dir, err := ioutil.TempDir("", "deltaiota")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+
+		store, err := blob.NewFileStore(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+
 		// Set up HTTP test server
-		srv := httptest.NewServer(NewServeMux(db))
+		srv := httptest.NewServer(NewServeMux(db, store))
 		defer srv.Close()
 
 		// Set up temporary user for authentication
 		user := ditest.MockUser()
-		if err := db.InsertUser(user); err != nil {
+		if err := db.InsertUser(ctx, user); err != nil {
 			t.Fatal(err)
 		}
 
@@ -148,7 +166,7 @@ func testNewServeMux(t *testing.T, method string, path string, code int) {
 			if err := user.SetPassword(user.Password); err != nil {
 				t.Fatal(err)
 			}
-			if err := db.UpdateUser(user); err != nil {
+			if err := db.UpdateUser(ctx, user); err != nil {
 				t.Fatal(err)
 			}
 		}
@@ -158,7 +176,7 @@ func testNewServeMux(t *testing.T, method string, path string, code int) {
 		if err != nil {
 			t.Fatal(err)
 		}
-		if err := db.InsertSession(session); err != nil {
+		if err := db.InsertSession(ctx, session); err != nil {
 			t.Fatal(err)
 		}
 
@@ -205,15 +223,19 @@ func testNewServeMux(t *testing.T, method string, path string, code int) {
 // withContext sets up a test context with an API context wrapping a
 // temporary database.
 func withContext(t *testing.T, fn func(c *Context) error) {
-	// Invoke tests with temporary database
+	// Invoke tests with temporary database and storage
 	err := ditest.WithTemporaryDB(func(db *data.DB) error {
-		// Build context
-		c := &Context{
-			db: db,
-		}
+		return ditest.WithTemporaryFileStore(func(store *blob.FileStore) error {
+			// Build context
+			c := &Context{
+				db:    db,
+				store: store,
+				audit: audit.NewContext(db),
+			}
 
-		// Invoke test
-		return fn(c)
+			// Invoke test
+			return fn(c)
+		})
 	})
 
 	// Check for errors from database setup/cleanup
@@ -224,10 +246,12 @@ func withContext(t *testing.T, fn func(c *Context) error) {
 
 // withContextUser builds upon withContext, adding a mock user.
 func withContextUser(t *testing.T, fn func(c *Context, user *models.User) error) {
+	ctx := context.Background()
+
 	withContext(t, func(c *Context) error {
 		// Generate mock user
 		user := ditest.MockUser()
-		if err := c.db.InsertUser(user); err != nil {
+		if err := c.db.InsertUser(ctx, user); err != nil {
 			return err
 		}
 
@@ -0,0 +1,176 @@
+package v0
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/mdlayher/deltaiota/api/util"
+	"github.com/mdlayher/deltaiota/data"
+	"github.com/mdlayher/deltaiota/data/models"
+	"github.com/mdlayher/deltaiota/vcard"
+
+	"github.com/gorilla/mux"
+)
+
+var (
+	// vcardOrg is the organization listed on each member's vCard.
+	vcardOrg = []string{"Phi Mu Alpha Sinfonia", "Delta Iota"}
+
+	// vcardNamespace is the namespace UUID used to generate name-based UUIDs
+	// for members' vCards.  It is the RFC 4122 URL namespace.
+	vcardNamespace = []byte{
+		0x6b, 0xa7, 0xb8, 0x11, 0x9d, 0xad, 0x11, 0xd1,
+		0x80, 0xb4, 0x00, 0xc0, 0x4f, 0xd4, 0x30, 0xc8,
+	}
+)
+
+// GetUserVCard is a http.HandlerFunc which writes HTTP 200 and a user's contact
+// details as a vCard on success, or a non-200 HTTP status code and a JSON error
+// response on failure.  Private contact details are omitted, unless the user
+// requests their own vCard or is an officer.
+func (c *Context) GetUserVCard(w http.ResponseWriter, r *http.Request) {
+	user, code, body, err := c.userFromVars(r.Context(), util.Vars(mux.Vars(r)))
+	if err != nil {
+		util.Logger(r).Error("request error", "err", err)
+		writeJSONErr(w, r, util.Code[util.InternalServerError], util.JSON[util.InternalServerError])
+		return
+	}
+	if body != nil {
+		writeJSONErr(w, r, code, body)
+		return
+	}
+
+	c.writeVCards(w, r, fmt.Sprintf("%s.vcf", user.Username), []*models.User{user})
+}
+
+// ListUsersVCard is a http.HandlerFunc which writes HTTP 200 and the contact
+// details of all users as a series of vCards on success, or a non-200 HTTP status
+// code and a JSON error response on failure.  Users may optionally be filtered
+// using the "status" and "pledgeClass" query parameters.  Private contact details
+// are omitted, except for the requesting user's own vCard, or if the requesting
+// user is an officer.
+func (c *Context) ListUsersVCard(w http.ResponseWriter, r *http.Request) {
+	// Build filter from query parameters
+	query := r.URL.Query()
+	filter := data.UserFilter{
+		Status:      models.MemberStatus(query.Get("status")),
+		PledgeClass: query.Get("pledgeClass"),
+	}
+
+	// Verify status filter, if set
+	if filter.Status != "" && !filter.Status.Valid() {
+		writeJSONErr(w, r, usersCode[userInvalidStatus], usersJSON[userInvalidStatus])
+		return
+	}
+
+	users, err := c.db.SelectUsersByFilter(r.Context(), filter)
+	if err != nil {
+		util.Logger(r).Error("request error", "err", err)
+		writeJSONErr(w, r, util.Code[util.InternalServerError], util.JSON[util.InternalServerError])
+		return
+	}
+
+	c.writeVCards(w, r, "users.vcf", users)
+}
+
+// writeVCards encodes the input users as vCards, and writes them to the client
+// as a file with the input name.
+func (c *Context) writeVCards(w http.ResponseWriter, r *http.Request, filename string, users []*models.User) {
+	// Users may always see their own contact details, and officers may see
+	// any user's
+	p, err := c.privacy(r)
+	if err != nil {
+		util.Logger(r).Error("request error", "err", err)
+		writeJSONErr(w, r, util.Code[util.InternalServerError], util.JSON[util.InternalServerError])
+		return
+	}
+
+	buf := bytes.NewBuffer(nil)
+	enc := vcard.NewEncoder(buf)
+	for _, u := range users {
+		if err := enc.Encode(userVCard(u, p.showPrivate(u))); err != nil {
+			util.Logger(r).Error("request error", "err", err)
+			writeJSONErr(w, r, util.Code[util.InternalServerError], util.JSON[util.InternalServerError])
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", vcard.ContentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+	w.WriteHeader(http.StatusOK)
+	if r.Method != "HEAD" {
+		w.Write(buf.Bytes())
+	}
+}
+
+// userVCard converts a User into a vCard.  Unless showPrivate is set, the User's
+// privacy settings are applied to their contact details.
+func userVCard(u *models.User, showPrivate bool) *vcard.Card {
+	card := &vcard.Card{
+		UID:           vcardUID(u.ID),
+		FormattedName: vcardName(u),
+		FamilyName:    u.LastName,
+		GivenName:     u.FirstName,
+		Email:         u.Email,
+		Phone:         u.Phone,
+		Address:       u.Address,
+		Org:           vcardOrg,
+		Note:          u.Bio,
+	}
+
+	if !showPrivate && u.PrivateEmail {
+		card.Email = ""
+	}
+	if !showPrivate && u.PrivatePhone {
+		card.Phone = ""
+	}
+
+	if u.Status != "" {
+		card.Categories = append(card.Categories, string(u.Status))
+	}
+	if u.PledgeClass != "" {
+		card.Categories = append(card.Categories, u.PledgeClass)
+	}
+
+	return card
+}
+
+// vcardUID generates a stable, name-based UUID URI for the user with the input
+// ID, so that contact applications can recognize updated vCards.
+func vcardUID(id uint64) string {
+	h := sha1.New()
+	h.Write(vcardNamespace)
+	fmt.Fprintf(h, "deltaiota:user:%d", id)
+	u := h.Sum(nil)[:16]
+
+	// Set version 5 and RFC 4122 variant bits
+	u[6] = (u[6] & 0x0f) | 0x50
+	u[8] = (u[8] & 0x3f) | 0x80
+
+	return fmt.Sprintf("urn:uuid:%x-%x-%x-%x-%x", u[0:4], u[4:6], u[6:8], u[8:10], u[10:16])
+}
+
+// writeJSONErr writes a JSON error response, including the request's ID, to the
+// client, omitting the body for HEAD requests.
+func writeJSONErr(w http.ResponseWriter, r *http.Request, code int, body []byte) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if r.Method != "HEAD" {
+		w.Write(util.AddRequestID(r, body))
+	}
+}
+
+// vcardName returns the formatted name of a User for a vCard, which falls back
+// to the User's username when no name is set.
+func vcardName(u *models.User) string {
+	if name := strings.TrimSpace(u.FirstName + " " + u.LastName); name != "" {
+		return name
+	}
+
+	return u.Username
+}
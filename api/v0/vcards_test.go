@@ -0,0 +1,152 @@
+package v0
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mdlayher/deltaiota/api/auth"
+	"github.com/mdlayher/deltaiota/data/models"
+	"github.com/mdlayher/deltaiota/ditest"
+
+	"github.com/gorilla/mux"
+)
+
+// TestUsersVCard verifies that GetUserVCard and ListUsersVCard write vCards for
+// users, filtered by status, and respect users' privacy settings.
+func TestUsersVCard(t *testing.T) {
+	ctx := context.Background()
+
+	withContextUser(t, func(c *Context, user *models.User) error {
+		// Generate an alumnus who keeps their contact details private
+		alum := ditest.MockUser()
+		alum.Email = "alum@example.com"
+		alum.Phone = "555-0100"
+		alum.Status = models.StatusAlumni
+		alum.PrivateEmail = true
+		alum.PrivatePhone = true
+		if err := c.db.InsertUser(ctx, alum); err != nil {
+			return err
+		}
+
+		// Authenticate requests as the input user
+		as := func(u *models.User, h http.HandlerFunc) http.HandlerFunc {
+			return func(w http.ResponseWriter, r *http.Request) {
+				r = auth.WithUser(r, u)
+				h(w, r)
+			}
+		}
+
+		// Table of tests to iterate
+		var tests = []struct {
+			as       *models.User
+			path     string
+			code     int
+			contains []string
+			excludes []string
+		}{
+			// Invalid user ID
+			{user, "/users/foo.vcf", http.StatusBadRequest, []string{userInvalidID}, nil},
+			// User not found
+			{user, "/users/100.vcf", http.StatusNotFound, []string{userNotFound}, nil},
+			// Invalid status filter
+			{user, "/users.vcf?status=foo", http.StatusBadRequest, []string{userInvalidStatus}, nil},
+			// Private details hidden from other users
+			{user, fmt.Sprintf("/users/%d.vcf", alum.ID), http.StatusOK, []string{
+				"BEGIN:VCARD\r\n",
+				"VERSION:4.0\r\n",
+				fmt.Sprintf("FN:%s %s\r\n", alum.FirstName, alum.LastName),
+				"CATEGORIES:alumni\r\n",
+			}, []string{alum.Email, alum.Phone}},
+			// Private details shown to the user themself
+			{alum, fmt.Sprintf("/users/%d.vcf", alum.ID), http.StatusOK, []string{
+				"EMAIL:alum@example.com\r\n",
+				"TEL;VALUE=text:555-0100\r\n",
+			}, nil},
+			// Directory filtered by status
+			{user, "/users.vcf?status=alumni", http.StatusOK, []string{alum.LastName}, []string{user.LastName}},
+		}
+
+		for _, test := range tests {
+			// Route requests so that path variables are set
+			m := mux.NewRouter()
+			m.HandleFunc("/users.vcf", as(test.as, c.ListUsersVCard))
+			m.HandleFunc("/users/{id}.vcf", as(test.as, c.GetUserVCard))
+
+			r, err := http.NewRequest("GET", test.path, nil)
+			if err != nil {
+				return err
+			}
+
+			w := httptest.NewRecorder()
+			m.ServeHTTP(w, r)
+
+			// Ensure proper HTTP status code
+			if w.Code != test.code {
+				return fmt.Errorf("%s: unexpected code: %v != %v", test.path, w.Code, test.code)
+			}
+
+			body := w.Body.String()
+			for _, s := range test.contains {
+				if !strings.Contains(body, s) {
+					return fmt.Errorf("%s: body missing %q:\n%s", test.path, s, body)
+				}
+			}
+			for _, s := range test.excludes {
+				if strings.Contains(body, s) {
+					return fmt.Errorf("%s: body unexpectedly contains %q:\n%s", test.path, s, body)
+				}
+			}
+		}
+
+		return nil
+	})
+}
+
+// Test_vcardUID verifies that vcardUID generates stable, distinct version 5 UUIDs.
+func Test_vcardUID(t *testing.T) {
+	a, b := vcardUID(1), vcardUID(2)
+	if a != vcardUID(1) {
+		t.Fatalf("unstable UID: %v != %v", a, vcardUID(1))
+	}
+	if a == b {
+		t.Fatalf("duplicate UID: %v", a)
+	}
+
+	// urn:uuid:xxxxxxxx-xxxx-5xxx-[89ab]xxx-xxxxxxxxxxxx
+	uuid := strings.TrimPrefix(a, "urn:uuid:")
+	if len(uuid) != 36 || uuid[14] != '5' || !strings.ContainsRune("89ab", rune(uuid[19])) {
+		t.Fatalf("invalid UUID: %v", uuid)
+	}
+}
+
+// Test_vcardName verifies that vcardName trims a user's name, and falls back
+// to the user's username when no name is set.
+func Test_vcardName(t *testing.T) {
+	var tests = []struct {
+		first string
+		last  string
+		name  string
+	}{
+		{"John", "Doe", "John Doe"},
+		{"John", "", "John"},
+		{"", "Doe", "Doe"},
+		{"", "", "jdoe"},
+		{" ", " ", "jdoe"},
+	}
+
+	for i, test := range tests {
+		u := &models.User{
+			Username:  "jdoe",
+			FirstName: test.first,
+			LastName:  test.last,
+		}
+
+		if name := vcardName(u); name != test.name {
+			t.Fatalf("[%02d] unexpected name: %q != %q", i, name, test.name)
+		}
+	}
+}
@@ -27,43 +27,370 @@ func bindata_read(data []byte, name string) ([]byte, error) {
 
 func res_sqlite_deltaiota_sql() ([]byte, error) {
 	return bindata_read([]byte{
-		0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x00, 0xff, 0x94, 0x92,
+		0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xff, 0x94, 0x92,
 		0xcf, 0xce, 0xa2, 0x30, 0x14, 0xc5, 0xd7, 0x1f, 0x4f, 0x71, 0xd3, 0x95,
-		0x9a, 0x49, 0x7c, 0x00, 0x57, 0xe8, 0x5c, 0x4d, 0x33, 0x52, 0x66, 0x6a,
-		0x49, 0x74, 0x45, 0x1a, 0xad, 0xb1, 0x19, 0xfe, 0x28, 0x2d, 0x19, 0xe7,
-		0xed, 0x3f, 0x40, 0x11, 0x89, 0xd5, 0x28, 0xcb, 0x7b, 0xee, 0x3d, 0x9c,
-		0xf3, 0x4b, 0xc7, 0x23, 0xd8, 0xa9, 0xc4, 0x4a, 0x9d, 0x5b, 0x09, 0xe6,
-		0x94, 0x68, 0xab, 0xc0, 0x6c, 0x0f, 0x2a, 0x95, 0x30, 0x1a, 0x7b, 0x53,
-		0x5c, 0x50, 0x06, 0x82, 0xfb, 0x6c, 0xe5, 0xcf, 0x04, 0x0d, 0xd9, 0xc4,
-		0xab, 0x0e, 0xb2, 0xdc, 0xea, 0xbd, 0xde, 0x4a, 0xab, 0xf3, 0xcc, 0xd4,
-		0x6b, 0x33, 0x8e, 0xbe, 0x40, 0x10, 0xfe, 0x74, 0x89, 0x40, 0x7a, 0x32,
-		0x81, 0x81, 0xf7, 0x45, 0xf4, 0x8e, 0xc0, 0xed, 0xa3, 0x4c, 0xe0, 0x02,
-		0x39, 0xfc, 0xe6, 0x34, 0xf0, 0xf9, 0x06, 0x7e, 0xe1, 0x06, 0xfc, 0x48,
-		0x84, 0x94, 0x55, 0x3e, 0x01, 0x32, 0xe1, 0x7d, 0xfd, 0x00, 0x52, 0x1a,
-		0x55, 0xc4, 0x97, 0xbb, 0xf6, 0x80, 0x85, 0x02, 0x58, 0xb4, 0x5c, 0x36,
-		0xba, 0xd5, 0xa9, 0x32, 0x56, 0xa6, 0x47, 0xe2, 0xd6, 0x0b, 0x25, 0xdb,
-		0x9f, 0xba, 0xef, 0xd5, 0xd9, 0x76, 0xa1, 0x04, 0xae, 0x45, 0x5f, 0x2f,
-		0x0b, 0x7d, 0x97, 0xb9, 0xaf, 0xd7, 0x0b, 0xf3, 0x90, 0x23, 0x5d, 0xb0,
-		0x3a, 0xfd, 0xe0, 0x9a, 0x75, 0x08, 0x1c, 0xe7, 0xc8, 0x91, 0xcd, 0x70,
-		0x05, 0xf5, 0xcc, 0x0c, 0xaa, 0xa1, 0x37, 0x6c, 0xa0, 0x19, 0x65, 0x8c,
-		0x9b, 0x57, 0xab, 0x3c, 0xa0, 0xfa, 0x10, 0x94, 0xb3, 0xe6, 0x5f, 0xf5,
-		0x9f, 0x3c, 0x2f, 0xa9, 0xce, 0x47, 0x5d, 0x28, 0xe2, 0x40, 0xf4, 0x71,
-		0xc5, 0x6b, 0xa7, 0x88, 0xd1, 0x3f, 0x11, 0x56, 0x7e, 0x3f, 0x71, 0xdd,
-		0x55, 0x8b, 0xcb, 0x4c, 0x9f, 0x4a, 0x15, 0x37, 0x69, 0x42, 0xd6, 0xeb,
-		0xdc, 0x44, 0xbc, 0x30, 0x6a, 0x0c, 0x1f, 0x01, 0x35, 0x63, 0xc7, 0x43,
-		0xfa, 0x00, 0x50, 0x26, 0x53, 0x45, 0x9e, 0x51, 0xd8, 0xeb, 0xc2, 0xd8,
-		0xf8, 0xb6, 0xf2, 0xb8, 0x90, 0xc8, 0x7b, 0xdd, 0xc5, 0x31, 0x95, 0x3a,
-		0x79, 0xf5, 0x9a, 0x8e, 0x87, 0x3c, 0x53, 0x2f, 0x17, 0xa4, 0x31, 0xff,
-		0xf2, 0x62, 0xe7, 0x0c, 0xf9, 0x0c, 0x6f, 0x03, 0xa6, 0x65, 0xdb, 0xd5,
-		0xac, 0x01, 0xb7, 0xcc, 0xba, 0xf6, 0x6f, 0x99, 0x5c, 0x8b, 0xf4, 0x1c,
-		0x2e, 0xb3, 0xb7, 0xce, 0xbb, 0x16, 0x3d, 0x87, 0xdb, 0xb8, 0x36, 0x09,
-		0x83, 0x80, 0x8a, 0x89, 0xf7, 0x1d, 0x00, 0x00, 0xff, 0xff, 0x71, 0x08,
-		0x13, 0xe1, 0x79, 0x04, 0x00, 0x00,
+		0x98, 0x49, 0x7c, 0x00, 0x57, 0x7c, 0xcc, 0xd5, 0x34, 0x23, 0x65, 0xa6,
+		0x96, 0x44, 0x57, 0xa4, 0x91, 0x1a, 0x9b, 0xe1, 0x8f, 0xd2, 0x92, 0x71,
+		0xde, 0x7e, 0x02, 0x8a, 0x0c, 0xa1, 0x7e, 0x51, 0x96, 0xf7, 0x9c, 0x7b,
+		0x39, 0xe7, 0x97, 0x2e, 0xe6, 0x90, 0xa9, 0xdc, 0x4a, 0x5d, 0x59, 0x09,
+		0xe6, 0x92, 0x6b, 0xab, 0xc0, 0x1c, 0x4e, 0xaa, 0x90, 0x30, 0x5f, 0x78,
+		0x9f, 0xb8, 0xa6, 0x0c, 0x04, 0x0f, 0xd8, 0x36, 0x08, 0x05, 0x8d, 0xd9,
+		0xd2, 0x5b, 0xcc, 0xa1, 0xac, 0xac, 0x3e, 0xea, 0x83, 0xb4, 0xba, 0x2a,
+		0x4d, 0x6b, 0x0b, 0x39, 0x06, 0x02, 0x41, 0x04, 0x9f, 0x1b, 0x04, 0x32,
+		0x92, 0x09, 0xcc, 0xbc, 0x0f, 0xa2, 0x33, 0x02, 0x8f, 0x8f, 0x32, 0x81,
+		0x6b, 0xe4, 0xf0, 0x93, 0xd3, 0x28, 0xe0, 0x7b, 0xf8, 0x81, 0x7b, 0x08,
+		0x12, 0x11, 0x53, 0x16, 0x72, 0x8c, 0x90, 0x09, 0xef, 0xe3, 0x1b, 0x90,
+		0xc6, 0xa8, 0x3a, 0xbd, 0xed, 0xf5, 0x0b, 0x2c, 0x16, 0xc0, 0x92, 0xcd,
+		0xa6, 0xd3, 0xad, 0x2e, 0x94, 0xb1, 0xb2, 0x38, 0x13, 0xb7, 0x5e, 0x2b,
+		0xd9, 0xff, 0xd4, 0xbd, 0xaf, 0xae, 0x76, 0x08, 0x25, 0x70, 0x27, 0xc6,
+		0x7a, 0x53, 0x6b, 0x02, 0x4f, 0xf4, 0xf6, 0xc0, 0x2a, 0xe6, 0x48, 0xd7,
+		0xac, 0x4d, 0x3f, 0xbb, 0x67, 0xf5, 0x81, 0xe3, 0x0a, 0x39, 0xb2, 0x10,
+		0xb7, 0xd0, 0xce, 0xcc, 0x4c, 0x67, 0xbe, 0xe7, 0x77, 0xd0, 0x8c, 0x32,
+		0xc6, 0xcd, 0xab, 0x57, 0x26, 0xa8, 0xde, 0x04, 0xe5, 0xac, 0xf9, 0x5b,
+		0xfd, 0x25, 0xee, 0x12, 0xed, 0xae, 0xba, 0x9e, 0x75, 0xad, 0x88, 0x03,
+		0xd1, 0xdb, 0x15, 0xef, 0x9d, 0x12, 0x46, 0x7f, 0x25, 0x08, 0x94, 0x7d,
+		0xc7, 0xdd, 0x50, 0x2d, 0x6d, 0x4a, 0x7d, 0x69, 0x54, 0xda, 0xa5, 0x89,
+		0xd9, 0xa8, 0x33, 0x69, 0x87, 0x37, 0x46, 0xdd, 0xc1, 0x29, 0xa0, 0x6e,
+		0xec, 0x78, 0x48, 0x6f, 0x00, 0x2a, 0x65, 0xa1, 0xc8, 0x33, 0x0a, 0x47,
+		0x5d, 0x1b, 0x9b, 0x3e, 0x2c, 0x53, 0x43, 0x2e, 0xff, 0xd7, 0x5d, 0x1c,
+		0x0b, 0xa9, 0xf3, 0x21, 0xd9, 0xd4, 0x70, 0x3e, 0x55, 0xa5, 0xfa, 0xd2,
+		0x20, 0x8d, 0xf9, 0x53, 0xd5, 0x19, 0x71, 0x19, 0x9e, 0xe1, 0xed, 0xc0,
+		0xf4, 0x6c, 0x87, 0x9a, 0x31, 0x1b, 0x98, 0x0d, 0xed, 0x5f, 0x3a, 0x72,
+		0x2f, 0x32, 0xba, 0x70, 0x9b, 0xbd, 0xb4, 0x3e, 0xb4, 0x18, 0x5d, 0x78,
+		0x8c, 0xfd, 0xa5, 0x17, 0xc6, 0x51, 0x44, 0xc5, 0xd2, 0xfb, 0x37, 0x00,
+		0x71, 0x08, 0x13, 0xe1, 0x79, 0x04, 0x00, 0x00,
 	},
 		"res/sqlite/deltaiota.sql",
 	)
 }
 
+func res_sqlite_migrations_0001_member_profile_sql() ([]byte, error) {
+	return bindata_read([]byte{
+		0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xff, 0xa4, 0x92,
+		0xcd, 0x6a, 0xeb, 0x30, 0x10, 0x85, 0xf7, 0x79, 0x8a, 0x41, 0x9b, 0xdc,
+		0x04, 0x2e, 0xe9, 0xba, 0x59, 0xb9, 0xb1, 0x5a, 0x02, 0xaa, 0x02, 0x41,
+		0x86, 0xec, 0x8c, 0x6c, 0x8d, 0x9d, 0x01, 0xf9, 0xa7, 0xd2, 0xb8, 0xd0,
+		0xb7, 0x2f, 0x34, 0x4d, 0x82, 0x69, 0x49, 0x0a, 0xd6, 0x56, 0xcc, 0xa7,
+		0xef, 0x1c, 0xcd, 0x6a, 0x09, 0x0e, 0x3d, 0x5b, 0xea, 0xd8, 0x42, 0x7c,
+		0xf3, 0xc4, 0x08, 0x0d, 0xd5, 0xc1, 0x32, 0x75, 0xed, 0x23, 0x94, 0x47,
+		0xdb, 0x33, 0x86, 0xff, 0xb1, 0xc7, 0x92, 0x2a, 0x2a, 0xa1, 0xc1, 0xa6,
+		0xc0, 0x00, 0x7d, 0xe8, 0x2a, 0xf2, 0x08, 0x15, 0xa1, 0x77, 0x11, 0x96,
+		0xab, 0x59, 0xa2, 0x8c, 0xdc, 0x83, 0x49, 0x9e, 0x94, 0x04, 0x31, 0x44,
+		0x0c, 0x51, 0x40, 0x92, 0xa6, 0xb0, 0xd9, 0xa9, 0xec, 0x55, 0x83, 0xa0,
+		0x96, 0x98, 0xbe, 0xa8, 0xb9, 0xb3, 0x8c, 0x02, 0xb6, 0xda, 0xc8, 0x17,
+		0xb9, 0x07, 0xbd, 0x33, 0xa0, 0x33, 0xa5, 0x20, 0x95, 0xcf, 0x49, 0xa6,
+		0x0c, 0x3c, 0xac, 0xef, 0xc2, 0x7a, 0x8f, 0xae, 0xc6, 0xbc, 0xf4, 0x36,
+		0x46, 0x01, 0xa7, 0x63, 0xe4, 0xc1, 0xfc, 0x84, 0xcd, 0xe7, 0xf7, 0x69,
+		0x05, 0xd5, 0x79, 0x11, 0x3a, 0x3e, 0x62, 0xc8, 0xc9, 0x09, 0x98, 0xa4,
+		0x46, 0x6d, 0xe4, 0x30, 0x34, 0xd8, 0xf2, 0xc5, 0x6c, 0x82, 0x5a, 0x1d,
+		0xac, 0x1b, 0x4e, 0xad, 0x7d, 0xa0, 0x0d, 0xd3, 0x5a, 0x8b, 0x6c, 0x79,
+		0xb8, 0x5a, 0xdd, 0x52, 0xb3, 0x25, 0xd3, 0x3b, 0xfe, 0x41, 0xd0, 0x3a,
+		0x17, 0xf0, 0xfa, 0x09, 0x13, 0xe3, 0x16, 0xd4, 0x8d, 0xf5, 0x6e, 0xd2,
+		0x36, 0x7b, 0x99, 0x18, 0x09, 0x5b, 0x9d, 0xca, 0xc3, 0x37, 0x2e, 0x3f,
+		0x67, 0xdc, 0xe9, 0xcb, 0x03, 0xff, 0xce, 0xc1, 0x17, 0xbf, 0x8f, 0x8c,
+		0x97, 0x69, 0x34, 0x38, 0xba, 0x5a, 0xac, 0x67, 0x9f, 0x03, 0x00, 0x0d,
+		0x3b, 0xd7, 0x30, 0x2e, 0x03, 0x00, 0x00,
+	},
+		"res/sqlite/migrations/0001_member_profile.sql",
+	)
+}
+
+func res_sqlite_migrations_0002_user_avatar_sql() ([]byte, error) {
+	return bindata_read([]byte{
+		0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xff, 0x00, 0x7d,
+		0x00, 0x82, 0xff, 0x2f, 0x2a, 0x20, 0x64, 0x65, 0x6c, 0x74, 0x61, 0x69,
+		0x6f, 0x74, 0x61, 0x20, 0x73, 0x71, 0x6c, 0x69, 0x74, 0x65, 0x20, 0x6d,
+		0x69, 0x67, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x3a, 0x20, 0x75, 0x73,
+		0x65, 0x72, 0x20, 0x61, 0x76, 0x61, 0x74, 0x61, 0x72, 0x20, 0x63, 0x6f,
+		0x6e, 0x74, 0x65, 0x6e, 0x74, 0x20, 0x74, 0x79, 0x70, 0x65, 0x20, 0x2a,
+		0x2f, 0x0a, 0x41, 0x4c, 0x54, 0x45, 0x52, 0x20, 0x54, 0x41, 0x42, 0x4c,
+		0x45, 0x20, 0x22, 0x75, 0x73, 0x65, 0x72, 0x73, 0x22, 0x20, 0x41, 0x44,
+		0x44, 0x20, 0x43, 0x4f, 0x4c, 0x55, 0x4d, 0x4e, 0x20, 0x22, 0x61, 0x76,
+		0x61, 0x74, 0x61, 0x72, 0x22, 0x20, 0x54, 0x45, 0x58, 0x54, 0x20, 0x4e,
+		0x4f, 0x54, 0x20, 0x4e, 0x55, 0x4c, 0x4c, 0x20, 0x44, 0x45, 0x46, 0x41,
+		0x55, 0x4c, 0x54, 0x20, 0x27, 0x27, 0x3b, 0x0a, 0x03, 0x00, 0xc3, 0x3d,
+		0x49, 0x7b, 0x7d, 0x00, 0x00, 0x00,
+	},
+		"res/sqlite/migrations/0002_user_avatar.sql",
+	)
+}
+
+func res_sqlite_migrations_0003_officer_positions_sql() ([]byte, error) {
+	return bindata_read([]byte{
+		0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xff, 0xa4, 0x91,
+		0xb1, 0x6e, 0xc2, 0x30, 0x10, 0x86, 0x67, 0xf2, 0x14, 0x27, 0x4f, 0x24,
+		0x42, 0xca, 0x5e, 0xa6, 0x94, 0x1e, 0x28, 0x2a, 0x38, 0xad, 0xeb, 0x48,
+		0x30, 0x45, 0x16, 0x31, 0xad, 0x55, 0x70, 0xc0, 0x36, 0x43, 0xdf, 0xbe,
+		0x72, 0x20, 0x60, 0x20, 0x9d, 0xea, 0xf1, 0xfc, 0xfd, 0xba, 0xcf, 0xbf,
+		0xd3, 0x04, 0x6a, 0xb9, 0x75, 0x42, 0x35, 0x4e, 0x80, 0x3d, 0x6c, 0x95,
+		0x93, 0xb0, 0x53, 0x9f, 0x46, 0x38, 0xd5, 0xe8, 0x27, 0x68, 0x36, 0x1b,
+		0xb5, 0x96, 0x06, 0xf6, 0x8d, 0x55, 0x7e, 0x62, 0x41, 0xe8, 0x1a, 0x9c,
+		0x34, 0x3b, 0xf8, 0x52, 0xd6, 0x35, 0xe6, 0x07, 0x92, 0x34, 0x4a, 0x93,
+		0x00, 0x48, 0xd2, 0x68, 0xc2, 0x30, 0xe3, 0x08, 0x3c, 0x7b, 0x9e, 0x23,
+		0x90, 0xcb, 0x15, 0x81, 0x61, 0x34, 0x20, 0xaa, 0x26, 0x10, 0x9c, 0x9c,
+		0x72, 0x9c, 0x21, 0x83, 0x37, 0x96, 0x2f, 0x32, 0xb6, 0x82, 0x57, 0x5c,
+		0x41, 0x56, 0xf2, 0x22, 0xa7, 0x13, 0x86, 0x0b, 0xa4, 0x3c, 0x1a, 0x8c,
+		0x80, 0x68, 0xb1, 0x93, 0x61, 0x8c, 0xe3, 0x92, 0x03, 0x2d, 0x38, 0xd0,
+		0x72, 0x3e, 0x6f, 0x89, 0x5a, 0xda, 0xb5, 0x51, 0x7b, 0xbf, 0x88, 0xf4,
+		0x13, 0x46, 0xe8, 0x6f, 0x72, 0xbf, 0xf6, 0x42, 0xc4, 0xe3, 0x4e, 0xbb,
+		0xa4, 0xf9, 0x7b, 0x89, 0x90, 0xd3, 0x17, 0x5c, 0x06, 0xf6, 0xd5, 0x51,
+		0xab, 0xc3, 0x51, 0x56, 0x27, 0x95, 0x82, 0xde, 0x3e, 0xec, 0x64, 0x18,
+		0x8f, 0x7d, 0x17, 0xbe, 0x9e, 0x9e, 0x1e, 0xda, 0xf1, 0x3f, 0x3a, 0x38,
+		0x5a, 0x69, 0xaa, 0x2e, 0xf9, 0xe0, 0x3f, 0x18, 0x5d, 0x85, 0x5a, 0xaa,
+		0x97, 0xb0, 0x4e, 0x18, 0xd7, 0xed, 0xee, 0x25, 0xa4, 0x0e, 0xdc, 0x1e,
+		0x08, 0x8f, 0x4c, 0x0b, 0x86, 0xf9, 0x8c, 0x7a, 0xcb, 0xe1, 0xd9, 0x29,
+		0x06, 0x86, 0x53, 0x64, 0x48, 0x27, 0xf8, 0x01, 0x7e, 0x66, 0x87, 0xaa,
+		0x8e, 0xef, 0xe1, 0x40, 0xef, 0x26, 0xd0, 0xcd, 0x4f, 0xa1, 0xeb, 0x47,
+		0x9c, 0x7f, 0xa0, 0xed, 0xad, 0xba, 0xbc, 0xbe, 0xa0, 0xd7, 0x2a, 0x49,
+		0x37, 0xfd, 0x23, 0x14, 0x6c, 0xbc, 0x0b, 0x86, 0x37, 0xf1, 0x38, 0xfa,
+		0x1d, 0x00, 0xf8, 0x24, 0xe1, 0x12, 0x07, 0x03, 0x00, 0x00,
+	},
+		"res/sqlite/migrations/0003_officer_positions.sql",
+	)
+}
+
+func res_sqlite_migrations_0004_committees_sql() ([]byte, error) {
+	return bindata_read([]byte{
+		0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xff, 0x7c, 0x91,
+		0xcd, 0x8e, 0xb2, 0x30, 0x14, 0x86, 0xd7, 0x72, 0x15, 0x27, 0x5d, 0x09,
+		0x31, 0x61, 0xff, 0xb9, 0xe2, 0x73, 0x8e, 0x86, 0x8c, 0x96, 0x99, 0x4e,
+		0x49, 0x74, 0xd5, 0x74, 0xa4, 0x19, 0x9b, 0x58, 0xd4, 0xb6, 0xdc, 0xff,
+		0x04, 0xb1, 0xa1, 0xfe, 0x64, 0xd8, 0x51, 0x9e, 0x73, 0xfa, 0xbe, 0x0f,
+		0x79, 0x06, 0x8d, 0x3a, 0x7a, 0xa9, 0x4f, 0x5e, 0x82, 0xbb, 0x1c, 0xb5,
+		0x57, 0x60, 0xf4, 0x8f, 0x95, 0x5e, 0x9f, 0xda, 0x7f, 0xb0, 0x3f, 0x19,
+		0xa3, 0xbd, 0x57, 0xca, 0x81, 0x6c, 0x9b, 0xf1, 0x15, 0x8c, 0x32, 0xdf,
+		0xca, 0xba, 0x83, 0x3e, 0x43, 0x96, 0x27, 0x79, 0x16, 0x93, 0x59, 0x9e,
+		0x2c, 0x18, 0x16, 0x1c, 0x81, 0x17, 0xff, 0xd7, 0x08, 0x64, 0xfc, 0x46,
+		0x60, 0x9a, 0x4c, 0x88, 0x6e, 0x08, 0x44, 0x4f, 0x49, 0x39, 0xae, 0x90,
+		0xc1, 0x07, 0x2b, 0x37, 0x05, 0xdb, 0xc1, 0x3b, 0xee, 0xa0, 0xa8, 0x79,
+		0x55, 0xd2, 0x05, 0xc3, 0x0d, 0x52, 0x9e, 0x4c, 0x66, 0x40, 0x5a, 0x69,
+		0x54, 0x3c, 0xc6, 0x71, 0xcb, 0x81, 0x56, 0x1c, 0x68, 0xbd, 0x5e, 0x5f,
+		0x89, 0x46, 0xb9, 0xbd, 0xd5, 0xe7, 0x3e, 0x39, 0x79, 0x26, 0xd2, 0x79,
+		0x48, 0x55, 0xd3, 0xf2, 0xb3, 0x46, 0x28, 0xe9, 0x1b, 0x6e, 0xe3, 0x70,
+		0xa2, 0x6b, 0xf5, 0xa5, 0x53, 0x62, 0xb8, 0xa9, 0xa2, 0x0f, 0xc1, 0x87,
+		0x04, 0xe9, 0xfc, 0xae, 0xad, 0xb8, 0x89, 0xf8, 0xa3, 0x74, 0x40, 0x86,
+		0xee, 0xe3, 0xf1, 0xd5, 0x42, 0xe8, 0x7e, 0x57, 0xa4, 0x73, 0xca, 0x8a,
+		0x20, 0xe9, 0x25, 0xb1, 0x3f, 0x48, 0x6d, 0x83, 0x8d, 0x27, 0xa2, 0x47,
+		0x22, 0x99, 0xd3, 0xf8, 0xce, 0x19, 0xdc, 0xb6, 0xa7, 0x3d, 0xb5, 0xac,
+		0x18, 0x96, 0x2b, 0xfa, 0x44, 0xa5, 0xc0, 0x70, 0x89, 0x0c, 0xe9, 0x02,
+		0xbf, 0xa2, 0x5f, 0x3b, 0x7d, 0x31, 0x16, 0xd6, 0xc5, 0x13, 0xfd, 0xd9,
+		0x00, 0x8f, 0xd6, 0x1f, 0x75, 0x07, 0x2d, 0xe2, 0xb6, 0xe0, 0xc1, 0x78,
+		0x64, 0x8d, 0x74, 0x4e, 0x59, 0xa1, 0x1b, 0x92, 0xce, 0x93, 0xdf, 0x01,
+		0x00, 0x90, 0x49, 0x1f, 0xbf, 0xb0, 0x02, 0x00, 0x00,
+	},
+		"res/sqlite/migrations/0004_committees.sql",
+	)
+}
+
+func res_sqlite_migrations_0005_dues_ledger_sql() ([]byte, error) {
+	return bindata_read([]byte{
+		0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xff, 0xb4, 0x92,
+		0x4f, 0x8f, 0xda, 0x30, 0x10, 0xc5, 0xcf, 0xe4, 0x53, 0x8c, 0x7c, 0x22,
+		0x51, 0xa5, 0xdc, 0xcb, 0x29, 0xa5, 0x03, 0x8a, 0x0a, 0x4e, 0xe5, 0x1a,
+		0x09, 0x4e, 0xc8, 0x8d, 0xa7, 0x60, 0x95, 0x24, 0xac, 0xed, 0xac, 0xc4,
+		0xb7, 0x5f, 0x05, 0x91, 0x6c, 0xb2, 0x4b, 0xd8, 0xcb, 0x6e, 0x8e, 0x9e,
+		0xdf, 0xfc, 0xc9, 0x7b, 0x2f, 0x8e, 0x40, 0xd3, 0xc9, 0x2b, 0x53, 0x79,
+		0x05, 0xee, 0xe9, 0x64, 0x3c, 0x41, 0x61, 0x0e, 0x56, 0x79, 0x53, 0x95,
+		0xdf, 0x41, 0xd7, 0xe4, 0xe0, 0x44, 0xfa, 0x40, 0x16, 0xf2, 0xa3, 0xb2,
+		0x07, 0x72, 0xa0, 0x4a, 0x0d, 0x67, 0x75, 0x29, 0xa8, 0xf4, 0x0e, 0xa2,
+		0x38, 0x88, 0xa3, 0xae, 0x14, 0xc5, 0xc1, 0x5c, 0x60, 0x22, 0x11, 0x64,
+		0xf2, 0x63, 0x85, 0xc0, 0x6e, 0x05, 0x06, 0xd3, 0x60, 0xc2, 0x8c, 0x66,
+		0xd0, 0xfb, 0x52, 0x2e, 0x71, 0x89, 0x02, 0x7e, 0x8b, 0x74, 0x9d, 0x88,
+		0x1d, 0xfc, 0xc2, 0x1d, 0x24, 0x1b, 0x99, 0xa5, 0x7c, 0x2e, 0x70, 0x8d,
+		0x5c, 0x06, 0x93, 0x6f, 0xc0, 0x6a, 0x47, 0x76, 0xdf, 0x76, 0xb6, 0x2d,
+		0x3c, 0x93, 0xc0, 0x37, 0xab, 0xd5, 0x95, 0xf8, 0x6f, 0xca, 0xc1, 0x60,
+		0x89, 0x5b, 0x39, 0x24, 0x34, 0xb9, 0xdc, 0x9a, 0x73, 0xf3, 0x4b, 0xec,
+		0x3e, 0xa1, 0x8a, 0xaa, 0x2e, 0x3d, 0x83, 0xf1, 0x2d, 0xb9, 0x25, 0xe5,
+		0xe9, 0xd1, 0x1d, 0xba, 0xa6, 0xd7, 0x33, 0xee, 0x12, 0x96, 0x0a, 0x53,
+		0xea, 0xdb, 0x90, 0xbb, 0x44, 0xf5, 0x4c, 0xb6, 0x9b, 0xf3, 0x8e, 0x68,
+		0x90, 0x45, 0x26, 0x30, 0x5d, 0xf2, 0x46, 0xad, 0xe9, 0x4d, 0x9b, 0x10,
+		0x04, 0x2e, 0x50, 0x20, 0x9f, 0xe3, 0x1f, 0x68, 0xde, 0xdc, 0xd4, 0xe8,
+		0x30, 0x08, 0x67, 0xad, 0x19, 0x29, 0xff, 0x89, 0xdb, 0xce, 0x8c, 0x7d,
+		0x27, 0x69, 0xc6, 0xfb, 0x0e, 0x75, 0x52, 0x8f, 0x36, 0x5e, 0x2f, 0x7b,
+		0xd3, 0xd4, 0xbc, 0x85, 0xb3, 0x26, 0x04, 0xfd, 0x4c, 0x0c, 0x53, 0xd0,
+		0x56, 0xbe, 0x34, 0x06, 0x1f, 0x5b, 0x58, 0x90, 0x3f, 0x56, 0x9a, 0x3d,
+		0x08, 0x8a, 0xa5, 0x7f, 0x64, 0xa9, 0xcc, 0x89, 0x8d, 0x11, 0xde, 0x14,
+		0xe4, 0xbc, 0x2a, 0xce, 0x6c, 0x6c, 0x8b, 0xa5, 0xbc, 0xb2, 0x9a, 0xf4,
+		0xfe, 0xef, 0x85, 0x7d, 0xba, 0x85, 0xad, 0x92, 0x43, 0x0f, 0x7b, 0xfa,
+		0xf6, 0x4d, 0x7c, 0x19, 0x00, 0x6f, 0x8c, 0xf3, 0xc4, 0xdc, 0x03, 0x00,
+		0x00,
+	},
+		"res/sqlite/migrations/0005_dues_ledger.sql",
+	)
+}
+
+func res_sqlite_migrations_0006_events_calendar_sql() ([]byte, error) {
+	return bindata_read([]byte{
+		0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xff, 0x9c, 0x93,
+		0xcf, 0x6f, 0x9b, 0x30, 0x14, 0xc7, 0xcf, 0xcd, 0x5f, 0xf1, 0xe4, 0x53,
+		0x88, 0x90, 0x72, 0x5f, 0x4e, 0x2c, 0x73, 0x2b, 0xb4, 0xd4, 0x74, 0xae,
+		0x99, 0xda, 0x13, 0xb2, 0xf0, 0xdb, 0x64, 0x95, 0x19, 0x66, 0x7b, 0x95,
+		0xf6, 0xdf, 0x4f, 0xe6, 0x57, 0x21, 0x01, 0x0e, 0xcb, 0x2d, 0xcf, 0x1f,
+		0x9b, 0xaf, 0x3f, 0xef, 0xf9, 0x78, 0x00, 0x85, 0x95, 0x97, 0xba, 0xf6,
+		0x12, 0xdc, 0xef, 0x4a, 0x7b, 0x84, 0x5f, 0xfa, 0xa7, 0x95, 0x5e, 0xd7,
+		0xe6, 0x13, 0xe0, 0x3b, 0x1a, 0xef, 0x62, 0xe0, 0xcf, 0xdf, 0x9f, 0x5c,
+		0x0c, 0xd2, 0x28, 0x28, 0x65, 0x85, 0x46, 0x49, 0x0b, 0x3f, 0x10, 0x15,
+		0xf8, 0xfa, 0x0d, 0x8d, 0x83, 0xc3, 0x71, 0x77, 0x3c, 0xf4, 0x74, 0xf8,
+		0x73, 0xe6, 0x34, 0x11, 0x14, 0x44, 0xf2, 0xf9, 0x42, 0x81, 0x74, 0x75,
+		0x02, 0xfb, 0xdd, 0x1d, 0xd1, 0x8a, 0xc0, 0xe4, 0x97, 0x32, 0x41, 0x1f,
+		0x28, 0x87, 0x27, 0x9e, 0x3e, 0x26, 0xfc, 0x15, 0xbe, 0xd2, 0x57, 0x48,
+		0x72, 0x91, 0xa5, 0xec, 0xcc, 0xe9, 0x23, 0x65, 0x62, 0x77, 0x17, 0x03,
+		0xf1, 0xda, 0x57, 0x38, 0xd9, 0x27, 0xe8, 0x8b, 0x00, 0x96, 0x09, 0x60,
+		0xf9, 0xe5, 0xd2, 0x12, 0x0a, 0x5d, 0x69, 0x75, 0x13, 0x52, 0x93, 0x65,
+		0xa2, 0xaa, 0x4b, 0x39, 0x2e, 0x2f, 0x12, 0xce, 0x4b, 0xeb, 0xc9, 0x55,
+		0xb0, 0x19, 0x81, 0x46, 0x91, 0x9b, 0xe8, 0x33, 0x42, 0x56, 0x55, 0xa1,
+		0xe4, 0x5f, 0xb2, 0x4e, 0x94, 0x16, 0xa5, 0x47, 0xb5, 0x41, 0xfc, 0x69,
+		0xd4, 0x06, 0x11, 0x9d, 0x06, 0xbb, 0x29, 0xfb, 0x42, 0x5f, 0x06, 0xbb,
+		0x45, 0x9f, 0x3e, 0x63, 0x13, 0xdf, 0xa4, 0x2b, 0x46, 0xa7, 0xd0, 0x1d,
+		0xeb, 0xde, 0x9b, 0x85, 0xe6, 0xb4, 0xe5, 0xae, 0x37, 0xed, 0xbe, 0x62,
+		0xec, 0xd0, 0x72, 0x38, 0x87, 0xb6, 0xd0, 0x6b, 0xe1, 0x42, 0x7c, 0x8b,
+		0xae, 0xa9, 0x8d, 0xc3, 0x75, 0xd1, 0xdb, 0x17, 0x0c, 0xc8, 0x64, 0x1c,
+		0xf6, 0x43, 0xaa, 0x18, 0xfa, 0x6f, 0x47, 0x81, 0xb8, 0xcf, 0x38, 0x4d,
+		0x1f, 0xd8, 0x8c, 0x88, 0x80, 0xd3, 0x7b, 0xca, 0x29, 0x3b, 0xd3, 0xe7,
+		0x7e, 0x18, 0xf7, 0x0b, 0xf8, 0x70, 0xcc, 0x94, 0x0e, 0xb5, 0x0e, 0xbe,
+		0xf1, 0xdb, 0x0a, 0x2a, 0xfa, 0x4d, 0x04, 0x32, 0xf6, 0xe1, 0x6c, 0xb4,
+		0xd1, 0x19, 0x1e, 0x9e, 0x46, 0xf1, 0xf1, 0x2a, 0xe6, 0xae, 0xaf, 0x80,
+		0xce, 0xfa, 0x78, 0xf2, 0xea, 0x8b, 0x08, 0xf7, 0x25, 0xed, 0x99, 0x03,
+		0xb4, 0xa8, 0x75, 0x7b, 0xb2, 0xfe, 0xd7, 0x42, 0xce, 0xd2, 0x6f, 0xf9,
+		0x28, 0xe3, 0xea, 0x06, 0x45, 0x9f, 0x2a, 0x63, 0x37, 0x4b, 0x61, 0xfc,
+		0x7c, 0xfd, 0x86, 0x86, 0x44, 0xa7, 0xdd, 0xbf, 0x01, 0x00, 0xc8, 0xa5,
+		0x10, 0x1c, 0x67, 0x04, 0x00, 0x00,
+	},
+		"res/sqlite/migrations/0006_events_calendar.sql",
+	)
+}
+
+func res_sqlite_migrations_0007_user_privacy_sql() ([]byte, error) {
+	return bindata_read([]byte{
+		0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xff, 0x8c, 0xcc,
+		0xb1, 0x0a, 0xc2, 0x30, 0x14, 0x46, 0xe1, 0xdd, 0xa7, 0xf8, 0xc9, 0xd8,
+		0xa5, 0xce, 0x3a, 0x45, 0x1b, 0x45, 0x88, 0x29, 0x94, 0x74, 0x96, 0x50,
+		0x2f, 0xf5, 0x42, 0x9b, 0xd4, 0xe4, 0x2a, 0xf8, 0xf6, 0xa2, 0x0f, 0x20,
+		0xee, 0xe7, 0x7c, 0x75, 0x85, 0x2b, 0x4d, 0x12, 0x38, 0x49, 0x40, 0xb9,
+		0x4f, 0x2c, 0x84, 0x99, 0xc7, 0x1c, 0x84, 0x53, 0xdc, 0xe0, 0x51, 0x28,
+		0x63, 0x48, 0x51, 0xc2, 0x20, 0x58, 0x32, 0x3f, 0xc3, 0xf0, 0x42, 0x21,
+		0x11, 0x8e, 0x63, 0x41, 0x55, 0xaf, 0xb4, 0xf5, 0xa6, 0x83, 0xd7, 0x3b,
+		0x6b, 0xa0, 0x3e, 0x75, 0x51, 0xd0, 0x4d, 0x83, 0x7d, 0x6b, 0xfb, 0xb3,
+		0x83, 0xfa, 0x3e, 0x42, 0x17, 0x9a, 0x03, 0x4f, 0x0a, 0x27, 0xe7, 0xcd,
+		0xd1, 0x74, 0x70, 0xad, 0x87, 0xeb, 0xad, 0x45, 0x63, 0x0e, 0xba, 0xb7,
+		0x1e, 0xeb, 0xed, 0xdf, 0xd4, 0x72, 0x4b, 0x91, 0x7e, 0x53, 0xef, 0x01,
+		0x00, 0x15, 0xe0, 0x39, 0x99, 0xd6, 0x00, 0x00, 0x00,
+	},
+		"res/sqlite/migrations/0007_user_privacy.sql",
+	)
+}
+
+func res_sqlite_migrations_0008_invitations_sql() ([]byte, error) {
+	return bindata_read([]byte{
+		0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xff, 0x7c, 0x90,
+		0x41, 0x4b, 0xc3, 0x40, 0x14, 0x84, 0xcf, 0xcd, 0xaf, 0x18, 0xf6, 0x64,
+		0x8b, 0xd0, 0xbb, 0x3d, 0xc5, 0xfa, 0x90, 0x60, 0xba, 0xd1, 0x65, 0x03,
+		0xed, 0xa9, 0x2c, 0xe9, 0x43, 0x16, 0xd3, 0x8d, 0x66, 0x5f, 0xc5, 0x9f,
+		0x2f, 0xb4, 0xa6, 0xa6, 0x9a, 0x66, 0x8f, 0x3b, 0x33, 0xdf, 0x1b, 0x66,
+		0x3e, 0xc3, 0x8e, 0x6b, 0x71, 0xbe, 0x11, 0x87, 0xf8, 0x51, 0x7b, 0x61,
+		0xec, 0xfd, 0x6b, 0xeb, 0xc4, 0x37, 0xe1, 0x0e, 0xae, 0xaa, 0x9a, 0x43,
+		0x10, 0xf8, 0xf0, 0xe9, 0xe5, 0xf8, 0x17, 0x31, 0x9b, 0x27, 0x4b, 0x43,
+		0xa9, 0x25, 0xd8, 0xf4, 0x3e, 0x27, 0xa8, 0x9e, 0xa8, 0x70, 0x93, 0x4c,
+		0x94, 0xdf, 0x29, 0xf4, 0x5e, 0xa6, 0x2d, 0x3d, 0x92, 0xc1, 0xb3, 0xc9,
+		0x56, 0xa9, 0xd9, 0xe0, 0x89, 0x36, 0x48, 0x4b, 0x5b, 0x64, 0x7a, 0x69,
+		0x68, 0x45, 0xda, 0x26, 0x93, 0x5b, 0x28, 0xde, 0x3b, 0x5f, 0xf7, 0x72,
+		0x96, 0xd6, 0x16, 0xba, 0xb0, 0xd0, 0x65, 0x9e, 0x1f, 0x1d, 0x87, 0xc8,
+		0xed, 0xb6, 0x63, 0x77, 0xd0, 0x0b, 0x87, 0x34, 0x6f, 0x1c, 0x46, 0x19,
+		0x55, 0xcb, 0x4e, 0x78, 0x8c, 0xc1, 0x5f, 0xef, 0xbe, 0x65, 0x85, 0xeb,
+		0x8e, 0xc8, 0x41, 0xce, 0x47, 0xfe, 0x39, 0xa6, 0x8b, 0x6e, 0x9e, 0x52,
+		0x67, 0x2f, 0x25, 0x21, 0xd3, 0x0f, 0xb4, 0xbe, 0x58, 0x69, 0xfb, 0xd3,
+		0xb3, 0xd0, 0x7f, 0xc7, 0x53, 0x27, 0xe5, 0x97, 0x31, 0x10, 0x3e, 0xcf,
+		0x30, 0x10, 0xef, 0xb4, 0x51, 0xc0, 0xa9, 0xff, 0x40, 0x3a, 0x72, 0x10,
+		0x35, 0x5d, 0x24, 0xdf, 0x03, 0x00, 0xa0, 0x71, 0xe1, 0x72, 0x15, 0x02,
+		0x00, 0x00,
+	},
+		"res/sqlite/migrations/0008_invitations.sql",
+	)
+}
+
+func res_sqlite_migrations_0009_audit_log_sql() ([]byte, error) {
+	return bindata_read([]byte{
+		0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xff, 0xac, 0x92,
+		0xc1, 0x8e, 0xda, 0x30, 0x10, 0x86, 0xcf, 0xf8, 0x29, 0x46, 0xbe, 0xec,
+		0x82, 0xb6, 0xe2, 0xde, 0x9c, 0x12, 0x32, 0x45, 0x51, 0xb3, 0xce, 0xca,
+		0x6b, 0xa4, 0xe5, 0x14, 0xb9, 0x78, 0x48, 0x2d, 0x85, 0x38, 0x35, 0xe6,
+		0xc0, 0xdb, 0x57, 0xd0, 0xa6, 0x38, 0x85, 0xe6, 0xd4, 0x1c, 0xfd, 0xe7,
+		0x9f, 0xcf, 0xfe, 0x34, 0xcb, 0x05, 0x18, 0x6a, 0x83, 0xb6, 0x2e, 0x68,
+		0x38, 0xfe, 0x68, 0x6d, 0x20, 0x38, 0xd8, 0xc6, 0xeb, 0x60, 0x5d, 0xf7,
+		0x19, 0xf4, 0xc9, 0xd8, 0x00, 0xad, 0x6b, 0x60, 0xb1, 0x64, 0x2b, 0x89,
+		0xa9, 0x42, 0x50, 0x69, 0x56, 0x22, 0xf0, 0x6b, 0x54, 0xb7, 0xae, 0xe1,
+		0xf0, 0xcc, 0x66, 0xdc, 0x1a, 0x0e, 0xf1, 0x57, 0x08, 0x85, 0x6b, 0x94,
+		0xf0, 0x26, 0x8b, 0xd7, 0x54, 0x6e, 0xe1, 0x2b, 0x6e, 0x21, 0xdd, 0xa8,
+		0xaa, 0x10, 0x2b, 0x89, 0xaf, 0x28, 0x14, 0x9b, 0xbd, 0x00, 0xd7, 0xbb,
+		0xe0, 0x7c, 0x3d, 0x74, 0x87, 0x8e, 0xa8, 0x14, 0x88, 0x4d, 0x59, 0x0e,
+		0xbf, 0x58, 0xd7, 0x0d, 0xc3, 0x15, 0x7e, 0xa8, 0x71, 0x1e, 0xb4, 0x6f,
+		0x28, 0xd4, 0xe1, 0xdc, 0x13, 0x9f, 0xc8, 0x7f, 0x33, 0x1e, 0x22, 0xbe,
+		0xd1, 0xde, 0x79, 0x8a, 0x11, 0xd7, 0x63, 0xbd, 0x0f, 0xe4, 0x39, 0xfc,
+		0x7d, 0xec, 0xe9, 0xe0, 0x02, 0xd5, 0xda, 0x18, 0xff, 0x10, 0xb8, 0xf3,
+		0xa4, 0x03, 0x0d, 0x3a, 0xee, 0x80, 0xf3, 0x64, 0x10, 0x59, 0x88, 0x1c,
+		0x3f, 0x22, 0x91, 0xf5, 0xcd, 0x46, 0x25, 0xc6, 0x82, 0x6f, 0xa2, 0x26,
+		0xea, 0xbf, 0x5e, 0xfa, 0xa0, 0x1c, 0x2b, 0x1a, 0x19, 0x99, 0x18, 0xf6,
+		0xe7, 0x15, 0x77, 0xd3, 0x86, 0x64, 0x9e, 0x30, 0xb6, 0x5c, 0x80, 0xfa,
+		0x4e, 0xd1, 0x9a, 0xd8, 0x23, 0xe8, 0xbe, 0xa7, 0xce, 0x7c, 0x72, 0x5d,
+		0x7b, 0x8e, 0xb7, 0x46, 0x16, 0xeb, 0x8b, 0x87, 0x08, 0xd1, 0xb9, 0xfa,
+		0xd4, 0x1b, 0x1d, 0x88, 0x43, 0x86, 0x5f, 0x2a, 0x89, 0xb0, 0x79, 0xcb,
+		0x2f, 0x97, 0x19, 0x23, 0x59, 0x86, 0xeb, 0x42, 0xb0, 0xd9, 0x3b, 0x96,
+		0xb8, 0x52, 0x20, 0xd3, 0xe2, 0x1d, 0x9f, 0xd3, 0xac, 0x92, 0xea, 0x05,
+		0x9e, 0xfe, 0x45, 0x7e, 0x9a, 0x27, 0x0c, 0x45, 0x9e, 0x4c, 0xe3, 0x0d,
+		0xb5, 0x14, 0xe1, 0x73, 0x2c, 0xf1, 0x3f, 0xe3, 0x7f, 0x0e, 0x00, 0x42,
+		0x75, 0xcd, 0x6e, 0x60, 0x03, 0x00, 0x00,
+	},
+		"res/sqlite/migrations/0009_audit_log.sql",
+	)
+}
+
+func res_sqlite_migrations_0010_user_soft_delete_sql() ([]byte, error) {
+	return bindata_read([]byte{
+		0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xff, 0x4c, 0xcc,
+		0xc1, 0xaa, 0x83, 0x30, 0x10, 0x85, 0xe1, 0xbd, 0x4f, 0x71, 0xc8, 0xea,
+		0x5e, 0x37, 0x76, 0x5d, 0x57, 0xa9, 0x99, 0x16, 0x21, 0x8d, 0x20, 0x09,
+		0x74, 0x27, 0x01, 0x63, 0x09, 0xd8, 0x86, 0x9a, 0xe9, 0xfb, 0x17, 0xa5,
+		0x14, 0x97, 0x33, 0x9c, 0xff, 0xab, 0x4a, 0x8c, 0x61, 0x66, 0x1f, 0x13,
+		0x7b, 0xe4, 0xd7, 0x1c, 0x39, 0xe0, 0x11, 0xef, 0x8b, 0xe7, 0x98, 0x9e,
+		0x47, 0xe4, 0x34, 0xf1, 0x3a, 0x08, 0xeb, 0x89, 0x34, 0xe1, 0x9d, 0xc3,
+		0x92, 0x51, 0x56, 0x85, 0xd4, 0x96, 0x7a, 0x58, 0x79, 0xd2, 0x04, 0xb1,
+		0x7d, 0x05, 0xa4, 0x52, 0x68, 0x3a, 0xed, 0xae, 0x06, 0x62, 0x8b, 0xc2,
+		0x38, 0x78, 0x16, 0x68, 0x8d, 0xa5, 0x0b, 0xf5, 0x30, 0x9d, 0x85, 0x71,
+		0x5a, 0x43, 0xd1, 0x59, 0x3a, 0x6d, 0x71, 0xa8, 0x8b, 0xa6, 0x27, 0x69,
+		0x09, 0xad, 0x51, 0x74, 0xfb, 0x42, 0xc3, 0xbe, 0xed, 0xcc, 0x8f, 0xff,
+		0xdb, 0xa3, 0xff, 0x75, 0xf1, 0x19, 0x00, 0xd5, 0x5d, 0x51, 0xd6, 0xbc,
+		0x00, 0x00, 0x00,
+	},
+		"res/sqlite/migrations/0010_user_soft_delete.sql",
+	)
+}
+
+func res_sqlite_migrations_0011_status_transitions_sql() ([]byte, error) {
+	return bindata_read([]byte{
+		0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xff, 0x74, 0x90,
+		0x41, 0x4b, 0xc3, 0x40, 0x10, 0x46, 0xcf, 0xcd, 0xaf, 0xf8, 0xd8, 0x93,
+		0x2d, 0x42, 0xef, 0xf6, 0x14, 0xeb, 0x20, 0xc1, 0x74, 0x23, 0xcb, 0x16,
+		0xda, 0x53, 0x58, 0xdb, 0x55, 0x17, 0x9a, 0xac, 0xee, 0x4c, 0xff, 0xbf,
+		0xd8, 0x92, 0x18, 0x69, 0xb2, 0xd7, 0x79, 0xc3, 0xbe, 0x79, 0xcb, 0x05,
+		0x8e, 0xfe, 0x24, 0x2e, 0x44, 0x71, 0xe0, 0xef, 0x53, 0x10, 0x8f, 0x26,
+		0x7c, 0x24, 0x27, 0x21, 0xb6, 0x0f, 0x68, 0x7c, 0xf3, 0xe6, 0x13, 0x7f,
+		0x86, 0x2f, 0xb0, 0x38, 0x39, 0x33, 0x24, 0xb9, 0x96, 0xc3, 0xef, 0x94,
+		0xb1, 0x58, 0x66, 0x6b, 0x43, 0xb9, 0x25, 0xd8, 0xfc, 0xb1, 0x24, 0xa8,
+		0x2b, 0x53, 0x0f, 0x18, 0x85, 0xbb, 0x6c, 0xa6, 0xc2, 0x51, 0x61, 0xf0,
+		0x0a, 0x6d, 0xe9, 0x99, 0x0c, 0x5e, 0x4d, 0xb1, 0xc9, 0xcd, 0x1e, 0x2f,
+		0xb4, 0x47, 0xbe, 0xb5, 0x55, 0xa1, 0xd7, 0x86, 0x36, 0xa4, 0x6d, 0x36,
+		0xbb, 0x87, 0x3a, 0xb3, 0x4f, 0x75, 0xb7, 0xd9, 0xad, 0xe8, 0xca, 0x42,
+		0x6f, 0xcb, 0xf2, 0x42, 0xbc, 0xa7, 0xd8, 0xd4, 0xd7, 0x3f, 0x2f, 0x94,
+		0xa5, 0x9d, 0xfd, 0x4f, 0x48, 0x1c, 0xce, 0xc7, 0x88, 0xe4, 0x1d, 0xc7,
+		0xf6, 0x4f, 0xef, 0x96, 0x70, 0x07, 0x89, 0xbd, 0xc8, 0xa8, 0xc7, 0x21,
+		0x79, 0x27, 0x7e, 0xca, 0x74, 0xbe, 0xea, 0x2a, 0x15, 0xfa, 0x89, 0x76,
+		0x63, 0x95, 0xea, 0xfe, 0xd6, 0x4a, 0x4f, 0x54, 0xec, 0x73, 0xcc, 0x57,
+		0xd9, 0xcf, 0x00, 0x88, 0x51, 0x13, 0xdc, 0xb5, 0x01, 0x00, 0x00,
+	},
+		"res/sqlite/migrations/0011_status_transitions.sql",
+	)
+}
+
 // Asset loads and returns the asset for the given name.
 // It returns an error if the asset could not be found or
 // could not be loaded.
@@ -87,6 +414,17 @@ func AssetNames() []string {
 // _bindata is a table, holding each asset generator, mapped to its name.
 var _bindata = map[string]func() ([]byte, error){
 	"res/sqlite/deltaiota.sql": res_sqlite_deltaiota_sql,
+	"res/sqlite/migrations/0001_member_profile.sql": res_sqlite_migrations_0001_member_profile_sql,
+	"res/sqlite/migrations/0002_user_avatar.sql": res_sqlite_migrations_0002_user_avatar_sql,
+	"res/sqlite/migrations/0003_officer_positions.sql": res_sqlite_migrations_0003_officer_positions_sql,
+	"res/sqlite/migrations/0004_committees.sql": res_sqlite_migrations_0004_committees_sql,
+	"res/sqlite/migrations/0005_dues_ledger.sql": res_sqlite_migrations_0005_dues_ledger_sql,
+	"res/sqlite/migrations/0006_events_calendar.sql": res_sqlite_migrations_0006_events_calendar_sql,
+	"res/sqlite/migrations/0007_user_privacy.sql": res_sqlite_migrations_0007_user_privacy_sql,
+	"res/sqlite/migrations/0008_invitations.sql": res_sqlite_migrations_0008_invitations_sql,
+	"res/sqlite/migrations/0009_audit_log.sql": res_sqlite_migrations_0009_audit_log_sql,
+	"res/sqlite/migrations/0010_user_soft_delete.sql": res_sqlite_migrations_0010_user_soft_delete_sql,
+	"res/sqlite/migrations/0011_status_transitions.sql": res_sqlite_migrations_0011_status_transitions_sql,
 }
 // AssetDir returns the file names below a certain
 // directory embedded in the file by go-bindata.
@@ -132,6 +470,30 @@ var _bintree = &_bintree_t{nil, map[string]*_bintree_t{
 		"sqlite": &_bintree_t{nil, map[string]*_bintree_t{
 			"deltaiota.sql": &_bintree_t{res_sqlite_deltaiota_sql, map[string]*_bintree_t{
 			}},
+			"migrations": &_bintree_t{nil, map[string]*_bintree_t{
+				"0001_member_profile.sql": &_bintree_t{res_sqlite_migrations_0001_member_profile_sql, map[string]*_bintree_t{
+				}},
+				"0002_user_avatar.sql": &_bintree_t{res_sqlite_migrations_0002_user_avatar_sql, map[string]*_bintree_t{
+				}},
+				"0003_officer_positions.sql": &_bintree_t{res_sqlite_migrations_0003_officer_positions_sql, map[string]*_bintree_t{
+				}},
+				"0004_committees.sql": &_bintree_t{res_sqlite_migrations_0004_committees_sql, map[string]*_bintree_t{
+				}},
+				"0005_dues_ledger.sql": &_bintree_t{res_sqlite_migrations_0005_dues_ledger_sql, map[string]*_bintree_t{
+				}},
+				"0006_events_calendar.sql": &_bintree_t{res_sqlite_migrations_0006_events_calendar_sql, map[string]*_bintree_t{
+				}},
+				"0007_user_privacy.sql": &_bintree_t{res_sqlite_migrations_0007_user_privacy_sql, map[string]*_bintree_t{
+				}},
+				"0008_invitations.sql": &_bintree_t{res_sqlite_migrations_0008_invitations_sql, map[string]*_bintree_t{
+				}},
+				"0009_audit_log.sql": &_bintree_t{res_sqlite_migrations_0009_audit_log_sql, map[string]*_bintree_t{
+				}},
+				"0010_user_soft_delete.sql": &_bintree_t{res_sqlite_migrations_0010_user_soft_delete_sql, map[string]*_bintree_t{
+				}},
+				"0011_status_transitions.sql": &_bintree_t{res_sqlite_migrations_0011_status_transitions_sql, map[string]*_bintree_t{
+				}},
+			}},
 		}},
 	}},
 }}
@@ -0,0 +1,55 @@
+// Package blob provides binary large object storage for the Phi Mu Alpha
+// Sinfonia - Delta Iota chapter website.
+package blob
+
+import (
+	"errors"
+	"io"
+	"path"
+	"strings"
+)
+
+var (
+	// ErrNotExist is returned when a blob does not exist in a Store.
+	ErrNotExist = errors.New("blob: does not exist")
+
+	// ErrInvalidKey is returned when a key is not valid for use with a Store.
+	ErrInvalidKey = errors.New("blob: invalid key")
+)
+
+// Store provides storage and retrieval of blobs by key.  Keys are slash-separated
+// relative paths, such as "avatars/1/128".
+type Store interface {
+	// Put stores the contents of the input io.Reader under the input key,
+	// replacing any existing blob.
+	Put(key string, r io.Reader) error
+
+	// Get returns an io.ReadCloser for the blob stored under the input key.
+	// If no blob exists, ErrNotExist is returned.
+	Get(key string) (io.ReadCloser, error)
+
+	// Delete removes the blob stored under the input key.  If no blob
+	// exists, ErrNotExist is returned.
+	Delete(key string) error
+}
+
+// validKey returns whether or not an input key is a clean, relative path which
+// cannot escape the root of a Store.
+func validKey(key string) bool {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return false
+	}
+
+	// Key must already be in its cleanest form, and may not reference a
+	// parent directory
+	if path.Clean(key) != key {
+		return false
+	}
+	for _, p := range strings.Split(key, "/") {
+		if p == ".." {
+			return false
+		}
+	}
+
+	return true
+}
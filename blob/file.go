@@ -0,0 +1,112 @@
+package blob
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// FileStore is a Store which stores blobs as files within a directory on the
+// local filesystem.
+type FileStore struct {
+	dir string
+}
+
+// NewFileStore creates a new FileStore rooted at the input directory, creating
+// the directory if it does not exist.
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+
+	return &FileStore{
+		dir: dir,
+	}, nil
+}
+
+// Put stores the contents of the input io.Reader under the input key.  The blob
+// is written to a temporary file and renamed into place, so readers never observe
+// a partially written blob.
+func (s *FileStore) Put(key string, r io.Reader) error {
+	p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	// Create any parent directories for the blob
+	if err := os.MkdirAll(filepath.Dir(p), 0700); err != nil {
+		return err
+	}
+
+	// Write blob to temporary file in the same directory
+	f, err := ioutil.TempFile(filepath.Dir(p), ".blob")
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+
+	// Move completed blob into place
+	if err := os.Rename(f.Name(), p); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+
+	return nil
+}
+
+// Get returns an io.ReadCloser for the blob stored under the input key.
+func (s *FileStore) Get(key string) (io.ReadCloser, error) {
+	p, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(p)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNotExist
+		}
+
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// Delete removes the blob stored under the input key.
+func (s *FileStore) Delete(key string) error {
+	p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(p); err != nil {
+		if os.IsNotExist(err) {
+			return ErrNotExist
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// path returns the filesystem path for the input key, or ErrInvalidKey if the
+// key is not valid.
+func (s *FileStore) path(key string) (string, error) {
+	if !validKey(key) {
+		return "", ErrInvalidKey
+	}
+
+	return filepath.Join(s.dir, filepath.FromSlash(key)), nil
+}
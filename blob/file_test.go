@@ -0,0 +1,76 @@
+package blob
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+// TestFileStore verifies that FileStore can store, retrieve, and delete blobs.
+func TestFileStore(t *testing.T) {
+	withFileStore(t, func(s *FileStore) {
+		const key = "avatars/1/128"
+		data := []byte("hello world")
+
+		// Blob does not exist yet
+		if _, err := s.Get(key); err != ErrNotExist {
+			t.Fatalf("unexpected error: %v != %v", err, ErrNotExist)
+		}
+
+		// Store blob, and verify it can be retrieved
+		if err := s.Put(key, bytes.NewReader(data)); err != nil {
+			t.Fatal(err)
+		}
+
+		rc, err := s.Get(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		buf, err := ioutil.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(buf, data) {
+			t.Fatalf("unexpected blob: %v != %v", buf, data)
+		}
+
+		// Delete blob, and verify it no longer exists
+		if err := s.Delete(key); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.Delete(key); err != ErrNotExist {
+			t.Fatalf("unexpected error: %v != %v", err, ErrNotExist)
+		}
+	})
+}
+
+// TestFileStoreInvalidKey verifies that FileStore rejects keys which could
+// escape its root directory.
+func TestFileStoreInvalidKey(t *testing.T) {
+	withFileStore(t, func(s *FileStore) {
+		for _, key := range []string{"", "/etc/passwd", "../foo", "foo/../../bar", "foo//bar", "foo\\bar", "foo/"} {
+			if err := s.Put(key, bytes.NewReader(nil)); err != ErrInvalidKey {
+				t.Fatalf("%q: unexpected error: %v != %v", key, err, ErrInvalidKey)
+			}
+		}
+	})
+}
+
+// withFileStore creates a FileStore within a temporary directory, invokes an
+// input closure, and removes the directory once the closure returns.
+func withFileStore(t *testing.T, fn func(s *FileStore)) {
+	dir, err := ioutil.TempDir("", "blob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s, err := NewFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fn(s)
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+)
+
+// configCommands are the subcommands of the config command, by name.
+var configCommands = map[string]*command{
+	"print": {"[-json]", "print the effective configuration, with secrets masked", configPrint},
+}
+
+// configPrint prints the effective configuration as TOML, or as JSON if
+// specified.  Secrets are masked, so the output may be shared safely.
+func configPrint(ctx context.Context, fs *flag.FlagSet, args []string) error {
+	asJSON := fs.Bool("json", false, "print the configuration as JSON instead of TOML")
+	if err := parseArgs(fs, args, 0); err != nil {
+		return err
+	}
+
+	var b []byte
+	var err error
+	if *asJSON {
+		b, err = json.MarshalIndent(cfg.Masked(), "", "\t")
+		b = append(b, '\n')
+	} else {
+		b, err = cfg.Masked().TOML()
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = os.Stdout.Write(b)
+	return err
+}
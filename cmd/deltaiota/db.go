@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/mdlayher/deltaiota/data"
+)
+
+// dbCommands are the subcommands of the db command, by name.
+var dbCommands = map[string]*command{
+	"check": {"", "check the database's integrity, and report pending schema migrations", dbCheck},
+}
+
+// migrate creates the database if needed, and applies all pending schema
+// migrations.
+func migrate(ctx context.Context, fs *flag.FlagSet, args []string) error {
+	if err := parseArgs(fs, args, 0); err != nil {
+		return err
+	}
+
+	if driver == sqlite3 {
+		created, err := sqlite3Setup(cfg.Database.DSN)
+		if err != nil {
+			return err
+		}
+		if created {
+			log.Println("deltaiota: created sqlite3 database:", cfg.Database.DSN)
+		}
+	}
+
+	didb, err := openDB()
+	if err != nil {
+		return err
+	}
+	defer didb.Close()
+
+	migrations, err := didb.Migrate(ctx)
+	if err != nil {
+		return err
+	}
+	for _, m := range migrations {
+		log.Printf("deltaiota: applied migration: %04d_%s", m.Version, m.Name)
+	}
+	if len(migrations) == 0 {
+		log.Println("deltaiota: no pending migrations")
+	}
+
+	return nil
+}
+
+// backup writes a backup of the database to the backup directory, and removes
+// the oldest backups beyond the retention limit.
+func backup(ctx context.Context, fs *flag.FlagSet, args []string) error {
+	if err := parseArgs(fs, args, 0); err != nil {
+		return err
+	}
+
+	didb, err := openDB()
+	if err != nil {
+		return err
+	}
+	defer didb.Close()
+
+	p, err := didb.BackupToDir(ctx, cfg.Backup.Dir, cfg.Backup.Keep)
+	if err != nil {
+		return err
+	}
+
+	log.Println("deltaiota: created backup:", p)
+	return nil
+}
+
+// restore checks the integrity of the input backup, and then replaces the
+// contents of the database with the backup.  The server must not be running
+// while the database is restored.
+func restore(ctx context.Context, fs *flag.FlagSet, args []string) error {
+	if err := parseArgs(fs, args, 1); err != nil {
+		return err
+	}
+
+	backup := fs.Arg(0)
+	if err := data.Restore(ctx, backup, cfg.Database.DSN); err != nil {
+		return err
+	}
+
+	log.Printf("deltaiota: restored backup %s to database: %s", backup, cfg.Database.DSN)
+	return nil
+}
+
+// dbCheck checks the integrity of the database, and reports any pending
+// schema migrations.  An error is returned if either check fails.
+func dbCheck(ctx context.Context, fs *flag.FlagSet, args []string) error {
+	if err := parseArgs(fs, args, 0); err != nil {
+		return err
+	}
+
+	didb, err := openDB()
+	if err != nil {
+		return err
+	}
+	defer didb.Close()
+
+	if err := didb.IntegrityCheck(ctx); err != nil {
+		return err
+	}
+	fmt.Println("integrity: ok")
+
+	pending, err := didb.PendingMigrations(ctx)
+	if err != nil {
+		return err
+	}
+	for _, m := range pending {
+		fmt.Printf("migration pending: %04d_%s\n", m.Version, m.Name)
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("deltaiota: %d schema migration(s) pending", len(pending))
+	}
+	fmt.Println("migrations: ok")
+
+	return nil
+}
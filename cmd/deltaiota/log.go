@@ -0,0 +1,28 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+
+	"github.com/mdlayher/deltaiota/config"
+)
+
+// setLogger sets the default slog.Logger, which also receives the output of
+// the standard logger, to write entries at or above the configured level to
+// stderr as logfmt or JSON.
+func setLogger(c config.Log) error {
+	level, err := c.SlogLevel()
+	if err != nil {
+		return err
+	}
+
+	opts := &slog.HandlerOptions{Level: level}
+
+	var h slog.Handler = slog.NewTextHandler(os.Stderr, opts)
+	if c.Format == "json" {
+		h = slog.NewJSONHandler(os.Stderr, opts)
+	}
+
+	slog.SetDefault(slog.New(h))
+	return nil
+}
@@ -1,24 +1,24 @@
 // Command deltaiota serves a HTTP API for the Phi Mu Alpha Sinfonia - Delta
-// Iota chapter website.
+// Iota chapter website.  It also provides commands which administer the
+// database directly, so that an instance may be managed and recovered without
+// the HTTP API.  Run "deltaiota -help" for a list of commands.
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"log"
-	"net"
-	"net/http"
 	"os"
 	"path"
-	"time"
+	"sort"
+	"strings"
 
-	"github.com/mdlayher/deltaiota/api"
-	"github.com/mdlayher/deltaiota/bindata"
+	"github.com/mdlayher/deltaiota/api/auth"
+	"github.com/mdlayher/deltaiota/api/util"
+	"github.com/mdlayher/deltaiota/api/v0"
+	"github.com/mdlayher/deltaiota/config"
 	"github.com/mdlayher/deltaiota/data"
-	"github.com/mdlayher/deltaiota/data/models"
-	"github.com/mdlayher/deltaiota/ditest"
-
-	"github.com/stretchr/graceful"
 )
 
 const (
@@ -36,142 +36,217 @@ const (
 var version string
 
 var (
-	// db is the DSN used for the database instance
-	db string
-
-	// host is the address to which the HTTP server is bound
-	host string
+	// configPath is the path to a TOML or JSON configuration file
+	configPath string
 
-	// noRoot disables creation of a root account on database creation
-	noRoot bool
-
-	// timeout is the duration the server will wait before forcibly closing
-	// ongoing HTTP connections
-	timeout time.Duration
+	// cfg is the effective configuration, loaded from defaults, the
+	// configuration file, environment variables, and flags
+	cfg *config.Config
 )
 
 func init() {
-	// Set up flags
-	flag.StringVar(&db, "db", "deltaiota.db", "DSN for database instance")
-	flag.StringVar(&host, "host", ":1898", "HTTP server host")
-	flag.BoolVar(&noRoot, "no-root", false, "disable creation of root account for new database")
-	flag.DurationVar(&timeout, "timeout", 5*time.Second, "HTTP graceful timeout duration")
+	// Set up flags; every configuration field has a flag, which overrides
+	// the configuration file and environment variables
+	flag.StringVar(&configPath, "config", "", "path to TOML or JSON configuration file (default $"+config.EnvPrefix+"CONFIG)")
+	config.RegisterFlags(flag.CommandLine)
 }
 
 func main() {
 	// Parse all flags
+	flag.Usage = usage
 	flag.Parse()
 
-	// Report information on startup
-	log.Println(fmt.Sprintf("deltaiota: starting [pid: %d] [version: %s]", os.Getpid(), version))
-
-	// Determine if database newly created
-	var created bool
+	// Load configuration, using the configuration file from the environment
+	// if not specified by flag
+	if configPath == "" {
+		configPath = os.Getenv(config.EnvPrefix + "CONFIG")
+	}
 	var err error
+	cfg, err = config.Load(configPath, os.LookupEnv, flag.CommandLine)
+	if err != nil {
+		log.Fatal(err)
+	}
 
-	// If database is sqlite3, perform initial setup
-	if driver == sqlite3 {
-		// Attempt setup, check if already created
-		created, err = sqlite3Setup(db)
-		if err != nil {
-			log.Fatal(err)
-		}
+	// Set up structured log output in the configured format
+	if err := setLogger(cfg.Log); err != nil {
+		log.Fatal(err)
+	}
 
-		if created {
-			log.Println("deltaiota: created sqlite3 database:", db)
-		} else {
-			log.Println("deltaiota: using sqlite3 database:", db)
-		}
+	// Bound the duration of database queries for each API request
+	util.QueryTimeout = cfg.Server.QueryTimeout.Duration
+
+	// Set the duration of new and extended sessions
+	auth.SessionDuration = cfg.Session.Duration.Duration
+
+	// Report the server's version using the API
+	v0.Version = version
+
+	// Configure backups created using the API
+	v0.BackupDir = cfg.Backup.Dir
+	v0.BackupRetention = cfg.Backup.Keep
+	v0.BackupTimeout = cfg.Backup.Timeout.Duration
+
+	// Serve the API unless another command is specified
+	args := flag.Args()
+	if len(args) == 0 {
+		args = []string{"serve"}
 	}
 
-	// Open database connection
-	didb := &data.DB{}
-	if err := didb.Open(driver, db); err != nil {
+	if err := dispatch(context.Background(), "", commands, args); err != nil {
 		log.Fatal(err)
 	}
+}
 
-	// Unless skipped, perform initial root user setup for sqlite3
-	if driver == sqlite3 && created && !noRoot {
-		// Generate root user
-		root := &models.User{
-			Username: "root",
-		}
+// A command is a deltaiota command, which operates on the database.
+type command struct {
+	// usage describes the command's flags and arguments, and help describes
+	// what the command does
+	usage string
+	help  string
 
-		// Generate a random password
-		password := ditest.RandomString(12)
-		log.Println("deltaiota: creating root user: root /", password)
-		if err := root.SetPassword(password); err != nil {
-			log.Fatal(err)
-		}
+	// run runs the command with the arguments which follow its name, which
+	// are parsed using fs once the command defines any flags of its own
+	run func(ctx context.Context, fs *flag.FlagSet, args []string) error
+}
+
+// commands is the set of all top-level commands, by name.  It is populated
+// in init, since usage refers to it.
+var commands map[string]*command
+
+func init() {
+	commands = map[string]*command{
+		"serve":   {"", "serve the HTTP API (default)", serve},
+		"migrate": {"", "create the database if needed, and apply pending schema migrations", migrate},
+		"backup":  {"", "back up the database to the backup directory", backup},
+		"restore": {"<backup>", "check a backup's integrity, and restore it to the database", restore},
+		"user": {"", "manage users", func(ctx context.Context, fs *flag.FlagSet, args []string) error {
+			return dispatch(ctx, "user", userCommands, args)
+		}},
+		"session": {"", "manage sessions", func(ctx context.Context, fs *flag.FlagSet, args []string) error {
+			return dispatch(ctx, "session", sessionCommands, args)
+		}},
+		"notify": {"", "manage notifications", func(ctx context.Context, fs *flag.FlagSet, args []string) error {
+			return dispatch(ctx, "notify", notifyCommands, args)
+		}},
+		"db": {"", "inspect the database", func(ctx context.Context, fs *flag.FlagSet, args []string) error {
+			return dispatch(ctx, "db", dbCommands, args)
+		}},
+		"config": {"", "inspect the configuration", func(ctx context.Context, fs *flag.FlagSet, args []string) error {
+			return dispatch(ctx, "config", configCommands, args)
+		}},
+	}
+}
+
+// dispatch runs the command in cmds named by the first argument, passing it
+// the remaining arguments.  parent is the name of the command which owns cmds,
+// or empty for top-level commands.
+func dispatch(ctx context.Context, parent string, cmds map[string]*command, args []string) error {
+	if len(args) == 0 {
+		usage()
+		return fmt.Errorf("deltaiota: %s: no command specified", parent)
+	}
 
-		// Save root user
-		if err := didb.InsertUser(root); err != nil {
-			log.Fatal(err)
+	name := strings.TrimSpace(parent + " " + args[0])
+	cmd, ok := cmds[args[0]]
+	if !ok {
+		usage()
+		return fmt.Errorf("deltaiota: unknown command: %q", name)
+	}
+
+	return cmd.run(ctx, commandFlags(name, cmd), args[1:])
+}
+
+// usage prints usage information for all flags and commands.
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: deltaiota [flags] [command]\n\ncommands:\n")
+
+	// Print top-level commands, followed by any subcommands
+	subcommands := map[string]map[string]*command{
+		"user":    userCommands,
+		"session": sessionCommands,
+		"notify":  notifyCommands,
+		"db":      dbCommands,
+		"config":  configCommands,
+	}
+	for _, name := range sortedNames(commands) {
+		subs, ok := subcommands[name]
+		if !ok {
+			printCommand(name, commands[name])
+			continue
 		}
-	} else if noRoot {
-		log.Println("deltaiota: skipping creation of root user")
-	}
-
-	// Start HTTP server using deltaiota handler on specified host
-	log.Println("deltaiota: listening:", host)
-	if err := graceful.ListenAndServe(&http.Server{
-		Addr:    host,
-		Handler: api.NewServeMux(didb),
-	}, timeout); err != nil {
-		// Ignore error on failed "accept" when closing
-		if nErr, ok := err.(*net.OpError); !ok || nErr.Op != "accept" {
-			log.Fatal(err)
+
+		for _, sub := range sortedNames(subs) {
+			printCommand(name+" "+sub, subs[sub])
 		}
 	}
 
-	log.Println("deltaiota: shutting down")
+	fmt.Fprintf(os.Stderr, "\nflags:\n")
+	flag.PrintDefaults()
+}
 
-	// Close database connection
-	if err := didb.Close(); err != nil {
-		log.Fatal(err)
+// printCommand prints usage information for a single command.
+func printCommand(name string, cmd *command) {
+	fmt.Fprintf(os.Stderr, "  %s\n    \t%s\n", strings.TrimSpace(name+" "+cmd.usage), cmd.help)
+}
+
+// sortedNames returns the names of all input commands, sorted.
+func sortedNames(cmds map[string]*command) []string {
+	names := make([]string, 0, len(cmds))
+	for name := range cmds {
+		names = append(names, name)
 	}
 
-	log.Println("deltaiota: graceful shutdown complete")
+	sort.Strings(names)
+	return names
 }
 
-// sqlite3Setup performs setup routines specific to a sqlite3 database.
-// On success, it returns a boolean indicating if the database was created.
-// On failure, it returns an error.
-func sqlite3Setup(dsn string) (bool, error) {
-	// Check if database already exists at specified location
-	dbPath := path.Clean(dsn)
-	_, err := os.Stat(dbPath)
-	if err == nil {
-		// Database exists, skip setup
-		return false, nil
+// commandFlags returns a flag.FlagSet for the named command, which prints the
+// command's usage on error.  Flags must precede any other arguments.
+func commandFlags(name string, cmd *command) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: deltaiota [flags] %s\n", strings.TrimSpace(name+" "+cmd.usage))
+		fs.PrintDefaults()
 	}
 
-	// Any other errors, return now
-	if !os.IsNotExist(err) {
-		return false, err
-	}
+	return fs
+}
 
-	// Retrieve sqlite3 database schema asset
-	asset, err := bindata.Asset(sqlite3SchemaAsset)
-	if err != nil {
-		return false, err
+// parseArgs parses the input arguments using fs, and verifies that exactly n
+// arguments remain.
+func parseArgs(fs *flag.FlagSet, args []string, n int) error {
+	if err := fs.Parse(args); err != nil {
+		return err
 	}
 
-	// Open empty database file at target path
-	didb := &data.DB{}
-	if err := didb.Open(sqlite3, dbPath); err != nil {
-		return false, err
+	return checkArgs(fs, n)
+}
+
+// checkArgs verifies that exactly n arguments remain after parsing flags
+// using fs.
+func checkArgs(fs *flag.FlagSet, n int) error {
+	if fs.NArg() != n {
+		fs.Usage()
+		return fmt.Errorf("deltaiota: %s: expected %d argument(s), got %d", fs.Name(), n, fs.NArg())
 	}
 
-	// Execute schema to build database
-	if _, err := didb.Exec(string(asset)); err != nil {
-		return false, err
+	return nil
+}
+
+// openDB opens the existing database, for commands which operate directly on
+// it.
+func openDB() (*data.DB, error) {
+	// Do not create a new sqlite3 database, since it would lack a schema
+	if driver == sqlite3 {
+		if _, err := os.Stat(path.Clean(cfg.Database.DSN)); err != nil {
+			return nil, err
+		}
 	}
 
-	// Close database
-	if err := didb.Close(); err != nil {
-		return false, err
+	didb := &data.DB{}
+	if err := didb.Open(driver, cfg.Database.DSN, cfg.DBOptions()); err != nil {
+		return nil, err
 	}
 
-	return true, nil
+	return didb, nil
 }
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/mdlayher/deltaiota/data"
+	"github.com/mdlayher/deltaiota/data/models"
+)
+
+// notifyCommands are the subcommands of the notify command, by name.
+var notifyCommands = map[string]*command{
+	"send": {"[-uri u] [-all] [<username>] <text>", "send a notification to a user, or with -all, to all users", notifySend},
+}
+
+// notifySend sends a notification to a single user, or to all users which are
+// not deleted.
+func notifySend(ctx context.Context, fs *flag.FlagSet, args []string) error {
+	var all bool
+	var uri string
+
+	fs.BoolVar(&all, "all", false, "send the notification to all users")
+	fs.StringVar(&uri, "uri", "", "URI linked by the notification")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	// A username is only required when not notifying all users
+	n := 2
+	if all {
+		n = 1
+	}
+	if err := checkArgs(fs, n); err != nil {
+		return err
+	}
+
+	didb, err := openDB()
+	if err != nil {
+		return err
+	}
+	defer didb.Close()
+
+	var users []*models.User
+	if all {
+		users, err = didb.SelectAllUsers(ctx)
+		if err != nil {
+			return err
+		}
+	} else {
+		u, err := selectUser(ctx, didb, fs.Arg(0))
+		if err != nil {
+			return err
+		}
+
+		users = []*models.User{u}
+	}
+
+	// Send all notifications, or none
+	now := uint64(time.Now().Unix())
+	err = didb.WithTx(ctx, func(tx *data.Tx) error {
+		for _, u := range users {
+			if err := tx.InsertNotification(ctx, &models.Notification{
+				UserID:    u.ID,
+				Timestamp: now,
+				Text:      fs.Arg(fs.NArg() - 1),
+				URI:       uri,
+			}); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("sent %d notification(s)\n", len(users))
+	return nil
+}
@@ -0,0 +1,416 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/mdlayher/deltaiota/api"
+	"github.com/mdlayher/deltaiota/api/util"
+	"github.com/mdlayher/deltaiota/bindata"
+	"github.com/mdlayher/deltaiota/blob"
+	"github.com/mdlayher/deltaiota/data"
+	"github.com/mdlayher/deltaiota/data/models"
+	"github.com/mdlayher/deltaiota/ditest"
+	"github.com/mdlayher/deltaiota/mailer"
+	"github.com/mdlayher/deltaiota/tlsutil"
+
+	"github.com/stretchr/graceful"
+)
+
+// serve serves the HTTP API, and performs background work such as dues
+// notifications and invitation delivery.  If the database does not exist, it
+// is created, along with a root user unless disabled.
+func serve(ctx context.Context, fs *flag.FlagSet, args []string) error {
+	if err := parseArgs(fs, args, 0); err != nil {
+		return err
+	}
+
+	// Report information on startup
+	log.Println(fmt.Sprintf("deltaiota: starting [pid: %d] [version: %s]", os.Getpid(), version))
+
+	// Determine if database newly created
+	var created bool
+	var err error
+
+	// DSN used to open the database
+	db := cfg.Database.DSN
+	dsn := db
+
+	// If database is sqlite3, perform initial setup
+	if driver == sqlite3 && cfg.Server.Readonly {
+		// A read-only database cannot be created, so it must already exist
+		if _, err := os.Stat(path.Clean(db)); err != nil {
+			return err
+		}
+
+		dsn = "file:" + db + "?mode=ro"
+		log.Println("deltaiota: using read-only sqlite3 database:", db)
+	} else if driver == sqlite3 {
+		// Attempt setup, check if already created
+		created, err = sqlite3Setup(db)
+		if err != nil {
+			return err
+		}
+
+		if created {
+			log.Println("deltaiota: created sqlite3 database:", db)
+		} else {
+			log.Println("deltaiota: using sqlite3 database:", db)
+		}
+	}
+
+	// Open database connection
+	didb := &data.DB{}
+	if err := didb.Open(driver, dsn, cfg.DBOptions()); err != nil {
+		return err
+	}
+
+	// Apply any pending schema migrations, or verify that a read-only database
+	// needs none
+	migrations, err := migrateSchema(ctx, didb)
+	if err != nil {
+		return err
+	}
+	for _, m := range migrations {
+		log.Printf("deltaiota: applied migration: %04d_%s", m.Version, m.Name)
+	}
+
+	// Unless skipped, perform initial root user setup for sqlite3
+	if driver == sqlite3 && created && !cfg.Database.NoRoot {
+		// Generate root user
+		root := &models.User{
+			Username: "root",
+			Status:   models.StatusActive,
+		}
+
+		// Generate a random password, which is never logged; administrators
+		// set their own using the command line
+		if err := root.SetPassword(ditest.RandomString(12)); err != nil {
+			return err
+		}
+
+		// Save root user
+		if err := didb.InsertUser(ctx, root); err != nil {
+			return err
+		}
+
+		log.Println("deltaiota: created root user, set its password using: deltaiota user passwd root")
+	} else if cfg.Database.NoRoot {
+		log.Println("deltaiota: skipping creation of root user")
+	}
+
+	// Officer access is only granted by an active term, so the first officer
+	// must be appointed using the command line
+	hasOfficers, err := didb.HasOfficers(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+	if !hasOfficers {
+		log.Println("deltaiota: no officers appointed, appoint one using: deltaiota user set-role -create <username> <position>")
+	}
+
+	// Open storage for uploaded files
+	store, err := blob.NewFileStore(cfg.Server.Blobs)
+	if err != nil {
+		return err
+	}
+	log.Println("deltaiota: using blob storage:", cfg.Server.Blobs)
+
+	// Background work modifies the database, so it cannot be performed when
+	// the database is read-only
+	duesInterval := cfg.Dues.Interval.Duration
+	inviteInterval := cfg.Invite.Interval.Duration
+	if didb.Readonly() {
+		log.Println("deltaiota: read-only mode, rejecting API changes and skipping dues notifications and invitations")
+		duesInterval = 0
+		inviteInterval = 0
+	}
+
+	// Background work is stopped, and must finish, before the database is
+	// closed on shutdown
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var wg sync.WaitGroup
+
+	// Periodically notify users of upcoming and overdue dues charges
+	if duesInterval > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			notifyDues(ctx, didb, duesInterval, cfg.Dues.Window.Duration)
+		}()
+	}
+
+	// Periodically mail queued invitations
+	if inviteInterval > 0 {
+		var m mailer.Mailer = &mailer.LogMailer{}
+		if s := cfg.SMTP; s.Host != "" {
+			sm, err := mailer.NewSMTPMailer(s.Host, s.From, s.User, s.Password)
+			if err != nil {
+				return err
+			}
+			m = sm
+
+			log.Println("deltaiota: using SMTP server:", s.Host)
+		} else {
+			log.Println("deltaiota: no SMTP server configured, logging email")
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			deliverInvitations(ctx, didb, m, inviteInterval)
+		}()
+	}
+
+	// Limit the rate of requests from each client, if configured
+	var handler http.Handler = api.NewServeMux(didb, store)
+	if rl := cfg.RateLimit; rl.Rate > 0 {
+		handler = util.NewRateLimiter(rl.Rate, rl.Burst).Handler(handler)
+		log.Printf("deltaiota: limiting clients to %v request(s) per second, with bursts of %d", rl.Rate, rl.Burst)
+	}
+
+	// Start HTTP server using deltaiota handler on specified host, using TLS
+	// if configured
+	server := &http.Server{
+		Addr:    cfg.Server.Host,
+		Handler: handler,
+	}
+	if cfg.TLS.Enabled() {
+		err = serveTLS(ctx, server)
+	} else {
+		log.Println("deltaiota: listening:", cfg.Server.Host)
+		err = graceful.ListenAndServe(server, cfg.Server.Timeout.Duration)
+	}
+	if err != nil {
+		// Ignore error on failed "accept" when closing
+		if nErr, ok := err.(*net.OpError); !ok || nErr.Op != "accept" {
+			return err
+		}
+	}
+
+	log.Println("deltaiota: shutting down")
+
+	// Stop background work
+	cancel()
+	wg.Wait()
+
+	// Close database connection
+	if err := didb.Close(); err != nil {
+		return err
+	}
+
+	log.Println("deltaiota: graceful shutdown complete")
+	return nil
+}
+
+// migrateSchema applies all pending schema migrations to the database, and
+// returns the migrations which were applied.  A read-only database cannot be
+// migrated, and a server could not authenticate users using a database which
+// is behind its schema, so a read-only database with pending migrations, such
+// as a replica whose primary is being migrated, is an error.
+func migrateSchema(ctx context.Context, didb *data.DB) ([]*data.Migration, error) {
+	if !didb.Readonly() {
+		return didb.Migrate(ctx)
+	}
+
+	pending, err := didb.PendingMigrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(pending) > 0 {
+		return nil, fmt.Errorf("deltaiota: read-only database has %d pending schema migration(s), and must be migrated before it is served", len(pending))
+	}
+
+	return nil, nil
+}
+
+// serveTLS serves the HTTP server over TLS, reloading the certificate on SIGHUP
+// and when its files change.  If configured, HTTP requests are redirected to
+// HTTPS by a second server, and HTTPS responses enable HSTS.
+func serveTLS(ctx context.Context, server *http.Server) error {
+	r, err := tlsutil.NewReloader(cfg.TLS.Cert, cfg.TLS.Key)
+	if err != nil {
+		return err
+	}
+	log.Println("deltaiota: using TLS certificate:", cfg.TLS.Cert)
+
+	// Reload the certificate on request, or once its files change
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		for range hup {
+			if err := r.Reload(); err != nil {
+				log.Println("deltaiota: reloading TLS certificate:", err)
+				continue
+			}
+
+			log.Println("deltaiota: reloaded TLS certificate:", cfg.TLS.Cert)
+		}
+	}()
+	if interval := cfg.TLS.ReloadInterval.Duration; interval > 0 {
+		go r.Watch(interval, ctx.Done())
+	}
+
+	// Redirect HTTP requests to HTTPS
+	if cfg.TLS.Redirect != "" {
+		go func() {
+			log.Println("deltaiota: redirecting to HTTPS:", cfg.TLS.Redirect)
+			if err := graceful.ListenAndServe(&http.Server{
+				Addr:    cfg.TLS.Redirect,
+				Handler: util.RedirectHandler(cfg.Server.Host),
+			}, cfg.Server.Timeout.Duration); err != nil {
+				log.Println("deltaiota: HTTPS redirect:", err)
+			}
+		}()
+	}
+
+	server.Handler = util.HSTSHandler(server.Handler, cfg.TLS.HSTS.Duration)
+
+	l, err := net.Listen("tcp", server.Addr)
+	if err != nil {
+		return err
+	}
+
+	log.Println("deltaiota: listening (TLS):", server.Addr)
+	return graceful.Serve(server, tls.NewListener(l, tlsutil.Config(r)), cfg.Server.Timeout.Duration)
+}
+
+// notifyDues notifies users of upcoming and overdue dues charges once immediately,
+// and then at each interval, until the input context is canceled.  Charges due
+// within the input window produce a reminder.
+func notifyDues(ctx context.Context, didb *data.DB, interval time.Duration, window time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		notifications, err := didb.NotifyCharges(ctx, time.Now(), window)
+		if err != nil {
+			log.Println("deltaiota: dues notifications:", err)
+		} else if len(notifications) > 0 {
+			log.Printf("deltaiota: sent %d dues notification(s)", len(notifications))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// deliverInvitations mails any queued invitations once immediately, and then at
+// each interval, until the input context is canceled.  Invitations which fail to
+// send are retried on the next run.
+func deliverInvitations(ctx context.Context, didb *data.DB, m mailer.Mailer, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		invitations, err := didb.SelectUnsentInvitations(ctx)
+		if err != nil {
+			log.Println("deltaiota: invitations:", err)
+		}
+
+		var sent int
+		for _, i := range invitations {
+			// Expired invitations can no longer be accepted
+			if i.IsExpired() {
+				continue
+			}
+
+			if err := m.Send(invitationMessage(i)); err != nil {
+				log.Printf("deltaiota: invitation %d: %v", i.ID, err)
+				continue
+			}
+
+			i.Sent = uint64(time.Now().Unix())
+			if err := didb.UpdateInvitation(ctx, i); err != nil {
+				log.Printf("deltaiota: invitation %d: %v", i.ID, err)
+				continue
+			}
+
+			sent++
+		}
+
+		if sent > 0 {
+			log.Printf("deltaiota: sent %d invitation(s)", sent)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// invitationMessage generates an email message for an invitation.
+func invitationMessage(i *models.Invitation) *mailer.Message {
+	return &mailer.Message{
+		To:      i.Email,
+		Subject: "Your Phi Mu Alpha Sinfonia - Delta Iota account",
+		Body: fmt.Sprintf(`Hello,
+
+You have been invited to set up your account on the Phi Mu Alpha Sinfonia -
+Delta Iota chapter website.  To choose your username and password, visit:
+
+%s%s
+
+This invitation expires on %s.
+`, cfg.Invite.URL, i.Token, time.Unix(int64(i.Expire), 0).Format("January 2, 2006")),
+	}
+}
+
+// sqlite3Setup performs setup routines specific to a sqlite3 database.
+// On success, it returns a boolean indicating if the database was created.
+// On failure, it returns an error.
+func sqlite3Setup(dsn string) (bool, error) {
+	// Check if database already exists at specified location
+	dbPath := path.Clean(dsn)
+	_, err := os.Stat(dbPath)
+	if err == nil {
+		// Database exists, skip setup
+		return false, nil
+	}
+
+	// Any other errors, return now
+	if !os.IsNotExist(err) {
+		return false, err
+	}
+
+	// Retrieve sqlite3 database schema asset
+	asset, err := bindata.Asset(sqlite3SchemaAsset)
+	if err != nil {
+		return false, err
+	}
+
+	// Open empty database file at target path
+	didb := &data.DB{}
+	if err := didb.Open(sqlite3, dbPath, cfg.DBOptions()); err != nil {
+		return false, err
+	}
+
+	// Execute schema to build database
+	if _, err := didb.Exec(string(asset)); err != nil {
+		return false, err
+	}
+
+	// Close database
+	if err := didb.Close(); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
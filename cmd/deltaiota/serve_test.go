@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mdlayher/deltaiota/data"
+	"github.com/mdlayher/deltaiota/ditest"
+	"github.com/mdlayher/deltaiota/mailer"
+)
+
+// Test_migrateSchemaReadonly verifies that migrateSchema accepts a read-only
+// database whose schema is current.
+func Test_migrateSchemaReadonly(t *testing.T) {
+	ditest.WithTemporaryReadonlyDB(t, nil, func(t *testing.T, db *data.DB) {
+		migrations, err := migrateSchema(context.Background(), db)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(migrations) != 0 {
+			t.Fatalf("unexpected migrations applied: %d", len(migrations))
+		}
+	})
+}
+
+// Test_migrateSchemaReadonlyPending verifies that migrateSchema refuses a
+// read-only database which is behind its schema.
+func Test_migrateSchemaReadonlyPending(t *testing.T) {
+	ditest.WithTemporaryUnmigratedReadonlyDB(t, func(t *testing.T, db *data.DB) {
+		if _, err := migrateSchema(context.Background(), db); err == nil {
+			t.Fatal("expected an error, but none occurred")
+		}
+	})
+}
+
+// Test_backgroundWorkCanceled verifies that background work run by the server
+// stops once its context is canceled, rather than waiting for its next interval.
+func Test_backgroundWorkCanceled(t *testing.T) {
+	ditest.WithTemporaryDBNew(t, func(t *testing.T, db *data.DB) {
+		var tests = []struct {
+			name string
+			fn   func(ctx context.Context)
+		}{
+			{"notifyDues", func(ctx context.Context) {
+				notifyDues(ctx, db, time.Hour, time.Hour)
+			}},
+			{"deliverInvitations", func(ctx context.Context) {
+				deliverInvitations(ctx, db, &mailer.LogMailer{}, time.Hour)
+			}},
+		}
+
+		for i, test := range tests {
+			ctx, cancel := context.WithCancel(context.Background())
+
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				test.fn(ctx)
+			}()
+
+			cancel()
+
+			select {
+			case <-done:
+			case <-time.After(5 * time.Second):
+				t.Fatalf("[%02d] %s did not stop after cancelation", i, test.name)
+			}
+		}
+	})
+}
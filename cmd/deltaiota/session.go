@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+)
+
+// sessionCommands are the subcommands of the session command, by name.
+var sessionCommands = map[string]*command{
+	"revoke": {"<username>", "revoke all of a user's sessions", sessionRevoke},
+}
+
+// sessionRevoke revokes all sessions belonging to a user.
+func sessionRevoke(ctx context.Context, fs *flag.FlagSet, args []string) error {
+	if err := parseArgs(fs, args, 1); err != nil {
+		return err
+	}
+
+	didb, err := openDB()
+	if err != nil {
+		return err
+	}
+	defer didb.Close()
+
+	u, err := selectUser(ctx, didb, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	if err := didb.DeleteSessionsByUserID(ctx, u.ID); err != nil {
+		return err
+	}
+
+	fmt.Println("revoked sessions for user:", u.Username)
+	return nil
+}
@@ -0,0 +1,327 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/mdlayher/deltaiota/data"
+	"github.com/mdlayher/deltaiota/data/models"
+)
+
+// userCommands are the subcommands of the user command, by name.
+var userCommands = map[string]*command{
+	"create":   {"[-email e] [-first f] [-last l] [-password p] [-status s] <username>", "create a user; a random password is generated and printed if none is specified", userCreate},
+	"list":     {"[-deleted] [-status s]", "list users", userList},
+	"passwd":   {"[-password p] <username>", "change a user's password and revoke their sessions; a random password is generated and printed if none is specified", userPasswd},
+	"delete":   {"<username>", "delete a user, so that they may later be restored using the API, and revoke their sessions", userDelete},
+	"set-role": {"[-create] [-remove] <username> <position>", "appoint a user to an officer position, or with -remove, end their term in it", userSetRole},
+}
+
+// userCreate creates a new user.
+func userCreate(ctx context.Context, fs *flag.FlagSet, args []string) error {
+	u := &models.User{}
+	var password string
+
+	fs.StringVar(&u.Email, "email", "", "email address")
+	fs.StringVar(&u.FirstName, "first", "", "first name")
+	fs.StringVar(&u.LastName, "last", "", "last name")
+	fs.StringVar(&password, "password", "", "password (empty to generate a random password)")
+	fs.StringVar((*string)(&u.Status), "status", string(models.StatusActive), "membership status")
+	if err := parseArgs(fs, args, 1); err != nil {
+		return err
+	}
+	u.Username = fs.Arg(0)
+
+	generated, err := setPassword(u, password)
+	if err != nil {
+		return err
+	}
+	if err := u.Validate(); err != nil {
+		return err
+	}
+
+	didb, err := openDB()
+	if err != nil {
+		return err
+	}
+	defer didb.Close()
+
+	// Check for conflicts, including deleted users, which may be restored
+	inUse, err := didb.UsernameInUse(ctx, u.Username)
+	if err != nil {
+		return err
+	}
+	if inUse {
+		return fmt.Errorf("deltaiota: username already in use: %q", u.Username)
+	}
+	inUse, err = didb.EmailInUse(ctx, u.Email)
+	if err != nil {
+		return err
+	}
+	if inUse {
+		return fmt.Errorf("deltaiota: email already in use: %q", u.Email)
+	}
+
+	if err := didb.InsertUser(ctx, u); err != nil {
+		return err
+	}
+
+	fmt.Printf("created user %d: %s\n", u.ID, u.Username)
+	if generated != "" {
+		fmt.Println("password:", generated)
+	}
+
+	return nil
+}
+
+// userList lists all users which are not deleted, or all deleted users.
+func userList(ctx context.Context, fs *flag.FlagSet, args []string) error {
+	var deleted bool
+	var status string
+
+	fs.BoolVar(&deleted, "deleted", false, "list deleted users which have not been purged")
+	fs.StringVar(&status, "status", "", "only list users with a membership status")
+	if err := parseArgs(fs, args, 0); err != nil {
+		return err
+	}
+
+	didb, err := openDB()
+	if err != nil {
+		return err
+	}
+	defer didb.Close()
+
+	var users []*models.User
+	if deleted {
+		users, err = didb.SelectDeletedUsers(ctx)
+	} else {
+		users, err = didb.SelectUsersByFilter(ctx, data.UserFilter{
+			Status: models.MemberStatus(status),
+		})
+	}
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tUSERNAME\tNAME\tEMAIL\tSTATUS")
+	for _, u := range users {
+		if deleted && status != "" && string(u.Status) != status {
+			continue
+		}
+
+		name := strings.TrimSpace(u.FirstName + " " + u.LastName)
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\n", u.ID, u.Username, name, u.Email, u.Status)
+	}
+
+	return tw.Flush()
+}
+
+// userPasswd changes a user's password, and revokes all of their sessions.
+func userPasswd(ctx context.Context, fs *flag.FlagSet, args []string) error {
+	var password string
+
+	fs.StringVar(&password, "password", "", "new password (empty to generate a random password)")
+	if err := parseArgs(fs, args, 1); err != nil {
+		return err
+	}
+
+	didb, err := openDB()
+	if err != nil {
+		return err
+	}
+	defer didb.Close()
+
+	u, err := selectUser(ctx, didb, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	generated, err := setPassword(u, password)
+	if err != nil {
+		return err
+	}
+
+	// Sessions created using the old password are no longer valid
+	err = didb.WithTx(ctx, func(tx *data.Tx) error {
+		if err := tx.DeleteSessionsByUserID(ctx, u.ID); err != nil {
+			return err
+		}
+
+		return tx.UpdateUser(ctx, u)
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("changed password for user:", u.Username)
+	if generated != "" {
+		fmt.Println("password:", generated)
+	}
+
+	return nil
+}
+
+// userDelete deletes a user, and revokes all of their sessions.  Like users
+// deleted using the API, the user may later be restored or purged.
+func userDelete(ctx context.Context, fs *flag.FlagSet, args []string) error {
+	if err := parseArgs(fs, args, 1); err != nil {
+		return err
+	}
+
+	didb, err := openDB()
+	if err != nil {
+		return err
+	}
+	defer didb.Close()
+
+	u, err := selectUser(ctx, didb, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	u.DeletedAt = uint64(time.Now().Unix())
+	err = didb.WithTx(ctx, func(tx *data.Tx) error {
+		if err := tx.DeleteSessionsByUserID(ctx, u.ID); err != nil {
+			return err
+		}
+
+		return tx.UpdateUser(ctx, u)
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("deleted user:", u.Username)
+	return nil
+}
+
+// userSetRole appoints a user to an officer position, starting a new term
+// immediately, or ends the user's current term in the position.
+func userSetRole(ctx context.Context, fs *flag.FlagSet, args []string) error {
+	var create, remove bool
+
+	fs.BoolVar(&create, "create", false, "create the position if it does not exist, such as to appoint the first officer")
+	fs.BoolVar(&remove, "remove", false, "end the user's current term in the position")
+	if err := parseArgs(fs, args, 2); err != nil {
+		return err
+	}
+
+	didb, err := openDB()
+	if err != nil {
+		return err
+	}
+	defer didb.Close()
+
+	u, err := selectUser(ctx, didb, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	// Find the position by name
+	positions, err := didb.SelectAllPositions(ctx)
+	if err != nil {
+		return err
+	}
+	var position *models.Position
+	var names []string
+	for _, p := range positions {
+		if strings.EqualFold(p.Name, fs.Arg(1)) {
+			position = p
+		}
+
+		names = append(names, p.Name)
+	}
+	if position == nil && create && !remove {
+		position = &models.Position{
+			Name: fs.Arg(1),
+		}
+		if err := didb.InsertPosition(ctx, position); err != nil {
+			return err
+		}
+
+		fmt.Println("created position:", position.Name)
+	}
+	if position == nil && len(names) == 0 {
+		return fmt.Errorf("deltaiota: no such position: %q (no positions exist)", fs.Arg(1))
+	}
+	if position == nil {
+		return fmt.Errorf("deltaiota: no such position: %q (positions: %s)", fs.Arg(1), strings.Join(names, ", "))
+	}
+
+	// Find the user's current term in the position, if any
+	now := time.Now()
+	terms, err := didb.SelectTermsByUserID(ctx, u.ID)
+	if err != nil {
+		return err
+	}
+	var current *models.Term
+	for _, t := range terms {
+		if t.PositionID == position.ID && t.IsActive(now) {
+			current = t
+			break
+		}
+	}
+
+	if remove {
+		if current == nil {
+			return fmt.Errorf("deltaiota: user %q does not hold position %q", u.Username, position.Name)
+		}
+
+		current.End = uint64(now.Unix())
+		if err := didb.UpdateTerm(ctx, current); err != nil {
+			return err
+		}
+
+		fmt.Printf("ended term of user %s as %s\n", u.Username, position.Name)
+		return nil
+	}
+
+	if current != nil {
+		return fmt.Errorf("deltaiota: user %q already holds position %q", u.Username, position.Name)
+	}
+
+	if err := didb.InsertTerm(ctx, &models.Term{
+		UserID:     u.ID,
+		PositionID: position.ID,
+		Start:      uint64(now.Unix()),
+	}); err != nil {
+		return err
+	}
+
+	fmt.Printf("appointed user %s as %s\n", u.Username, position.Name)
+	return nil
+}
+
+// selectUser returns the user which is not deleted with the input username.
+func selectUser(ctx context.Context, didb *data.DB, username string) (*models.User, error) {
+	u, err := didb.SelectUserByUsername(ctx, username)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("deltaiota: no such user: %q", username)
+	}
+
+	return u, err
+}
+
+// setPassword sets a user's password.  If password is empty, a random password
+// is generated, set, and returned.
+func setPassword(u *models.User, password string) (string, error) {
+	var generated string
+	if password == "" {
+		p, err := models.RandomPassword()
+		if err != nil {
+			return "", err
+		}
+
+		password = p
+		generated = p
+	}
+
+	return generated, u.SetPassword(password)
+}
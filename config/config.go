@@ -0,0 +1,508 @@
+// Package config provides configuration loading for the Phi Mu Alpha Sinfonia -
+// Delta Iota chapter website.  Configuration is read from defaults, which are
+// overridden by a TOML or JSON file, then by DELTAIOTA_* environment variables,
+// and finally by command-line flags.
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mdlayher/deltaiota/data"
+
+	"github.com/BurntSushi/toml"
+)
+
+const (
+	// EnvPrefix is the prefix of all environment variables which configure
+	// deltaiota.  Each variable is named using the prefix, the field's section,
+	// and the field's name, such as DELTAIOTA_SMTP_PASSWORD.
+	EnvPrefix = "DELTAIOTA_"
+
+	// masked replaces the value of non-empty secret fields when a Config is
+	// printed.
+	masked = "********"
+)
+
+var (
+	// ErrUnknownFormat is returned when a configuration file is neither TOML
+	// nor JSON, as determined by its extension.
+	ErrUnknownFormat = errors.New("config: file must have a .toml or .json extension")
+)
+
+// Config is the complete configuration for deltaiota.  Each field is tagged
+// with its name in configuration files, the name of its command-line flag,
+// and the flag's usage.  Fields tagged secret are masked when printed.
+type Config struct {
+	Server    Server    `toml:"server" json:"server"`
+	TLS       TLS       `toml:"tls" json:"tls"`
+	Database  Database  `toml:"database" json:"database"`
+	Backup    Backup    `toml:"backup" json:"backup"`
+	Session   Session   `toml:"session" json:"session"`
+	Dues      Dues      `toml:"dues" json:"dues"`
+	Invite    Invite    `toml:"invite" json:"invite"`
+	SMTP      SMTP      `toml:"smtp" json:"smtp"`
+	Log       Log       `toml:"log" json:"log"`
+	RateLimit RateLimit `toml:"rate_limit" json:"rate_limit"`
+}
+
+// Server configures the HTTP server.
+type Server struct {
+	Host         string   `toml:"host" json:"host" flag:"host" usage:"HTTP server host"`
+	Blobs        string   `toml:"blobs" json:"blobs" flag:"blobs" usage:"directory for uploaded file storage"`
+	Timeout      Duration `toml:"timeout" json:"timeout" flag:"timeout" usage:"HTTP graceful timeout duration"`
+	QueryTimeout Duration `toml:"query_timeout" json:"query_timeout" flag:"query-timeout" usage:"maximum duration of database queries for a single API request (0 to disable)"`
+	Readonly     bool     `toml:"readonly" json:"readonly" flag:"readonly" usage:"open an existing database in read-only mode, rejecting API requests which modify it"`
+}
+
+// TLS configures HTTPS.  If a certificate and key are set, the HTTP server is
+// served over TLS.
+type TLS struct {
+	Cert           string   `toml:"cert" json:"cert" flag:"tls-cert" usage:"TLS certificate file, enabling HTTPS (empty to serve HTTP)"`
+	Key            string   `toml:"key" json:"key" flag:"tls-key" usage:"TLS private key file"`
+	Redirect       string   `toml:"redirect" json:"redirect" flag:"tls-redirect" usage:"HTTP server host which redirects all requests to HTTPS (empty to disable)"`
+	HSTS           Duration `toml:"hsts" json:"hsts" flag:"hsts-max-age" usage:"duration clients must only connect using HTTPS, sent in the Strict-Transport-Security header (0 to disable)"`
+	ReloadInterval Duration `toml:"reload_interval" json:"reload_interval" flag:"tls-reload-interval" usage:"interval between checks for a changed TLS certificate, which is also reloaded on SIGHUP (0 to disable)"`
+}
+
+// Enabled returns whether or not TLS is enabled.
+func (t TLS) Enabled() bool {
+	return t.Cert != "" || t.Key != ""
+}
+
+// Database configures the database and its connections.
+type Database struct {
+	DSN         string   `toml:"dsn" json:"dsn" flag:"db" usage:"DSN for database instance"`
+	NoRoot      bool     `toml:"no_root" json:"no_root" flag:"no-root" usage:"disable creation of root account for new database"`
+	JournalMode string   `toml:"journal_mode" json:"journal_mode" flag:"db-journal-mode" usage:"sqlite3 journal mode (DELETE, TRUNCATE, PERSIST, MEMORY, WAL, or OFF)"`
+	Synchronous string   `toml:"synchronous" json:"synchronous" flag:"db-synchronous" usage:"sqlite3 synchronous level (OFF, NORMAL, FULL, or EXTRA)"`
+	BusyTimeout Duration `toml:"busy_timeout" json:"busy_timeout" flag:"db-busy-timeout" usage:"how long to wait for a locked database before failing"`
+	CacheSize   int      `toml:"cache_size" json:"cache_size" flag:"db-cache-size" usage:"sqlite3 page cache size per connection in KiB (0 for default)"`
+	MmapSize    int64    `toml:"mmap_size" json:"mmap_size" flag:"db-mmap-size" usage:"maximum bytes of the database accessed using memory-mapped I/O (0 to disable)"`
+}
+
+// Backup configures database backups.
+type Backup struct {
+	Dir     string   `toml:"dir" json:"dir" flag:"backup-dir" usage:"directory for database backups (empty to disable backups using the API)"`
+	Keep    int      `toml:"keep" json:"keep" flag:"backup-keep" usage:"number of database backups to keep (0 to keep all)"`
+	Timeout Duration `toml:"timeout" json:"timeout" flag:"backup-timeout" usage:"maximum duration of a database backup created using the API"`
+}
+
+// Session configures authentication sessions.
+type Session struct {
+	Duration Duration `toml:"duration" json:"duration" flag:"session-duration" usage:"duration a session may exist before expiring, extended on each use"`
+}
+
+// Dues configures dues notifications.
+type Dues struct {
+	Interval Duration `toml:"interval" json:"interval" flag:"dues-interval" usage:"interval between dues notification runs (0 to disable)"`
+	Window   Duration `toml:"window" json:"window" flag:"dues-window" usage:"how far in advance users are reminded of upcoming dues"`
+}
+
+// Invite configures invitation delivery.
+type Invite struct {
+	Interval Duration `toml:"interval" json:"interval" flag:"invite-interval" usage:"interval between invitation delivery runs (0 to disable)"`
+	URL      string   `toml:"url" json:"url" flag:"invite-url" usage:"URL prefix for invitation links, followed by the invitation token"`
+}
+
+// SMTP configures outgoing email.
+type SMTP struct {
+	Host     string `toml:"host" json:"host" flag:"smtp" usage:"SMTP server host:port for outgoing email (empty to log email instead)"`
+	From     string `toml:"from" json:"from" flag:"smtp-from" usage:"sender address for outgoing email"`
+	User     string `toml:"user" json:"user" flag:"smtp-user" usage:"SMTP server username (empty to disable authentication)"`
+	Password string `toml:"password" json:"password" flag:"smtp-password" usage:"SMTP server password" secret:"true"`
+}
+
+// Log configures log output.
+type Log struct {
+	Format string `toml:"format" json:"format" flag:"log-format" usage:"log output format (logfmt or json)"`
+	Level  string `toml:"level" json:"level" flag:"log-level" usage:"minimum level of log entries (debug, info, warn, or error)"`
+}
+
+// SlogLevel returns the slog.Level specified by the receiving Log.
+func (l Log) SlogLevel() (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(l.Level)); err != nil {
+		return 0, fmt.Errorf("config: invalid log level: %q", l.Level)
+	}
+
+	return level, nil
+}
+
+// RateLimit configures limits on the rate of API requests from each client.
+type RateLimit struct {
+	Rate  float64 `toml:"rate" json:"rate" flag:"rate-limit" usage:"maximum sustained API requests per second from each client (0 to disable)"`
+	Burst int     `toml:"burst" json:"burst" flag:"rate-limit-burst" usage:"maximum API requests from each client in a single burst"`
+}
+
+// Default returns a Config containing the default value of every field.
+func Default() *Config {
+	opts := data.DefaultOptions()
+
+	return &Config{
+		Server: Server{
+			Host:         ":1898",
+			Blobs:        "blobs",
+			Timeout:      Duration{5 * time.Second},
+			QueryTimeout: Duration{10 * time.Second},
+		},
+		TLS: TLS{
+			HSTS:           Duration{365 * 24 * time.Hour},
+			ReloadInterval: Duration{1 * time.Minute},
+		},
+		Database: Database{
+			DSN:         "deltaiota.db",
+			JournalMode: opts.JournalMode,
+			Synchronous: opts.Synchronous,
+			BusyTimeout: Duration{opts.BusyTimeout},
+			CacheSize:   opts.CacheSize,
+			MmapSize:    opts.MmapSize,
+		},
+		Backup: Backup{
+			Dir:     "backups",
+			Keep:    7,
+			Timeout: Duration{10 * time.Minute},
+		},
+		Session: Session{
+			Duration: Duration{7 * 24 * time.Hour},
+		},
+		Dues: Dues{
+			Interval: Duration{1 * time.Hour},
+			Window:   Duration{72 * time.Hour},
+		},
+		Invite: Invite{
+			Interval: Duration{1 * time.Minute},
+			URL:      "http://localhost:1898/invitations/",
+		},
+		SMTP: SMTP{
+			From: "Delta Iota <noreply@localhost>",
+		},
+		Log: Log{
+			Format: "logfmt",
+			Level:  "info",
+		},
+		RateLimit: RateLimit{
+			Burst: 20,
+		},
+	}
+}
+
+// Load returns a Config built from defaults, overridden in turn by the file
+// at the input path (if not empty), by environment variables found using the
+// input lookup function, typically os.LookupEnv, and by any flags which were
+// set in fs.  fs must have been set up using RegisterFlags and parsed.  The
+// resulting Config is validated.
+func Load(path string, lookup func(string) (string, bool), fs *flag.FlagSet) (*Config, error) {
+	c := Default()
+
+	if path != "" {
+		if err := c.loadFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := c.loadEnv(lookup); err != nil {
+		return nil, err
+	}
+
+	// Only flags which were explicitly set override other sources
+	var err error
+	fs.Visit(func(f *flag.Flag) {
+		if err != nil {
+			return
+		}
+
+		if v, ok := c.fields()[f.Name]; ok {
+			err = v.set(f.Value.String())
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// RegisterFlags registers a flag for each field of a Config with fs.  The
+// flags' values are only used to determine which flags were set when passed
+// to Load, so they need not be read directly.
+func RegisterFlags(fs *flag.FlagSet) {
+	c := Default()
+	for _, f := range c.walk() {
+		fs.Var(f, f.flag, f.usage)
+	}
+}
+
+// loadFile overrides the receiving Config with the contents of a TOML or JSON
+// file.  Unknown keys are an error, so that mistakes are not ignored.
+func (c *Config) loadFile(path string) error {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".toml":
+		md, err := toml.DecodeFile(path, c)
+		if err != nil {
+			return fmt.Errorf("config: %s: %v", path, err)
+		}
+
+		if undecoded := md.Undecoded(); len(undecoded) > 0 {
+			return fmt.Errorf("config: %s: unknown key: %s", path, undecoded[0])
+		}
+
+		return nil
+	case ".json":
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		d := json.NewDecoder(f)
+		d.DisallowUnknownFields()
+		if err := d.Decode(c); err != nil {
+			return fmt.Errorf("config: %s: %v", path, err)
+		}
+
+		return nil
+	default:
+		return ErrUnknownFormat
+	}
+}
+
+// loadEnv overrides the receiving Config with any environment variables found
+// using the input lookup function.
+func (c *Config) loadEnv(lookup func(string) (string, bool)) error {
+	for _, f := range c.walk() {
+		s, ok := lookup(f.env)
+		if !ok {
+			continue
+		}
+
+		if err := f.set(s); err != nil {
+			return fmt.Errorf("config: %s: %v", f.env, err)
+		}
+	}
+
+	return nil
+}
+
+// Validate verifies that all fields of the receiving Config are valid.
+func (c *Config) Validate() error {
+	if c.Server.Host == "" {
+		return errors.New("config: server host must not be empty")
+	}
+	if c.TLS.Enabled() && (c.TLS.Cert == "" || c.TLS.Key == "") {
+		return errors.New("config: TLS certificate and key must both be set")
+	}
+	if c.TLS.Redirect != "" && !c.TLS.Enabled() {
+		return errors.New("config: TLS redirect requires a TLS certificate and key")
+	}
+	if c.Database.DSN == "" {
+		return errors.New("config: database DSN must not be empty")
+	}
+	if err := c.DBOptions().Validate(); err != nil {
+		return err
+	}
+	if c.Backup.Keep < 0 {
+		return fmt.Errorf("config: invalid number of backups to keep: %d", c.Backup.Keep)
+	}
+	if c.Backup.Timeout.Duration <= 0 {
+		return fmt.Errorf("config: invalid backup timeout: %v", c.Backup.Timeout)
+	}
+	if c.Session.Duration.Duration <= 0 {
+		return fmt.Errorf("config: invalid session duration: %v", c.Session.Duration)
+	}
+	if c.Log.Format != "logfmt" && c.Log.Format != "json" {
+		return fmt.Errorf("config: invalid log format: %q", c.Log.Format)
+	}
+	if _, err := c.Log.SlogLevel(); err != nil {
+		return err
+	}
+	if c.RateLimit.Rate < 0 {
+		return fmt.Errorf("config: invalid rate limit: %v", c.RateLimit.Rate)
+	}
+	if c.RateLimit.Rate > 0 && c.RateLimit.Burst < 1 {
+		return fmt.Errorf("config: invalid rate limit burst: %d", c.RateLimit.Burst)
+	}
+
+	// No duration may be negative
+	for _, f := range c.walk() {
+		if d, ok := f.v.Interface().(Duration); ok && d.Duration < 0 {
+			return fmt.Errorf("config: invalid %s: %v", f.flag, d)
+		}
+	}
+
+	return nil
+}
+
+// DBOptions returns the data.Options specified by the receiving Config.
+func (c *Config) DBOptions() *data.Options {
+	return &data.Options{
+		JournalMode: c.Database.JournalMode,
+		Synchronous: c.Database.Synchronous,
+		BusyTimeout: c.Database.BusyTimeout.Duration,
+		CacheSize:   c.Database.CacheSize,
+		MmapSize:    c.Database.MmapSize,
+	}
+}
+
+// Masked returns a copy of the receiving Config, with the value of every
+// non-empty secret field masked, so that it may be displayed safely.
+func (c *Config) Masked() *Config {
+	m := *c
+	for _, f := range m.walk() {
+		if f.secret && f.v.String() != "" {
+			f.v.SetString(masked)
+		}
+	}
+
+	return &m
+}
+
+// TOML returns the TOML representation of the receiving Config.
+func (c *Config) TOML() ([]byte, error) {
+	var b bytes.Buffer
+	if err := toml.NewEncoder(&b).Encode(c); err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
+
+// Duration is a time.Duration which is represented in configuration files as
+// a string, such as "1h30m".
+type Duration struct {
+	time.Duration
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (d *Duration) UnmarshalText(b []byte) error {
+	v, err := time.ParseDuration(string(b))
+	if err != nil {
+		return err
+	}
+
+	d.Duration = v
+	return nil
+}
+
+// A field is a single field of a Config, which implements flag.Value so that
+// it may be set from a string.
+type field struct {
+	v      reflect.Value
+	flag   string
+	env    string
+	usage  string
+	secret bool
+}
+
+// walk returns every field of the receiving Config, in order.
+func (c *Config) walk() []*field {
+	var fields []*field
+
+	cv := reflect.ValueOf(c).Elem()
+	for i := 0; i < cv.NumField(); i++ {
+		section := cv.Type().Field(i).Tag.Get("toml")
+
+		sv := cv.Field(i)
+		for j := 0; j < sv.NumField(); j++ {
+			sf := sv.Type().Field(j)
+			fields = append(fields, &field{
+				v:      sv.Field(j),
+				flag:   sf.Tag.Get("flag"),
+				env:    EnvPrefix + strings.ToUpper(section+"_"+sf.Tag.Get("toml")),
+				usage:  sf.Tag.Get("usage"),
+				secret: sf.Tag.Get("secret") == "true",
+			})
+		}
+	}
+
+	return fields
+}
+
+// fields returns every field of the receiving Config, keyed by flag name.
+func (c *Config) fields() map[string]*field {
+	fields := make(map[string]*field)
+	for _, f := range c.walk() {
+		fields[f.flag] = f
+	}
+
+	return fields
+}
+
+// String implements flag.Value.
+func (f *field) String() string {
+	// The flag package may call String on a zero value
+	if f == nil || !f.v.IsValid() {
+		return ""
+	}
+
+	if d, ok := f.v.Interface().(Duration); ok {
+		return d.String()
+	}
+
+	return fmt.Sprint(f.v.Interface())
+}
+
+// IsBoolFlag implements the optional interface used by the flag package, so
+// that boolean flags may be set without a value.
+func (f *field) IsBoolFlag() bool {
+	return f.v.Kind() == reflect.Bool
+}
+
+// Set implements flag.Value.
+func (f *field) Set(s string) error {
+	return f.set(s)
+}
+
+// set parses the input string according to the field's type, and sets the
+// field's value.
+func (f *field) set(s string) error {
+	if d, ok := f.v.Addr().Interface().(*Duration); ok {
+		return d.UnmarshalText([]byte(s))
+	}
+
+	switch f.v.Kind() {
+	case reflect.String:
+		f.v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		f.v.SetBool(b)
+	case reflect.Int, reflect.Int64:
+		i, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return err
+		}
+		f.v.SetInt(i)
+	case reflect.Float64:
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return err
+		}
+		f.v.SetFloat(v)
+	default:
+		return fmt.Errorf("config: unsupported field type: %s", f.v.Type())
+	}
+
+	return nil
+}
@@ -0,0 +1,239 @@
+package config
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestLoadPrecedence verifies that Load applies defaults, then a file, then
+// environment variables, and then flags, each overriding the last.
+func TestLoadPrecedence(t *testing.T) {
+	path := writeFile(t, "deltaiota.toml", `
+[server]
+host = ":8080"
+blobs = "/srv/blobs"
+
+[database]
+dsn = "/srv/deltaiota.db"
+journal_mode = "DELETE"
+
+[session]
+duration = "24h"
+`)
+
+	env := map[string]string{
+		"DELTAIOTA_SERVER_BLOBS":    "/env/blobs",
+		"DELTAIOTA_DATABASE_DSN":    "/env/deltaiota.db",
+		"DELTAIOTA_RATE_LIMIT_RATE": "2.5",
+	}
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	RegisterFlags(fs)
+	if err := fs.Parse([]string{"-db", "/flag/deltaiota.db", "-readonly"}); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := Load(path, lookup(env), fs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = []struct {
+		got      interface{}
+		expected interface{}
+	}{
+		// File
+		{c.Server.Host, ":8080"},
+		{c.Database.JournalMode, "DELETE"},
+		{c.Session.Duration.Duration, 24 * time.Hour},
+		// Environment overrides file
+		{c.Server.Blobs, "/env/blobs"},
+		{c.RateLimit.Rate, 2.5},
+		// Flags override environment
+		{c.Database.DSN, "/flag/deltaiota.db"},
+		{c.Server.Readonly, true},
+		// Defaults
+		{c.Server.Timeout.Duration, 5 * time.Second},
+		{c.Database.Synchronous, "NORMAL"},
+		{c.RateLimit.Burst, 20},
+	}
+
+	for i, test := range tests {
+		if !reflect.DeepEqual(test.got, test.expected) {
+			t.Fatalf("[%02d] unexpected value: %v != %v", i, test.got, test.expected)
+		}
+	}
+}
+
+// TestLoadDefaults verifies that Load returns the default Config when no other
+// sources are used, and that unset flags do not override it.
+func TestLoadDefaults(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	RegisterFlags(fs)
+	if err := fs.Parse(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := Load("", lookup(nil), fs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(c, Default()) {
+		t.Fatalf("unexpected config: %+v != %+v", c, Default())
+	}
+}
+
+// TestLoadFileJSON verifies that Load reads JSON configuration files.
+func TestLoadFileJSON(t *testing.T) {
+	path := writeFile(t, "deltaiota.json", `{
+	"server": {"host": ":8080"},
+	"dues": {"interval": "30m"},
+	"smtp": {"password": "secret"}
+}`)
+
+	c, err := Load(path, lookup(nil), flag.NewFlagSet("test", flag.ContinueOnError))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c.Server.Host != ":8080" || c.Dues.Interval.Duration != 30*time.Minute || c.SMTP.Password != "secret" {
+		t.Fatalf("unexpected config: %+v", c)
+	}
+}
+
+// TestLoadErrors verifies that Load rejects invalid files, environment
+// variables, and values.
+func TestLoadErrors(t *testing.T) {
+	var tests = []struct {
+		name     string
+		contents string
+		env      map[string]string
+		err      string
+	}{
+		// Unknown keys in files
+		{"unknown.toml", "[server]\nport = 80\n", nil, "unknown key: server.port"},
+		{"unknown.json", `{"server": {"port": 80}}`, nil, "unknown field"},
+		// Unknown file format
+		{"deltaiota.yaml", "", nil, ErrUnknownFormat.Error()},
+		// Unparseable environment variable
+		{"", "", map[string]string{"DELTAIOTA_BACKUP_KEEP": "seven"}, "DELTAIOTA_BACKUP_KEEP"},
+		// Invalid values
+		{"", "", map[string]string{"DELTAIOTA_SERVER_HOST": ""}, "host must not be empty"},
+		{"", "", map[string]string{"DELTAIOTA_DATABASE_JOURNAL_MODE": "WAL; DROP TABLE users"}, "invalid journal mode"},
+		{"", "", map[string]string{"DELTAIOTA_BACKUP_KEEP": "-1"}, "invalid number of backups"},
+		{"", "", map[string]string{"DELTAIOTA_BACKUP_TIMEOUT": "0s"}, "invalid backup timeout"},
+		{"", "", map[string]string{"DELTAIOTA_SESSION_DURATION": "0s"}, "invalid session duration"},
+		{"", "", map[string]string{"DELTAIOTA_LOG_FORMAT": "xml"}, "invalid log format"},
+		{"", "", map[string]string{"DELTAIOTA_LOG_LEVEL": "verbose"}, "invalid log level"},
+		{"", "", map[string]string{"DELTAIOTA_RATE_LIMIT_RATE": "-1"}, "invalid rate limit"},
+		{"", "", map[string]string{"DELTAIOTA_RATE_LIMIT_RATE": "1", "DELTAIOTA_RATE_LIMIT_BURST": "0"}, "invalid rate limit burst"},
+		{"", "", map[string]string{"DELTAIOTA_DUES_WINDOW": "-1h"}, "invalid dues-window"},
+		{"", "", map[string]string{"DELTAIOTA_TLS_CERT": "cert.pem"}, "certificate and key must both be set"},
+		{"", "", map[string]string{"DELTAIOTA_TLS_REDIRECT": ":80"}, "redirect requires"},
+	}
+
+	for i, test := range tests {
+		var path string
+		if test.name != "" {
+			path = writeFile(t, test.name, test.contents)
+		}
+
+		_, err := Load(path, lookup(test.env), flag.NewFlagSet("test", flag.ContinueOnError))
+		if err == nil {
+			t.Fatalf("[%02d] expected error, but none occurred", i)
+		}
+		if !strings.Contains(err.Error(), test.err) {
+			t.Fatalf("[%02d] unexpected error: %v", i, err)
+		}
+	}
+}
+
+// TestMasked verifies that Config.Masked masks secrets in a copy of a Config,
+// and leaves the original unmodified.
+func TestMasked(t *testing.T) {
+	c := Default()
+	c.SMTP.User = "deltaiota"
+	c.SMTP.Password = "secret"
+
+	m := c.Masked()
+	if m.SMTP.Password != masked {
+		t.Fatalf("password not masked: %q", m.SMTP.Password)
+	}
+	if m.SMTP.User != "deltaiota" {
+		t.Fatalf("unexpected masked user: %q", m.SMTP.User)
+	}
+	if c.SMTP.Password != "secret" {
+		t.Fatalf("original password modified: %q", c.SMTP.Password)
+	}
+
+	b, err := m.TOML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(b), "secret") {
+		t.Fatalf("TOML contains secret:\n%s", b)
+	}
+
+	// Empty secrets remain empty, so it is clear they are unset
+	if p := Default().Masked().SMTP.Password; p != "" {
+		t.Fatalf("empty password masked: %q", p)
+	}
+}
+
+// TestTOMLRoundTrip verifies that the TOML representation of a Config may be
+// loaded to produce the same Config.
+func TestTOMLRoundTrip(t *testing.T) {
+	c := Default()
+	c.Server.Readonly = true
+	c.Dues.Interval = Duration{90 * time.Minute}
+	c.RateLimit.Rate = 0.5
+
+	b, err := c.TOML()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := Load(writeFile(t, "deltaiota.toml", string(b)), lookup(nil), flag.NewFlagSet("test", flag.ContinueOnError))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(got, c) {
+		t.Fatalf("unexpected config: %+v != %+v", got, c)
+	}
+}
+
+// lookup returns a function which looks up environment variables in the input
+// map, for use with Load.
+func lookup(env map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		v, ok := env[key]
+		return v, ok
+	}
+}
+
+// writeFile writes the input contents to a file with the input name in a
+// temporary directory, and returns its path.
+func writeFile(t *testing.T, name string, contents string) string {
+	dir, err := ioutil.TempDir("", "deltaiota-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		os.RemoveAll(dir)
+	})
+
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, []byte(contents), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
@@ -0,0 +1,93 @@
+package data
+
+import (
+	"context"
+
+	"github.com/mdlayher/deltaiota/data/models"
+)
+
+// auditLogTable maps AuditEntries onto the audit_log table.  Audit entries may
+// never be updated or deleted.
+var auditLogTable = newTable[models.AuditEntry]("audit_log")
+
+const (
+	// sqlAuditFilter is the SQL condition used to match AuditEntries against an
+	// AuditFilter
+	sqlAuditFilter = `
+		(? = 0 OR actor_id = ?)
+		AND (? = '' OR action = ?)
+		AND (? = '' OR target_type = ?)
+		AND (? = 0 OR target_id = ?)
+		AND (? = 0 OR created >= ?)
+		AND (? = 0 OR created < ?)
+	`
+
+	// sqlCountAuditEntriesByFilter is the SQL statement used to count all
+	// AuditEntries which match an AuditFilter
+	sqlCountAuditEntriesByFilter = `
+		SELECT COUNT(*) FROM audit_log WHERE` + sqlAuditFilter + `;
+	`
+)
+
+var (
+	// sqlSelectAuditEntriesByFilter is the SQL statement used to select a page of
+	// AuditEntries which match an AuditFilter, newest first
+	sqlSelectAuditEntriesByFilter = auditLogTable.selectWhere("WHERE" + sqlAuditFilter + `
+		ORDER BY id DESC LIMIT ? OFFSET ?`)
+)
+
+// AuditFilter specifies optional conditions used to select a subset of
+// AuditEntries.  Empty fields are ignored.  Since and Until are UNIX
+// timestamps, and Until is exclusive.
+type AuditFilter struct {
+	ActorID    uint64
+	Action     models.AuditAction
+	TargetType string
+	TargetID   uint64
+	Since      uint64
+	Until      uint64
+}
+
+// args returns the SQL arguments for sqlAuditFilter.
+func (f AuditFilter) args() []interface{} {
+	return []interface{}{
+		f.ActorID, f.ActorID,
+		f.Action, f.Action,
+		f.TargetType, f.TargetType,
+		f.TargetID, f.TargetID,
+		f.Since, f.Since,
+		f.Until, f.Until,
+	}
+}
+
+// SelectAuditEntriesByFilter returns a slice of at most limit AuditEntries
+// which match the input filter, skipping the first offset matches, from the
+// database.  Entries are ordered from newest to oldest.
+func (db *DB) SelectAuditEntriesByFilter(ctx context.Context, f AuditFilter, limit int, offset int) ([]*models.AuditEntry, error) {
+	return selectAll[models.AuditEntry](ctx, db, sqlSelectAuditEntriesByFilter, append(f.args(), limit, offset)...)
+}
+
+// CountAuditEntriesByFilter returns the number of AuditEntries which match the
+// input filter from the database.
+func (db *DB) CountAuditEntriesByFilter(ctx context.Context, f AuditFilter) (int, error) {
+	return db.count(ctx, sqlCountAuditEntriesByFilter, f.args()...)
+}
+
+// InsertAuditEntry starts a transaction, inserts a new AuditEntry, and attempts
+// to commit the transaction.
+func (db *DB) InsertAuditEntry(ctx context.Context, a *models.AuditEntry) error {
+	return db.WithTx(ctx, func(tx *Tx) error {
+		return tx.InsertAuditEntry(ctx, a)
+	})
+}
+
+// InsertAuditEntry inserts a new AuditEntry in the context of the current
+// transaction.
+func (tx *Tx) InsertAuditEntry(ctx context.Context, a *models.AuditEntry) error {
+	return insertRow(ctx, tx, auditLogTable, a)
+}
+
+// ScanAuditEntries returns a slice of AuditEntries from wrapped rows.
+func (r *Rows) ScanAuditEntries() ([]*models.AuditEntry, error) {
+	return ScanInto[models.AuditEntry](r)
+}
@@ -0,0 +1,248 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+const (
+	// backupPrefix and backupSuffix surround the timestamp in the name of
+	// each backup created by BackupToDir.
+	backupPrefix = "deltaiota-"
+	backupSuffix = ".db"
+
+	// backupTimeFormat is the format of the timestamp in the name of each
+	// backup, which sorts lexically in the order backups were created.
+	backupTimeFormat = "20060102T150405Z"
+
+	// backupPages is the number of pages copied in each step of a backup,
+	// between which the backup may be canceled, and other connections may
+	// use the database.
+	backupPages = 256
+
+	// sqlIntegrityCheck is the SQL statement used to check the integrity of
+	// a sqlite3 database
+	sqlIntegrityCheck = `
+		PRAGMA integrity_check;
+	`
+)
+
+var (
+	// ErrBackupUnsupported is returned when attempting to back up or restore a
+	// database which does not support online backups.
+	ErrBackupUnsupported = errors.New("db: backups are only supported for sqlite3 databases")
+)
+
+// Backup writes a consistent snapshot of the database to a new sqlite3
+// database file at the input path, using the sqlite3 online backup API.  The
+// database may continue to be used while the backup is in progress.  The
+// snapshot is written to a temporary file which replaces path on success, so
+// an incomplete backup never appears at path.
+func (db *DB) Backup(ctx context.Context, path string) error {
+	if db.driver != driverSqlite3 {
+		return ErrBackupUnsupported
+	}
+
+	// Remove any temporary file left by an earlier failed backup
+	tmp := path + ".tmp"
+	if err := os.Remove(tmp); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	// Copy the database from a dedicated connection to the temporary file
+	conn, err := db.DB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	err = conn.Raw(func(dc interface{}) error {
+		return sqlite3Copy(ctx, dc, tmp)
+	})
+	if cErr := conn.Close(); err == nil {
+		err = cErr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	return os.Rename(tmp, path)
+}
+
+// BackupToDir writes a backup of the database to a new file in the input
+// directory, named using the current time, and creating the directory if
+// needed.  Once the backup is complete, all but the keep most recent backups
+// in the directory are removed; if keep is 0 or less, no backups are removed.
+// On success, the path of the new backup is returned.
+func (db *DB) BackupToDir(ctx context.Context, dir string, keep int) (string, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, backupPrefix+time.Now().UTC().Format(backupTimeFormat)+backupSuffix)
+	if err := db.Backup(ctx, path); err != nil {
+		return "", err
+	}
+
+	if keep <= 0 {
+		return path, nil
+	}
+
+	// Remove the oldest backups beyond the retention limit
+	backups, err := Backups(dir)
+	if err != nil {
+		return "", err
+	}
+	for len(backups) > keep {
+		if err := os.Remove(backups[0]); err != nil {
+			return "", err
+		}
+
+		backups = backups[1:]
+	}
+
+	return path, nil
+}
+
+// Backups returns the paths of all backups created by BackupToDir in the input
+// directory, oldest first.
+func Backups(dir string) ([]string, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, backupPrefix+"*"+backupSuffix))
+	if err != nil {
+		return nil, err
+	}
+
+	// Only consider files whose names contain a valid backup timestamp
+	var backups []string
+	for _, p := range paths {
+		ts := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(p), backupPrefix), backupSuffix)
+		if _, err := time.Parse(backupTimeFormat, ts); err != nil {
+			continue
+		}
+
+		backups = append(backups, p)
+	}
+
+	sort.Strings(backups)
+	return backups, nil
+}
+
+// IntegrityCheck checks the integrity of the database, returning an error which
+// describes any problems found.
+func (db *DB) IntegrityCheck(ctx context.Context) error {
+	if db.driver != driverSqlite3 {
+		return ErrBackupUnsupported
+	}
+
+	rows, err := db.QueryContext(ctx, sqlIntegrityCheck)
+	if err != nil {
+		return err
+	}
+
+	// A healthy database produces a single "ok" row; otherwise, each row
+	// describes a problem
+	var problems []string
+	for rows.Next() {
+		var s string
+		if err := rows.Scan(&s); err != nil {
+			rows.Close()
+			return err
+		}
+
+		if s != "ok" {
+			problems = append(problems, s)
+		}
+	}
+	if err := rows.Close(); err != nil {
+		return err
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("db: integrity check failed: %s", strings.Join(problems, "; "))
+	}
+
+	return nil
+}
+
+// Restore replaces the contents of the sqlite3 database at the input DSN with
+// the backup at the input path.  The backup's integrity is checked before any
+// changes are made, and a backup which fails the check is not restored.  The
+// database should not be in use by a running server during a restore.
+func Restore(ctx context.Context, backup string, dsn string) error {
+	// The backup must already exist, and is never modified
+	if _, err := os.Stat(backup); err != nil {
+		return err
+	}
+	src := &DB{}
+	if err := src.Open(driverSqlite3, "file:"+backup+"?mode=ro", nil); err != nil {
+		return err
+	}
+	defer src.Close()
+
+	if err := src.IntegrityCheck(ctx); err != nil {
+		return err
+	}
+
+	conn, err := src.DB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return conn.Raw(func(dc interface{}) error {
+		return sqlite3Copy(ctx, dc, dsn)
+	})
+}
+
+// sqlite3Copy copies the main database of a sqlite3 driver connection into the
+// database at the input DSN, replacing its contents.
+func sqlite3Copy(ctx context.Context, dc interface{}, dsn string) error {
+	src, ok := dc.(*sqlite3.SQLiteConn)
+	if !ok {
+		return ErrBackupUnsupported
+	}
+
+	// Open a connection to the destination directly, since it is not managed
+	// by database/sql
+	dest, err := (&sqlite3.SQLiteDriver{}).Open(dsn)
+	if err != nil {
+		return err
+	}
+	defer dest.Close()
+
+	b, err := dest.(*sqlite3.SQLiteConn).Backup("main", src, "main")
+	if err != nil {
+		return err
+	}
+
+	// Copy pages in steps, yielding between them, until the copy is done
+	for {
+		if err := ctx.Err(); err != nil {
+			b.Close()
+			return err
+		}
+
+		done, err := b.Step(backupPages)
+		if err != nil {
+			b.Close()
+			return err
+		}
+		if done {
+			break
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	return b.Finish()
+}
@@ -0,0 +1,63 @@
+package data
+
+import (
+	"context"
+
+	"github.com/mdlayher/deltaiota/data/models"
+)
+
+// calendarTokensTable maps CalendarTokens onto the calendar_tokens table, which
+// is keyed by user ID.
+var calendarTokensTable = newKeyedTable[models.CalendarToken]("calendar_tokens")
+
+var (
+	// sqlSelectCalendarTokenByUserID is the SQL statement used to select a User's
+	// CalendarToken, by the User's ID
+	sqlSelectCalendarTokenByUserID = calendarTokensTable.selectWhere("WHERE user_id = ?")
+
+	// sqlSelectCalendarTokenByToken is the SQL statement used to select a single
+	// CalendarToken by its token value
+	sqlSelectCalendarTokenByToken = calendarTokensTable.selectWhere("WHERE token = ?")
+
+	// sqlDeleteCalendarTokenByUserID is the SQL statement used to delete a User's
+	// CalendarToken, by the User's ID
+	sqlDeleteCalendarTokenByUserID = calendarTokensTable.deleteWhere("user_id = ?")
+)
+
+// SelectCalendarTokenByUserID returns the CalendarToken for the User with the
+// input ID from the database.
+func (db *DB) SelectCalendarTokenByUserID(ctx context.Context, userID uint64) (*models.CalendarToken, error) {
+	return selectSingle[models.CalendarToken](ctx, db, sqlSelectCalendarTokenByUserID, userID)
+}
+
+// SelectCalendarTokenByToken returns a single CalendarToken by its token value
+// from the database.
+func (db *DB) SelectCalendarTokenByToken(ctx context.Context, token string) (*models.CalendarToken, error) {
+	return selectSingle[models.CalendarToken](ctx, db, sqlSelectCalendarTokenByToken, token)
+}
+
+// SetCalendarToken starts a transaction, sets the input CalendarToken for its
+// User, replacing any existing token, and attempts to commit the transaction.
+func (db *DB) SetCalendarToken(ctx context.Context, t *models.CalendarToken) error {
+	return db.WithTx(ctx, func(tx *Tx) error {
+		return tx.SetCalendarToken(ctx, t)
+	})
+}
+
+// SetCalendarToken sets the input CalendarToken for its User, replacing any
+// existing token, in the context of the current transaction.
+func (tx *Tx) SetCalendarToken(ctx context.Context, t *models.CalendarToken) error {
+	return replaceRow(ctx, tx, calendarTokensTable, t)
+}
+
+// DeleteCalendarTokenByUserID deletes the CalendarToken for the User with the
+// input ID, in the context of the current transaction.
+func (tx *Tx) DeleteCalendarTokenByUserID(ctx context.Context, userID uint64) error {
+	_, err := tx.Tx.ExecContext(ctx, sqlDeleteCalendarTokenByUserID, userID)
+	return err
+}
+
+// ScanCalendarTokens returns a slice of CalendarTokens from wrapped rows.
+func (r *Rows) ScanCalendarTokens() ([]*models.CalendarToken, error) {
+	return ScanInto[models.CalendarToken](r)
+}
@@ -0,0 +1,216 @@
+package data
+
+import (
+	"context"
+
+	"github.com/mdlayher/deltaiota/data/models"
+)
+
+// committeesTable maps Committees onto the committees table.
+var committeesTable = newTable[models.Committee]("committees")
+
+// committeeMembersTable maps CommitteeMembers onto the committee_members table,
+// which is keyed by committee and user ID.
+var committeeMembersTable = newKeyedTable[models.CommitteeMember]("committee_members")
+
+var (
+	// sqlSelectAllCommittees is the SQL statement used to select all Committees
+	sqlSelectAllCommittees = committeesTable.selectWhere("ORDER BY name")
+
+	// sqlSelectCommitteeByID is the SQL statement used to select a single Committee by ID
+	sqlSelectCommitteeByID = committeesTable.selectWhere("WHERE id = ?")
+
+	// sqlSelectCommitteeMembersByCommitteeID is the SQL statement used to select all
+	// members of a Committee who are not deleted, by the Committee's ID, chairs first
+	sqlSelectCommitteeMembersByCommitteeID = committeeMembersTable.selectWhere(`
+		WHERE committee_id = ? AND user_id IN (SELECT id FROM users WHERE deleted_at = 0)
+		ORDER BY chair DESC, user_id`)
+
+	// sqlSelectCommitteeMember is the SQL statement used to select a single member
+	// of a Committee, by the Committee's ID and the User's ID
+	sqlSelectCommitteeMember = committeeMembersTable.selectWhere("WHERE committee_id = ? AND user_id = ?")
+
+	// sqlDeleteCommitteeMember is the SQL statement used to remove a member from
+	// a Committee
+	sqlDeleteCommitteeMember = committeeMembersTable.deleteWhere("committee_id = ? AND user_id = ?")
+
+	// sqlDeleteCommitteeMembersByCommitteeID is the SQL statement used to remove
+	// all members from a Committee, by the Committee's ID
+	sqlDeleteCommitteeMembersByCommitteeID = committeeMembersTable.deleteWhere("committee_id = ?")
+
+	// sqlDeleteCommitteeMembersByUserID is the SQL statement used to remove a User
+	// from all Committees, by the User's ID
+	sqlDeleteCommitteeMembersByUserID = committeeMembersTable.deleteWhere("user_id = ?")
+)
+
+// SelectAllCommittees returns a slice of all Committees from the database.
+func (db *DB) SelectAllCommittees(ctx context.Context) ([]*models.Committee, error) {
+	return selectAll[models.Committee](ctx, db, sqlSelectAllCommittees)
+}
+
+// SelectCommitteeByID returns a single Committee by ID from the database.
+func (db *DB) SelectCommitteeByID(ctx context.Context, id uint64) (*models.Committee, error) {
+	return selectSingle[models.Committee](ctx, db, sqlSelectCommitteeByID, id)
+}
+
+// SelectCommitteeMembersByCommitteeID returns a slice of all members of the
+// Committee with the input ID from the database, chairs first.  Deleted Users
+// are omitted.
+func (db *DB) SelectCommitteeMembersByCommitteeID(ctx context.Context, committeeID uint64) ([]*models.CommitteeMember, error) {
+	return selectAll[models.CommitteeMember](ctx, db, sqlSelectCommitteeMembersByCommitteeID, committeeID)
+}
+
+// SelectCommitteeMember returns a single member of the Committee with the input
+// committee ID, by user ID, from the database.
+func (db *DB) SelectCommitteeMember(ctx context.Context, committeeID uint64, userID uint64) (*models.CommitteeMember, error) {
+	return selectSingle[models.CommitteeMember](ctx, db, sqlSelectCommitteeMember, committeeID, userID)
+}
+
+// InsertCommittee starts a transaction, inserts a new Committee, and attempts to commit
+// the transaction.
+func (db *DB) InsertCommittee(ctx context.Context, c *models.Committee) error {
+	return db.WithTx(ctx, func(tx *Tx) error {
+		return tx.InsertCommittee(ctx, c)
+	})
+}
+
+// UpdateCommittee starts a transaction, updates the input Committee by its ID, and attempts
+// to commit the transaction.
+func (db *DB) UpdateCommittee(ctx context.Context, c *models.Committee) error {
+	return db.WithTx(ctx, func(tx *Tx) error {
+		return tx.UpdateCommittee(ctx, c)
+	})
+}
+
+// DeleteCommittee starts a transaction, removes all members from and deletes the
+// input Committee by its ID, and attempts to commit the transaction.
+func (db *DB) DeleteCommittee(ctx context.Context, c *models.Committee) error {
+	return db.WithTx(ctx, func(tx *Tx) error {
+		if err := tx.DeleteCommitteeMembersByCommitteeID(ctx, c.ID); err != nil {
+			return err
+		}
+
+		return tx.DeleteCommittee(ctx, c)
+	})
+}
+
+// SetCommitteeMember starts a transaction, adds or updates the input CommitteeMember,
+// and attempts to commit the transaction.
+func (db *DB) SetCommitteeMember(ctx context.Context, m *models.CommitteeMember) error {
+	return db.WithTx(ctx, func(tx *Tx) error {
+		return tx.SetCommitteeMember(ctx, m)
+	})
+}
+
+// DeleteCommitteeMember starts a transaction, removes the input CommitteeMember
+// from its Committee, and attempts to commit the transaction.
+func (db *DB) DeleteCommitteeMember(ctx context.Context, m *models.CommitteeMember) error {
+	return db.WithTx(ctx, func(tx *Tx) error {
+		return tx.DeleteCommitteeMember(ctx, m)
+	})
+}
+
+// NotifyCommittee starts a transaction, inserts a copy of the input Notification
+// for each member of the Committee with the input ID, and attempts to commit the
+// transaction.  The UserID of the input Notification is ignored.  On success, the
+// inserted Notifications are returned; on failure, no Notifications are inserted.
+func (db *DB) NotifyCommittee(ctx context.Context, committeeID uint64, n *models.Notification) ([]*models.Notification, error) {
+	var notifications []*models.Notification
+	err := db.WithTx(ctx, func(tx *Tx) error {
+		var err error
+		notifications, err = tx.NotifyCommittee(ctx, committeeID, n)
+		return err
+	})
+
+	return notifications, err
+}
+
+// InsertCommittee inserts a new Committee in the context of the current transaction.
+func (tx *Tx) InsertCommittee(ctx context.Context, c *models.Committee) error {
+	return insertRow(ctx, tx, committeesTable, c)
+}
+
+// UpdateCommittee updates the input Committee by its ID, in the context of the
+// current transaction.
+func (tx *Tx) UpdateCommittee(ctx context.Context, c *models.Committee) error {
+	return updateRow(ctx, tx, committeesTable, c)
+}
+
+// DeleteCommittee deletes the input Committee by its ID, in the context of the
+// current transaction.
+func (tx *Tx) DeleteCommittee(ctx context.Context, c *models.Committee) error {
+	return deleteRow(ctx, tx, committeesTable, c)
+}
+
+// SetCommitteeMember adds or updates the input CommitteeMember, in the context
+// of the current transaction.
+func (tx *Tx) SetCommitteeMember(ctx context.Context, m *models.CommitteeMember) error {
+	return replaceRow(ctx, tx, committeeMembersTable, m)
+}
+
+// DeleteCommitteeMember removes the input CommitteeMember from its Committee, in
+// the context of the current transaction.
+func (tx *Tx) DeleteCommitteeMember(ctx context.Context, m *models.CommitteeMember) error {
+	_, err := tx.Tx.ExecContext(ctx, sqlDeleteCommitteeMember, m.CommitteeID, m.UserID)
+	return err
+}
+
+// DeleteCommitteeMembersByCommitteeID removes all members from the Committee with
+// the input ID, in the context of the current transaction.
+func (tx *Tx) DeleteCommitteeMembersByCommitteeID(ctx context.Context, committeeID uint64) error {
+	_, err := tx.Tx.ExecContext(ctx, sqlDeleteCommitteeMembersByCommitteeID, committeeID)
+	return err
+}
+
+// DeleteCommitteeMembersByUserID removes the User with the input ID from all
+// Committees, in the context of the current transaction.
+func (tx *Tx) DeleteCommitteeMembersByUserID(ctx context.Context, userID uint64) error {
+	_, err := tx.Tx.ExecContext(ctx, sqlDeleteCommitteeMembersByUserID, userID)
+	return err
+}
+
+// NotifyCommittee inserts a copy of the input Notification for each member of
+// the Committee with the input ID, in the context of the current transaction.
+// The UserID of the input Notification is ignored.
+func (tx *Tx) NotifyCommittee(ctx context.Context, committeeID uint64, n *models.Notification) ([]*models.Notification, error) {
+	// Fetch all committee members within this transaction, so that membership
+	// cannot change while notifications are inserted
+	rows, err := tx.Tx.QueryContext(ctx, sqlSelectCommitteeMembersByCommitteeID, committeeID)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := (&Rows{Rows: rows}).ScanCommitteeMembers()
+	if cErr := rows.Close(); err == nil {
+		err = cErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Insert a notification for each member
+	notifications := make([]*models.Notification, 0, len(members))
+	for _, m := range members {
+		mn := *n
+		mn.ID = 0
+		mn.UserID = m.UserID
+
+		if err := tx.InsertNotification(ctx, &mn); err != nil {
+			return nil, err
+		}
+
+		notifications = append(notifications, &mn)
+	}
+
+	return notifications, nil
+}
+
+// ScanCommittees returns a slice of Committees from wrapped rows.
+func (r *Rows) ScanCommittees() ([]*models.Committee, error) {
+	return ScanInto[models.Committee](r)
+}
+
+// ScanCommitteeMembers returns a slice of CommitteeMembers from wrapped rows.
+func (r *Rows) ScanCommitteeMembers() ([]*models.CommitteeMember, error) {
+	return ScanInto[models.CommitteeMember](r)
+}
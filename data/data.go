@@ -3,8 +3,12 @@
 package data
 
 import (
+	"context"
 	"database/sql"
+	"database/sql/driver"
 	"errors"
+	"net/url"
+	"strings"
 	"sync"
 
 	"github.com/mattn/go-sqlite3"
@@ -27,6 +31,12 @@ type DB struct {
 
 	driver string
 
+	// readonly indicates if the database was opened in read-only mode.
+	readonly bool
+
+	// opts configures each connection to the database.
+	opts *Options
+
 	// preparedStmts is a map of query strings to prepared database statements.
 	// On first use, queries are prepared and added to the map for later re-use.
 	// On shutdown, all prepared statementes are cleaned up.
@@ -34,21 +44,41 @@ type DB struct {
 	stmtMutex     *sync.RWMutex
 }
 
-// Open opens and initializes a database instance.
-func (db *DB) Open(driver string, dsn string) error {
-	// Open database
-	d, err := sql.Open(driver, dsn)
-	if err != nil {
+// Open opens and initializes a database instance.  The input Options configure
+// each connection to a sqlite3 database; if nil, DefaultOptions are used.
+func (db *DB) Open(driver string, dsn string, opts *Options) error {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	if err := opts.Validate(); err != nil {
 		return err
 	}
-	db.DB = d
+
 	db.driver = driver
+	db.readonly = isReadonlyDSN(dsn)
+	db.opts = opts
 
-	// Perform driver-specific setup
+	// Open database; sqlite3 connections are configured as each is opened,
+	// since pragmas only apply to a single connection
 	if db.driver == driverSqlite3 {
-		if err := db.sqlite3Setup(); err != nil {
+		db.DB = sql.OpenDB(&sqlite3Connector{
+			dsn: dsn,
+			driver: &sqlite3.SQLiteDriver{
+				ConnectHook: db.sqlite3Setup,
+			},
+		})
+	} else {
+		d, err := sql.Open(driver, dsn)
+		if err != nil {
 			return err
 		}
+		db.DB = d
+	}
+
+	// Verify the database can be opened
+	if err := db.DB.Ping(); err != nil {
+		db.DB.Close()
+		return err
 	}
 
 	// Initialize prepared statement map and mutex
@@ -72,10 +102,46 @@ func (db *DB) Close() error {
 	return db.DB.Close()
 }
 
+// PreparedStatements returns the number of prepared statements which are cached
+// for reuse.
+func (db *DB) PreparedStatements() int {
+	db.stmtMutex.RLock()
+	defer db.stmtMutex.RUnlock()
+
+	return len(db.preparedStmts)
+}
+
+// Readonly returns whether or not the database was opened in read-only mode,
+// such as a sqlite3 DSN of the form "file:deltaiota.db?mode=ro".  All attempts
+// to modify a read-only database fail.
+func (db *DB) Readonly() bool {
+	return db.readonly
+}
+
+// isReadonlyDSN returns whether or not an input sqlite3 URI DSN specifies
+// read-only mode.
+func isReadonlyDSN(dsn string) bool {
+	if !strings.HasPrefix(dsn, "file:") {
+		return false
+	}
+
+	// Parse the query string following the file path, if any
+	i := strings.Index(dsn, "?")
+	if i == -1 {
+		return false
+	}
+	q, err := url.ParseQuery(dsn[i+1:])
+	if err != nil {
+		return false
+	}
+
+	return q.Get("mode") == "ro"
+}
+
 // Begin starts a transaction on this database instance.
-func (db *DB) Begin() (*Tx, error) {
+func (db *DB) Begin(ctx context.Context) (*Tx, error) {
 	// Start a transaction on underlying database
-	dbtx, err := db.DB.Begin()
+	dbtx, err := db.DB.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -113,12 +179,35 @@ func (db *DB) IsReadonly(err error) bool {
 	return false
 }
 
+// txKey is the context key for a transaction which database operations join.
+type txKey struct{}
+
+// NewTxContext returns a copy of the input context which carries the input
+// transaction.  Database operations performed using the returned context are
+// performed within the transaction, and WithTx joins the transaction rather than
+// starting a new one, so that all changes are committed or rolled back together.
+func NewTxContext(ctx context.Context, tx *Tx) context.Context {
+	return context.WithValue(ctx, txKey{}, tx)
+}
+
+// txFromContext returns the transaction carried by the input context, if any.
+func txFromContext(ctx context.Context) (*Tx, bool) {
+	tx, ok := ctx.Value(txKey{}).(*Tx)
+	return tx, ok
+}
+
 // WithTx creates a new wrapped transaction, invokes an input closure, and
 // commits or rolls back the transaction, depending on the result of the
-// closure invocation.
-func (db *DB) WithTx(fn func(tx *Tx) error) error {
+// closure invocation.  If the input context carries a transaction, the closure
+// is invoked with it instead, and its owner commits or rolls it back.
+func (db *DB) WithTx(ctx context.Context, fn func(tx *Tx) error) error {
+	// Join an existing transaction
+	if tx, ok := txFromContext(ctx); ok {
+		return fn(tx)
+	}
+
 	// Start a wrapped transaction
-	tx, err := db.Begin()
+	tx, err := db.Begin(ctx)
 	if err != nil {
 		return err
 	}
@@ -140,8 +229,19 @@ func (db *DB) WithTx(fn func(tx *Tx) error) error {
 
 // withPreparedStmt creates or re-uses a prepared statement for the input SQL query.
 // On first use, prepared statements are created and set into the preparedStmts map
-// for later reuse.
-func (db *DB) withPreparedStmt(query string, fn func(stmt *sql.Stmt) error) error {
+// for later reuse.  If the input context carries a transaction, the statement is
+// prepared within the transaction instead, and closed once the closure returns.
+func (db *DB) withPreparedStmt(ctx context.Context, query string, fn func(stmt *sql.Stmt) error) error {
+	if tx, ok := txFromContext(ctx); ok {
+		stmt, err := tx.Tx.PrepareContext(ctx, query)
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+
+		return fn(stmt)
+	}
+
 	// Check for pre-existing statement
 	db.stmtMutex.RLock()
 	stmt, ok := db.preparedStmts[query]
@@ -149,7 +249,7 @@ func (db *DB) withPreparedStmt(query string, fn func(stmt *sql.Stmt) error) erro
 	if !ok {
 		// Prepare statement using input query
 		var err error
-		stmt, err = db.Prepare(query)
+		stmt, err = db.PrepareContext(ctx, query)
 		if err != nil {
 			return err
 		}
@@ -167,11 +267,11 @@ func (db *DB) withPreparedStmt(query string, fn func(stmt *sql.Stmt) error) erro
 // withPreparedRows creates or retrieves a prepared statement with the input SQL query,
 // invokes an input closure containing SQL rows, and handles cleanup of rows
 // once the closure invocation is complete.
-func (db *DB) withPreparedRows(query string, fn func(rows *Rows) error, args ...interface{}) error {
+func (db *DB) withPreparedRows(ctx context.Context, query string, fn func(rows *Rows) error, args ...interface{}) error {
 	// Create or retrieve a prepared statement
-	return db.withPreparedStmt(query, func(stmt *sql.Stmt) error {
+	return db.withPreparedStmt(ctx, query, func(stmt *sql.Stmt) error {
 		// Perform input query, sending arguments from caller
-		rows, err := stmt.Query(args...)
+		rows, err := stmt.QueryContext(ctx, args...)
 		if err != nil {
 			return err
 		}
@@ -197,23 +297,11 @@ func (db *DB) withPreparedRows(query string, fn func(rows *Rows) error, args ...
 }
 
 // sqlite3Setup performs setup routines specific to the sqlite3 database driver,
-// each time the database is initialized.
-func (db *DB) sqlite3Setup() error {
-	// Slice of queries which will be executed, in-order, on startup
-	queries := []string{
-		// Enforce foreign keys
-		"PRAGMA foreign_keys = ON;",
-
-		// Do not wait for data to be written to disk
-		"PRAGMA synchronous = OFF;",
-
-		// Keep rollback journal in memory
-		"PRAGMA journal_mode = MEMORY;",
-	}
-
+// each time a connection to the database is opened.
+func (db *DB) sqlite3Setup(conn *sqlite3.SQLiteConn) error {
 	// Execute startup queries in order
-	for _, q := range queries {
-		if _, err := db.Exec(q); err != nil {
+	for _, q := range db.opts.pragmas(db.readonly) {
+		if _, err := conn.Exec(q, nil); err != nil {
 			return err
 		}
 	}
@@ -221,6 +309,23 @@ func (db *DB) sqlite3Setup() error {
 	return nil
 }
 
+// sqlite3Connector is a driver.Connector which opens connections to a sqlite3
+// database using a configured driver.
+type sqlite3Connector struct {
+	dsn    string
+	driver *sqlite3.SQLiteDriver
+}
+
+// Connect opens a new connection to the database.
+func (c *sqlite3Connector) Connect(_ context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.dsn)
+}
+
+// Driver returns the connector's underlying driver.
+func (c *sqlite3Connector) Driver() driver.Driver {
+	return c.driver
+}
+
 // Tx is a wrapped database transaction, which provides additional methods
 // for interacting directly with custom types.
 type Tx struct {
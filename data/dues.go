@@ -0,0 +1,412 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/mdlayher/deltaiota/data/models"
+)
+
+// chargesTable maps Charges onto the charges table.
+var chargesTable = newTable[models.Charge]("charges")
+
+// paymentsTable maps Payments onto the payments table.
+var paymentsTable = newTable[models.Payment]("payments")
+
+var (
+	// sqlSelectChargesByUserID is the SQL statement used to select all Charges
+	// for a User, by the User's ID, oldest due first
+	sqlSelectChargesByUserID = chargesTable.selectWhere("WHERE user_id = ? ORDER BY due, id")
+
+	// sqlSelectChargeByID is the SQL statement used to select a single Charge by ID
+	sqlSelectChargeByID = chargesTable.selectWhere("WHERE id = ?")
+
+	// sqlSelectChargesForReminder is the SQL statement used to select all Charges
+	// of Users who are not deleted which are due within a window of time, and for
+	// which no reminder was sent
+	sqlSelectChargesForReminder = chargesTable.selectWhere(`
+		WHERE due > ? AND due <= ? AND reminded = 0
+			AND user_id IN (SELECT id FROM users WHERE deleted_at = 0)
+		ORDER BY user_id, due, id`)
+
+	// sqlSelectChargesForOverdue is the SQL statement used to select all Charges
+	// of Users who are not deleted which are past due, and for which no overdue
+	// notice was sent
+	sqlSelectChargesForOverdue = chargesTable.selectWhere(`
+		WHERE due <= ? AND overdue = 0
+			AND user_id IN (SELECT id FROM users WHERE deleted_at = 0)
+		ORDER BY user_id, due, id`)
+
+	// sqlDeleteChargesByUserID is the SQL statement used to delete all Charges
+	// for a User, by the User's ID
+	sqlDeleteChargesByUserID = chargesTable.deleteWhere("user_id = ?")
+
+	// sqlSelectPaymentsByUserID is the SQL statement used to select all Payments
+	// made by a User, by the User's ID, oldest first
+	sqlSelectPaymentsByUserID = paymentsTable.selectWhere("WHERE user_id = ? ORDER BY timestamp, id")
+
+	// sqlSelectPaymentByID is the SQL statement used to select a single Payment by ID
+	sqlSelectPaymentByID = paymentsTable.selectWhere("WHERE id = ?")
+
+	// sqlDeletePaymentsByUserID is the SQL statement used to delete all Payments
+	// made by a User, by the User's ID
+	sqlDeletePaymentsByUserID = paymentsTable.deleteWhere("user_id = ?")
+)
+
+const (
+	// sqlSelectChargeTotalsByUserID is the SQL statement used to select the total
+	// of all Charges for a User, and the total of those which are due at a given time
+	sqlSelectChargeTotalsByUserID = `
+		SELECT
+			COALESCE(SUM(amount), 0)
+			, COALESCE(SUM(CASE WHEN due <= ? THEN amount ELSE 0 END), 0)
+		FROM charges WHERE user_id = ?;
+	`
+
+	// sqlSelectPaymentTotalByUserID is the SQL statement used to select the total
+	// of all Payments made by a User
+	sqlSelectPaymentTotalByUserID = `
+		SELECT COALESCE(SUM(amount), 0) FROM payments WHERE user_id = ?;
+	`
+
+	// sqlSelectOverdueBalances is the SQL statement used to select the ledger
+	// totals of all Users who are not deleted, and whose past due Charges exceed
+	// their Payments, largest overdue amount first
+	sqlSelectOverdueBalances = `
+		SELECT c.user_id, c.charged, COALESCE(p.paid, 0), c.due_total FROM (
+			SELECT
+				user_id
+				, SUM(amount) AS charged
+				, SUM(CASE WHEN due <= ? THEN amount ELSE 0 END) AS due_total
+			FROM charges
+			WHERE user_id IN (SELECT id FROM users WHERE deleted_at = 0)
+			GROUP BY user_id
+		) c LEFT JOIN (
+			SELECT user_id, SUM(amount) AS paid FROM payments GROUP BY user_id
+		) p ON c.user_id = p.user_id
+		WHERE c.due_total - COALESCE(p.paid, 0) > 0
+		ORDER BY c.due_total - COALESCE(p.paid, 0) DESC, c.user_id;
+	`
+)
+
+// SelectChargesByUserID returns a slice of all Charges for the User with the input
+// ID from the database, oldest due first.
+func (db *DB) SelectChargesByUserID(ctx context.Context, userID uint64) ([]*models.Charge, error) {
+	return selectAll[models.Charge](ctx, db, sqlSelectChargesByUserID, userID)
+}
+
+// SelectChargeByID returns a single Charge by ID from the database.
+func (db *DB) SelectChargeByID(ctx context.Context, id uint64) (*models.Charge, error) {
+	return selectSingle[models.Charge](ctx, db, sqlSelectChargeByID, id)
+}
+
+// SelectPaymentsByUserID returns a slice of all Payments made by the User with
+// the input ID from the database, oldest first.
+func (db *DB) SelectPaymentsByUserID(ctx context.Context, userID uint64) ([]*models.Payment, error) {
+	return selectAll[models.Payment](ctx, db, sqlSelectPaymentsByUserID, userID)
+}
+
+// SelectPaymentByID returns a single Payment by ID from the database.
+func (db *DB) SelectPaymentByID(ctx context.Context, id uint64) (*models.Payment, error) {
+	return selectSingle[models.Payment](ctx, db, sqlSelectPaymentByID, id)
+}
+
+// SelectBalanceByUserID returns the ledger Balance for the User with the input
+// ID, as of the input time.  Payments are applied to Charges oldest due first,
+// so a Balance is overdue by the amount that past due Charges exceed Payments.
+func (db *DB) SelectBalanceByUserID(ctx context.Context, userID uint64, at time.Time) (*models.Balance, error) {
+	b := &models.Balance{
+		UserID: userID,
+	}
+
+	// Total all charges, and those which are past due
+	var due models.Cents
+	err := db.withPreparedStmt(ctx, sqlSelectChargeTotalsByUserID, func(stmt *sql.Stmt) error {
+		return stmt.QueryRowContext(ctx, at.Unix(), userID).Scan(&b.Charged, &due)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Total all payments
+	err = db.withPreparedStmt(ctx, sqlSelectPaymentTotalByUserID, func(stmt *sql.Stmt) error {
+		return stmt.QueryRowContext(ctx, userID).Scan(&b.Paid)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	computeBalance(b, due)
+	return b, nil
+}
+
+// SelectOverdueBalances returns a slice of ledger Balances for all Users with
+// an overdue amount as of the input time, largest overdue amount first.  Deleted
+// Users are omitted.
+func (db *DB) SelectOverdueBalances(ctx context.Context, at time.Time) ([]*models.Balance, error) {
+	var balances []*models.Balance
+	err := db.withPreparedRows(ctx, sqlSelectOverdueBalances, func(rows *Rows) error {
+		for rows.Next() {
+			b := new(models.Balance)
+			var due models.Cents
+			if err := rows.Scan(&b.UserID, &b.Charged, &b.Paid, &due); err != nil {
+				return err
+			}
+
+			computeBalance(b, due)
+			balances = append(balances, b)
+		}
+
+		return nil
+	}, at.Unix())
+
+	return balances, err
+}
+
+// InsertCharge starts a transaction, inserts a new Charge, and attempts to commit
+// the transaction.
+func (db *DB) InsertCharge(ctx context.Context, c *models.Charge) error {
+	return db.WithTx(ctx, func(tx *Tx) error {
+		return tx.InsertCharge(ctx, c)
+	})
+}
+
+// UpdateCharge starts a transaction, updates the input Charge by its ID, and attempts
+// to commit the transaction.
+func (db *DB) UpdateCharge(ctx context.Context, c *models.Charge) error {
+	return db.WithTx(ctx, func(tx *Tx) error {
+		return tx.UpdateCharge(ctx, c)
+	})
+}
+
+// DeleteCharge starts a transaction, deletes the input Charge by its ID, and attempts
+// to commit the transaction.
+func (db *DB) DeleteCharge(ctx context.Context, c *models.Charge) error {
+	return db.WithTx(ctx, func(tx *Tx) error {
+		return tx.DeleteCharge(ctx, c)
+	})
+}
+
+// InsertPayment starts a transaction, inserts a new Payment, and attempts to commit
+// the transaction.
+func (db *DB) InsertPayment(ctx context.Context, p *models.Payment) error {
+	return db.WithTx(ctx, func(tx *Tx) error {
+		return tx.InsertPayment(ctx, p)
+	})
+}
+
+// DeletePayment starts a transaction, deletes the input Payment by its ID, and attempts
+// to commit the transaction.
+func (db *DB) DeletePayment(ctx context.Context, p *models.Payment) error {
+	return db.WithTx(ctx, func(tx *Tx) error {
+		return tx.DeletePayment(ctx, p)
+	})
+}
+
+// NotifyCharges starts a transaction, notifies Users of Charges which are due
+// within the input window after the input time, and of Charges which are past due
+// at the input time, and attempts to commit the transaction.  Each Charge produces
+// at most one reminder and one overdue notice, and Charges which are already
+// covered by Payments produce neither.  Deleted Users are not notified.  On success,
+// the inserted Notifications are returned.
+func (db *DB) NotifyCharges(ctx context.Context, at time.Time, window time.Duration) ([]*models.Notification, error) {
+	var notifications []*models.Notification
+	err := db.WithTx(ctx, func(tx *Tx) error {
+		var err error
+		notifications, err = tx.NotifyCharges(ctx, at, window)
+		return err
+	})
+
+	return notifications, err
+}
+
+// InsertCharge inserts a new Charge in the context of the current transaction.
+func (tx *Tx) InsertCharge(ctx context.Context, c *models.Charge) error {
+	return insertRow(ctx, tx, chargesTable, c)
+}
+
+// UpdateCharge updates the input Charge by its ID, in the context of the
+// current transaction.
+func (tx *Tx) UpdateCharge(ctx context.Context, c *models.Charge) error {
+	return updateRow(ctx, tx, chargesTable, c)
+}
+
+// DeleteCharge deletes the input Charge by its ID, in the context of the
+// current transaction.
+func (tx *Tx) DeleteCharge(ctx context.Context, c *models.Charge) error {
+	return deleteRow(ctx, tx, chargesTable, c)
+}
+
+// DeleteChargesByUserID deletes all Charges for the User with the input ID, in
+// the context of the current transaction.
+func (tx *Tx) DeleteChargesByUserID(ctx context.Context, userID uint64) error {
+	_, err := tx.Tx.ExecContext(ctx, sqlDeleteChargesByUserID, userID)
+	return err
+}
+
+// InsertPayment inserts a new Payment in the context of the current transaction.
+func (tx *Tx) InsertPayment(ctx context.Context, p *models.Payment) error {
+	return insertRow(ctx, tx, paymentsTable, p)
+}
+
+// DeletePayment deletes the input Payment by its ID, in the context of the
+// current transaction.
+func (tx *Tx) DeletePayment(ctx context.Context, p *models.Payment) error {
+	return deleteRow(ctx, tx, paymentsTable, p)
+}
+
+// DeletePaymentsByUserID deletes all Payments made by the User with the input ID,
+// in the context of the current transaction.
+func (tx *Tx) DeletePaymentsByUserID(ctx context.Context, userID uint64) error {
+	_, err := tx.Tx.ExecContext(ctx, sqlDeletePaymentsByUserID, userID)
+	return err
+}
+
+// NotifyCharges notifies Users of Charges which are due within the input window
+// after the input time, and of Charges which are past due at the input time, in
+// the context of the current transaction.
+func (tx *Tx) NotifyCharges(ctx context.Context, at time.Time, window time.Duration) ([]*models.Notification, error) {
+	now := at.Unix()
+
+	// Gather charges needing a reminder, and charges needing an overdue notice
+	upcoming, err := tx.queryCharges(ctx, sqlSelectChargesForReminder, now, at.Add(window).Unix())
+	if err != nil {
+		return nil, err
+	}
+	overdue, err := tx.queryCharges(ctx, sqlSelectChargesForOverdue, now)
+	if err != nil {
+		return nil, err
+	}
+
+	// Cache the charges covered by payments for each user
+	covered := make(map[uint64]map[uint64]struct{})
+	isCovered := func(c *models.Charge) (bool, error) {
+		ids, ok := covered[c.UserID]
+		if !ok {
+			var err error
+			ids, err = tx.coveredCharges(ctx, c.UserID)
+			if err != nil {
+				return false, err
+			}
+			covered[c.UserID] = ids
+		}
+
+		_, ok = ids[c.ID]
+		return ok, nil
+	}
+
+	var notifications []*models.Notification
+	notify := func(c *models.Charge, format string) error {
+		// Charges which are already paid need no notification
+		paid, err := isCovered(c)
+		if err != nil || paid {
+			return err
+		}
+
+		n := &models.Notification{
+			UserID:    c.UserID,
+			Timestamp: uint64(now),
+			Text: fmt.Sprintf(format, c.Kind, c.Amount, c.Description,
+				time.Unix(int64(c.Due), 0).Format("Jan 2, 2006")),
+		}
+		if err := tx.InsertNotification(ctx, n); err != nil {
+			return err
+		}
+
+		notifications = append(notifications, n)
+		return nil
+	}
+
+	for _, c := range upcoming {
+		if err := notify(c, "Upcoming %s charge of %s (%s) is due %s"); err != nil {
+			return nil, err
+		}
+
+		c.Reminded = true
+		if err := tx.UpdateCharge(ctx, c); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, c := range overdue {
+		if err := notify(c, "Overdue %s charge of %s (%s) was due %s"); err != nil {
+			return nil, err
+		}
+
+		// An overdue notice replaces any reminder which was not yet sent
+		c.Reminded = true
+		c.Overdue = true
+		if err := tx.UpdateCharge(ctx, c); err != nil {
+			return nil, err
+		}
+	}
+
+	return notifications, nil
+}
+
+// computeBalance computes the Balance and Overdue totals for the input Balance,
+// using its Charged and Paid totals, and the input total of past due Charges.
+func computeBalance(b *models.Balance, due models.Cents) {
+	b.Balance = b.Charged - b.Paid
+
+	// Payments apply to the oldest charges first, so any amount paid beyond
+	// the past due charges is applied to charges which are not yet due
+	if due > b.Paid {
+		b.Overdue = due - b.Paid
+	}
+}
+
+// queryCharges returns a slice of Charges based upon an input SQL query and
+// arguments, in the context of the current transaction.
+func (tx *Tx) queryCharges(ctx context.Context, query string, args ...interface{}) ([]*models.Charge, error) {
+	rows, err := tx.Tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	charges, err := (&Rows{Rows: rows}).ScanCharges()
+	if cErr := rows.Close(); err == nil {
+		err = cErr
+	}
+
+	return charges, err
+}
+
+// coveredCharges returns the set of IDs of Charges for the User with the input
+// ID which are fully paid, by applying the User's Payments to their Charges,
+// oldest due first, in the context of the current transaction.
+func (tx *Tx) coveredCharges(ctx context.Context, userID uint64) (map[uint64]struct{}, error) {
+	var paid models.Cents
+	if err := tx.Tx.QueryRowContext(ctx, sqlSelectPaymentTotalByUserID, userID).Scan(&paid); err != nil {
+		return nil, err
+	}
+
+	charges, err := tx.queryCharges(ctx, sqlSelectChargesByUserID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	covered := make(map[uint64]struct{})
+	for _, c := range charges {
+		if c.Amount > paid {
+			break
+		}
+
+		paid -= c.Amount
+		covered[c.ID] = struct{}{}
+	}
+
+	return covered, nil
+}
+
+// ScanCharges returns a slice of Charges from wrapped rows.
+func (r *Rows) ScanCharges() ([]*models.Charge, error) {
+	return ScanInto[models.Charge](r)
+}
+
+// ScanPayments returns a slice of Payments from wrapped rows.
+func (r *Rows) ScanPayments() ([]*models.Payment, error) {
+	return ScanInto[models.Payment](r)
+}
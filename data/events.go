@@ -0,0 +1,169 @@
+package data
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mdlayher/deltaiota/data/models"
+)
+
+// eventsTable maps Events onto the events table.
+var eventsTable = newTable[models.Event]("events")
+
+// rsvpsTable maps RSVPs onto the rsvps table, which is keyed by event and user ID.
+var rsvpsTable = newKeyedTable[models.RSVP]("rsvps")
+
+var (
+	// sqlSelectAllEvents is the SQL statement used to select all Events
+	sqlSelectAllEvents = eventsTable.selectWhere("ORDER BY start, id")
+
+	// sqlSelectEventByID is the SQL statement used to select a single Event by ID
+	sqlSelectEventByID = eventsTable.selectWhere("WHERE id = ?")
+
+	// sqlSelectEventsByRSVP is the SQL statement used to select all Events which
+	// a User has responded yes or maybe to, by the User's ID
+	sqlSelectEventsByRSVP = fmt.Sprintf(`
+		SELECT %s FROM events
+			JOIN rsvps ON rsvps.event_id = events.id
+		WHERE rsvps.user_id = ?
+			AND rsvps.response IN ('yes', 'maybe')
+		ORDER BY events.start, events.id;
+	`, eventsTable.qualifiedColumns())
+
+	// sqlSelectRSVPsByEventID is the SQL statement used to select all RSVPs for
+	// an Event from Users who are not deleted, by the Event's ID
+	sqlSelectRSVPsByEventID = rsvpsTable.selectWhere(`
+		WHERE event_id = ? AND user_id IN (SELECT id FROM users WHERE deleted_at = 0)
+		ORDER BY user_id`)
+
+	// sqlDeleteRSVP is the SQL statement used to remove a User's RSVP to an Event
+	sqlDeleteRSVP = rsvpsTable.deleteWhere("event_id = ? AND user_id = ?")
+
+	// sqlDeleteRSVPsByEventID is the SQL statement used to remove all RSVPs for
+	// an Event, by the Event's ID
+	sqlDeleteRSVPsByEventID = rsvpsTable.deleteWhere("event_id = ?")
+
+	// sqlDeleteRSVPsByUserID is the SQL statement used to remove all RSVPs made
+	// by a User, by the User's ID
+	sqlDeleteRSVPsByUserID = rsvpsTable.deleteWhere("user_id = ?")
+)
+
+// SelectAllEvents returns a slice of all Events from the database, ordered by
+// start time.
+func (db *DB) SelectAllEvents(ctx context.Context) ([]*models.Event, error) {
+	return selectAll[models.Event](ctx, db, sqlSelectAllEvents)
+}
+
+// SelectEventsByRSVP returns a slice of all Events which the User with the input
+// ID has responded yes or maybe to, ordered by start time.
+func (db *DB) SelectEventsByRSVP(ctx context.Context, userID uint64) ([]*models.Event, error) {
+	return selectAll[models.Event](ctx, db, sqlSelectEventsByRSVP, userID)
+}
+
+// SelectEventByID returns a single Event by ID from the database.
+func (db *DB) SelectEventByID(ctx context.Context, id uint64) (*models.Event, error) {
+	return selectSingle[models.Event](ctx, db, sqlSelectEventByID, id)
+}
+
+// SelectRSVPsByEventID returns a slice of all RSVPs for the Event with the input
+// ID from the database.  RSVPs of deleted Users are omitted.
+func (db *DB) SelectRSVPsByEventID(ctx context.Context, eventID uint64) ([]*models.RSVP, error) {
+	return selectAll[models.RSVP](ctx, db, sqlSelectRSVPsByEventID, eventID)
+}
+
+// InsertEvent starts a transaction, inserts a new Event, and attempts to commit
+// the transaction.
+func (db *DB) InsertEvent(ctx context.Context, e *models.Event) error {
+	return db.WithTx(ctx, func(tx *Tx) error {
+		return tx.InsertEvent(ctx, e)
+	})
+}
+
+// UpdateEvent starts a transaction, updates the input Event by its ID, and attempts
+// to commit the transaction.
+func (db *DB) UpdateEvent(ctx context.Context, e *models.Event) error {
+	return db.WithTx(ctx, func(tx *Tx) error {
+		return tx.UpdateEvent(ctx, e)
+	})
+}
+
+// DeleteEvent starts a transaction, removes all RSVPs for and deletes the input
+// Event by its ID, and attempts to commit the transaction.
+func (db *DB) DeleteEvent(ctx context.Context, e *models.Event) error {
+	return db.WithTx(ctx, func(tx *Tx) error {
+		if err := tx.DeleteRSVPsByEventID(ctx, e.ID); err != nil {
+			return err
+		}
+
+		return tx.DeleteEvent(ctx, e)
+	})
+}
+
+// SetRSVP starts a transaction, adds or updates the input RSVP, and attempts
+// to commit the transaction.
+func (db *DB) SetRSVP(ctx context.Context, r *models.RSVP) error {
+	return db.WithTx(ctx, func(tx *Tx) error {
+		return tx.SetRSVP(ctx, r)
+	})
+}
+
+// DeleteRSVP starts a transaction, removes the input RSVP from its Event, and
+// attempts to commit the transaction.
+func (db *DB) DeleteRSVP(ctx context.Context, r *models.RSVP) error {
+	return db.WithTx(ctx, func(tx *Tx) error {
+		return tx.DeleteRSVP(ctx, r)
+	})
+}
+
+// InsertEvent inserts a new Event in the context of the current transaction.
+func (tx *Tx) InsertEvent(ctx context.Context, e *models.Event) error {
+	return insertRow(ctx, tx, eventsTable, e)
+}
+
+// UpdateEvent updates the input Event by its ID, in the context of the current
+// transaction.
+func (tx *Tx) UpdateEvent(ctx context.Context, e *models.Event) error {
+	return updateRow(ctx, tx, eventsTable, e)
+}
+
+// DeleteEvent deletes the input Event by its ID, in the context of the current
+// transaction.
+func (tx *Tx) DeleteEvent(ctx context.Context, e *models.Event) error {
+	return deleteRow(ctx, tx, eventsTable, e)
+}
+
+// SetRSVP adds or updates the input RSVP, in the context of the current transaction.
+func (tx *Tx) SetRSVP(ctx context.Context, r *models.RSVP) error {
+	return replaceRow(ctx, tx, rsvpsTable, r)
+}
+
+// DeleteRSVP removes the input RSVP from its Event, in the context of the current
+// transaction.
+func (tx *Tx) DeleteRSVP(ctx context.Context, r *models.RSVP) error {
+	_, err := tx.Tx.ExecContext(ctx, sqlDeleteRSVP, r.EventID, r.UserID)
+	return err
+}
+
+// DeleteRSVPsByEventID removes all RSVPs for the Event with the input ID, in the
+// context of the current transaction.
+func (tx *Tx) DeleteRSVPsByEventID(ctx context.Context, eventID uint64) error {
+	_, err := tx.Tx.ExecContext(ctx, sqlDeleteRSVPsByEventID, eventID)
+	return err
+}
+
+// DeleteRSVPsByUserID removes all RSVPs made by the User with the input ID, in
+// the context of the current transaction.
+func (tx *Tx) DeleteRSVPsByUserID(ctx context.Context, userID uint64) error {
+	_, err := tx.Tx.ExecContext(ctx, sqlDeleteRSVPsByUserID, userID)
+	return err
+}
+
+// ScanEvents returns a slice of Events from wrapped rows.
+func (r *Rows) ScanEvents() ([]*models.Event, error) {
+	return ScanInto[models.Event](r)
+}
+
+// ScanRSVPs returns a slice of RSVPs from wrapped rows.
+func (r *Rows) ScanRSVPs() ([]*models.RSVP, error) {
+	return ScanInto[models.RSVP](r)
+}
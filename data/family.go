@@ -0,0 +1,156 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/mdlayher/deltaiota/data/models"
+)
+
+var (
+	// sqlSelectLittlesByUserID is the SQL statement used to select all Users
+	// which are not deleted whose big brother is a User, by the User's ID
+	sqlSelectLittlesByUserID = usersTable.selectWhere("WHERE big_brother_id = ? AND deleted_at = 0 ORDER BY id")
+
+	// sqlSelectAncestorsByUserID is the SQL statement used to select all Users
+	// which are not deleted in a User's line of big brothers, nearest first
+	sqlSelectAncestorsByUserID = fmt.Sprintf(`
+		WITH RECURSIVE ancestors(id, depth) AS (
+			SELECT big_brother_id, 1 FROM users WHERE id = ? AND big_brother_id != 0
+			UNION
+			SELECT u.big_brother_id, a.depth + 1 FROM users u
+				JOIN ancestors a ON u.id = a.id
+				WHERE u.big_brother_id != 0 AND a.depth < ?
+		)
+		SELECT %s FROM users
+			JOIN (SELECT id, MIN(depth) AS depth FROM ancestors GROUP BY id) a ON users.id = a.id
+			WHERE users.id != ? AND users.deleted_at = 0
+			ORDER BY a.depth;
+	`, usersTable.qualifiedColumns())
+
+	// sqlSelectDescendantsByUserID is the SQL statement used to select all Users
+	// which are not deleted descended from a User through littles, nearest
+	// generation first
+	sqlSelectDescendantsByUserID = fmt.Sprintf(`
+		WITH RECURSIVE descendants(id, depth) AS (
+			SELECT id, 1 FROM users WHERE big_brother_id = ?
+			UNION
+			SELECT u.id, d.depth + 1 FROM users u
+				JOIN descendants d ON u.big_brother_id = d.id
+				WHERE d.depth < ?
+		)
+		SELECT %s FROM users
+			JOIN (SELECT id, MIN(depth) AS depth FROM descendants GROUP BY id) d ON users.id = d.id
+			WHERE users.id != ? AND users.deleted_at = 0
+			ORDER BY d.depth, users.id;
+	`, usersTable.qualifiedColumns())
+)
+
+const (
+	// maxFamilyDepth is the maximum number of generations traversed when
+	// selecting ancestors or descendants in a family tree.  It guards against
+	// runaway recursion, should a cycle ever exist in the tree.
+	maxFamilyDepth = 128
+
+	// sqlSelectIsDescendant is the SQL statement used to determine if a User
+	// is descended from another User
+	sqlSelectIsDescendant = `
+		WITH RECURSIVE descendants(id, depth) AS (
+			SELECT id, 1 FROM users WHERE big_brother_id = ?
+			UNION
+			SELECT u.id, d.depth + 1 FROM users u
+				JOIN descendants d ON u.big_brother_id = d.id
+				WHERE d.depth < ?
+		)
+		SELECT COUNT(*) FROM descendants WHERE id = ?;
+	`
+
+	// sqlUpdateBigBrother is the SQL statement used to set the big brother of
+	// an existing User
+	sqlUpdateBigBrother = `
+		UPDATE users SET "big_brother_id" = ? WHERE id = ?;
+	`
+
+	// sqlOrphanLittlesByUserID is the SQL statement used to remove a User as the
+	// big brother of all of their littles
+	sqlOrphanLittlesByUserID = `
+		UPDATE users SET "big_brother_id" = 0 WHERE big_brother_id = ?;
+	`
+)
+
+var (
+	// ErrFamilyCycle is returned when setting a User's big brother would create
+	// a cycle in the family tree.
+	ErrFamilyCycle = errors.New("db: big brother would create a family tree cycle")
+)
+
+// SelectLittlesByUserID returns a slice of all Users whose big brother is the
+// User with the input ID.
+func (db *DB) SelectLittlesByUserID(ctx context.Context, userID uint64) ([]*models.User, error) {
+	return selectAll[models.User](ctx, db, sqlSelectLittlesByUserID, userID)
+}
+
+// SelectAncestorsByUserID returns a slice of all Users in the line of big brothers
+// of the User with the input ID, beginning with the User's own big brother.
+func (db *DB) SelectAncestorsByUserID(ctx context.Context, userID uint64) ([]*models.User, error) {
+	return selectAll[models.User](ctx, db, sqlSelectAncestorsByUserID, userID, maxFamilyDepth, userID)
+}
+
+// SelectDescendantsByUserID returns a slice of all Users descended from the User
+// with the input ID through littles, ordered by generation.
+func (db *DB) SelectDescendantsByUserID(ctx context.Context, userID uint64) ([]*models.User, error) {
+	return selectAll[models.User](ctx, db, sqlSelectDescendantsByUserID, userID, maxFamilyDepth, userID)
+}
+
+// IsDescendant returns whether or not the User with the input user ID is descended
+// from the User with the input ancestor ID through littles.
+func (db *DB) IsDescendant(ctx context.Context, ancestorID uint64, userID uint64) (bool, error) {
+	var count int
+	err := db.withPreparedStmt(ctx, sqlSelectIsDescendant, func(stmt *sql.Stmt) error {
+		return stmt.QueryRowContext(ctx, ancestorID, maxFamilyDepth, userID).Scan(&count)
+	})
+
+	return count > 0, err
+}
+
+// SetBigBrother starts a transaction, sets the big brother of the User with the
+// input user ID, and attempts to commit the transaction.  A big ID of 0 removes
+// the User's big brother.
+func (db *DB) SetBigBrother(ctx context.Context, userID uint64, bigID uint64) error {
+	return db.WithTx(ctx, func(tx *Tx) error {
+		return tx.SetBigBrother(ctx, userID, bigID)
+	})
+}
+
+// SetBigBrother sets the big brother of the User with the input user ID, in the
+// context of the current transaction.  If the change would create a cycle in the
+// family tree, ErrFamilyCycle is returned.
+func (tx *Tx) SetBigBrother(ctx context.Context, userID uint64, bigID uint64) error {
+	if bigID != 0 {
+		// A user cannot be their own big brother
+		if bigID == userID {
+			return ErrFamilyCycle
+		}
+
+		// A user's big brother cannot be one of their descendants
+		var count int
+		if err := tx.Tx.QueryRowContext(ctx, sqlSelectIsDescendant, userID, maxFamilyDepth, bigID).Scan(&count); err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrFamilyCycle
+		}
+	}
+
+	_, err := tx.Tx.ExecContext(ctx, sqlUpdateBigBrother, bigID, userID)
+	return err
+}
+
+// OrphanLittles removes the User with the input ID as the big brother of all
+// of their littles, in the context of the current transaction.
+func (tx *Tx) OrphanLittles(ctx context.Context, userID uint64) error {
+	_, err := tx.Tx.ExecContext(ctx, sqlOrphanLittlesByUserID, userID)
+	return err
+}
@@ -0,0 +1,115 @@
+package data
+
+import (
+	"context"
+
+	"github.com/mdlayher/deltaiota/data/models"
+)
+
+// invitationsTable maps Invitations onto the invitations table.
+var invitationsTable = newTable[models.Invitation]("invitations")
+
+var (
+	// sqlSelectAllInvitations is the SQL statement used to select all Invitations
+	sqlSelectAllInvitations = invitationsTable.selectWhere("ORDER BY id")
+
+	// sqlSelectUnsentInvitations is the SQL statement used to select all Invitations
+	// which have not yet been mailed
+	sqlSelectUnsentInvitations = invitationsTable.selectWhere("WHERE sent = 0 ORDER BY id")
+
+	// sqlSelectInvitationByID is the SQL statement used to select a single
+	// Invitation by ID
+	sqlSelectInvitationByID = invitationsTable.selectWhere("WHERE id = ?")
+
+	// sqlSelectInvitationByToken is the SQL statement used to select a single
+	// Invitation by its token
+	sqlSelectInvitationByToken = invitationsTable.selectWhere("WHERE token = ?")
+
+	// sqlSelectInvitationsByUserID is the SQL statement used to select all
+	// Invitations for an existing User, by the User's ID
+	sqlSelectInvitationsByUserID = invitationsTable.selectWhere("WHERE user_id = ? ORDER BY id")
+
+	// sqlDeleteInvitationsByUserID is the SQL statement used to delete all
+	// Invitations for an existing User, by the User's ID
+	sqlDeleteInvitationsByUserID = invitationsTable.deleteWhere("user_id = ?")
+)
+
+// SelectAllInvitations returns a slice of all Invitations from the database.
+func (db *DB) SelectAllInvitations(ctx context.Context) ([]*models.Invitation, error) {
+	return selectAll[models.Invitation](ctx, db, sqlSelectAllInvitations)
+}
+
+// SelectUnsentInvitations returns a slice of all Invitations which have not yet
+// been mailed from the database.
+func (db *DB) SelectUnsentInvitations(ctx context.Context) ([]*models.Invitation, error) {
+	return selectAll[models.Invitation](ctx, db, sqlSelectUnsentInvitations)
+}
+
+// SelectInvitationByID returns a single Invitation by ID from the database.
+func (db *DB) SelectInvitationByID(ctx context.Context, id uint64) (*models.Invitation, error) {
+	return selectSingle[models.Invitation](ctx, db, sqlSelectInvitationByID, id)
+}
+
+// SelectInvitationByToken returns a single Invitation by its token from the database.
+func (db *DB) SelectInvitationByToken(ctx context.Context, token string) (*models.Invitation, error) {
+	return selectSingle[models.Invitation](ctx, db, sqlSelectInvitationByToken, token)
+}
+
+// SelectInvitationsByUserID returns a slice of all Invitations for the existing
+// User with the input ID from the database.
+func (db *DB) SelectInvitationsByUserID(ctx context.Context, userID uint64) ([]*models.Invitation, error) {
+	return selectAll[models.Invitation](ctx, db, sqlSelectInvitationsByUserID, userID)
+}
+
+// InsertInvitation starts a transaction, inserts a new Invitation, and attempts
+// to commit the transaction.
+func (db *DB) InsertInvitation(ctx context.Context, i *models.Invitation) error {
+	return db.WithTx(ctx, func(tx *Tx) error {
+		return tx.InsertInvitation(ctx, i)
+	})
+}
+
+// UpdateInvitation starts a transaction, updates the input Invitation by its ID,
+// and attempts to commit the transaction.
+func (db *DB) UpdateInvitation(ctx context.Context, i *models.Invitation) error {
+	return db.WithTx(ctx, func(tx *Tx) error {
+		return tx.UpdateInvitation(ctx, i)
+	})
+}
+
+// DeleteInvitation starts a transaction, deletes the input Invitation by its ID,
+// and attempts to commit the transaction.
+func (db *DB) DeleteInvitation(ctx context.Context, i *models.Invitation) error {
+	return db.WithTx(ctx, func(tx *Tx) error {
+		return tx.DeleteInvitation(ctx, i)
+	})
+}
+
+// InsertInvitation inserts a new Invitation in the context of the current transaction.
+func (tx *Tx) InsertInvitation(ctx context.Context, i *models.Invitation) error {
+	return insertRow(ctx, tx, invitationsTable, i)
+}
+
+// UpdateInvitation updates the input Invitation by its ID, in the context of the
+// current transaction.
+func (tx *Tx) UpdateInvitation(ctx context.Context, i *models.Invitation) error {
+	return updateRow(ctx, tx, invitationsTable, i)
+}
+
+// DeleteInvitation deletes the input Invitation by its ID, in the context of the
+// current transaction.
+func (tx *Tx) DeleteInvitation(ctx context.Context, i *models.Invitation) error {
+	return deleteRow(ctx, tx, invitationsTable, i)
+}
+
+// DeleteInvitationsByUserID deletes all Invitations for the existing User with
+// the input ID, in the context of the current transaction.
+func (tx *Tx) DeleteInvitationsByUserID(ctx context.Context, userID uint64) error {
+	_, err := tx.Tx.ExecContext(ctx, sqlDeleteInvitationsByUserID, userID)
+	return err
+}
+
+// ScanInvitations returns a slice of Invitations from wrapped rows.
+func (r *Rows) ScanInvitations() ([]*models.Invitation, error) {
+	return ScanInto[models.Invitation](r)
+}
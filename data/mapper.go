@@ -0,0 +1,330 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// mappings caches the column to field index mapping for each model type
+// scanned or written by the mapper, keyed by reflect.Type.
+var mappings sync.Map
+
+// mapping describes how the fields of a model struct type map onto database
+// columns, using the `db` tags of the struct's fields.
+type mapping struct {
+	// Column names, in struct field order
+	columns []string
+
+	// Struct field index for each column name
+	fields map[string]int
+}
+
+// mappingFor returns the mapping for the input struct type, creating and
+// caching it on first use.
+func mappingFor(typ reflect.Type) *mapping {
+	if m, ok := mappings.Load(typ); ok {
+		return m.(*mapping)
+	}
+
+	if typ.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("db: cannot map non-struct type %s", typ))
+	}
+
+	m := &mapping{
+		fields: make(map[string]int),
+	}
+	for i := 0; i < typ.NumField(); i++ {
+		// Skip fields with no column, or which are explicitly ignored
+		column := typ.Field(i).Tag.Get("db")
+		if column == "" || column == "-" {
+			continue
+		}
+
+		m.columns = append(m.columns, column)
+		m.fields[column] = i
+	}
+
+	actual, _ := mappings.LoadOrStore(typ, m)
+	return actual.(*mapping)
+}
+
+// A table maps a model struct type onto a database table.  The column named
+// "id" is treated as the table's generated primary key, and is never written
+// by inserts or updates.
+type table[T any] struct {
+	name string
+	*mapping
+
+	// Generated SQL statements, built once when the table is created so that
+	// they may be reused as prepared statements
+	insert  string
+	update  string
+	delete  string
+	replace string
+}
+
+// newTable creates a table with the input name for the model type T.  It
+// panics if T is not a struct type with an "id" column, since that is a
+// programming error.
+func newTable[T any](name string) *table[T] {
+	m := mappingFor(reflect.TypeOf((*T)(nil)).Elem())
+	if _, ok := m.fields["id"]; !ok {
+		panic(fmt.Sprintf("db: table %q has no id column", name))
+	}
+
+	t := &table[T]{
+		name:    name,
+		mapping: m,
+	}
+
+	// Build insert and update statements using all columns except the ID
+	writes := t.writeColumns()
+	t.insert = fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s);",
+		t.name,
+		quoteColumns(writes),
+		strings.TrimSuffix(strings.Repeat("?, ", len(writes)), ", "),
+	)
+
+	sets := make([]string, 0, len(writes))
+	for _, c := range writes {
+		sets = append(sets, fmt.Sprintf("%q = ?", c))
+	}
+	t.update = fmt.Sprintf("UPDATE %s SET %s WHERE id = ?;", t.name, strings.Join(sets, ", "))
+
+	t.delete = t.deleteWhere("id = ?")
+
+	return t
+}
+
+// newKeyedTable creates a table with the input name for the model type T,
+// for tables with no generated ID, which are instead keyed by a unique set
+// of columns, such as a User's membership in a Committee.  Rows of a keyed
+// table are only written using replaceRow, and removed using statements
+// built with deleteWhere.  It panics if T is not a struct type, or has an
+// "id" column, since that is a programming error.
+func newKeyedTable[T any](name string) *table[T] {
+	m := mappingFor(reflect.TypeOf((*T)(nil)).Elem())
+	if _, ok := m.fields["id"]; ok {
+		panic(fmt.Sprintf("db: keyed table %q has an id column", name))
+	}
+
+	t := &table[T]{
+		name:    name,
+		mapping: m,
+	}
+
+	// Build a statement which inserts a row, or replaces the existing row
+	// with the same key
+	t.replace = fmt.Sprintf("INSERT OR REPLACE INTO %s (%s) VALUES (%s);",
+		t.name,
+		quoteColumns(t.columns),
+		strings.TrimSuffix(strings.Repeat("?, ", len(t.columns)), ", "),
+	)
+
+	return t
+}
+
+// selectWhere returns a SQL statement which selects all columns of the table,
+// followed by the input clause, such as a WHERE or ORDER BY clause.
+func (t *table[T]) selectWhere(clause string) string {
+	return fmt.Sprintf("SELECT %s FROM %s %s;", quoteColumns(t.columns), t.name, clause)
+}
+
+// qualifiedColumns returns a comma-separated, quoted list of all columns of the
+// table, each qualified by the table's name, for use in queries which join
+// other tables.
+func (t *table[T]) qualifiedColumns() string {
+	qualified := make([]string, 0, len(t.columns))
+	for _, c := range t.columns {
+		qualified = append(qualified, fmt.Sprintf("%s.%q", t.name, c))
+	}
+
+	return strings.Join(qualified, ", ")
+}
+
+// deleteWhere returns a SQL statement which deletes all rows of the table
+// matching the input condition.
+func (t *table[T]) deleteWhere(cond string) string {
+	return fmt.Sprintf("DELETE FROM %s WHERE %s;", t.name, cond)
+}
+
+// writeColumns returns all columns of the table except the ID, in struct
+// field order.
+func (t *table[T]) writeColumns() []string {
+	columns := make([]string, 0, len(t.columns))
+	for _, c := range t.columns {
+		if c != "id" {
+			columns = append(columns, c)
+		}
+	}
+
+	return columns
+}
+
+// writeValues returns the values of all written columns of the input model,
+// followed by its ID for use in a WHERE clause.
+func (t *table[T]) writeValues(v *T) []interface{} {
+	rv := reflect.ValueOf(v).Elem()
+
+	values := make([]interface{}, 0, len(t.columns))
+	for _, c := range t.writeColumns() {
+		values = append(values, rv.Field(t.fields[c]).Interface())
+	}
+
+	// Last argument for WHERE clause
+	return append(values, rv.Field(t.fields["id"]).Interface())
+}
+
+// values returns the values of all columns of the input model, in struct field
+// order.
+func (t *table[T]) values(v *T) []interface{} {
+	rv := reflect.ValueOf(v).Elem()
+
+	values := make([]interface{}, 0, len(t.columns))
+	for _, c := range t.columns {
+		values = append(values, rv.Field(t.fields[c]).Interface())
+	}
+
+	return values
+}
+
+// id returns a reflect.Value for the ID field of the input model.
+func (t *table[T]) id(v *T) reflect.Value {
+	return reflect.ValueOf(v).Elem().Field(t.fields["id"])
+}
+
+// quoteColumns returns a comma-separated, quoted list of the input columns.
+func quoteColumns(columns []string) string {
+	quoted := make([]string, 0, len(columns))
+	for _, c := range columns {
+		quoted = append(quoted, fmt.Sprintf("%q", c))
+	}
+
+	return strings.Join(quoted, ", ")
+}
+
+// ScanInto returns a slice of T from wrapped rows.  Each column in the result
+// set is scanned into the field of T with a matching `db` tag, so the order of
+// columns in a query does not matter.  A column with no matching field is an
+// error, as is any error which occurs while iterating the rows.
+func ScanInto[T any](r *Rows) ([]*T, error) {
+	m := mappingFor(reflect.TypeOf((*T)(nil)).Elem())
+
+	// Determine the field index for each column in the result set
+	columns, err := r.Rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make([]int, len(columns))
+	for i, c := range columns {
+		f, ok := m.fields[c]
+		if !ok {
+			return nil, fmt.Errorf("db: no field for column %q in %T", c, *new(T))
+		}
+
+		fields[i] = f
+	}
+
+	// Iterate all returned rows
+	var out []*T
+	dest := make([]interface{}, len(columns))
+	for r.Rows.Next() {
+		// Scan new value into struct, using fields matched to columns
+		v := new(T)
+		rv := reflect.ValueOf(v).Elem()
+		for i, f := range fields {
+			dest[i] = rv.Field(f).Addr().Interface()
+		}
+
+		if err := r.Rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+
+		out = append(out, v)
+	}
+
+	return out, r.Rows.Err()
+}
+
+// selectAll returns a slice of T from the database, based upon an input SQL
+// query and arguments.
+func selectAll[T any](ctx context.Context, db *DB, query string, args ...interface{}) ([]*T, error) {
+	// Slice of results to return
+	var out []*T
+
+	// Invoke closure with prepared statement and wrapped rows,
+	// passing any arguments from the caller
+	err := db.withPreparedRows(ctx, query, func(rows *Rows) error {
+		var err error
+		out, err = ScanInto[T](rows)
+		return err
+	}, args...)
+
+	return out, err
+}
+
+// selectSingle returns a single T from the database, based upon an input SQL
+// query and arguments.  sql.ErrNoRows is returned if no results are found, and
+// ErrMultipleResults is returned if more than one result is found.
+func selectSingle[T any](ctx context.Context, db *DB, query string, args ...interface{}) (*T, error) {
+	out, err := selectAll[T](ctx, db, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	// Verify only 0 or 1 result returned
+	if len(out) == 0 {
+		return nil, sql.ErrNoRows
+	} else if len(out) == 1 {
+		return out[0], nil
+	}
+
+	// More than one result returned
+	return nil, ErrMultipleResults
+}
+
+// insertRow inserts the input model into table t, in the context of the input
+// transaction, and stores its generated ID.
+func insertRow[T any](ctx context.Context, tx *Tx, t *table[T], v *T) error {
+	values := t.writeValues(v)
+	result, err := tx.Tx.ExecContext(ctx, t.insert, values[:len(values)-1]...)
+	if err != nil {
+		return err
+	}
+
+	// Retrieve generated ID
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	// Store generated ID
+	t.id(v).SetUint(uint64(id))
+	return nil
+}
+
+// updateRow updates the input model in table t by its ID, in the context of
+// the input transaction.
+func updateRow[T any](ctx context.Context, tx *Tx, t *table[T], v *T) error {
+	_, err := tx.Tx.ExecContext(ctx, t.update, t.writeValues(v)...)
+	return err
+}
+
+// deleteRow deletes the input model from table t by its ID, in the context of
+// the input transaction.
+func deleteRow[T any](ctx context.Context, tx *Tx, t *table[T], v *T) error {
+	_, err := tx.Tx.ExecContext(ctx, t.delete, t.id(v).Interface())
+	return err
+}
+
+// replaceRow inserts the input model into keyed table t, replacing any existing
+// row with the same key, in the context of the input transaction.
+func replaceRow[T any](ctx context.Context, tx *Tx, t *table[T], v *T) error {
+	_, err := tx.Tx.ExecContext(ctx, t.replace, t.values(v)...)
+	return err
+}
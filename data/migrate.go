@@ -0,0 +1,240 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mdlayher/deltaiota/bindata"
+)
+
+const (
+	// sqlite3MigrationsDir is the name of the bindata asset directory which stores
+	// sqlite3 schema migrations
+	sqlite3MigrationsDir = "res/sqlite/migrations"
+
+	// sqlCreateSchemaMigrations is the SQL statement used to create the table which
+	// tracks applied schema migrations
+	sqlCreateSchemaMigrations = `
+		CREATE TABLE IF NOT EXISTS "schema_migrations" (
+			"version"   INTEGER PRIMARY KEY
+			, "name"       TEXT NOT NULL
+			, "applied" INTEGER NOT NULL
+		);
+	`
+
+	// sqlSelectSchemaMigrationsExists is the SQL statement used to determine if
+	// the table which tracks applied schema migrations exists
+	sqlSelectSchemaMigrationsExists = `
+		SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'schema_migrations';
+	`
+
+	// sqlSelectSchemaMigrationVersions is the SQL statement used to select the versions
+	// of all applied schema migrations
+	sqlSelectSchemaMigrationVersions = `
+		SELECT version FROM schema_migrations;
+	`
+
+	// sqlSelectSchemaVersion is the SQL statement used to select the version of
+	// the most recently applied schema migration
+	sqlSelectSchemaVersion = `
+		SELECT COALESCE(MAX(version), 0) FROM schema_migrations;
+	`
+
+	// sqlInsertSchemaMigration is the SQL statement used to record an applied
+	// schema migration
+	sqlInsertSchemaMigration = `
+		INSERT INTO schema_migrations (
+			"version"
+			, "name"
+			, "applied"
+		) VALUES (?, ?, ?);
+	`
+)
+
+// Migration is a versioned set of SQL statements which modify the database schema.
+// Migrations are stored as bindata assets, named in the form: 0001_name.sql.
+type Migration struct {
+	Version int
+	Name    string
+	SQL     string
+}
+
+// Migrations returns all known schema migrations for the database driver, sorted
+// in ascending order by version.
+func (db *DB) Migrations() ([]*Migration, error) {
+	// Only sqlite3 migrations are currently available
+	if db.driver != driverSqlite3 {
+		return nil, nil
+	}
+
+	// Retrieve names of all migration assets
+	names, err := bindata.AssetDir(sqlite3MigrationsDir)
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := make([]*Migration, 0, len(names))
+	for _, n := range names {
+		// Parse version and name from asset name
+		m, err := parseMigrationName(n)
+		if err != nil {
+			return nil, err
+		}
+
+		// Retrieve migration SQL
+		asset, err := bindata.Asset(path.Join(sqlite3MigrationsDir, n))
+		if err != nil {
+			return nil, err
+		}
+		m.SQL = string(asset)
+
+		migrations = append(migrations, m)
+	}
+
+	sort.Sort(migrationsByVersion(migrations))
+	return migrations, nil
+}
+
+// PendingMigrations returns all schema migrations which have not yet been applied
+// to the database, sorted in ascending order by version.
+func (db *DB) PendingMigrations(ctx context.Context) ([]*Migration, error) {
+	// Fetch all known migrations
+	migrations, err := db.Migrations()
+	if err != nil {
+		return nil, err
+	}
+
+	// Ensure migrations table exists before checking it; if it does not, no
+	// migrations have been applied
+	ok, err := db.schemaMigrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return migrations, nil
+	}
+
+	// Fetch all applied migration versions
+	rows, err := db.QueryContext(ctx, sqlSelectSchemaMigrationVersions)
+	if err != nil {
+		return nil, err
+	}
+
+	applied := make(map[int]struct{})
+	for rows.Next() {
+		var v int
+		if err := rows.Scan(&v); err != nil {
+			rows.Close()
+			return nil, err
+		}
+
+		applied[v] = struct{}{}
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Discard any migrations which were already applied
+	var pending []*Migration
+	for _, m := range migrations {
+		if _, ok := applied[m.Version]; ok {
+			continue
+		}
+
+		pending = append(pending, m)
+	}
+
+	return pending, nil
+}
+
+// SchemaVersion returns the version of the most recently applied schema
+// migration, or 0 if none have been applied.
+func (db *DB) SchemaVersion(ctx context.Context) (int, error) {
+	// Ensure migrations table exists before checking it
+	ok, err := db.schemaMigrations(ctx)
+	if err != nil || !ok {
+		return 0, err
+	}
+
+	var v int
+	err = db.QueryRowContext(ctx, sqlSelectSchemaVersion).Scan(&v)
+	return v, err
+}
+
+// schemaMigrations ensures that the table which tracks applied schema
+// migrations exists, and returns whether or not it does.  The table cannot be
+// created in a read-only database, so its existence is only checked.
+func (db *DB) schemaMigrations(ctx context.Context) (bool, error) {
+	if !db.Readonly() {
+		_, err := db.ExecContext(ctx, sqlCreateSchemaMigrations)
+		return err == nil, err
+	}
+
+	var n int
+	err := db.QueryRowContext(ctx, sqlSelectSchemaMigrationsExists).Scan(&n)
+	return n > 0, err
+}
+
+// Migrate applies all pending schema migrations to the database, in order.
+// Each migration is applied and recorded within its own transaction.  On success,
+// the migrations which were applied are returned.
+func (db *DB) Migrate(ctx context.Context) ([]*Migration, error) {
+	// Fetch all migrations which are not yet applied
+	pending, err := db.PendingMigrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, m := range pending {
+		err := db.WithTx(ctx, func(tx *Tx) error {
+			// Apply migration to the schema
+			if _, err := tx.ExecContext(ctx, m.SQL); err != nil {
+				return err
+			}
+
+			// Record migration as applied
+			_, err := tx.ExecContext(ctx, sqlInsertSchemaMigration, m.Version, m.Name, time.Now().Unix())
+			return err
+		})
+		if err != nil {
+			return pending[:i], fmt.Errorf("db: migration %04d_%s: %v", m.Version, m.Name, err)
+		}
+	}
+
+	return pending, nil
+}
+
+// parseMigrationName parses the version and name of a migration from the name
+// of its bindata asset, in the form: 0001_name.sql.
+func parseMigrationName(asset string) (*Migration, error) {
+	// Split version from name
+	pair := strings.SplitN(strings.TrimSuffix(asset, ".sql"), "_", 2)
+	if len(pair) != 2 {
+		return nil, fmt.Errorf("db: invalid migration name: %s", asset)
+	}
+
+	version, err := strconv.Atoi(pair[0])
+	if err != nil {
+		return nil, fmt.Errorf("db: invalid migration version: %s", asset)
+	}
+
+	return &Migration{
+		Version: version,
+		Name:    pair[1],
+	}, nil
+}
+
+// migrationsByVersion is used to sort Migrations by ascending version.
+type migrationsByVersion []*Migration
+
+func (m migrationsByVersion) Len() int           { return len(m) }
+func (m migrationsByVersion) Less(i, j int) bool { return m[i].Version < m[j].Version }
+func (m migrationsByVersion) Swap(i, j int)      { m[i], m[j] = m[j], m[i] }
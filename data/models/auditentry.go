@@ -0,0 +1,40 @@
+package models
+
+// AuditAction is the kind of change recorded by an AuditEntry.
+type AuditAction string
+
+// AuditAction values which may be assigned to an AuditEntry.
+const (
+	AuditCreate AuditAction = "create"
+	AuditUpdate AuditAction = "update"
+	AuditDelete AuditAction = "delete"
+
+	// A deleted User may be restored, or purged permanently
+	AuditRestore AuditAction = "restore"
+	AuditPurge   AuditAction = "purge"
+)
+
+// Valid returns whether or not the receiving AuditAction is a known action.
+func (a AuditAction) Valid() bool {
+	switch a {
+	case AuditCreate, AuditUpdate, AuditDelete, AuditRestore, AuditPurge:
+		return true
+	}
+
+	return false
+}
+
+// AuditEntry represents a single record in the audit log, describing a change
+// made by a User using the HTTP API.  Before and After contain only the fields
+// which were changed, with any secrets redacted.
+type AuditEntry struct {
+	ID         uint64      `db:"id" json:"id"`
+	ActorID    uint64      `db:"actor_id" json:"actorId"`
+	Action     AuditAction `db:"action" json:"action"`
+	TargetType string      `db:"target_type" json:"targetType"`
+	TargetID   uint64      `db:"target_id" json:"targetId"`
+	Before     JSONText    `db:"before" json:"before"`
+	After      JSONText    `db:"after" json:"after"`
+	RemoteAddr string      `db:"remote_addr" json:"remoteAddr"`
+	Created    uint64      `db:"created" json:"created"`
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+)
+
+// CalendarToken represents a secret token which grants read-only access to a
+// User's calendar feed.  Calendar applications cannot perform the usual key
+// authentication, so the token is passed as part of the feed URL instead.
+type CalendarToken struct {
+	UserID  uint64 `db:"user_id" json:"userId"`
+	Token   string `db:"token" json:"token"`
+	Created uint64 `db:"created" json:"created"`
+}
+
+// NewCalendarToken generates a new, random CalendarToken for the specified user ID.
+func NewCalendarToken(userID uint64) (*CalendarToken, error) {
+	token, err := randomToken(20)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CalendarToken{
+		UserID:  userID,
+		Token:   token,
+		Created: uint64(time.Now().Unix()),
+	}, nil
+}
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"fmt"
+)
+
+// Cents is an amount of money, in US cents.  Monetary amounts are always stored
+// as integer cents, to avoid floating point rounding errors.
+type Cents int64
+
+// String returns a human-readable dollar representation of the receiving Cents,
+// such as "$12.34" or "-$0.50".
+func (c Cents) String() string {
+	sign := ""
+	if c < 0 {
+		sign = "-"
+		c = -c
+	}
+
+	return fmt.Sprintf("%s$%d.%02d", sign, c/100, c%100)
+}
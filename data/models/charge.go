@@ -0,0 +1,93 @@
+package models
+
+import (
+	"time"
+)
+
+// ChargeKind is the kind of a Charge assigned to a User.
+type ChargeKind string
+
+// ChargeKind values which may be assigned to a Charge.
+const (
+	ChargeDues ChargeKind = "dues"
+	ChargeFine ChargeKind = "fine"
+	ChargeFee  ChargeKind = "fee"
+)
+
+// Valid returns whether or not the receiving ChargeKind is a known kind of charge.
+func (k ChargeKind) Valid() bool {
+	switch k {
+	case ChargeDues, ChargeFine, ChargeFee:
+		return true
+	}
+
+	return false
+}
+
+// Charge represents an amount owed by a User, such as dues, a fine, or a fee,
+// which is due at a certain time.
+type Charge struct {
+	ID          uint64     `db:"id" json:"id"`
+	UserID      uint64     `db:"user_id" json:"userId"`
+	Kind        ChargeKind `db:"kind" json:"kind"`
+	Description string     `db:"description" json:"description"`
+	Amount      Cents      `db:"amount" json:"amount"`
+	Created     uint64     `db:"created" json:"created"`
+	Due         uint64     `db:"due" json:"due"`
+
+	// Reminded and Overdue record whether or not a User has been notified
+	// of this Charge before and after it is due, respectively.
+	Reminded bool `db:"reminded" json:"-"`
+	Overdue  bool `db:"overdue" json:"-"`
+}
+
+// IsDue returns whether or not the receiving Charge is due at the input time.
+func (c *Charge) IsDue(at time.Time) bool {
+	return c.Due <= uint64(at.Unix())
+}
+
+// CopyFrom copies fields from an input Charge into the receiving Charge struct.
+func (c *Charge) CopyFrom(charge *Charge) {
+	c.Kind = charge.Kind
+	c.Description = charge.Description
+	c.Amount = charge.Amount
+	c.Due = charge.Due
+}
+
+// Validate verifies that all fields for the receiving Charge struct contain
+// valid input.
+func (c *Charge) Validate() error {
+	// Check for required fields
+	if c.UserID == 0 {
+		return &EmptyFieldError{
+			Field: "userId",
+		}
+	}
+	if c.Kind == "" {
+		return &EmptyFieldError{
+			Field: "kind",
+		}
+	}
+	if c.Due == 0 {
+		return &EmptyFieldError{
+			Field: "due",
+		}
+	}
+
+	if !c.Kind.Valid() {
+		return &InvalidFieldError{
+			Field:   "kind",
+			Details: "unknown kind of charge",
+		}
+	}
+
+	// Credits are recorded as payments, not negative charges
+	if c.Amount <= 0 {
+		return &InvalidFieldError{
+			Field:   "amount",
+			Details: "amount must be a positive number of cents",
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,35 @@
+package models
+
+// Committee represents a chapter committee, which organizes a group of Users
+// around a particular area of chapter work.
+type Committee struct {
+	ID          uint64 `db:"id" json:"id"`
+	Name        string `db:"name" json:"name"`
+	Description string `db:"description" json:"description"`
+}
+
+// CopyFrom copies fields from an input Committee into the receiving Committee struct.
+func (c *Committee) CopyFrom(committee *Committee) {
+	c.Name = committee.Name
+	c.Description = committee.Description
+}
+
+// Validate verifies that all fields for the receiving Committee struct contain
+// valid input.
+func (c *Committee) Validate() error {
+	if c.Name == "" {
+		return &EmptyFieldError{
+			Field: "name",
+		}
+	}
+
+	return nil
+}
+
+// CommitteeMember represents a User's membership in a Committee.  Chairs lead
+// the committee, and may manage its membership.
+type CommitteeMember struct {
+	CommitteeID uint64 `db:"committee_id" json:"committeeId"`
+	UserID      uint64 `db:"user_id" json:"userId"`
+	Chair       bool   `db:"chair" json:"chair"`
+}
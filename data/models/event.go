@@ -0,0 +1,116 @@
+package models
+
+import (
+	"time"
+)
+
+// Event represents a scheduled chapter event, such as a meeting or social.
+type Event struct {
+	ID          uint64 `db:"id" json:"id"`
+	Title       string `db:"title" json:"title"`
+	Description string `db:"description" json:"description"`
+	Location    string `db:"location" json:"location"`
+	Start       uint64 `db:"start" json:"start"`
+	End         uint64 `db:"end" json:"end"`
+	AllDay      bool   `db:"all_day" json:"allDay"`
+	Created     uint64 `db:"created" json:"created"`
+	Updated     uint64 `db:"updated" json:"updated"`
+}
+
+// StartTime returns the start of the receiving Event as a time.Time value.
+func (e *Event) StartTime() time.Time {
+	return time.Unix(int64(e.Start), 0)
+}
+
+// EndTime returns the end of the receiving Event as a time.Time value.  If the
+// Event has no end time, the zero time.Time value is returned.
+func (e *Event) EndTime() time.Time {
+	if e.End == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(int64(e.End), 0)
+}
+
+// CopyFrom copies fields from an input Event into the receiving Event struct.
+func (e *Event) CopyFrom(event *Event) {
+	e.Title = event.Title
+	e.Description = event.Description
+	e.Location = event.Location
+	e.Start = event.Start
+	e.End = event.End
+	e.AllDay = event.AllDay
+}
+
+// Validate verifies that all fields for the receiving Event struct contain
+// valid input.
+func (e *Event) Validate() error {
+	// Check for required fields
+	if e.Title == "" {
+		return &EmptyFieldError{
+			Field: "title",
+		}
+	}
+	if e.Start == 0 {
+		return &EmptyFieldError{
+			Field: "start",
+		}
+	}
+
+	// An end time of zero indicates no end time
+	if e.End != 0 && e.End < e.Start {
+		return &InvalidFieldError{
+			Field:   "end",
+			Details: "event must end after it starts",
+		}
+	}
+
+	return nil
+}
+
+// RSVPResponse is a User's response to an invitation to an Event.
+type RSVPResponse string
+
+// RSVPResponse values which may be assigned to an RSVP.
+const (
+	RSVPYes   RSVPResponse = "yes"
+	RSVPNo    RSVPResponse = "no"
+	RSVPMaybe RSVPResponse = "maybe"
+)
+
+// Valid returns whether or not the receiving RSVPResponse is a known response.
+func (r RSVPResponse) Valid() bool {
+	switch r {
+	case RSVPYes, RSVPNo, RSVPMaybe:
+		return true
+	}
+
+	return false
+}
+
+// RSVP represents a User's response to an Event.
+type RSVP struct {
+	EventID  uint64       `db:"event_id" json:"eventId"`
+	UserID   uint64       `db:"user_id" json:"userId"`
+	Response RSVPResponse `db:"response" json:"response"`
+	Updated  uint64       `db:"updated" json:"updated"`
+}
+
+// Validate verifies that all fields for the receiving RSVP struct contain
+// valid input.
+func (r *RSVP) Validate() error {
+	if r.Response == "" {
+		return &EmptyFieldError{
+			Field: "response",
+		}
+	}
+
+	if !r.Response.Valid() {
+		return &InvalidFieldError{
+			Field:   "response",
+			Details: "response must be one of: yes, no, maybe",
+		}
+	}
+
+	return nil
+}
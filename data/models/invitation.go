@@ -0,0 +1,68 @@
+package models
+
+import (
+	"net/mail"
+	"time"
+)
+
+// Invitation represents an invitation, sent to an email address, to set up an
+// account.  If UserID is set, the invitation allows an existing User, such as
+// one created by a roster import, to choose their password.
+type Invitation struct {
+	ID      uint64 `db:"id" json:"id"`
+	Email   string `db:"email" json:"email"`
+	UserID  uint64 `db:"user_id" json:"userId"`
+	Token   string `db:"token" json:"-"`
+	Created uint64 `db:"created" json:"created"`
+	Expire  uint64 `db:"expire" json:"expire"`
+
+	// Sent is the time at which the invitation was mailed, or zero if it is
+	// still queued for delivery.
+	Sent uint64 `db:"sent" json:"sent"`
+}
+
+// NewInvitation creates a new Invitation for the specified email address and
+// optional user ID, which will expire at the specified time.
+func NewInvitation(email string, userID uint64, expire time.Time) (*Invitation, error) {
+	token, err := randomToken(20)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Invitation{
+		Email:   email,
+		UserID:  userID,
+		Token:   token,
+		Created: uint64(time.Now().Unix()),
+		Expire:  uint64(expire.Unix()),
+	}, nil
+}
+
+// IsExpired returns if the current invitation is expired; meaning that the
+// current UNIX timestamp is greater than the one set for the invitation.
+func (i *Invitation) IsExpired() bool {
+	return uint64(time.Now().Unix()) > i.Expire
+}
+
+// Validate verifies that all fields for the receiving Invitation struct contain
+// valid input.
+func (i *Invitation) Validate() error {
+	if i.Email == "" {
+		return &EmptyFieldError{
+			Field: "email",
+		}
+	}
+
+	// Perform basic validation of email address
+	address, err := mail.ParseAddress(i.Email)
+	if err != nil {
+		return &InvalidFieldError{
+			Field:   "email",
+			Err:     err,
+			Details: "could not parse valid email address",
+		}
+	}
+	i.Email = address.Address
+
+	return nil
+}
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+)
+
+// JSONText is a raw JSON value which is stored in a single database column.
+// An empty JSONText is stored as NULL, and encoded as JSON null.
+type JSONText []byte
+
+// Scan implements sql.Scanner, and copies a JSON value from a database column
+// into the receiving JSONText.
+func (j *JSONText) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*j = nil
+	case []byte:
+		*j = append(JSONText(nil), v...)
+	case string:
+		*j = JSONText(v)
+	default:
+		return fmt.Errorf("models: cannot scan %T into JSONText", src)
+	}
+
+	return nil
+}
+
+// Value implements driver.Valuer, and returns the receiving JSONText for storage
+// in a database column.
+func (j JSONText) Value() (driver.Value, error) {
+	// Empty value is stored as NULL
+	if len(j) == 0 {
+		return nil, nil
+	}
+
+	return string(j), nil
+}
+
+// MarshalJSON implements json.Marshaler, and returns the receiving JSONText
+// as-is, or null if it is empty.
+func (j JSONText) MarshalJSON() ([]byte, error) {
+	if len(j) == 0 {
+		return []byte("null"), nil
+	}
+
+	return j, nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler, and copies the input JSON value
+// into the receiving JSONText.
+func (j *JSONText) UnmarshalJSON(b []byte) error {
+	if j == nil {
+		return errors.New("models: UnmarshalJSON on nil JSONText")
+	}
+
+	// JSON null is an empty value
+	if string(b) == "null" {
+		*j = nil
+		return nil
+	}
+
+	*j = append(JSONText(nil), b...)
+	return nil
+}
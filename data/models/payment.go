@@ -0,0 +1,80 @@
+package models
+
+// PaymentMethod is the method by which a Payment was made.
+type PaymentMethod string
+
+// PaymentMethod values which may be assigned to a Payment.
+const (
+	PaymentCash   PaymentMethod = "cash"
+	PaymentCheck  PaymentMethod = "check"
+	PaymentCard   PaymentMethod = "card"
+	PaymentOnline PaymentMethod = "online"
+	PaymentOther  PaymentMethod = "other"
+)
+
+// Valid returns whether or not the receiving PaymentMethod is a known payment
+// method.
+func (m PaymentMethod) Valid() bool {
+	switch m {
+	case PaymentCash, PaymentCheck, PaymentCard, PaymentOnline, PaymentOther:
+		return true
+	}
+
+	return false
+}
+
+// Payment represents an amount paid by a User, as recorded by an officer.
+// Payments are applied to a User's Charges, oldest due first.
+type Payment struct {
+	ID         uint64        `db:"id" json:"id"`
+	UserID     uint64        `db:"user_id" json:"userId"`
+	Amount     Cents         `db:"amount" json:"amount"`
+	Method     PaymentMethod `db:"method" json:"method"`
+	Reference  string        `db:"reference" json:"reference"`
+	Timestamp  uint64        `db:"timestamp" json:"timestamp"`
+	RecordedBy uint64        `db:"recorded_by" json:"recordedBy"`
+}
+
+// Validate verifies that all fields for the receiving Payment struct contain
+// valid input.
+func (p *Payment) Validate() error {
+	// Check for required fields
+	if p.UserID == 0 {
+		return &EmptyFieldError{
+			Field: "userId",
+		}
+	}
+	if p.Method == "" {
+		return &EmptyFieldError{
+			Field: "method",
+		}
+	}
+
+	if !p.Method.Valid() {
+		return &InvalidFieldError{
+			Field:   "method",
+			Details: "unknown payment method",
+		}
+	}
+
+	// Refunds are not payments
+	if p.Amount <= 0 {
+		return &InvalidFieldError{
+			Field:   "amount",
+			Details: "amount must be a positive number of cents",
+		}
+	}
+
+	return nil
+}
+
+// Balance is a summary of a User's dues ledger.  A positive Balance is owed by
+// the User; a negative Balance is a credit.  Overdue is the portion of Balance
+// which is past due.
+type Balance struct {
+	UserID  uint64 `json:"userId"`
+	Charged Cents  `json:"charged"`
+	Paid    Cents  `json:"paid"`
+	Balance Cents  `json:"balance"`
+	Overdue Cents  `json:"overdue"`
+}
@@ -0,0 +1,29 @@
+package models
+
+// Position represents an officer position within the chapter, such as
+// President or Treasurer.
+type Position struct {
+	ID          uint64 `db:"id" json:"id"`
+	Name        string `db:"name" json:"name"`
+	Description string `db:"description" json:"description"`
+	Rank        int    `db:"rank" json:"rank"`
+}
+
+// CopyFrom copies fields from an input Position into the receiving Position struct.
+func (p *Position) CopyFrom(position *Position) {
+	p.Name = position.Name
+	p.Description = position.Description
+	p.Rank = position.Rank
+}
+
+// Validate verifies that all fields for the receiving Position struct contain
+// valid input.
+func (p *Position) Validate() error {
+	if p.Name == "" {
+		return &EmptyFieldError{
+			Field: "name",
+		}
+	}
+
+	return nil
+}
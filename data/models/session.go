@@ -6,7 +6,7 @@ import (
 	"fmt"
 	"time"
 
-	"code.google.com/p/go.crypto/pbkdf2"
+	"golang.org/x/crypto/pbkdf2"
 )
 
 // Session represents an application session.
@@ -50,27 +50,3 @@ func (s *Session) IsExpired() bool {
 func (s *Session) SetExpire(expire time.Time) {
 	s.Expire = uint64(expire.Unix())
 }
-
-// SQLReadFields returns the correct field order to scan SQL row results into the
-// receiving Session struct.
-func (s *Session) SQLReadFields() []interface{} {
-	return []interface{}{
-		&s.ID,
-		&s.UserID,
-		&s.Key,
-		&s.Expire,
-	}
-}
-
-// SQLWriteFields returns the correct field order for SQL write actions (such as
-// insert or update), for the receiving Session struct.
-func (s *Session) SQLWriteFields() []interface{} {
-	return []interface{}{
-		s.UserID,
-		s.Key,
-		s.Expire,
-
-		// Last argument for WHERE clause
-		s.ID,
-	}
-}
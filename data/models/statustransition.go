@@ -0,0 +1,58 @@
+package models
+
+import (
+	"fmt"
+)
+
+// maxReasonLength is the maximum length, in bytes, of the reason given for a
+// StatusTransition.
+const maxReasonLength = 1024
+
+// StatusTransition represents a change in a User's membership status, made by
+// an officer for the specified reason.
+type StatusTransition struct {
+	ID      uint64       `db:"id" json:"id"`
+	UserID  uint64       `db:"user_id" json:"userId"`
+	From    MemberStatus `db:"from_status" json:"from"`
+	To      MemberStatus `db:"to_status" json:"to"`
+	Reason  string       `db:"reason" json:"reason"`
+	ActorID uint64       `db:"actor_id" json:"actorId"`
+	Created uint64       `db:"created" json:"created"`
+}
+
+// Validate verifies that all fields for the receiving StatusTransition struct
+// contain valid input, and that the transition is permitted.
+func (s *StatusTransition) Validate() error {
+	if s.To == "" {
+		return &EmptyFieldError{
+			Field: "status",
+		}
+	}
+	if !s.To.Valid() {
+		return &InvalidFieldError{
+			Field:   "status",
+			Details: "unknown membership status",
+		}
+	}
+
+	if s.Reason == "" {
+		return &EmptyFieldError{
+			Field: "reason",
+		}
+	}
+	if len(s.Reason) > maxReasonLength {
+		return &InvalidFieldError{
+			Field:   "reason",
+			Details: "reason is too long",
+		}
+	}
+
+	if !s.From.CanTransition(s.To) {
+		return &InvalidFieldError{
+			Field:   "status",
+			Details: fmt.Sprintf("membership status may not be changed from %s to %s", s.From, s.To),
+		}
+	}
+
+	return nil
+}
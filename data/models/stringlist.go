@@ -0,0 +1,50 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// StringList is a list of strings which is stored in a single database column,
+// encoded as a JSON array.
+type StringList []string
+
+// Scan implements sql.Scanner, and decodes a JSON array from a database column
+// into the receiving StringList.
+func (s *StringList) Scan(src interface{}) error {
+	var buf []byte
+	switch v := src.(type) {
+	case nil:
+	case []byte:
+		buf = v
+	case string:
+		buf = []byte(v)
+	default:
+		return fmt.Errorf("models: cannot scan %T into StringList", src)
+	}
+
+	// Empty column is an empty list
+	if len(buf) == 0 {
+		*s = nil
+		return nil
+	}
+
+	return json.Unmarshal(buf, s)
+}
+
+// Value implements driver.Valuer, and encodes the receiving StringList as a JSON
+// array for storage in a database column.
+func (s StringList) Value() (driver.Value, error) {
+	// Empty list is stored as an empty column
+	if len(s) == 0 {
+		return "", nil
+	}
+
+	buf, err := json.Marshal([]string(s))
+	if err != nil {
+		return nil, err
+	}
+
+	return string(buf), nil
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"time"
+)
+
+// Term represents a period of time during which a User holds a Position.
+// A Term with an End of 0 is ongoing.
+type Term struct {
+	ID         uint64 `db:"id" json:"id"`
+	UserID     uint64 `db:"user_id" json:"userId"`
+	PositionID uint64 `db:"position_id" json:"positionId"`
+	Start      uint64 `db:"start" json:"start"`
+	End        uint64 `db:"end" json:"end"`
+}
+
+// IsActive returns if the receiving Term is active at the input time; meaning
+// that it has started, and has either not ended or is ongoing.
+func (t *Term) IsActive(at time.Time) bool {
+	now := uint64(at.Unix())
+	return t.Start <= now && (t.End == 0 || t.End > now)
+}
+
+// CopyFrom copies fields from an input Term into the receiving Term struct.
+func (t *Term) CopyFrom(term *Term) {
+	t.UserID = term.UserID
+	t.PositionID = term.PositionID
+	t.Start = term.Start
+	t.End = term.End
+}
+
+// Validate verifies that all fields for the receiving Term struct contain
+// valid input.
+func (t *Term) Validate() error {
+	// Check for required fields
+	if t.UserID == 0 {
+		return &EmptyFieldError{
+			Field: "userId",
+		}
+	}
+	if t.PositionID == 0 {
+		return &EmptyFieldError{
+			Field: "positionId",
+		}
+	}
+	if t.Start == 0 {
+		return &EmptyFieldError{
+			Field: "start",
+		}
+	}
+
+	// Terms must end after they start, if they end at all
+	if t.End != 0 && t.End <= t.Start {
+		return &InvalidFieldError{
+			Field:   "end",
+			Details: "term must end after it starts",
+		}
+	}
+
+	return nil
+}
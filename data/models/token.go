@@ -0,0 +1,17 @@
+package models
+
+import (
+	"crypto/rand"
+	"fmt"
+)
+
+// randomToken generates a random, hex-encoded token from n bytes of
+// cryptographically secure random data.
+func randomToken(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%x", buf), nil
+}
@@ -3,9 +3,10 @@ package models
 import (
 	"errors"
 	"net/mail"
+	"strings"
 	"time"
 
-	"code.google.com/p/go.crypto/bcrypt"
+	"golang.org/x/crypto/bcrypt"
 )
 
 var (
@@ -14,6 +15,89 @@ var (
 	ErrInvalidPassword = errors.New("invalid password")
 )
 
+const (
+	// minGraduationYear is the earliest valid graduation year for a User, the
+	// year in which Phi Mu Alpha Sinfonia was founded.
+	minGraduationYear = 1898
+
+	// maxGraduationYear is the latest valid graduation year for a User.
+	maxGraduationYear = 9999
+
+	// maxBioLength is the maximum length, in bytes, of a User's biography.
+	maxBioLength = 4096
+)
+
+// MemberStatus is the membership status of a User within the chapter.
+type MemberStatus string
+
+// MemberStatus values which may be assigned to a User.
+const (
+	StatusPledge   MemberStatus = "pledge"
+	StatusActive   MemberStatus = "active"
+	StatusAlumni   MemberStatus = "alumni"
+	StatusInactive MemberStatus = "inactive"
+	StatusExpelled MemberStatus = "expelled"
+)
+
+// statusTransitions is the set of membership statuses to which a User may be
+// transitioned from each membership status.  Alumni may be returned to active
+// status, in case of a mistaken transition, but expulsion is final.
+var statusTransitions = map[MemberStatus][]MemberStatus{
+	StatusPledge:   {StatusActive, StatusInactive, StatusExpelled},
+	StatusActive:   {StatusAlumni, StatusInactive, StatusExpelled},
+	StatusAlumni:   {StatusActive, StatusExpelled},
+	StatusInactive: {StatusActive, StatusAlumni, StatusExpelled},
+	StatusExpelled: nil,
+}
+
+// Valid returns whether or not the receiving MemberStatus is a known membership
+// status.
+func (s MemberStatus) Valid() bool {
+	_, ok := statusTransitions[s]
+	return ok
+}
+
+// CanTransition returns whether or not a User with the receiving MemberStatus
+// may be transitioned to the input MemberStatus.
+func (s MemberStatus) CanTransition(to MemberStatus) bool {
+	for _, t := range statusTransitions[s] {
+		if t == to {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Access returns the level of API access granted to a User with the receiving
+// MemberStatus.
+func (s MemberStatus) Access() Access {
+	switch s {
+	case StatusPledge, StatusActive:
+		return AccessMember
+	case StatusAlumni, StatusInactive:
+		return AccessRead
+	}
+
+	return AccessNone
+}
+
+// Access is a level of API access granted to a User by their membership status.
+type Access int
+
+// Access levels, in increasing order of privilege.
+const (
+	// AccessNone users may not authenticate.
+	AccessNone Access = iota
+
+	// AccessRead users may read the directory and other member data, and
+	// manage their own sessions, but may not make changes.
+	AccessRead
+
+	// AccessMember users have full member access.
+	AccessMember
+)
+
 // User represents a user of the application.
 type User struct {
 	ID        uint64 `db:"id" json:"id"`
@@ -23,9 +107,41 @@ type User struct {
 	Email     string `db:"email" json:"email"`
 	Phone     string `db:"phone" json:"phone"`
 	Password  string `db:"password" json:"password,omitempty"`
+
+	InitiationDate uint64       `db:"initiation_date" json:"initiationDate"`
+	PledgeClass    string       `db:"pledge_class" json:"pledgeClass"`
+	BigBrotherID   uint64       `db:"big_brother_id" json:"bigBrotherId"`
+	Instruments    StringList   `db:"instruments" json:"instruments"`
+	GraduationYear int          `db:"graduation_year" json:"graduationYear"`
+	Status         MemberStatus `db:"status" json:"status"`
+	Address        string       `db:"address" json:"address"`
+	Bio            string       `db:"bio" json:"bio"`
+
+	// Avatar is the content type of the User's avatar thumbnails, or empty if
+	// the User has no avatar.  AvatarURL is populated by the API for clients.
+	Avatar    string `db:"avatar" json:"-"`
+	AvatarURL string `json:"avatarUrl,omitempty"`
+
+	// PrivateEmail and PrivatePhone hide the User's email address and phone
+	// number from other members in directory exports.
+	PrivateEmail bool `db:"private_email" json:"privateEmail"`
+	PrivatePhone bool `db:"private_phone" json:"privatePhone"`
+
+	// DeletedAt is the time at which the User was deleted, or zero if the
+	// User is not deleted.  Deleted Users may be restored until they are
+	// purged.
+	DeletedAt uint64 `db:"deleted_at" json:"deletedAt,omitempty"`
+}
+
+// IsDeleted returns whether or not the receiving User has been deleted, but
+// not yet purged.
+func (u *User) IsDeleted() bool {
+	return u.DeletedAt != 0
 }
 
 // CopyFrom copies fields from an input User into the receiving User struct.
+// A User's membership status is not copied, because it may only be changed
+// using a StatusTransition.
 func (u *User) CopyFrom(user *User) {
 	u.Username = user.Username
 	u.FirstName = user.FirstName
@@ -33,6 +149,15 @@ func (u *User) CopyFrom(user *User) {
 	u.Email = user.Email
 	u.Phone = user.Phone
 	u.Password = user.Password
+	u.InitiationDate = user.InitiationDate
+	u.PledgeClass = user.PledgeClass
+	u.BigBrotherID = user.BigBrotherID
+	u.Instruments = user.Instruments
+	u.GraduationYear = user.GraduationYear
+	u.Address = user.Address
+	u.Bio = user.Bio
+	u.PrivateEmail = user.PrivateEmail
+	u.PrivatePhone = user.PrivatePhone
 }
 
 // NewSession generates a new Session for this user.
@@ -60,6 +185,12 @@ func (u *User) SetPassword(password string) error {
 	return nil
 }
 
+// RandomPassword generates a random password, which may be assigned to a User
+// who will later choose their own password.
+func RandomPassword() (string, error) {
+	return randomToken(16)
+}
+
 // TryPassword attempts to verify the input password against the receiving User's
 // current password.
 func (u *User) TryPassword(password string) error {
@@ -77,36 +208,6 @@ func (u *User) TryPassword(password string) error {
 	return err
 }
 
-// SQLReadFields returns the correct field order to scan SQL row results into the
-// receiving User struct.
-func (u *User) SQLReadFields() []interface{} {
-	return []interface{}{
-		&u.ID,
-		&u.Username,
-		&u.FirstName,
-		&u.LastName,
-		&u.Email,
-		&u.Phone,
-		&u.Password,
-	}
-}
-
-// SQLWriteFields returns the correct field order for SQL write actions (such as
-// insert or update), for the receiving User struct.
-func (u *User) SQLWriteFields() []interface{} {
-	return []interface{}{
-		u.Username,
-		u.FirstName,
-		u.LastName,
-		u.Email,
-		u.Phone,
-		u.Password,
-
-		// Last argument for WHERE clause
-		u.ID,
-	}
-}
-
 // Validate verifies that all fields for the receiving User struct contain
 // valid input.
 func (u *User) Validate() error {
@@ -148,5 +249,48 @@ func (u *User) Validate() error {
 	}
 	u.Email = address.Address
 
+	// New users are active members unless otherwise specified
+	if u.Status == "" {
+		u.Status = StatusActive
+	}
+	if !u.Status.Valid() {
+		return &InvalidFieldError{
+			Field:   "status",
+			Details: "unknown membership status",
+		}
+	}
+
+	// Graduation year is optional, but must be reasonable if set
+	if u.GraduationYear != 0 && (u.GraduationYear < minGraduationYear || u.GraduationYear > maxGraduationYear) {
+		return &InvalidFieldError{
+			Field:   "graduationYear",
+			Details: "graduation year out of range",
+		}
+	}
+
+	// A user cannot be their own big brother
+	if u.BigBrotherID != 0 && u.BigBrotherID == u.ID {
+		return &InvalidFieldError{
+			Field:   "bigBrotherId",
+			Details: "user cannot be their own big brother",
+		}
+	}
+
+	// Trim whitespace from instruments, discarding any empty entries
+	var instruments StringList
+	for _, i := range u.Instruments {
+		if i = strings.TrimSpace(i); i != "" {
+			instruments = append(instruments, i)
+		}
+	}
+	u.Instruments = instruments
+
+	if len(u.Bio) > maxBioLength {
+		return &InvalidFieldError{
+			Field:   "bio",
+			Details: "biography too long",
+		}
+	}
+
 	return nil
 }
@@ -1,165 +1,86 @@
 package data
 
-import "github.com/mdlayher/deltaiota/data/models"
+import (
+	"context"
 
-const (
-	// sqlSelectNotificationsByUserID is the SQL statement used to select all Notifications
-	// for a user, by the user's ID
-	sqlSelectNotificationsByUserID = `
-		SELECT * FROM notifications WHERE user_id = ?;
-	`
-
-	// sqlInsertNotification is the SQL statement used to insert a new Notification
-	sqlInsertNotification = `
-		INSERT INTO notifications (
-			"user_id"
-			, "timestamp"
-			, "read"
-			, "text"
-			, "uri"
-		) VALUES (?, ?, ?, ?, ?);
-	`
+	"github.com/mdlayher/deltaiota/data/models"
+)
 
-	// sqlUpdateNotification is the SQL statement used to update an existing Notification
-	sqlUpdateNotification = `
-		UPDATE notifications SET
-			"user_id" = ?
-			, "timestamp" = ?
-			, "read" = ?
-			, "text" = ?
-			, "uri" = ?
-		WHERE id = ?;
-	`
+// notificationsTable maps Notifications onto the notifications table.
+var notificationsTable = newTable[models.Notification]("notifications")
 
-	// sqlDeleteNotification is the SQL statement used to delete an existing Notification
-	sqlDeleteNotification = `
-		DELETE FROM notifications WHERE id = ?;
-	`
+var (
+	// sqlSelectNotificationsByUserID is the SQL statement used to select all Notifications
+	// for a user, by the user's ID
+	sqlSelectNotificationsByUserID = notificationsTable.selectWhere("WHERE user_id = ?")
 
 	// sqlDeleteNotificationsByUserID is the SQL statement used to delete all Notifications
 	// for a user, by the user's ID
-	sqlDeleteNotificationsByUserID = `
-		DELETE FROM notifications WHERE user_id = ?;
-	`
+	sqlDeleteNotificationsByUserID = notificationsTable.deleteWhere("user_id = ?")
 )
 
 // SelectNotificationsByUserID returns a slice of Notifications by user ID from the database.
-func (db *DB) SelectNotificationsByUserID(userID uint64) ([]*models.Notification, error) {
-	return db.selectNotifications(sqlSelectNotificationsByUserID, userID)
+func (db *DB) SelectNotificationsByUserID(ctx context.Context, userID uint64) ([]*models.Notification, error) {
+	return selectAll[models.Notification](ctx, db, sqlSelectNotificationsByUserID, userID)
 }
 
 // InsertNotification starts a transaction, inserts a new Notification, and attempts to commit
 // the transaction.
-func (db *DB) InsertNotification(n *models.Notification) error {
-	return db.WithTx(func(tx *Tx) error {
-		return tx.InsertNotification(n)
+func (db *DB) InsertNotification(ctx context.Context, n *models.Notification) error {
+	return db.WithTx(ctx, func(tx *Tx) error {
+		return tx.InsertNotification(ctx, n)
 	})
 }
 
 // UpdateNotification starts a transaction, updates the input Notification by its ID, and attempts
 // to commit the transaction.
-func (db *DB) UpdateNotification(n *models.Notification) error {
-	return db.WithTx(func(tx *Tx) error {
-		return tx.UpdateNotification(n)
+func (db *DB) UpdateNotification(ctx context.Context, n *models.Notification) error {
+	return db.WithTx(ctx, func(tx *Tx) error {
+		return tx.UpdateNotification(ctx, n)
 	})
 }
 
 // DeleteNotification starts a transaction, deletes the input Notification by its ID, and attempts
 // to commit the transaction.
-func (db *DB) DeleteNotification(n *models.Notification) error {
-	return db.WithTx(func(tx *Tx) error {
-		return tx.DeleteNotification(n)
+func (db *DB) DeleteNotification(ctx context.Context, n *models.Notification) error {
+	return db.WithTx(ctx, func(tx *Tx) error {
+		return tx.DeleteNotification(ctx, n)
 	})
 }
 
 // DeleteNotificationsByUserID starts a transaction, deletes all Notifications with the matching user ID,
 // and attempts to commit the transaction.
-func (db *DB) DeleteNotificationsByUserID(userID uint64) error {
-	return db.WithTx(func(tx *Tx) error {
-		return tx.DeleteNotificationsByUserID(userID)
+func (db *DB) DeleteNotificationsByUserID(ctx context.Context, userID uint64) error {
+	return db.WithTx(ctx, func(tx *Tx) error {
+		return tx.DeleteNotificationsByUserID(ctx, userID)
 	})
 }
 
-// selectNotifications returns a slice of Notifications from the database, based upon an input
-// SQL query and arguments
-func (db *DB) selectNotifications(query string, args ...interface{}) ([]*models.Notification, error) {
-	// Slice of notifications to return
-	var notifications []*models.Notification
-
-	// Invoke closure with prepared statement and wrapped rows,
-	// passing any arguments from the caller
-	err := db.withPreparedRows(query, func(rows *Rows) error {
-		// Scan rows into a slice of Notifications
-		var err error
-		notifications, err = rows.ScanNotifications()
-
-		// Return errors from scanning
-		return err
-	}, args...)
-
-	// Return any matching notifications and error
-	return notifications, err
-}
-
 // InsertNotification inserts a new Notification in the context of the current transaction.
-func (tx *Tx) InsertNotification(n *models.Notification) error {
-	// Execute SQL to insert Notification
-	result, err := tx.Tx.Exec(sqlInsertNotification, n.SQLWriteFields()...)
-	if err != nil {
-		return err
-	}
-
-	// Retrieve generated ID
-	id, err := result.LastInsertId()
-	if err != nil {
-		return err
-	}
-
-	// Store generated ID
-	n.ID = uint64(id)
-	return nil
+func (tx *Tx) InsertNotification(ctx context.Context, n *models.Notification) error {
+	return insertRow(ctx, tx, notificationsTable, n)
 }
 
 // UpdateNotification updates the input Notification by its ID, in the context of the
 // current transaction.
-func (tx *Tx) UpdateNotification(n *models.Notification) error {
-	_, err := tx.Tx.Exec(sqlUpdateNotification, n.SQLWriteFields()...)
-	return err
+func (tx *Tx) UpdateNotification(ctx context.Context, n *models.Notification) error {
+	return updateRow(ctx, tx, notificationsTable, n)
 }
 
 // DeleteNotification updates the input Notification by its ID, in the context of the
 // current transaction.
-func (tx *Tx) DeleteNotification(n *models.Notification) error {
-	_, err := tx.Tx.Exec(sqlDeleteNotification, n.ID)
-	return err
+func (tx *Tx) DeleteNotification(ctx context.Context, n *models.Notification) error {
+	return deleteRow(ctx, tx, notificationsTable, n)
 }
 
 // DeleteNotificationsByUserID deletes all Notifications with the input user ID, in the
 // context of the current transaction.
-func (tx *Tx) DeleteNotificationsByUserID(userID uint64) error {
-	_, err := tx.Tx.Exec(sqlDeleteNotificationsByUserID, userID)
+func (tx *Tx) DeleteNotificationsByUserID(ctx context.Context, userID uint64) error {
+	_, err := tx.Tx.ExecContext(ctx, sqlDeleteNotificationsByUserID, userID)
 	return err
 }
 
 // ScanNotifications returns a slice of Notifications from wrapped rows.
 func (r *Rows) ScanNotifications() ([]*models.Notification, error) {
-	// Iterate all returned rows
-	var notifications []*models.Notification
-	for r.Rows.Next() {
-		// Scan new notification into struct, using specified fields
-		n := new(models.Notification)
-		if err := r.Rows.Scan(n.SQLReadFields()...); err != nil {
-			return nil, err
-		}
-
-		// Discard any nil results
-		if n == nil {
-			continue
-		}
-
-		// Append notification to output slice
-		notifications = append(notifications, n)
-	}
-
-	return notifications, nil
+	return ScanInto[models.Notification](r)
 }
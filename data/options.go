@@ -0,0 +1,107 @@
+package data
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Options specifies how a database connection is configured.  Options only
+// apply to sqlite3 databases, and are applied to every connection opened to
+// the database.
+type Options struct {
+	// JournalMode is the sqlite3 journal mode: one of DELETE, TRUNCATE,
+	// PERSIST, MEMORY, WAL, or OFF.  In WAL mode, readers are not blocked
+	// while the database is being written.  The journal mode of a read-only
+	// database is never changed.
+	JournalMode string
+
+	// Synchronous is the sqlite3 synchronous level: one of OFF, NORMAL, FULL,
+	// or EXTRA.  In WAL mode, NORMAL cannot corrupt the database, though the
+	// most recent transactions may be lost on power failure.  OFF may corrupt
+	// the database if the system crashes.
+	Synchronous string
+
+	// BusyTimeout is how long a connection waits for a lock held by another
+	// connection before failing.  If 0, a locked database fails immediately.
+	BusyTimeout time.Duration
+
+	// CacheSize is the maximum size of each connection's page cache, in KiB.
+	// If 0, the sqlite3 default is used.
+	CacheSize int
+
+	// MmapSize is the maximum number of bytes of the database which are
+	// accessed using memory-mapped I/O.  If 0, memory-mapped I/O is disabled.
+	MmapSize int64
+}
+
+// DefaultOptions returns the Options used when none are passed to Open.  By
+// default, databases use write-ahead logging with the NORMAL synchronous
+// level, which is safe against corruption, and wait up to 5 seconds for locks.
+func DefaultOptions() *Options {
+	return &Options{
+		JournalMode: "WAL",
+		Synchronous: "NORMAL",
+		BusyTimeout: 5 * time.Second,
+	}
+}
+
+// Validate verifies that all Options are valid, since they are used to build
+// pragma statements which cannot accept query arguments.
+func (o *Options) Validate() error {
+	if !oneOf(o.JournalMode, "DELETE", "TRUNCATE", "PERSIST", "MEMORY", "WAL", "OFF") {
+		return fmt.Errorf("db: invalid journal mode: %q", o.JournalMode)
+	}
+	if !oneOf(o.Synchronous, "OFF", "NORMAL", "FULL", "EXTRA") {
+		return fmt.Errorf("db: invalid synchronous level: %q", o.Synchronous)
+	}
+	if o.BusyTimeout < 0 {
+		return fmt.Errorf("db: invalid busy timeout: %v", o.BusyTimeout)
+	}
+	if o.CacheSize < 0 {
+		return fmt.Errorf("db: invalid cache size: %d", o.CacheSize)
+	}
+	if o.MmapSize < 0 {
+		return fmt.Errorf("db: invalid mmap size: %d", o.MmapSize)
+	}
+
+	return nil
+}
+
+// pragmas returns the sqlite3 pragma statements which apply the Options to
+// a connection, in order.  If readonly is true, options which would modify
+// the database file are omitted.
+func (o *Options) pragmas(readonly bool) []string {
+	pragmas := []string{
+		// Enforce foreign keys
+		"PRAGMA foreign_keys = ON;",
+
+		fmt.Sprintf("PRAGMA synchronous = %s;", strings.ToUpper(o.Synchronous)),
+		fmt.Sprintf("PRAGMA busy_timeout = %d;", o.BusyTimeout/time.Millisecond),
+		fmt.Sprintf("PRAGMA mmap_size = %d;", o.MmapSize),
+	}
+
+	// A negative cache size is interpreted by sqlite3 as KiB, rather than pages
+	if o.CacheSize > 0 {
+		pragmas = append(pragmas, fmt.Sprintf("PRAGMA cache_size = -%d;", o.CacheSize))
+	}
+
+	// Changing the journal mode may require writing to the database
+	if !readonly {
+		pragmas = append(pragmas, fmt.Sprintf("PRAGMA journal_mode = %s;", strings.ToUpper(o.JournalMode)))
+	}
+
+	return pragmas
+}
+
+// oneOf returns whether or not s is equal to any of the input values, ignoring
+// case.
+func oneOf(s string, values ...string) bool {
+	for _, v := range values {
+		if strings.EqualFold(s, v) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,76 @@
+package data
+
+import (
+	"context"
+
+	"github.com/mdlayher/deltaiota/data/models"
+)
+
+// positionsTable maps Positions onto the positions table.
+var positionsTable = newTable[models.Position]("positions")
+
+var (
+	// sqlSelectAllPositions is the SQL statement used to select all Positions,
+	// in order of rank
+	sqlSelectAllPositions = positionsTable.selectWhere("ORDER BY rank, id")
+
+	// sqlSelectPositionByID is the SQL statement used to select a single Position by ID
+	sqlSelectPositionByID = positionsTable.selectWhere("WHERE id = ?")
+)
+
+// SelectAllPositions returns a slice of all Positions from the database, in
+// order of rank.
+func (db *DB) SelectAllPositions(ctx context.Context) ([]*models.Position, error) {
+	return selectAll[models.Position](ctx, db, sqlSelectAllPositions)
+}
+
+// SelectPositionByID returns a single Position by ID from the database.
+func (db *DB) SelectPositionByID(ctx context.Context, id uint64) (*models.Position, error) {
+	return selectSingle[models.Position](ctx, db, sqlSelectPositionByID, id)
+}
+
+// InsertPosition starts a transaction, inserts a new Position, and attempts to commit
+// the transaction.
+func (db *DB) InsertPosition(ctx context.Context, p *models.Position) error {
+	return db.WithTx(ctx, func(tx *Tx) error {
+		return tx.InsertPosition(ctx, p)
+	})
+}
+
+// UpdatePosition starts a transaction, updates the input Position by its ID, and attempts
+// to commit the transaction.
+func (db *DB) UpdatePosition(ctx context.Context, p *models.Position) error {
+	return db.WithTx(ctx, func(tx *Tx) error {
+		return tx.UpdatePosition(ctx, p)
+	})
+}
+
+// DeletePosition starts a transaction, deletes the input Position by its ID, and attempts
+// to commit the transaction.
+func (db *DB) DeletePosition(ctx context.Context, p *models.Position) error {
+	return db.WithTx(ctx, func(tx *Tx) error {
+		return tx.DeletePosition(ctx, p)
+	})
+}
+
+// InsertPosition inserts a new Position in the context of the current transaction.
+func (tx *Tx) InsertPosition(ctx context.Context, p *models.Position) error {
+	return insertRow(ctx, tx, positionsTable, p)
+}
+
+// UpdatePosition updates the input Position by its ID, in the context of the
+// current transaction.
+func (tx *Tx) UpdatePosition(ctx context.Context, p *models.Position) error {
+	return updateRow(ctx, tx, positionsTable, p)
+}
+
+// DeletePosition deletes the input Position by its ID, in the context of the
+// current transaction.
+func (tx *Tx) DeletePosition(ctx context.Context, p *models.Position) error {
+	return deleteRow(ctx, tx, positionsTable, p)
+}
+
+// ScanPositions returns a slice of Positions from wrapped rows.
+func (r *Rows) ScanPositions() ([]*models.Position, error) {
+	return ScanInto[models.Position](r)
+}
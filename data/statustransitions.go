@@ -0,0 +1,45 @@
+package data
+
+import (
+	"context"
+
+	"github.com/mdlayher/deltaiota/data/models"
+)
+
+// statusTransitionsTable maps StatusTransitions onto the status_transitions table.
+var statusTransitionsTable = newTable[models.StatusTransition]("status_transitions")
+
+var (
+	// sqlSelectStatusTransitionsByUserID is the SQL statement used to select all
+	// StatusTransitions for a User, by the User's ID, in the order they were made
+	sqlSelectStatusTransitionsByUserID = statusTransitionsTable.selectWhere("WHERE user_id = ? ORDER BY id")
+
+	// sqlDeleteStatusTransitionsByUserID is the SQL statement used to delete all
+	// StatusTransitions for a User, by the User's ID
+	sqlDeleteStatusTransitionsByUserID = statusTransitionsTable.deleteWhere("user_id = ?")
+)
+
+// SelectStatusTransitionsByUserID returns a slice of all StatusTransitions for
+// the User with the input ID from the database, in the order they were made.
+func (db *DB) SelectStatusTransitionsByUserID(ctx context.Context, userID uint64) ([]*models.StatusTransition, error) {
+	return selectAll[models.StatusTransition](ctx, db, sqlSelectStatusTransitionsByUserID, userID)
+}
+
+// InsertStatusTransition inserts a new StatusTransition in the context of the
+// current transaction.  The User's membership status should be updated in the
+// same transaction.
+func (tx *Tx) InsertStatusTransition(ctx context.Context, s *models.StatusTransition) error {
+	return insertRow(ctx, tx, statusTransitionsTable, s)
+}
+
+// DeleteStatusTransitionsByUserID deletes all StatusTransitions for the User
+// with the input ID, in the context of the current transaction.
+func (tx *Tx) DeleteStatusTransitionsByUserID(ctx context.Context, userID uint64) error {
+	_, err := tx.Tx.ExecContext(ctx, sqlDeleteStatusTransitionsByUserID, userID)
+	return err
+}
+
+// ScanStatusTransitions returns a slice of StatusTransitions from wrapped rows.
+func (r *Rows) ScanStatusTransitions() ([]*models.StatusTransition, error) {
+	return ScanInto[models.StatusTransition](r)
+}
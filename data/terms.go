@@ -0,0 +1,171 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/mdlayher/deltaiota/data/models"
+)
+
+// termsTable maps Terms onto the terms table.
+var termsTable = newTable[models.Term]("terms")
+
+var (
+	// sqlSelectTermsByPositionID is the SQL statement used to select all Terms
+	// for a Position, by the Position's ID, most recent first
+	sqlSelectTermsByPositionID = termsTable.selectWhere("WHERE position_id = ? ORDER BY start DESC, id DESC")
+
+	// sqlSelectTermsByUserID is the SQL statement used to select all Terms
+	// held by a User, by the User's ID, most recent first
+	sqlSelectTermsByUserID = termsTable.selectWhere("WHERE user_id = ? ORDER BY start DESC, id DESC")
+
+	// sqlSelectActiveTerms is the SQL statement used to select all Terms which
+	// are active at a given time, in order of position rank
+	sqlSelectActiveTerms = fmt.Sprintf(`
+		SELECT %s FROM terms
+			JOIN positions ON terms.position_id = positions.id
+			WHERE terms.start <= ? AND (terms.end = 0 OR terms.end > ?)
+			ORDER BY positions.rank, positions.id, terms.start;
+	`, termsTable.qualifiedColumns())
+
+	// sqlSelectTermByID is the SQL statement used to select a single Term by ID
+	sqlSelectTermByID = termsTable.selectWhere("WHERE id = ?")
+
+	// sqlDeleteTermsByUserID is the SQL statement used to delete all Terms
+	// held by a User, by the User's ID
+	sqlDeleteTermsByUserID = termsTable.deleteWhere("user_id = ?")
+)
+
+const (
+	// sqlCountActiveTermsByUserID is the SQL statement used to count the Terms
+	// held by a User which are active at a given time
+	sqlCountActiveTermsByUserID = `
+		SELECT COUNT(*) FROM terms
+			WHERE user_id = ? AND start <= ? AND (end = 0 OR end > ?);
+	`
+
+	// sqlCountActiveTerms is the SQL statement used to count all Terms which
+	// are active at a given time
+	sqlCountActiveTerms = `
+		SELECT COUNT(*) FROM terms
+			WHERE start <= ? AND (end = 0 OR end > ?);
+	`
+
+	// sqlCountTermsByPositionID is the SQL statement used to count all Terms
+	// for a Position, by the Position's ID
+	sqlCountTermsByPositionID = `
+		SELECT COUNT(*) FROM terms WHERE position_id = ?;
+	`
+)
+
+// SelectTermsByPositionID returns a slice of all Terms for the Position with the
+// input ID from the database, most recent first.
+func (db *DB) SelectTermsByPositionID(ctx context.Context, positionID uint64) ([]*models.Term, error) {
+	return selectAll[models.Term](ctx, db, sqlSelectTermsByPositionID, positionID)
+}
+
+// SelectTermsByUserID returns a slice of all Terms held by the User with the
+// input ID from the database, most recent first.
+func (db *DB) SelectTermsByUserID(ctx context.Context, userID uint64) ([]*models.Term, error) {
+	return selectAll[models.Term](ctx, db, sqlSelectTermsByUserID, userID)
+}
+
+// SelectActiveTerms returns a slice of all Terms which are active at the input
+// time from the database, in order of position rank.
+func (db *DB) SelectActiveTerms(ctx context.Context, at time.Time) ([]*models.Term, error) {
+	now := at.Unix()
+	return selectAll[models.Term](ctx, db, sqlSelectActiveTerms, now, now)
+}
+
+// SelectTermByID returns a single Term by ID from the database.
+func (db *DB) SelectTermByID(ctx context.Context, id uint64) (*models.Term, error) {
+	return selectSingle[models.Term](ctx, db, sqlSelectTermByID, id)
+}
+
+// IsOfficer returns whether or not the User with the input ID holds at least
+// one Term which is active at the input time.
+func (db *DB) IsOfficer(ctx context.Context, userID uint64, at time.Time) (bool, error) {
+	now := at.Unix()
+	count, err := db.count(ctx, sqlCountActiveTermsByUserID, userID, now, now)
+	return count > 0, err
+}
+
+// HasOfficers returns whether or not any User holds a Term which is active at
+// the input time.
+func (db *DB) HasOfficers(ctx context.Context, at time.Time) (bool, error) {
+	now := at.Unix()
+	count, err := db.count(ctx, sqlCountActiveTerms, now, now)
+	return count > 0, err
+}
+
+// HasTerms returns whether or not any Terms, past or present, exist for the
+// Position with the input ID.
+func (db *DB) HasTerms(ctx context.Context, positionID uint64) (bool, error) {
+	count, err := db.count(ctx, sqlCountTermsByPositionID, positionID)
+	return count > 0, err
+}
+
+// InsertTerm starts a transaction, inserts a new Term, and attempts to commit
+// the transaction.
+func (db *DB) InsertTerm(ctx context.Context, t *models.Term) error {
+	return db.WithTx(ctx, func(tx *Tx) error {
+		return tx.InsertTerm(ctx, t)
+	})
+}
+
+// UpdateTerm starts a transaction, updates the input Term by its ID, and attempts
+// to commit the transaction.
+func (db *DB) UpdateTerm(ctx context.Context, t *models.Term) error {
+	return db.WithTx(ctx, func(tx *Tx) error {
+		return tx.UpdateTerm(ctx, t)
+	})
+}
+
+// DeleteTerm starts a transaction, deletes the input Term by its ID, and attempts
+// to commit the transaction.
+func (db *DB) DeleteTerm(ctx context.Context, t *models.Term) error {
+	return db.WithTx(ctx, func(tx *Tx) error {
+		return tx.DeleteTerm(ctx, t)
+	})
+}
+
+// count returns the single integer result of an input SQL query and arguments.
+func (db *DB) count(ctx context.Context, query string, args ...interface{}) (int, error) {
+	var count int
+	err := db.withPreparedStmt(ctx, query, func(stmt *sql.Stmt) error {
+		return stmt.QueryRowContext(ctx, args...).Scan(&count)
+	})
+
+	return count, err
+}
+
+// InsertTerm inserts a new Term in the context of the current transaction.
+func (tx *Tx) InsertTerm(ctx context.Context, t *models.Term) error {
+	return insertRow(ctx, tx, termsTable, t)
+}
+
+// UpdateTerm updates the input Term by its ID, in the context of the
+// current transaction.
+func (tx *Tx) UpdateTerm(ctx context.Context, t *models.Term) error {
+	return updateRow(ctx, tx, termsTable, t)
+}
+
+// DeleteTerm deletes the input Term by its ID, in the context of the
+// current transaction.
+func (tx *Tx) DeleteTerm(ctx context.Context, t *models.Term) error {
+	return deleteRow(ctx, tx, termsTable, t)
+}
+
+// DeleteTermsByUserID deletes all Terms held by the User with the input ID, in
+// the context of the current transaction.
+func (tx *Tx) DeleteTermsByUserID(ctx context.Context, userID uint64) error {
+	_, err := tx.Tx.ExecContext(ctx, sqlDeleteTermsByUserID, userID)
+	return err
+}
+
+// ScanTerms returns a slice of Terms from wrapped rows.
+func (r *Rows) ScanTerms() ([]*models.Term, error) {
+	return ScanInto[models.Term](r)
+}
@@ -1,188 +1,165 @@
 package data
 
 import (
-	"database/sql"
+	"context"
 
 	"github.com/mdlayher/deltaiota/data/models"
 )
 
-const (
-	// sqlSelectAllUsers is the SQL statement used to select all Users
-	sqlSelectAllUsers = `
-		SELECT * FROM users;
-	`
-	// sqlSelectUserByID is the SQL statement used to select a single user by ID
-	sqlSelectUserByID = `
-		SELECT * FROM users WHERE id = ?;
-	`
+// usersTable maps Users onto the users table.
+var usersTable = newTable[models.User]("users")
 
-	// sqlSelectUserByUsername is the SQL statement used to select a single user by username
-	sqlSelectUserByUsername = `
-		SELECT * FROM users WHERE username = ?;
-	`
+var (
+	// sqlSelectAllUsers is the SQL statement used to select all Users which are
+	// not deleted
+	sqlSelectAllUsers = usersTable.selectWhere("WHERE deleted_at = 0")
 
-	// sqlInsertUser is the SQL statement used to insert a new User
-	sqlInsertUser = `
-		INSERT INTO users (
-			"username"
-			, "first_name"
-			, "last_name"
-			, "email"
-			, "phone"
-			, "password"
-		) VALUES (?, ?, ?, ?, ?, ?);
-	`
+	// sqlSelectUsersByFilter is the SQL statement used to select all Users which
+	// are not deleted, and match an optional status and pledge class
+	sqlSelectUsersByFilter = usersTable.selectWhere(`WHERE
+			deleted_at = 0
+			AND (? = '' OR status = ?)
+			AND (? = '' OR pledge_class = ?)`)
+
+	// sqlSelectUserByID is the SQL statement used to select a single user which is
+	// not deleted by ID
+	sqlSelectUserByID = usersTable.selectWhere("WHERE id = ? AND deleted_at = 0")
+
+	// sqlSelectUserByUsername is the SQL statement used to select a single user which
+	// is not deleted by username
+	sqlSelectUserByUsername = usersTable.selectWhere("WHERE username = ? AND deleted_at = 0")
+
+	// sqlSelectUserByEmail is the SQL statement used to select a single user which is
+	// not deleted by email
+	sqlSelectUserByEmail = usersTable.selectWhere("WHERE email = ? AND deleted_at = 0")
+
+	// sqlSelectDeletedUsers is the SQL statement used to select all deleted Users,
+	// most recently deleted first
+	sqlSelectDeletedUsers = usersTable.selectWhere("WHERE deleted_at != 0 ORDER BY deleted_at DESC, id")
 
-	// sqlUpdateUser is the SQL statement used to update an existing User
-	sqlUpdateUser = `
-		UPDATE users SET
-			"username" = ?
-			, "first_name" = ?
-			, "last_name" = ?
-			, "email" = ?
-			, "phone" = ?
-			, "password" = ?
-		WHERE id = ?;
+	// sqlSelectDeletedUserByID is the SQL statement used to select a single deleted
+	// user by ID
+	sqlSelectDeletedUserByID = usersTable.selectWhere("WHERE id = ? AND deleted_at != 0")
+)
+
+const (
+	// sqlCountUsersByUsername is the SQL statement used to count all Users, including
+	// deleted Users, with a username
+	sqlCountUsersByUsername = `
+		SELECT COUNT(*) FROM users WHERE username = ?;
 	`
 
-	// sqlDeleteUser is the SQL statement used to delete an existing User
-	sqlDeleteUser = `
-		DELETE FROM users WHERE id = ?;
+	// sqlCountUsersByEmail is the SQL statement used to count all Users, including
+	// deleted Users, with an email
+	sqlCountUsersByEmail = `
+		SELECT COUNT(*) FROM users WHERE email = ?;
 	`
 )
 
-// SelectAllUsers returns a slice of all Users from the database.
-func (db *DB) SelectAllUsers() ([]*models.User, error) {
-	return db.selectUsers(sqlSelectAllUsers)
+// SelectAllUsers returns a slice of all Users which are not deleted from the database.
+func (db *DB) SelectAllUsers(ctx context.Context) ([]*models.User, error) {
+	return selectAll[models.User](ctx, db, sqlSelectAllUsers)
 }
 
-// SelectUserByID returns a single User by ID from the database.
-func (db *DB) SelectUserByID(id uint64) (*models.User, error) {
-	return db.selectSingleUser(sqlSelectUserByID, id)
+// UserFilter specifies optional conditions used to select a subset of Users.
+// Empty fields are ignored.
+type UserFilter struct {
+	Status      models.MemberStatus
+	PledgeClass string
 }
 
-// SelectUserByUsername returns a single User by Username from the database.
-func (db *DB) SelectUserByUsername(username string) (*models.User, error) {
-	return db.selectSingleUser(sqlSelectUserByUsername, username)
+// SelectUsersByFilter returns a slice of all Users which are not deleted, and
+// match the input filter from the database.
+func (db *DB) SelectUsersByFilter(ctx context.Context, f UserFilter) ([]*models.User, error) {
+	return selectAll[models.User](ctx, db, sqlSelectUsersByFilter, f.Status, f.Status, f.PledgeClass, f.PledgeClass)
 }
 
-// InsertUser starts a transaction, inserts a new User, and attempts to commit
-// the transaction.
-func (db *DB) InsertUser(u *models.User) error {
-	return db.WithTx(func(tx *Tx) error {
-		return tx.InsertUser(u)
-	})
+// SelectUserByID returns a single User which is not deleted by ID from the database.
+func (db *DB) SelectUserByID(ctx context.Context, id uint64) (*models.User, error) {
+	return selectSingle[models.User](ctx, db, sqlSelectUserByID, id)
 }
 
-// UpdateUser starts a transaction, updates the input User by its ID, and attempts
-// to commit the transaction.
-func (db *DB) UpdateUser(u *models.User) error {
-	return db.WithTx(func(tx *Tx) error {
-		return tx.UpdateUser(u)
-	})
+// SelectUserByUsername returns a single User which is not deleted by Username from
+// the database.
+func (db *DB) SelectUserByUsername(ctx context.Context, username string) (*models.User, error) {
+	return selectSingle[models.User](ctx, db, sqlSelectUserByUsername, username)
 }
 
-// DeleteUser starts a transaction, deletes the input User by its ID, and attempts
-// to commit the transaction.
-func (db *DB) DeleteUser(u *models.User) error {
-	return db.WithTx(func(tx *Tx) error {
-		return tx.DeleteUser(u)
-	})
+// SelectUserByEmail returns a single User which is not deleted by email address
+// from the database.
+func (db *DB) SelectUserByEmail(ctx context.Context, email string) (*models.User, error) {
+	return selectSingle[models.User](ctx, db, sqlSelectUserByEmail, email)
 }
 
-// selectUsers returns a slice of Users from the database, based upon an input
-// SQL query and arguments
-func (db *DB) selectUsers(query string, args ...interface{}) ([]*models.User, error) {
-	// Slice of users to return
-	var users []*models.User
+// SelectDeletedUsers returns a slice of all deleted Users which have not been
+// purged from the database, most recently deleted first.
+func (db *DB) SelectDeletedUsers(ctx context.Context) ([]*models.User, error) {
+	return selectAll[models.User](ctx, db, sqlSelectDeletedUsers)
+}
 
-	// Invoke closure with prepared statement and wrapped rows,
-	// passing any arguments from the caller
-	err := db.withPreparedRows(query, func(rows *Rows) error {
-		// Scan rows into a slice of Users
-		var err error
-		users, err = rows.ScanUsers()
+// SelectDeletedUserByID returns a single deleted User which has not been purged
+// by ID from the database.
+func (db *DB) SelectDeletedUserByID(ctx context.Context, id uint64) (*models.User, error) {
+	return selectSingle[models.User](ctx, db, sqlSelectDeletedUserByID, id)
+}
 
-		// Return errors from scanning
-		return err
-	}, args...)
+// UsernameInUse returns whether or not any User, including a deleted User which
+// has not been purged, has the input username.
+func (db *DB) UsernameInUse(ctx context.Context, username string) (bool, error) {
+	count, err := db.count(ctx, sqlCountUsersByUsername, username)
+	return count > 0, err
+}
 
-	// Return any matching users and error
-	return users, err
+// EmailInUse returns whether or not any User, including a deleted User which
+// has not been purged, has the input email address.
+func (db *DB) EmailInUse(ctx context.Context, email string) (bool, error) {
+	count, err := db.count(ctx, sqlCountUsersByEmail, email)
+	return count > 0, err
 }
 
-// selectSingleUser returns a User from the database, based upon an input
-// SQL query and arguments
-func (db *DB) selectSingleUser(query string, args ...interface{}) (*models.User, error) {
-	// Fetch users with matching condition
-	users, err := db.selectUsers(query, args...)
-	if err != nil {
-		return nil, err
-	}
+// InsertUser starts a transaction, inserts a new User, and attempts to commit
+// the transaction.
+func (db *DB) InsertUser(ctx context.Context, u *models.User) error {
+	return db.WithTx(ctx, func(tx *Tx) error {
+		return tx.InsertUser(ctx, u)
+	})
+}
 
-	// Verify only 0 or 1 user returned
-	if len(users) == 0 {
-		return nil, sql.ErrNoRows
-	} else if len(users) == 1 {
-		return users[0], nil
-	}
+// UpdateUser starts a transaction, updates the input User by its ID, and attempts
+// to commit the transaction.
+func (db *DB) UpdateUser(ctx context.Context, u *models.User) error {
+	return db.WithTx(ctx, func(tx *Tx) error {
+		return tx.UpdateUser(ctx, u)
+	})
+}
 
-	// More than one result returned
-	return nil, ErrMultipleResults
+// DeleteUser starts a transaction, permanently deletes the input User by its ID,
+// and attempts to commit the transaction.  To delete a User so that they may
+// later be restored, set the User's DeletedAt field using UpdateUser instead.
+func (db *DB) DeleteUser(ctx context.Context, u *models.User) error {
+	return db.WithTx(ctx, func(tx *Tx) error {
+		return tx.DeleteUser(ctx, u)
+	})
 }
 
 // InsertUser inserts a new User in the context of the current transaction.
-func (tx *Tx) InsertUser(u *models.User) error {
-	// Execute SQL to insert User
-	result, err := tx.Tx.Exec(sqlInsertUser, u.SQLWriteFields()...)
-	if err != nil {
-		return err
-	}
-
-	// Retrieve generated ID
-	id, err := result.LastInsertId()
-	if err != nil {
-		return err
-	}
-
-	// Store generated ID
-	u.ID = uint64(id)
-	return nil
+func (tx *Tx) InsertUser(ctx context.Context, u *models.User) error {
+	return insertRow(ctx, tx, usersTable, u)
 }
 
 // UpdateUser updates the input User by its ID, in the context of the
 // current transaction.
-func (tx *Tx) UpdateUser(u *models.User) error {
-	_, err := tx.Tx.Exec(sqlUpdateUser, u.SQLWriteFields()...)
-	return err
+func (tx *Tx) UpdateUser(ctx context.Context, u *models.User) error {
+	return updateRow(ctx, tx, usersTable, u)
 }
 
-// DeleteUser updates the input User by its ID, in the context of the
-// current transaction.
-func (tx *Tx) DeleteUser(u *models.User) error {
-	_, err := tx.Tx.Exec(sqlDeleteUser, u.ID)
-	return err
+// DeleteUser permanently deletes the input User by its ID, in the context of
+// the current transaction.
+func (tx *Tx) DeleteUser(ctx context.Context, u *models.User) error {
+	return deleteRow(ctx, tx, usersTable, u)
 }
 
 // ScanUsers returns a slice of Users from wrapped rows.
 func (r *Rows) ScanUsers() ([]*models.User, error) {
-	// Iterate all returned rows
-	var users []*models.User
-	for r.Rows.Next() {
-		// Scan new user into struct, using specified fields
-		u := new(models.User)
-		if err := r.Rows.Scan(u.SQLReadFields()...); err != nil {
-			return nil, err
-		}
-
-		// Discard any nil results
-		if u == nil {
-			continue
-		}
-
-		// Append user to output slice
-		users = append(users, u)
-	}
-
-	return users, nil
+	return ScanInto[models.User](r)
 }
@@ -0,0 +1,87 @@
+package diclient
+
+import (
+	"net/url"
+	"strconv"
+
+	"github.com/mdlayher/deltaiota/api/v0"
+	"github.com/mdlayher/deltaiota/data/models"
+)
+
+// AuditService provides access to the Audit API.
+type AuditService struct {
+	client *Client
+}
+
+// AuditListOptions specifies optional filters and paging for AuditService.List.
+// Empty fields are ignored.  Since and Until are UNIX timestamps, and Until is
+// exclusive.
+type AuditListOptions struct {
+	ActorID    uint64
+	Action     models.AuditAction
+	TargetType string
+	TargetID   uint64
+	Since      uint64
+	Until      uint64
+
+	Limit  int
+	Offset int
+}
+
+// endpoint returns the input endpoint with any filters encoded as query
+// parameters.  A nil AuditListOptions applies no filters.
+func (opt *AuditListOptions) endpoint(base string) string {
+	if opt == nil {
+		return base
+	}
+
+	v := url.Values{}
+	for k, n := range map[string]uint64{
+		"actorId":  opt.ActorID,
+		"targetId": opt.TargetID,
+		"since":    opt.Since,
+		"until":    opt.Until,
+	} {
+		if n != 0 {
+			v.Set(k, strconv.FormatUint(n, 10))
+		}
+	}
+	if opt.Action != "" {
+		v.Set("action", string(opt.Action))
+	}
+	if opt.TargetType != "" {
+		v.Set("targetType", opt.TargetType)
+	}
+	if opt.Limit != 0 {
+		v.Set("limit", strconv.Itoa(opt.Limit))
+	}
+	if opt.Offset != 0 {
+		v.Set("offset", strconv.Itoa(opt.Offset))
+	}
+
+	if len(v) == 0 {
+		return base
+	}
+
+	return base + "?" + v.Encode()
+}
+
+// List returns a page of audit log entries from the API which match the input
+// options, newest first, along with the total number of matching entries.
+func (a *AuditService) List(opt *AuditListOptions) ([]*models.AuditEntry, int, *Response, error) {
+	// Create request for Audit endpoint
+	req, err := a.client.NewRequest("GET", opt.endpoint("audit"), nil)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+
+	// Perform request, attempt to unmarshal response into an
+	// Audit API response
+	aRes := new(v0.AuditResponse)
+	res, err := a.client.Do(req, &aRes)
+	if err != nil {
+		return nil, 0, res, err
+	}
+
+	return aRes.Entries, aRes.Total, res, nil
+}
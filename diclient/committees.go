@@ -0,0 +1,164 @@
+package diclient
+
+import (
+	"fmt"
+
+	"github.com/mdlayher/deltaiota/api/v0"
+	"github.com/mdlayher/deltaiota/data/models"
+)
+
+// CommitteesService provides access to the Committees API.
+type CommitteesService struct {
+	client *Client
+}
+
+// List returns a slice of all Committee objects from the API.
+func (c *CommitteesService) List() ([]*models.Committee, *Response, error) {
+	cRes, res, err := c.request("GET", "committees", nil)
+
+	// Check for empty committees
+	if cRes == nil || cRes.Committees == nil {
+		return nil, res, err
+	}
+
+	return cRes.Committees, res, err
+}
+
+// Get returns a single Committee object with the input ID from the API.
+func (c *CommitteesService) Get(id uint64) (*models.Committee, *Response, error) {
+	cRes, res, err := c.request("GET", fmt.Sprintf("committees/%d", id), nil)
+
+	// Check for no committee found
+	if cRes == nil || cRes.Committees == nil || len(cRes.Committees) == 0 {
+		return nil, res, err
+	}
+
+	return cRes.Committees[0], res, err
+}
+
+// Create generates an API committee using the input Committee object.
+func (c *CommitteesService) Create(committee *models.Committee) (*models.Committee, *Response, error) {
+	cRes, res, err := c.request("POST", "committees", committee)
+
+	// Check for no committee returned
+	if cRes == nil || cRes.Committees == nil || len(cRes.Committees) == 0 {
+		return nil, res, err
+	}
+
+	return cRes.Committees[0], res, err
+}
+
+// Update updates an existing API committee using the input Committee object.
+func (c *CommitteesService) Update(committee *models.Committee) (*Response, error) {
+	_, res, err := c.request("PUT", fmt.Sprintf("committees/%d", committee.ID), committee)
+	return res, err
+}
+
+// Delete removes an existing API committee, and all of its memberships, using
+// the input Committee object.
+func (c *CommitteesService) Delete(committee *models.Committee) (*Response, error) {
+	// Create request for Committees endpoint
+	req, err := c.client.NewRequest("DELETE", fmt.Sprintf("committees/%d", committee.ID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Perform request, no response body is returned
+	return c.client.Do(req, nil)
+}
+
+// Members returns all members of the Committee with the input ID, chairs first.
+func (c *CommitteesService) Members(id uint64) ([]*models.CommitteeMember, *Response, error) {
+	mRes, res, err := c.membersRequest("GET", fmt.Sprintf("committees/%d/members", id), nil)
+
+	// Check for empty members
+	if mRes == nil || mRes.Members == nil {
+		return nil, res, err
+	}
+
+	return mRes.Members, res, err
+}
+
+// SetMember adds the User with the input user ID to the Committee with the input
+// ID, or updates their existing membership.  If chair is true, the User becomes
+// a chair of the Committee.
+func (c *CommitteesService) SetMember(id uint64, userID uint64, chair bool) (*Response, error) {
+	_, res, err := c.membersRequest("PUT", fmt.Sprintf("committees/%d/members/%d", id, userID), &v0.CommitteeMemberRequest{
+		Chair: chair,
+	})
+	return res, err
+}
+
+// RemoveMember removes the User with the input user ID from the Committee with
+// the input ID.
+func (c *CommitteesService) RemoveMember(id uint64, userID uint64) (*Response, error) {
+	// Create request for Committee Members endpoint
+	req, err := c.client.NewRequest("DELETE", fmt.Sprintf("committees/%d/members/%d", id, userID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Perform request, no response body is returned
+	return c.client.Do(req, nil)
+}
+
+// Notify sends a notification with the input text and URI to every member of
+// the Committee with the input ID, returning the created Notifications.
+func (c *CommitteesService) Notify(id uint64, text string, uri string) ([]*models.Notification, *Response, error) {
+	// Create request for Committee Notifications endpoint
+	req, err := c.client.NewRequest("POST", fmt.Sprintf("committees/%d/notifications", id), &v0.CommitteeNotificationRequest{
+		Text: text,
+		URI:  uri,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Perform request, attempt to unmarshal response into a
+	// Notifications API response
+	nRes := new(v0.NotificationsResponse)
+	res, err := c.client.Do(req, &nRes)
+	if err != nil {
+		return nil, res, err
+	}
+
+	return nRes.Notifications, res, nil
+}
+
+// request generates and performs a HTTP request to the Committees API.
+func (c *CommitteesService) request(method string, endpoint string, body interface{}) (*v0.CommitteesResponse, *Response, error) {
+	// Create request for Committees endpoint
+	req, err := c.client.NewRequest(method, endpoint, body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Perform request, attempt to unmarshal response into a
+	// Committees API response
+	cRes := new(v0.CommitteesResponse)
+	res, err := c.client.Do(req, &cRes)
+	if err != nil {
+		return nil, res, err
+	}
+
+	return cRes, res, nil
+}
+
+// membersRequest generates and performs a HTTP request to the Committee Members API.
+func (c *CommitteesService) membersRequest(method string, endpoint string, body interface{}) (*v0.CommitteeMembersResponse, *Response, error) {
+	// Create request for Committee Members endpoint
+	req, err := c.client.NewRequest(method, endpoint, body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Perform request, attempt to unmarshal response into a
+	// Committee Members API response
+	mRes := new(v0.CommitteeMembersResponse)
+	res, err := c.client.Do(req, &mRes)
+	if err != nil {
+		return nil, res, err
+	}
+
+	return mRes, res, nil
+}
@@ -36,7 +36,13 @@ type Client struct {
 	username string
 	session  *models.Session
 
+	Audit         *AuditService
+	Committees    *CommitteesService
+	Dues          *DuesService
+	Events        *EventsService
+	Invitations   *InvitationsService
 	Notifications *NotificationsService
+	Positions     *PositionsService
 	Sessions      *SessionsService
 	Status        *StatusService
 	Users         *UsersService
@@ -66,7 +72,13 @@ func NewClient(host string, client *http.Client) (*Client, error) {
 	}
 
 	// Set up individual services within client
+	c.Audit = &AuditService{client: c}
+	c.Committees = &CommitteesService{client: c}
+	c.Dues = &DuesService{client: c}
+	c.Events = &EventsService{client: c}
+	c.Invitations = &InvitationsService{client: c}
 	c.Notifications = &NotificationsService{client: c}
+	c.Positions = &PositionsService{client: c}
 	c.Sessions = &SessionsService{client: c}
 	c.Status = &StatusService{client: c}
 	c.Users = &UsersService{client: c}
@@ -113,10 +125,18 @@ func (c *Client) AuthenticateSession(username string, key string) error {
 }
 
 // NewRequest creates a new HTTP request, using the specified HTTP method and API endpoint.
-// Optionally, a request body may be sent.
+// Optionally, a request body may be sent.  If the body is an io.Reader, it is sent
+// as-is, and the caller should set an appropriate Content-Type header.  Otherwise,
+// the body is encoded as JSON.
 func (c *Client) NewRequest(method string, endpoint string, body interface{}) (*http.Request, error) {
 	// Generate relative URL using API root, version, and endpoint
-	rel, err := url.Parse(fmt.Sprintf("api/%s/%s", version, endpoint))
+	return c.newRequest(method, fmt.Sprintf("api/%s/%s", version, endpoint), body)
+}
+
+// newRequest creates a new HTTP request, as with NewRequest, for a path relative
+// to the server's root, rather than the API.
+func (c *Client) newRequest(method string, path string, body interface{}) (*http.Request, error) {
+	rel, err := url.Parse(path)
 	if err != nil {
 		return nil, err
 	}
@@ -124,17 +144,24 @@ func (c *Client) NewRequest(method string, endpoint string, body interface{}) (*
 	// Resolve relative URL to base, using input host
 	u := c.url.ResolveReference(rel)
 
-	// If a body object was specified, encode it to JSON
-	buf := bytes.NewBuffer(nil)
-	if body != nil {
-		err := json.NewEncoder(buf).Encode(body)
-		if err != nil {
+	// If a body object was specified, encode it to JSON, unless it is
+	// already a io.Reader
+	var rBody io.Reader
+	switch bt := body.(type) {
+	case nil:
+		rBody = bytes.NewBuffer(nil)
+	case io.Reader:
+		rBody = bt
+	default:
+		buf := bytes.NewBuffer(nil)
+		if err := json.NewEncoder(buf).Encode(body); err != nil {
 			return nil, err
 		}
+		rBody = buf
 	}
 
 	// Generate new HTTP request for appropriate URL, with optional body
-	req, err := http.NewRequest(method, u.String(), buf)
+	req, err := http.NewRequest(method, u.String(), rBody)
 	if err != nil {
 		return nil, err
 	}
@@ -146,7 +173,9 @@ func (c *Client) NewRequest(method string, endpoint string, body interface{}) (*
 
 	// Set headers to indicate proper content type
 	req.Header.Add("Accept", jsonContentType)
-	req.Header.Add("Content-Type", jsonContentType)
+	if _, ok := body.(io.Reader); !ok {
+		req.Header.Add("Content-Type", jsonContentType)
+	}
 
 	// Identify the client
 	req.Header.Add("User-Agent", c.userAgent)
@@ -0,0 +1,176 @@
+package diclient
+
+import (
+	"fmt"
+
+	"github.com/mdlayher/deltaiota/api/v0"
+	"github.com/mdlayher/deltaiota/data/models"
+)
+
+// DuesService provides access to the Dues API, which manages the ledger of
+// charges and payments for each user.
+type DuesService struct {
+	client *Client
+}
+
+// Charges returns all charges assigned to the User with the input ID, oldest
+// due first.
+func (d *DuesService) Charges(userID uint64) ([]*models.Charge, *Response, error) {
+	cRes, res, err := d.chargesRequest("GET", fmt.Sprintf("users/%d/charges", userID), nil)
+
+	// Check for empty charges
+	if cRes == nil || cRes.Charges == nil {
+		return nil, res, err
+	}
+
+	return cRes.Charges, res, err
+}
+
+// AddCharge assigns the input Charge to the User specified by its UserID.
+func (d *DuesService) AddCharge(charge *models.Charge) (*models.Charge, *Response, error) {
+	cRes, res, err := d.chargesRequest("POST", fmt.Sprintf("users/%d/charges", charge.UserID), charge)
+
+	// Check for no charge returned
+	if cRes == nil || cRes.Charges == nil || len(cRes.Charges) == 0 {
+		return nil, res, err
+	}
+
+	return cRes.Charges[0], res, err
+}
+
+// UpdateCharge updates an existing Charge using the input Charge object.
+func (d *DuesService) UpdateCharge(charge *models.Charge) (*Response, error) {
+	_, res, err := d.chargesRequest("PUT", fmt.Sprintf("users/%d/charges/%d", charge.UserID, charge.ID), charge)
+	return res, err
+}
+
+// DeleteCharge removes an existing Charge using the input Charge object.
+func (d *DuesService) DeleteCharge(charge *models.Charge) (*Response, error) {
+	// Create request for Charges endpoint
+	req, err := d.client.NewRequest("DELETE", fmt.Sprintf("users/%d/charges/%d", charge.UserID, charge.ID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Perform request, no response body is returned
+	return d.client.Do(req, nil)
+}
+
+// Payments returns all payments made by the User with the input ID, oldest first.
+func (d *DuesService) Payments(userID uint64) ([]*models.Payment, *Response, error) {
+	pRes, res, err := d.paymentsRequest("GET", fmt.Sprintf("users/%d/payments", userID), nil)
+
+	// Check for empty payments
+	if pRes == nil || pRes.Payments == nil {
+		return nil, res, err
+	}
+
+	return pRes.Payments, res, err
+}
+
+// RecordPayment records the input Payment for the User specified by its UserID.
+func (d *DuesService) RecordPayment(payment *models.Payment) (*models.Payment, *Response, error) {
+	pRes, res, err := d.paymentsRequest("POST", fmt.Sprintf("users/%d/payments", payment.UserID), payment)
+
+	// Check for no payment returned
+	if pRes == nil || pRes.Payments == nil || len(pRes.Payments) == 0 {
+		return nil, res, err
+	}
+
+	return pRes.Payments[0], res, err
+}
+
+// DeletePayment removes a Payment recorded in error, using the input Payment object.
+func (d *DuesService) DeletePayment(payment *models.Payment) (*Response, error) {
+	// Create request for Payments endpoint
+	req, err := d.client.NewRequest("DELETE", fmt.Sprintf("users/%d/payments/%d", payment.UserID, payment.ID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Perform request, no response body is returned
+	return d.client.Do(req, nil)
+}
+
+// Balance returns the ledger Balance of the User with the input ID.
+func (d *DuesService) Balance(userID uint64) (*models.Balance, *Response, error) {
+	bRes, res, err := d.balancesRequest(fmt.Sprintf("users/%d/balance", userID))
+
+	// Check for no balance returned
+	if bRes == nil || bRes.Balances == nil || len(bRes.Balances) == 0 {
+		return nil, res, err
+	}
+
+	return bRes.Balances[0], res, err
+}
+
+// Overdue returns the ledger Balances of all Users with an overdue amount,
+// largest first.
+func (d *DuesService) Overdue() ([]*models.Balance, *Response, error) {
+	bRes, res, err := d.balancesRequest("dues/overdue")
+
+	// Check for empty balances
+	if bRes == nil || bRes.Balances == nil {
+		return nil, res, err
+	}
+
+	return bRes.Balances, res, err
+}
+
+// chargesRequest generates and performs a HTTP request to the Charges API.
+func (d *DuesService) chargesRequest(method string, endpoint string, body interface{}) (*v0.ChargesResponse, *Response, error) {
+	// Create request for Charges endpoint
+	req, err := d.client.NewRequest(method, endpoint, body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Perform request, attempt to unmarshal response into a
+	// Charges API response
+	cRes := new(v0.ChargesResponse)
+	res, err := d.client.Do(req, &cRes)
+	if err != nil {
+		return nil, res, err
+	}
+
+	return cRes, res, nil
+}
+
+// paymentsRequest generates and performs a HTTP request to the Payments API.
+func (d *DuesService) paymentsRequest(method string, endpoint string, body interface{}) (*v0.PaymentsResponse, *Response, error) {
+	// Create request for Payments endpoint
+	req, err := d.client.NewRequest(method, endpoint, body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Perform request, attempt to unmarshal response into a
+	// Payments API response
+	pRes := new(v0.PaymentsResponse)
+	res, err := d.client.Do(req, &pRes)
+	if err != nil {
+		return nil, res, err
+	}
+
+	return pRes, res, nil
+}
+
+// balancesRequest generates and performs a HTTP GET request to a Balance or
+// Overdue API endpoint.
+func (d *DuesService) balancesRequest(endpoint string) (*v0.BalancesResponse, *Response, error) {
+	// Create request for Balances endpoint
+	req, err := d.client.NewRequest("GET", endpoint, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Perform request, attempt to unmarshal response into a
+	// Balances API response
+	bRes := new(v0.BalancesResponse)
+	res, err := d.client.Do(req, &bRes)
+	if err != nil {
+		return nil, res, err
+	}
+
+	return bRes, res, nil
+}
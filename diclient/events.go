@@ -0,0 +1,196 @@
+package diclient
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+
+	"github.com/mdlayher/deltaiota/api/v0"
+	"github.com/mdlayher/deltaiota/data/models"
+)
+
+// EventsService provides access to the Events and Calendar APIs.
+type EventsService struct {
+	client *Client
+}
+
+// List returns a slice of all Event objects from the API, ordered by start time.
+func (e *EventsService) List() ([]*models.Event, *Response, error) {
+	eRes, res, err := e.request("GET", "events", nil)
+
+	// Check for empty events
+	if eRes == nil || eRes.Events == nil {
+		return nil, res, err
+	}
+
+	return eRes.Events, res, err
+}
+
+// Get returns a single Event object with the input ID from the API.
+func (e *EventsService) Get(id uint64) (*models.Event, *Response, error) {
+	eRes, res, err := e.request("GET", fmt.Sprintf("events/%d", id), nil)
+
+	// Check for no event found
+	if eRes == nil || eRes.Events == nil || len(eRes.Events) == 0 {
+		return nil, res, err
+	}
+
+	return eRes.Events[0], res, err
+}
+
+// Create generates an API event using the input Event object.
+func (e *EventsService) Create(event *models.Event) (*models.Event, *Response, error) {
+	eRes, res, err := e.request("POST", "events", event)
+
+	// Check for no event returned
+	if eRes == nil || eRes.Events == nil || len(eRes.Events) == 0 {
+		return nil, res, err
+	}
+
+	return eRes.Events[0], res, err
+}
+
+// Update updates an existing API event using the input Event object.
+func (e *EventsService) Update(event *models.Event) (*Response, error) {
+	_, res, err := e.request("PUT", fmt.Sprintf("events/%d", event.ID), event)
+	return res, err
+}
+
+// Delete removes an existing API event, and all of its RSVPs, using the input
+// Event object.
+func (e *EventsService) Delete(event *models.Event) (*Response, error) {
+	// Create request for Events endpoint
+	req, err := e.client.NewRequest("DELETE", fmt.Sprintf("events/%d", event.ID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Perform request, no response body is returned
+	return e.client.Do(req, nil)
+}
+
+// RSVPs returns all RSVPs to the Event with the input ID.
+func (e *EventsService) RSVPs(id uint64) ([]*models.RSVP, *Response, error) {
+	rRes, res, err := e.rsvpsRequest("GET", fmt.Sprintf("events/%d/rsvps", id), nil)
+
+	// Check for empty RSVPs
+	if rRes == nil || rRes.RSVPs == nil {
+		return nil, res, err
+	}
+
+	return rRes.RSVPs, res, err
+}
+
+// RSVP sets the authenticated user's response to the Event with the input ID.
+func (e *EventsService) RSVP(id uint64, response models.RSVPResponse) (*Response, error) {
+	_, res, err := e.rsvpsRequest("PUT", fmt.Sprintf("events/%d/rsvp", id), &models.RSVP{
+		Response: response,
+	})
+	return res, err
+}
+
+// CancelRSVP removes the authenticated user's response to the Event with the
+// input ID.
+func (e *EventsService) CancelRSVP(id uint64) (*Response, error) {
+	// Create request for RSVP endpoint
+	req, err := e.client.NewRequest("DELETE", fmt.Sprintf("events/%d/rsvp", id), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Perform request, no response body is returned
+	return e.client.Do(req, nil)
+}
+
+// CalendarToken returns the authenticated user's calendar feed token.  If the
+// user has no token, one is generated.
+func (e *EventsService) CalendarToken() (*models.CalendarToken, *Response, error) {
+	return e.tokenRequest("GET")
+}
+
+// RotateCalendarToken generates a new calendar feed token for the authenticated
+// user, revoking any existing token.
+func (e *EventsService) RotateCalendarToken() (*models.CalendarToken, *Response, error) {
+	return e.tokenRequest("POST")
+}
+
+// Calendar streams the iCalendar feed authenticated by the input calendar feed
+// token into the input io.Writer.  If rsvpOnly is true, only events which the
+// token's owner has responded yes or maybe to are included.  If tz is not empty,
+// event times are expressed in the IANA time zone with that name.
+func (e *EventsService) Calendar(token string, rsvpOnly bool, tz string, w io.Writer) (*Response, error) {
+	query := url.Values{}
+	query.Set("token", token)
+	if rsvpOnly {
+		query.Set("filter", "rsvp")
+	}
+	if tz != "" {
+		query.Set("tz", tz)
+	}
+
+	// Create request for Calendar endpoint
+	req, err := e.client.NewRequest("GET", "calendar.ics?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Perform request, streaming feed into writer
+	return e.client.Do(req, w)
+}
+
+// request generates and performs a HTTP request to the Events API.
+func (e *EventsService) request(method string, endpoint string, body interface{}) (*v0.EventsResponse, *Response, error) {
+	// Create request for Events endpoint
+	req, err := e.client.NewRequest(method, endpoint, body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Perform request, attempt to unmarshal response into a
+	// Events API response
+	eRes := new(v0.EventsResponse)
+	res, err := e.client.Do(req, &eRes)
+	if err != nil {
+		return nil, res, err
+	}
+
+	return eRes, res, nil
+}
+
+// rsvpsRequest generates and performs a HTTP request to the RSVPs API.
+func (e *EventsService) rsvpsRequest(method string, endpoint string, body interface{}) (*v0.RSVPsResponse, *Response, error) {
+	// Create request for RSVPs endpoint
+	req, err := e.client.NewRequest(method, endpoint, body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Perform request, attempt to unmarshal response into a
+	// RSVPs API response
+	rRes := new(v0.RSVPsResponse)
+	res, err := e.client.Do(req, &rRes)
+	if err != nil {
+		return nil, res, err
+	}
+
+	return rRes, res, nil
+}
+
+// tokenRequest generates and performs a HTTP request to the Calendar Token API.
+func (e *EventsService) tokenRequest(method string) (*models.CalendarToken, *Response, error) {
+	// Create request for Calendar Token endpoint
+	req, err := e.client.NewRequest(method, "calendar/token", nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Perform request, attempt to unmarshal response into a
+	// Calendar Token API response
+	tRes := new(v0.CalendarTokenResponse)
+	res, err := e.client.Do(req, &tRes)
+	if err != nil {
+		return nil, res, err
+	}
+
+	return tRes.Token, res, nil
+}
@@ -0,0 +1,94 @@
+package diclient
+
+import (
+	"fmt"
+
+	"github.com/mdlayher/deltaiota/api/v0"
+	"github.com/mdlayher/deltaiota/data/models"
+)
+
+// InvitationsService provides access to the Invitations API.
+type InvitationsService struct {
+	client *Client
+}
+
+// List returns a slice of all Invitation objects from the API which have not
+// yet been accepted.
+func (i *InvitationsService) List() ([]*models.Invitation, *Response, error) {
+	iRes, res, err := i.request("GET", "invitations", nil)
+
+	// Check for empty invitations
+	if iRes == nil || iRes.Invitations == nil {
+		return nil, res, err
+	}
+
+	return iRes.Invitations, res, err
+}
+
+// Create invites the input email address to register an account.  The
+// invitation is mailed to the invitee by the server.
+func (i *InvitationsService) Create(email string) (*models.Invitation, *Response, error) {
+	iRes, res, err := i.request("POST", "invitations", &models.Invitation{
+		Email: email,
+	})
+
+	// Check for no invitation returned
+	if iRes == nil || iRes.Invitations == nil || len(iRes.Invitations) == 0 {
+		return nil, res, err
+	}
+
+	return iRes.Invitations[0], res, err
+}
+
+// Revoke removes an existing API invitation, so that it may no longer be
+// accepted, using the input Invitation object.
+func (i *InvitationsService) Revoke(invitation *models.Invitation) (*Response, error) {
+	// Create request for Invitations endpoint
+	req, err := i.client.NewRequest("DELETE", fmt.Sprintf("invitations/%d", invitation.ID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Perform request, no response body is returned
+	return i.client.Do(req, nil)
+}
+
+// Accept accepts the invitation with the input token, and returns the
+// invitee's user and their first session.  Accepting an invitation requires
+// no authentication.
+func (i *InvitationsService) Accept(token string, accept *v0.InvitationAcceptRequest) (*models.User, *models.Session, *Response, error) {
+	// Create request for Accept Invitation endpoint
+	req, err := i.client.NewRequest("POST", fmt.Sprintf("invitations/%s/accept", token), accept)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	// Perform request, attempt to unmarshal response into an
+	// Accept Invitation API response
+	aRes := new(v0.InvitationAcceptResponse)
+	res, err := i.client.Do(req, &aRes)
+	if err != nil {
+		return nil, nil, res, err
+	}
+
+	return aRes.User, aRes.Session, res, nil
+}
+
+// request generates and performs a HTTP request to the Invitations API.
+func (i *InvitationsService) request(method string, endpoint string, body interface{}) (*v0.InvitationsResponse, *Response, error) {
+	// Create request for Invitations endpoint
+	req, err := i.client.NewRequest(method, endpoint, body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Perform request, attempt to unmarshal response into a
+	// Invitations API response
+	iRes := new(v0.InvitationsResponse)
+	res, err := i.client.Do(req, &iRes)
+	if err != nil {
+		return nil, res, err
+	}
+
+	return iRes, res, nil
+}
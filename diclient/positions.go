@@ -0,0 +1,169 @@
+package diclient
+
+import (
+	"fmt"
+
+	"github.com/mdlayher/deltaiota/api/v0"
+	"github.com/mdlayher/deltaiota/data/models"
+)
+
+// PositionsService provides access to the Positions API, which manages officer
+// positions and their term history.
+type PositionsService struct {
+	client *Client
+}
+
+// List returns a slice of all Position objects from the API, in order of rank.
+func (p *PositionsService) List() ([]*models.Position, *Response, error) {
+	pRes, res, err := p.request("GET", "positions", nil)
+
+	// Check for empty positions
+	if pRes == nil || pRes.Positions == nil {
+		return nil, res, err
+	}
+
+	return pRes.Positions, res, err
+}
+
+// Get returns a single Position object with the input ID from the API.
+func (p *PositionsService) Get(id uint64) (*models.Position, *Response, error) {
+	pRes, res, err := p.request("GET", fmt.Sprintf("positions/%d", id), nil)
+
+	// Check for no position found
+	if pRes == nil || pRes.Positions == nil || len(pRes.Positions) == 0 {
+		return nil, res, err
+	}
+
+	return pRes.Positions[0], res, err
+}
+
+// Create generates an API position using the input Position object.
+func (p *PositionsService) Create(position *models.Position) (*models.Position, *Response, error) {
+	pRes, res, err := p.request("POST", "positions", position)
+
+	// Check for no position returned
+	if pRes == nil || pRes.Positions == nil || len(pRes.Positions) == 0 {
+		return nil, res, err
+	}
+
+	return pRes.Positions[0], res, err
+}
+
+// Update updates an existing API position using the input Position object.
+func (p *PositionsService) Update(position *models.Position) (*Response, error) {
+	_, res, err := p.request("PUT", fmt.Sprintf("positions/%d", position.ID), position)
+	return res, err
+}
+
+// Delete removes an existing API position using the input Position object.
+// Positions with any term history may not be deleted.
+func (p *PositionsService) Delete(position *models.Position) (*Response, error) {
+	// Create request for Positions endpoint
+	req, err := p.client.NewRequest("DELETE", fmt.Sprintf("positions/%d", position.ID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Perform request, no response body is returned
+	return p.client.Do(req, nil)
+}
+
+// Terms returns the term history of the Position with the input ID, most
+// recent first.
+func (p *PositionsService) Terms(id uint64) ([]*models.Term, *Response, error) {
+	tRes, res, err := p.termsRequest("GET", fmt.Sprintf("positions/%d/terms", id), nil)
+
+	// Check for empty terms
+	if tRes == nil || tRes.Terms == nil {
+		return nil, res, err
+	}
+
+	return tRes.Terms, res, err
+}
+
+// Appoint creates a new Term for the Position specified by the input Term.
+func (p *PositionsService) Appoint(term *models.Term) (*models.Term, *Response, error) {
+	tRes, res, err := p.termsRequest("POST", fmt.Sprintf("positions/%d/terms", term.PositionID), term)
+
+	// Check for no term returned
+	if tRes == nil || tRes.Terms == nil || len(tRes.Terms) == 0 {
+		return nil, res, err
+	}
+
+	return tRes.Terms[0], res, err
+}
+
+// UpdateTerm updates an existing Term using the input Term object.
+func (p *PositionsService) UpdateTerm(term *models.Term) (*Response, error) {
+	_, res, err := p.termsRequest("PUT", fmt.Sprintf("positions/%d/terms/%d", term.PositionID, term.ID), term)
+	return res, err
+}
+
+// DeleteTerm removes an existing Term using the input Term object.
+func (p *PositionsService) DeleteTerm(term *models.Term) (*Response, error) {
+	// Create request for Terms endpoint
+	req, err := p.client.NewRequest("DELETE", fmt.Sprintf("positions/%d/terms/%d", term.PositionID, term.ID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Perform request, no response body is returned
+	return p.client.Do(req, nil)
+}
+
+// Officers returns all current officers, in order of position rank.
+func (p *PositionsService) Officers() ([]*v0.Officer, *Response, error) {
+	// Create request for Officers endpoint
+	req, err := p.client.NewRequest("GET", "officers", nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Perform request, attempt to unmarshal response into an
+	// Officers API response
+	oRes := new(v0.OfficersResponse)
+	res, err := p.client.Do(req, &oRes)
+	if err != nil {
+		return nil, res, err
+	}
+
+	return oRes.Officers, res, nil
+}
+
+// request generates and performs a HTTP request to the Positions API.
+func (p *PositionsService) request(method string, endpoint string, body interface{}) (*v0.PositionsResponse, *Response, error) {
+	// Create request for Positions endpoint
+	req, err := p.client.NewRequest(method, endpoint, body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Perform request, attempt to unmarshal response into a
+	// Positions API response
+	pRes := new(v0.PositionsResponse)
+	res, err := p.client.Do(req, &pRes)
+	if err != nil {
+		return nil, res, err
+	}
+
+	return pRes, res, nil
+}
+
+// termsRequest generates and performs a HTTP request to the Terms API.
+func (p *PositionsService) termsRequest(method string, endpoint string, body interface{}) (*v0.TermsResponse, *Response, error) {
+	// Create request for Terms endpoint
+	req, err := p.client.NewRequest(method, endpoint, body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Perform request, attempt to unmarshal response into a
+	// Terms API response
+	tRes := new(v0.TermsResponse)
+	res, err := p.client.Do(req, &tRes)
+	if err != nil {
+		return nil, res, err
+	}
+
+	return tRes, res, nil
+}
@@ -1,10 +1,12 @@
 package diclient
 
 import (
+	"github.com/mdlayher/deltaiota/api"
 	"github.com/mdlayher/deltaiota/api/v0"
 )
 
-// StatusService provides access to the Status API.
+// StatusService provides access to the Status API, and the server's health
+// checks.
 type StatusService struct {
 	client *Client
 }
@@ -27,3 +29,33 @@ func (s *StatusService) Get() (*v0.Status, *Response, error) {
 
 	return sRes.Status, res, nil
 }
+
+// Health checks whether the API server is alive.  It requires no
+// authentication.
+func (s *StatusService) Health() (*api.HealthResponse, *Response, error) {
+	return s.check("healthz")
+}
+
+// Ready checks whether the API server is ready to serve requests, returning an
+// Error if its database is unavailable or has pending schema migrations.  It
+// requires no authentication.
+func (s *StatusService) Ready() (*api.HealthResponse, *Response, error) {
+	return s.check("readyz")
+}
+
+// check performs the health check at the input path, relative to the server's
+// root rather than the API.
+func (s *StatusService) check(path string) (*api.HealthResponse, *Response, error) {
+	req, err := s.client.newRequest("GET", path, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	hRes := new(api.HealthResponse)
+	res, err := s.client.Do(req, hRes)
+	if err != nil {
+		return nil, res, err
+	}
+
+	return hRes, res, nil
+}
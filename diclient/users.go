@@ -2,6 +2,8 @@ package diclient
 
 import (
 	"fmt"
+	"io"
+	"net/url"
 
 	"github.com/mdlayher/deltaiota/api/v0"
 	"github.com/mdlayher/deltaiota/data/models"
@@ -12,9 +14,44 @@ type UsersService struct {
 	client *Client
 }
 
+// UserListOptions specifies optional filters for UsersService.ListWithOptions
+// and UsersService.VCards.  Empty fields are ignored.
+type UserListOptions struct {
+	Status      models.MemberStatus
+	PledgeClass string
+}
+
+// endpoint returns the input endpoint with any filters encoded as query
+// parameters.  A nil UserListOptions applies no filters.
+func (opt *UserListOptions) endpoint(base string) string {
+	if opt == nil {
+		return base
+	}
+
+	v := url.Values{}
+	if opt.Status != "" {
+		v.Set("status", string(opt.Status))
+	}
+	if opt.PledgeClass != "" {
+		v.Set("pledgeClass", opt.PledgeClass)
+	}
+
+	if len(v) == 0 {
+		return base
+	}
+
+	return base + "?" + v.Encode()
+}
+
 // List returns a slice of all User objects from the API.
 func (u *UsersService) List() ([]*models.User, *Response, error) {
-	uRes, res, err := u.request("GET", "users", nil)
+	return u.ListWithOptions(nil)
+}
+
+// ListWithOptions returns a slice of all User objects from the API which match
+// the input options.
+func (u *UsersService) ListWithOptions(opt *UserListOptions) ([]*models.User, *Response, error) {
+	uRes, res, err := u.request("GET", opt.endpoint("users"), nil)
 
 	// Check for empty users
 	if uRes == nil || uRes.Users == nil {
@@ -48,6 +85,281 @@ func (u *UsersService) Update(user *models.User) (*Response, error) {
 	return res, err
 }
 
+// Delete deletes an existing API user using the input User object.  The user
+// may later be restored using Restore, until it is purged using Purge.
+func (u *UsersService) Delete(user *models.User) (*Response, error) {
+	// Create request for Users endpoint
+	req, err := u.client.NewRequest("DELETE", fmt.Sprintf("users/%d", user.ID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Perform request, no response body is returned
+	return u.client.Do(req, nil)
+}
+
+// Deleted returns a slice of all deleted User objects from the API which have
+// not been purged, most recently deleted first.
+func (u *UsersService) Deleted() ([]*models.User, *Response, error) {
+	uRes, res, err := u.request("GET", "users/deleted", nil)
+
+	// Check for empty users
+	if uRes == nil || uRes.Users == nil {
+		return nil, res, err
+	}
+
+	return uRes.Users, res, err
+}
+
+// Restore restores the deleted User with the input ID.
+func (u *UsersService) Restore(id uint64) (*models.User, *Response, error) {
+	uRes, res, err := u.request("POST", fmt.Sprintf("users/%d/restore", id), nil)
+
+	// Check for no user returned
+	if uRes == nil || uRes.Users == nil || len(uRes.Users) == 0 {
+		return nil, res, err
+	}
+
+	return uRes.Users[0], res, err
+}
+
+// Purge permanently deletes the deleted User with the input ID, and all of
+// their data.
+func (u *UsersService) Purge(id uint64) (*Response, error) {
+	// Create request for Purge endpoint
+	req, err := u.client.NewRequest("POST", fmt.Sprintf("users/%d/purge", id), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Perform request, no response body is returned
+	return u.client.Do(req, nil)
+}
+
+// VCard streams the contact details of the User with the input ID as a vCard
+// into the input io.Writer.
+func (u *UsersService) VCard(id uint64, w io.Writer) (*Response, error) {
+	// Create request for Directory endpoint
+	req, err := u.client.NewRequest("GET", fmt.Sprintf("users/%d.vcf", id), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Perform request, streaming vCard into writer
+	return u.client.Do(req, w)
+}
+
+// VCards streams the contact details of all Users which match the input options
+// as a series of vCards into the input io.Writer.
+func (u *UsersService) VCards(opt *UserListOptions, w io.Writer) (*Response, error) {
+	// Create request for Directory endpoint
+	req, err := u.client.NewRequest("GET", opt.endpoint("users.vcf"), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Perform request, streaming vCards into writer
+	return u.client.Do(req, w)
+}
+
+// GetAvatar streams the avatar thumbnail of the input size for the User with the
+// input ID into the input io.Writer.  If size is 0, the default size is used.
+func (u *UsersService) GetAvatar(id uint64, size int, w io.Writer) (*Response, error) {
+	endpoint := fmt.Sprintf("users/%d/avatar", id)
+	if size != 0 {
+		endpoint += fmt.Sprintf("?size=%d", size)
+	}
+
+	// Create request for Avatars endpoint
+	req, err := u.client.NewRequest("GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Perform request, streaming image into writer
+	return u.client.Do(req, w)
+}
+
+// SetAvatar uploads a JPEG or PNG image from the input io.Reader as the avatar
+// for the User with the input ID, returning the updated User.
+func (u *UsersService) SetAvatar(id uint64, r io.Reader) (*models.User, *Response, error) {
+	uRes, res, err := u.request("PUT", fmt.Sprintf("users/%d/avatar", id), r)
+
+	// Check for no user returned
+	if uRes == nil || uRes.Users == nil || len(uRes.Users) == 0 {
+		return nil, res, err
+	}
+
+	return uRes.Users[0], res, err
+}
+
+// ExportCSV streams the roster of all Users which match the input options as a
+// CSV document into the input io.Writer.
+func (u *UsersService) ExportCSV(opt *UserListOptions, w io.Writer) (*Response, error) {
+	// Create request for Roster Export endpoint
+	req, err := u.client.NewRequest("GET", opt.endpoint("users/export.csv"), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Perform request, streaming document into writer
+	return u.client.Do(req, w)
+}
+
+// UserImportOptions specifies optional parameters for UsersService.ImportCSV.
+type UserImportOptions struct {
+	// DryRun validates each row of the roster, without creating any Users.
+	DryRun bool
+
+	// Mapping maps CSV header names to the User fields they contain, for
+	// headers which do not match a field name.
+	Mapping map[string]string
+}
+
+// ImportCSV creates Users from the CSV document read from the input io.Reader,
+// returning the result for each row.  Either every User is created, or none are.
+func (u *UsersService) ImportCSV(r io.Reader, opt *UserImportOptions) (*v0.RosterImportResponse, *Response, error) {
+	// Encode any options as query parameters
+	endpoint := "users/import"
+	if opt != nil {
+		v := url.Values{}
+		if opt.DryRun {
+			v.Set("dryRun", "true")
+		}
+		for header, field := range opt.Mapping {
+			v.Add("map", header+":"+field)
+		}
+
+		if len(v) > 0 {
+			endpoint += "?" + v.Encode()
+		}
+	}
+
+	// Create request for Roster Import endpoint
+	req, err := u.client.NewRequest("POST", endpoint, r)
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("Content-Type", "text/csv")
+
+	// Perform request, attempt to unmarshal response into a
+	// Roster Import API response
+	iRes := new(v0.RosterImportResponse)
+	res, err := u.client.Do(req, &iRes)
+	if err != nil {
+		return nil, res, err
+	}
+
+	return iRes, res, nil
+}
+
+// DeleteAvatar removes the avatar for the User with the input ID.
+func (u *UsersService) DeleteAvatar(id uint64) (*Response, error) {
+	// Create request for Avatars endpoint
+	req, err := u.client.NewRequest("DELETE", fmt.Sprintf("users/%d/avatar", id), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Perform request, no response body is returned
+	return u.client.Do(req, nil)
+}
+
+// SetStatus changes the membership status of the User with the input ID, for
+// the input reason, and returns the recorded StatusTransition.
+func (u *UsersService) SetStatus(id uint64, status models.MemberStatus, reason string) (*models.StatusTransition, *Response, error) {
+	tRes, res, err := u.transitionsRequest("POST", fmt.Sprintf("users/%d/status", id), &v0.TransitionRequest{
+		Status: status,
+		Reason: reason,
+	})
+
+	// Check for no transition returned
+	if tRes == nil || len(tRes.Transitions) == 0 {
+		return nil, res, err
+	}
+
+	return tRes.Transitions[0], res, err
+}
+
+// StatusHistory returns a slice of all membership status transitions of the
+// User with the input ID, oldest first.
+func (u *UsersService) StatusHistory(id uint64) ([]*models.StatusTransition, *Response, error) {
+	tRes, res, err := u.transitionsRequest("GET", fmt.Sprintf("users/%d/status", id), nil)
+
+	// Check for empty history
+	if tRes == nil || tRes.Transitions == nil {
+		return nil, res, err
+	}
+
+	return tRes.Transitions, res, err
+}
+
+// BigBrother returns the big brother of the User with the input ID.
+func (u *UsersService) BigBrother(id uint64) (*models.User, *Response, error) {
+	uRes, res, err := u.request("GET", fmt.Sprintf("users/%d/big", id), nil)
+
+	// Check for no user found
+	if uRes == nil || uRes.Users == nil || len(uRes.Users) == 0 {
+		return nil, res, err
+	}
+
+	return uRes.Users[0], res, err
+}
+
+// SetBigBrother sets the big brother of the User with the input ID to the User
+// with the input big ID.  A big ID of 0 removes the User's big brother.
+func (u *UsersService) SetBigBrother(id uint64, bigID uint64) (*Response, error) {
+	_, res, err := u.request("PUT", fmt.Sprintf("users/%d/big", id), &v0.BigBrotherRequest{
+		BigBrotherID: bigID,
+	})
+	return res, err
+}
+
+// Littles returns a slice of all littles of the User with the input ID.
+func (u *UsersService) Littles(id uint64) ([]*models.User, *Response, error) {
+	uRes, res, err := u.request("GET", fmt.Sprintf("users/%d/littles", id), nil)
+
+	// Check for empty users
+	if uRes == nil || uRes.Users == nil {
+		return nil, res, err
+	}
+
+	return uRes.Users, res, err
+}
+
+// Family returns the family tree of the User with the input ID, rooted at the
+// User's most distant big brother.
+func (u *UsersService) Family(id uint64) (*v0.FamilyNode, *Response, error) {
+	// Create request for Family endpoint
+	req, err := u.client.NewRequest("GET", fmt.Sprintf("users/%d/family", id), nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Perform request, attempt to unmarshal response into a
+	// Family API response
+	fRes := new(v0.FamilyResponse)
+	res, err := u.client.Do(req, &fRes)
+	if err != nil {
+		return nil, res, err
+	}
+
+	return fRes.Family, res, nil
+}
+
+// FamilyDOT streams the family tree of the User with the input ID as a Graphviz
+// DOT document into the input io.Writer.
+func (u *UsersService) FamilyDOT(id uint64, w io.Writer) (*Response, error) {
+	// Create request for Family endpoint
+	req, err := u.client.NewRequest("GET", fmt.Sprintf("users/%d/family.dot", id), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Perform request, streaming document into writer
+	return u.client.Do(req, w)
+}
+
 // request generates and performs a HTTP request to the Users API.
 func (u *UsersService) request(method string, endpoint string, body interface{}) (*v0.UsersResponse, *Response, error) {
 	// Create request for Users endpoint
@@ -66,3 +378,22 @@ func (u *UsersService) request(method string, endpoint string, body interface{})
 
 	return uRes, res, nil
 }
+
+// transitionsRequest generates and performs a HTTP request to the membership
+// status endpoints of the Users API.
+func (u *UsersService) transitionsRequest(method string, endpoint string, body interface{}) (*v0.TransitionsResponse, *Response, error) {
+	req, err := u.client.NewRequest(method, endpoint, body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Perform request, attempt to unmarshal response into a
+	// Status Transitions API response
+	tRes := new(v0.TransitionsResponse)
+	res, err := u.client.Do(req, &tRes)
+	if err != nil {
+		return nil, res, err
+	}
+
+	return tRes, res, nil
+}
@@ -3,12 +3,17 @@
 package ditest
 
 import (
+	"context"
 	"fmt"
+	"io/ioutil"
 	"math/rand"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/mdlayher/deltaiota/bindata"
+	"github.com/mdlayher/deltaiota/blob"
 	"github.com/mdlayher/deltaiota/data"
 	"github.com/mdlayher/deltaiota/data/models"
 )
@@ -25,7 +30,7 @@ func WithTemporaryDB(fn func(db *data.DB) error) error {
 
 	// Open in-memory database
 	didb := &data.DB{}
-	if err := didb.Open("sqlite3", ":memory:"); err != nil {
+	if err := didb.Open("sqlite3", ":memory:", nil); err != nil {
 		return err
 	}
 
@@ -34,6 +39,11 @@ func WithTemporaryDB(fn func(db *data.DB) error) error {
 		return err
 	}
 
+	// Apply all schema migrations
+	if _, err := didb.Migrate(context.Background()); err != nil {
+		return err
+	}
+
 	// Invoke input closure with database
 	fnErr := fn(didb)
 
@@ -57,7 +67,7 @@ func WithTemporaryDBNew(t *testing.T, fn func(t *testing.T, db *data.DB)) {
 
 	// Open in-memory database
 	didb := &data.DB{}
-	if err := didb.Open("sqlite3", ":memory:"); err != nil {
+	if err := didb.Open("sqlite3", ":memory:", nil); err != nil {
 		t.Fatal(err)
 	}
 
@@ -66,6 +76,11 @@ func WithTemporaryDBNew(t *testing.T, fn func(t *testing.T, db *data.DB)) {
 		t.Fatal(err)
 	}
 
+	// Apply all schema migrations
+	if _, err := didb.Migrate(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
 	// Invoke input closure with test and database
 	fn(t, didb)
 
@@ -75,6 +90,92 @@ func WithTemporaryDBNew(t *testing.T, fn func(t *testing.T, db *data.DB)) {
 	}
 }
 
+// WithTemporaryReadonlyDB generates a temporary deltaiota sqlite3 database file
+// from bindata SQL schema, and invokes a setup closure which may populate it.
+// The database is then reopened in read-only mode, and passed to an input
+// closure.  The file is removed once the closure returns.
+func WithTemporaryReadonlyDB(t *testing.T, setup func(t *testing.T, db *data.DB), fn func(t *testing.T, db *data.DB)) {
+	withTemporaryReadonlyDB(t, true, setup, fn)
+}
+
+// WithTemporaryUnmigratedReadonlyDB is like WithTemporaryReadonlyDB, but no
+// schema migrations are applied to the database, as if it were last used by
+// an older release.
+func WithTemporaryUnmigratedReadonlyDB(t *testing.T, fn func(t *testing.T, db *data.DB)) {
+	withTemporaryReadonlyDB(t, false, nil, fn)
+}
+
+// withTemporaryReadonlyDB implements WithTemporaryReadonlyDB, applying schema
+// migrations only if migrate is set.
+func withTemporaryReadonlyDB(t *testing.T, migrate bool, setup func(t *testing.T, db *data.DB), fn func(t *testing.T, db *data.DB)) {
+	// Retrieve sqlite3 database schema asset
+	asset, err := bindata.Asset("res/sqlite/deltaiota.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Create temporary directory for database
+	dir, err := ioutil.TempDir("", "deltaiota")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "deltaiota.db")
+
+	// Build and populate database, then close it so that it may be reopened
+	didb := &data.DB{}
+	if err := didb.Open("sqlite3", path, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := didb.Exec(string(asset)); err != nil {
+		t.Fatal(err)
+	}
+	if migrate {
+		if _, err := didb.Migrate(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if setup != nil {
+		setup(t, didb)
+	}
+	if err := didb.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Reopen database in read-only mode
+	rodb := &data.DB{}
+	if err := rodb.Open("sqlite3", fmt.Sprintf("file:%s?mode=ro", path), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// Invoke input closure with test and database
+	fn(t, rodb)
+
+	// Close database
+	if err := rodb.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// WithTemporaryFileStore generates a blob.FileStore within a temporary directory,
+// invokes an input closure, and removes the directory once the closure returns.
+func WithTemporaryFileStore(fn func(store *blob.FileStore) error) error {
+	// Create temporary directory for store
+	dir, err := ioutil.TempDir("", "deltaiota")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	store, err := blob.NewFileStore(dir)
+	if err != nil {
+		return err
+	}
+
+	// Invoke input closure with store
+	return fn(store)
+}
+
 // MockUser generates a single User with mock data, used for testing.
 // The user is randomly generated, but is not guaranteed to be unique.
 func MockUser() *models.User {
@@ -84,6 +185,7 @@ func MockUser() *models.User {
 		LastName:  RandomString(10),
 		Email:     fmt.Sprintf("%s@%s.com", RandomString(6), RandomString(6)),
 		Password:  RandomString(10),
+		Status:    models.StatusActive,
 	}
 }
 
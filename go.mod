@@ -0,0 +1,13 @@
+module github.com/mdlayher/deltaiota
+
+go 1.21
+
+require (
+	github.com/BurntSushi/toml v1.2.0
+	github.com/gorilla/mux v1.8.1
+	github.com/mattn/go-sqlite3 v1.14.22
+	github.com/stretchr/graceful v1.2.15
+	golang.org/x/crypto v0.9.0
+)
+
+replace github.com/stretchr/graceful => github.com/tylerb/graceful v1.2.15
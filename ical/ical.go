@@ -0,0 +1,245 @@
+// Package ical implements an encoder for iCalendar data, as described in
+// RFC 5545.
+package ical
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// ContentType is the MIME type of iCalendar data.
+	ContentType = "text/calendar; charset=utf-8"
+
+	// maxLineOctets is the maximum length of a content line, excluding the
+	// line break, before it must be folded.
+	maxLineOctets = 75
+
+	// Layouts for DATE and DATE-TIME values.
+	dateLayout     = "20060102"
+	dateTimeLayout = "20060102T150405"
+)
+
+var (
+	// ErrNoProdID is returned when a Calendar has no product identifier.
+	ErrNoProdID = errors.New("ical: calendar has no product identifier")
+
+	// ErrNoUID is returned when an Event has no unique identifier.
+	ErrNoUID = errors.New("ical: event has no unique identifier")
+
+	// ErrEndBeforeStart is returned when an Event ends before it starts.
+	ErrEndBeforeStart = errors.New("ical: event ends before it starts")
+)
+
+// Calendar is an iCalendar object, containing a set of Events.
+type Calendar struct {
+	// ProdID identifies the product which created the Calendar, and is required.
+	ProdID string
+
+	// Name is an optional display name for the Calendar.
+	Name string
+
+	// Location is the time zone in which Event times are expressed.  If nil or
+	// UTC, times are expressed in UTC.  Otherwise, Location must be a named
+	// IANA time zone, such as one returned by time.LoadLocation, and a matching
+	// VTIMEZONE component is generated.
+	Location *time.Location
+
+	Events []*Event
+}
+
+// Event is a single VEVENT component within a Calendar.
+type Event struct {
+	// UID is a globally unique and persistent identifier for the Event, and
+	// is required.
+	UID string
+
+	// Stamp is the time at which the Event was last modified.
+	Stamp time.Time
+
+	// Start and End are the bounds of the Event.  If End is zero, the Event
+	// has no duration.  If AllDay is set, only the dates of Start and End are
+	// used, and End is exclusive; if End does not fall after Start, the Event
+	// lasts a single day.
+	Start  time.Time
+	End    time.Time
+	AllDay bool
+
+	Summary     string
+	Description string
+	Location    string
+}
+
+// An Encoder writes iCalendar data to an output stream.
+type Encoder struct {
+	w *bufio.Writer
+}
+
+// NewEncoder returns a new Encoder which writes to w.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{
+		w: bufio.NewWriter(w),
+	}
+}
+
+// Encode writes the iCalendar encoding of c to the stream.
+func (e *Encoder) Encode(c *Calendar) error {
+	// Verify calendar before writing any output
+	if c.ProdID == "" {
+		return ErrNoProdID
+	}
+	for _, ev := range c.Events {
+		if ev.UID == "" {
+			return ErrNoUID
+		}
+		if !ev.End.IsZero() && ev.End.Before(ev.Start) {
+			return ErrEndBeforeStart
+		}
+	}
+
+	// Times are expressed in UTC, unless a named time zone is specified
+	loc := c.Location
+	if loc == time.UTC {
+		loc = nil
+	}
+
+	e.line("BEGIN", "", "VCALENDAR")
+	e.line("VERSION", "", "2.0")
+	e.line("PRODID", "", escape(c.ProdID))
+	e.line("CALSCALE", "", "GREGORIAN")
+	if c.Name != "" {
+		e.line("X-WR-CALNAME", "", escape(c.Name))
+	}
+
+	if loc != nil {
+		e.line("X-WR-TIMEZONE", "", loc.String())
+		e.timezone(loc, c.Events)
+	}
+
+	for _, ev := range c.Events {
+		e.event(ev, loc)
+	}
+
+	e.line("END", "", "VCALENDAR")
+	return e.w.Flush()
+}
+
+// event writes a single VEVENT component, using the input time zone.
+func (e *Encoder) event(ev *Event, loc *time.Location) {
+	e.line("BEGIN", "", "VEVENT")
+	e.line("UID", "", escape(ev.UID))
+	e.line("DTSTAMP", "", ev.Stamp.UTC().Format(dateTimeLayout)+"Z")
+
+	if ev.AllDay {
+		// All day events are expressed as dates, with an exclusive end date
+		start := inLocation(ev.Start, loc)
+		end := inLocation(ev.End, loc)
+		startDate := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+		endDate := time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, time.UTC)
+		if ev.End.IsZero() || !endDate.After(startDate) {
+			endDate = startDate.AddDate(0, 0, 1)
+		}
+
+		e.line("DTSTART", ";VALUE=DATE", startDate.Format(dateLayout))
+		e.line("DTEND", ";VALUE=DATE", endDate.Format(dateLayout))
+	} else {
+		e.dateTime("DTSTART", ev.Start, loc)
+		if !ev.End.IsZero() {
+			e.dateTime("DTEND", ev.End, loc)
+		}
+	}
+
+	if ev.Summary != "" {
+		e.line("SUMMARY", "", escape(ev.Summary))
+	}
+	if ev.Description != "" {
+		e.line("DESCRIPTION", "", escape(ev.Description))
+	}
+	if ev.Location != "" {
+		e.line("LOCATION", "", escape(ev.Location))
+	}
+
+	e.line("END", "", "VEVENT")
+}
+
+// dateTime writes a DATE-TIME property, in UTC or in the input time zone.
+func (e *Encoder) dateTime(name string, t time.Time, loc *time.Location) {
+	if loc == nil {
+		e.line(name, "", t.UTC().Format(dateTimeLayout)+"Z")
+		return
+	}
+
+	e.line(name, ";TZID="+loc.String(), t.In(loc).Format(dateTimeLayout))
+}
+
+// line writes a single content line, with optional parameters, folding the line
+// as needed.  Any write error is retained by the underlying bufio.Writer, and
+// reported when it is flushed.
+func (e *Encoder) line(name string, params string, value string) {
+	s := name + params + ":" + value
+
+	// Fold lines longer than the maximum, without splitting any UTF-8
+	// sequences; continuation lines begin with a single space
+	limit := maxLineOctets
+	for len(s) > limit {
+		i := limit
+		for i > 0 && !utf8.RuneStart(s[i]) {
+			i--
+		}
+
+		e.w.WriteString(s[:i])
+		e.w.WriteString("\r\n ")
+		s = s[i:]
+
+		// Leading space counts toward continuation line length
+		limit = maxLineOctets - 1
+	}
+
+	e.w.WriteString(s)
+	e.w.WriteString("\r\n")
+}
+
+// inLocation returns t in the input time zone, or in UTC if loc is nil.
+func inLocation(t time.Time, loc *time.Location) time.Time {
+	if loc == nil {
+		return t.UTC()
+	}
+
+	return t.In(loc)
+}
+
+// textEscaper escapes special characters in TEXT values.
+var textEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	`;`, `\;`,
+	`,`, `\,`,
+	"\r\n", `\n`,
+	"\n", `\n`,
+	"\r", `\n`,
+)
+
+// escape escapes a TEXT value for use in a content line.
+func escape(s string) string {
+	return textEscaper.Replace(s)
+}
+
+// formatOffset formats a UTC offset, in seconds, as a UTC-OFFSET value.
+func formatOffset(offset int) string {
+	sign := "+"
+	if offset < 0 {
+		sign = "-"
+		offset = -offset
+	}
+
+	s := fmt.Sprintf("%s%02d%02d", sign, offset/3600, offset/60%60)
+	if offset%60 != 0 {
+		s += fmt.Sprintf("%02d", offset%60)
+	}
+
+	return s
+}
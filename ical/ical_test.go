@@ -0,0 +1,185 @@
+package ical
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestEncoderUTC verifies that Encoder produces the expected output for a
+// Calendar with times expressed in UTC.
+func TestEncoderUTC(t *testing.T) {
+	stamp := time.Date(2015, time.January, 2, 3, 4, 5, 0, time.UTC)
+
+	cal := &Calendar{
+		ProdID: "-//deltaiota//test//EN",
+		Name:   "Chapter",
+		Events: []*Event{
+			{
+				UID:         "event-1@test",
+				Stamp:       stamp,
+				Start:       time.Date(2015, time.February, 1, 18, 0, 0, 0, time.UTC),
+				End:         time.Date(2015, time.February, 1, 20, 0, 0, 0, time.UTC),
+				Summary:     "Chapter meeting",
+				Description: "Bring dues; snacks, too\nRoom 101",
+				Location:    `Hall \ Annex`,
+			},
+			{
+				UID:     "event-2@test",
+				Stamp:   stamp,
+				Start:   time.Date(2015, time.March, 7, 12, 0, 0, 0, time.UTC),
+				AllDay:  true,
+				Summary: "Service day",
+			},
+		},
+	}
+
+	want := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//deltaiota//test//EN",
+		"CALSCALE:GREGORIAN",
+		"X-WR-CALNAME:Chapter",
+		"BEGIN:VEVENT",
+		"UID:event-1@test",
+		"DTSTAMP:20150102T030405Z",
+		"DTSTART:20150201T180000Z",
+		"DTEND:20150201T200000Z",
+		"SUMMARY:Chapter meeting",
+		`DESCRIPTION:Bring dues\; snacks\, too\nRoom 101`,
+		`LOCATION:Hall \\ Annex`,
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:event-2@test",
+		"DTSTAMP:20150102T030405Z",
+		"DTSTART;VALUE=DATE:20150307",
+		"DTEND;VALUE=DATE:20150308",
+		"SUMMARY:Service day",
+		"END:VEVENT",
+		"END:VCALENDAR",
+		"",
+	}, "\r\n")
+
+	buf := bytes.NewBuffer(nil)
+	if err := NewEncoder(buf).Encode(cal); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := buf.String(); got != want {
+		t.Fatalf("unexpected output:\n%s\n!=\n%s", got, want)
+	}
+}
+
+// TestEncoderTimeZone verifies that Encoder expresses times in a named time
+// zone, and generates a VTIMEZONE component with its offset transitions.
+func TestEncoderTimeZone(t *testing.T) {
+	loc, err := time.LoadLocation("America/Chicago")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+
+	cal := &Calendar{
+		ProdID:   "-//deltaiota//test//EN",
+		Location: loc,
+		Events: []*Event{
+			{
+				UID:   "event-1@test",
+				Start: time.Date(2015, time.March, 1, 18, 0, 0, 0, loc),
+				End:   time.Date(2015, time.March, 1, 19, 0, 0, 0, loc),
+			},
+			{
+				UID:   "event-2@test",
+				Start: time.Date(2015, time.March, 20, 18, 0, 0, 0, loc),
+			},
+		},
+	}
+
+	buf := bytes.NewBuffer(nil)
+	if err := NewEncoder(buf).Encode(cal); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+
+	for _, line := range []string{
+		"X-WR-TIMEZONE:America/Chicago\r\n",
+		// Standard time began in November 2014, before the first event
+		"BEGIN:STANDARD\r\nDTSTART:20141102T020000\r\nTZOFFSETFROM:-0500\r\nTZOFFSETTO:-0600\r\nTZNAME:CST\r\nEND:STANDARD\r\n",
+		// Daylight saving time began in March 2015, between the events
+		"BEGIN:DAYLIGHT\r\nDTSTART:20150308T020000\r\nTZOFFSETFROM:-0600\r\nTZOFFSETTO:-0500\r\nTZNAME:CDT\r\nEND:DAYLIGHT\r\n",
+		"DTSTART;TZID=America/Chicago:20150301T180000\r\n",
+		"DTEND;TZID=America/Chicago:20150301T190000\r\n",
+		"DTSTART;TZID=America/Chicago:20150320T180000\r\n",
+	} {
+		if !strings.Contains(out, line) {
+			t.Fatalf("output missing %q:\n%s", line, out)
+		}
+	}
+}
+
+// TestEncoderFolding verifies that Encoder folds long content lines without
+// splitting UTF-8 sequences.
+func TestEncoderFolding(t *testing.T) {
+	cal := &Calendar{
+		ProdID: "-//deltaiota//test//EN",
+		Events: []*Event{{
+			UID:         "event-1@test",
+			Description: strings.Repeat("a", 60) + strings.Repeat("Δ", 60),
+		}},
+	}
+
+	buf := bytes.NewBuffer(nil)
+	if err := NewEncoder(buf).Encode(cal); err != nil {
+		t.Fatal(err)
+	}
+
+	var unfolded []string
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n") {
+		if len(line) > maxLineOctets {
+			t.Fatalf("line too long: %d > %d: %q", len(line), maxLineOctets, line)
+		}
+
+		if strings.HasPrefix(line, " ") {
+			unfolded[len(unfolded)-1] += line[1:]
+			continue
+		}
+		unfolded = append(unfolded, line)
+	}
+
+	want := "DESCRIPTION:" + cal.Events[0].Description
+	for _, line := range unfolded {
+		if line == want {
+			return
+		}
+	}
+
+	t.Fatalf("unfolded output missing %q:\n%v", want, unfolded)
+}
+
+// TestEncoderInvalid verifies that Encoder rejects invalid Calendars.
+func TestEncoderInvalid(t *testing.T) {
+	now := time.Now()
+
+	var tests = []struct {
+		cal *Calendar
+		err error
+	}{
+		{&Calendar{}, ErrNoProdID},
+		{&Calendar{ProdID: "test", Events: []*Event{{}}}, ErrNoUID},
+		{&Calendar{ProdID: "test", Events: []*Event{{
+			UID:   "event",
+			Start: now,
+			End:   now.Add(-time.Hour),
+		}}}, ErrEndBeforeStart},
+	}
+
+	for i, test := range tests {
+		buf := bytes.NewBuffer(nil)
+		if err := NewEncoder(buf).Encode(test.cal); err != test.err {
+			t.Fatalf("[%02d] unexpected error: %v != %v", i, err, test.err)
+		}
+		if buf.Len() != 0 {
+			t.Fatalf("[%02d] unexpected output: %q", i, buf.String())
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package ical
+
+import (
+	"time"
+)
+
+// transition is a change in the UTC offset of a time zone.
+type transition struct {
+	at    time.Time
+	from  int
+	to    int
+	name  string
+	isDST bool
+}
+
+// timezone writes a VTIMEZONE component for the input time zone, with one
+// observance for each offset transition which affects the input events.
+func (e *Encoder) timezone(loc *time.Location, events []*Event) {
+	// Determine the span of time covered by the events
+	var start, end time.Time
+	for i, ev := range events {
+		evEnd := ev.End
+		if evEnd.IsZero() {
+			evEnd = ev.Start
+		}
+
+		if i == 0 || ev.Start.Before(start) {
+			start = ev.Start
+		}
+		if i == 0 || evEnd.After(end) {
+			end = evEnd
+		}
+	}
+	if start.IsZero() {
+		start = time.Now()
+		end = start
+	}
+
+	e.line("BEGIN", "", "VTIMEZONE")
+	e.line("TZID", "", loc.String())
+
+	for _, t := range transitions(loc, start, end) {
+		kind := "STANDARD"
+		if t.isDST {
+			kind = "DAYLIGHT"
+		}
+
+		// Onset is expressed in local time, before the transition occurs
+		onset := t.at.UTC().Add(time.Duration(t.from) * time.Second)
+
+		e.line("BEGIN", "", kind)
+		e.line("DTSTART", "", onset.Format(dateTimeLayout))
+		e.line("TZOFFSETFROM", "", formatOffset(t.from))
+		e.line("TZOFFSETTO", "", formatOffset(t.to))
+		if t.name != "" {
+			e.line("TZNAME", "", escape(t.name))
+		}
+		e.line("END", "", kind)
+	}
+
+	e.line("END", "", "VTIMEZONE")
+}
+
+// transitions returns the offset transitions of the input time zone which are
+// in effect between start and end.  The first transition is the most recent
+// one before start; if none is found within a year, a transition which does not
+// change the offset is returned in its place.
+func transitions(loc *time.Location, start time.Time, end time.Time) []transition {
+	const day = 24 * time.Hour
+
+	// Look back up to a year for the transition which is in effect at start
+	var out []transition
+	if t, ok := findTransition(loc, start.Add(-366*day), start, true); ok {
+		out = append(out, t)
+	} else {
+		name, offset := start.In(loc).Zone()
+		out = append(out, transition{
+			at:    time.Date(1970, time.January, 1, 0, 0, 0, 0, time.UTC).Add(-time.Duration(offset) * time.Second),
+			from:  offset,
+			to:    offset,
+			name:  name,
+			isDST: start.In(loc).IsDST(),
+		})
+	}
+
+	// Add each transition which occurs before end
+	for from := start; from.Before(end); {
+		t, ok := findTransition(loc, from, end, false)
+		if !ok {
+			break
+		}
+
+		out = append(out, t)
+		from = t.at
+	}
+
+	return out
+}
+
+// findTransition searches for a transition of the input time zone between from
+// and to, in daily steps.  If last is set, the last transition in the range is
+// returned; otherwise, the first is returned.
+func findTransition(loc *time.Location, from time.Time, to time.Time, last bool) (transition, bool) {
+	const day = 24 * time.Hour
+
+	var found transition
+	var ok bool
+
+	prev := from
+	_, prevOffset := prev.In(loc).Zone()
+	for prev.Before(to) {
+		next := prev.Add(day)
+		if next.After(to) {
+			next = to
+		}
+
+		if _, offset := next.In(loc).Zone(); offset != prevOffset {
+			found, ok = bisect(loc, prev, next), true
+			if !last {
+				return found, ok
+			}
+
+			prevOffset = offset
+		}
+
+		prev = next
+	}
+
+	return found, ok
+}
+
+// bisect finds the exact second at which the offset of the input time zone
+// changes between lo and hi.
+func bisect(loc *time.Location, lo time.Time, hi time.Time) transition {
+	_, from := lo.In(loc).Zone()
+	for hi.Sub(lo) > time.Second {
+		mid := lo.Add(hi.Sub(lo) / 2).Truncate(time.Second)
+		if !mid.After(lo) {
+			break
+		}
+
+		if _, offset := mid.In(loc).Zone(); offset == from {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+
+	at := hi.In(loc)
+	name, to := at.Zone()
+	return transition{
+		at:    hi,
+		from:  from,
+		to:    to,
+		name:  name,
+		isDST: at.IsDST(),
+	}
+}
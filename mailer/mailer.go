@@ -0,0 +1,114 @@
+// Package mailer provides outgoing email delivery for the Phi Mu Alpha
+// Sinfonia - Delta Iota chapter website.
+package mailer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"net/mail"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrNoRecipient is returned when a Message has no recipient.
+	ErrNoRecipient = errors.New("mailer: no recipient")
+
+	// ErrInvalidHeader is returned when a Message header field contains a
+	// line break, which could be used to inject additional headers.
+	ErrInvalidHeader = errors.New("mailer: invalid header")
+)
+
+// Mailer delivers email messages.
+type Mailer interface {
+	// Send delivers the input Message to its recipient.
+	Send(m *Message) error
+}
+
+// Message is a plain text email message.
+type Message struct {
+	From    string
+	To      string
+	Subject string
+	Body    string
+	Date    time.Time
+}
+
+// Bytes returns the RFC 5322 representation of a Message, with CRLF line
+// endings.  Non-ASCII subjects are MIME encoded, and the body is sent as
+// UTF-8 plain text.
+func (m *Message) Bytes() ([]byte, error) {
+	if m.To == "" {
+		return nil, ErrNoRecipient
+	}
+	for _, h := range []string{m.From, m.To, m.Subject} {
+		if strings.ContainsAny(h, "\r\n") {
+			return nil, ErrInvalidHeader
+		}
+	}
+
+	date := m.Date
+	if date.IsZero() {
+		date = time.Now()
+	}
+
+	var b bytes.Buffer
+	header := func(k string, v string) {
+		fmt.Fprintf(&b, "%s: %s\r\n", k, v)
+	}
+
+	if m.From != "" {
+		header("From", m.From)
+	}
+	header("To", m.To)
+	header("Subject", mime.QEncoding.Encode("utf-8", m.Subject))
+	header("Date", date.Format(time.RFC1123Z))
+	header("MIME-Version", "1.0")
+	header("Content-Type", `text/plain; charset="utf-8"`)
+	header("Content-Transfer-Encoding", "8bit")
+	b.WriteString("\r\n")
+
+	// Normalize all body line endings to CRLF
+	body := strings.Replace(m.Body, "\r\n", "\n", -1)
+	b.WriteString(strings.Replace(body, "\n", "\r\n", -1))
+	if !strings.HasSuffix(body, "\n") {
+		b.WriteString("\r\n")
+	}
+
+	return b.Bytes(), nil
+}
+
+// address returns the bare email address from an RFC 5322 address, such as
+// "Delta Iota <noreply@example.com>".
+func address(s string) (string, error) {
+	a, err := mail.ParseAddress(s)
+	if err != nil {
+		return "", err
+	}
+
+	return a.Address, nil
+}
+
+// LogMailer is a Mailer which writes messages to a log.Logger, instead of
+// delivering them.  It is useful when no mail server is configured.
+type LogMailer struct {
+	Logger *log.Logger
+}
+
+// Send logs the recipient, subject, and body of the input Message.
+func (l *LogMailer) Send(m *Message) error {
+	if m.To == "" {
+		return ErrNoRecipient
+	}
+
+	printf := log.Printf
+	if l.Logger != nil {
+		printf = l.Logger.Printf
+	}
+
+	printf("mailer: to: %s, subject: %q\n%s", m.To, m.Subject, m.Body)
+	return nil
+}
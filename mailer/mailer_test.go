@@ -0,0 +1,83 @@
+package mailer
+
+import (
+	"bytes"
+	"log"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestMessageBytes verifies that Message.Bytes produces a correct RFC 5322
+// message.
+func TestMessageBytes(t *testing.T) {
+	m := &Message{
+		From:    "Delta Iota <noreply@example.com>",
+		To:      "test@example.com",
+		Subject: "Invitation to Δι",
+		Body:    "Hello,\nWelcome!",
+		Date:    time.Date(2015, time.March, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	out, err := m.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := strings.Join([]string{
+		"From: Delta Iota <noreply@example.com>",
+		"To: test@example.com",
+		"Subject: =?utf-8?q?Invitation_to_=CE=94=CE=B9?=",
+		"Date: Sun, 01 Mar 2015 12:00:00 +0000",
+		"MIME-Version: 1.0",
+		`Content-Type: text/plain; charset="utf-8"`,
+		"Content-Transfer-Encoding: 8bit",
+		"",
+		"Hello,",
+		"Welcome!",
+		"",
+	}, "\r\n")
+
+	if string(out) != expected {
+		t.Fatalf("unexpected message:\n%q\n%q", string(out), expected)
+	}
+}
+
+// TestMessageBytesInvalid verifies that Message.Bytes rejects messages without
+// a recipient, or with header injection.
+func TestMessageBytesInvalid(t *testing.T) {
+	var tests = []struct {
+		m   *Message
+		err error
+	}{
+		{&Message{Subject: "hello"}, ErrNoRecipient},
+		{&Message{To: "test@example.com", Subject: "hello\r\nBcc: evil@example.com"}, ErrInvalidHeader},
+		{&Message{To: "test@example.com\nBcc: evil@example.com"}, ErrInvalidHeader},
+	}
+
+	for i, test := range tests {
+		if _, err := test.m.Bytes(); err != test.err {
+			t.Fatalf("[%02d] unexpected error: %v != %v", i, err, test.err)
+		}
+	}
+}
+
+// TestLogMailer verifies that LogMailer logs messages.
+func TestLogMailer(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	m := &LogMailer{
+		Logger: log.New(buf, "", 0),
+	}
+
+	if err := m.Send(&Message{
+		To:      "test@example.com",
+		Subject: "hello",
+		Body:    "world",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if s := buf.String(); !strings.Contains(s, "test@example.com") || !strings.Contains(s, "world") {
+		t.Fatalf("unexpected log output: %q", s)
+	}
+}
@@ -0,0 +1,61 @@
+package mailer
+
+import (
+	"net"
+	"net/smtp"
+)
+
+// SMTPMailer is a Mailer which delivers messages using a SMTP server.
+type SMTPMailer struct {
+	addr string
+	from string
+	auth smtp.Auth
+}
+
+// NewSMTPMailer creates a new SMTPMailer which delivers messages from the
+// input address, using the SMTP server at addr.  If username is not empty,
+// PLAIN authentication is used with the server.
+func NewSMTPMailer(addr string, from string, username string, password string) (*SMTPMailer, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	// Verify the sender address can be parsed
+	if _, err := address(from); err != nil {
+		return nil, err
+	}
+
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	return &SMTPMailer{
+		addr: addr,
+		from: from,
+		auth: auth,
+	}, nil
+}
+
+// Send delivers the input Message using the SMTP server.  The Message is
+// always sent from the SMTPMailer's sender address.
+func (s *SMTPMailer) Send(m *Message) error {
+	to, err := address(m.To)
+	if err != nil {
+		return err
+	}
+	from, err := address(s.from)
+	if err != nil {
+		return err
+	}
+
+	msg := *m
+	msg.From = s.from
+	body, err := msg.Bytes()
+	if err != nil {
+		return err
+	}
+
+	return smtp.SendMail(s.addr, s.auth, from, []string{to}, body)
+}
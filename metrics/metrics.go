@@ -0,0 +1,391 @@
+// Package metrics provides metrics for the Phi Mu Alpha Sinfonia - Delta Iota
+// chapter website, which are exposed in the Prometheus text format.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// contentType is the HTTP Content-Type of the Prometheus text format.
+const contentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// DefaultBuckets are the default upper bounds of Histogram buckets, in seconds,
+// which suit the duration of HTTP requests.
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// A Metric is a named metric which may be written in the Prometheus text
+// format.
+type Metric interface {
+	// Name returns the metric's name.
+	Name() string
+
+	// write writes the metric's help, type, and samples.
+	write(w io.Writer)
+}
+
+// Registry is a set of Metrics which are written together.
+type Registry struct {
+	mu      sync.RWMutex
+	metrics map[string]Metric
+}
+
+// NewRegistry creates an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		metrics: make(map[string]Metric),
+	}
+}
+
+// Register adds the input Metrics to the Registry.  Registering two Metrics
+// with the same name is an error.
+func (r *Registry) Register(metrics ...Metric) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, m := range metrics {
+		if _, ok := r.metrics[m.Name()]; ok {
+			return fmt.Errorf("metrics: duplicate metric: %q", m.Name())
+		}
+
+		r.metrics[m.Name()] = m
+	}
+
+	return nil
+}
+
+// WriteTo writes all Metrics in the Registry in the Prometheus text format,
+// sorted by name.
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	r.mu.RLock()
+	names := make([]string, 0, len(r.metrics))
+	for name := range r.metrics {
+		names = append(names, name)
+	}
+	metrics := make([]Metric, 0, len(names))
+	sort.Strings(names)
+	for _, name := range names {
+		metrics = append(metrics, r.metrics[name])
+	}
+	r.mu.RUnlock()
+
+	bw := bufio.NewWriter(w)
+	cw := &countWriter{w: bw}
+	for _, m := range metrics {
+		m.write(cw)
+	}
+	if cw.err != nil {
+		return cw.n, cw.err
+	}
+
+	return cw.n, bw.Flush()
+}
+
+// ServeHTTP allows Registry to be used as a http.Handler, which serves all of
+// its Metrics.
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", contentType)
+	if req.Method == "HEAD" {
+		return
+	}
+
+	r.WriteTo(w)
+}
+
+// Counter is a metric whose value only increases, with a series for each
+// distinct set of label values.
+type Counter struct {
+	name   string
+	help   string
+	labels []string
+
+	mu     sync.Mutex
+	series map[string]*counterSeries
+}
+
+// counterSeries is a single series of a Counter.
+type counterSeries struct {
+	values []string
+	value  float64
+}
+
+// NewCounter creates a Counter with the input name, help text, and label names.
+func NewCounter(name string, help string, labels ...string) *Counter {
+	return &Counter{
+		name:   name,
+		help:   help,
+		labels: labels,
+		series: make(map[string]*counterSeries),
+	}
+}
+
+// Name implements Metric.
+func (c *Counter) Name() string { return c.name }
+
+// Inc increments the series with the input label values by 1.
+func (c *Counter) Inc(values ...string) {
+	c.Add(1, values...)
+}
+
+// Add adds v, which must not be negative, to the series with the input label
+// values.
+func (c *Counter) Add(v float64, values ...string) {
+	checkLabels(c.name, c.labels, values)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := seriesKey(values)
+	s, ok := c.series[key]
+	if !ok {
+		s = &counterSeries{values: values}
+		c.series[key] = s
+	}
+
+	s.value += v
+}
+
+// Value returns the value of the series with the input label values.
+func (c *Counter) Value(values ...string) float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	s, ok := c.series[seriesKey(values)]
+	if !ok {
+		return 0
+	}
+
+	return s.value
+}
+
+// write implements Metric.
+func (c *Counter) write(w io.Writer) {
+	writeHeader(w, c.name, c.help, "counter")
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, key := range sortedKeys(c.series) {
+		s := c.series[key]
+		writeSample(w, c.name, c.labels, s.values, "", "", s.value)
+	}
+}
+
+// Histogram is a metric which counts observed values in buckets, with a series
+// for each distinct set of label values.
+type Histogram struct {
+	name    string
+	help    string
+	buckets []float64
+	labels  []string
+
+	mu     sync.Mutex
+	series map[string]*histogramSeries
+}
+
+// histogramSeries is a single series of a Histogram.
+type histogramSeries struct {
+	values []string
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// NewHistogram creates a Histogram with the input name, help text, bucket
+// upper bounds in increasing order, and label names.
+func NewHistogram(name string, help string, buckets []float64, labels ...string) *Histogram {
+	return &Histogram{
+		name:    name,
+		help:    help,
+		buckets: buckets,
+		labels:  labels,
+		series:  make(map[string]*histogramSeries),
+	}
+}
+
+// Name implements Metric.
+func (h *Histogram) Name() string { return h.name }
+
+// Observe adds a value to the series with the input label values.
+func (h *Histogram) Observe(v float64, values ...string) {
+	checkLabels(h.name, h.labels, values)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	key := seriesKey(values)
+	s, ok := h.series[key]
+	if !ok {
+		s = &histogramSeries{
+			values: values,
+			counts: make([]uint64, len(h.buckets)),
+		}
+		h.series[key] = s
+	}
+
+	// Counts are stored per bucket, and accumulated when written
+	if i := sort.SearchFloat64s(h.buckets, v); i < len(h.buckets) {
+		s.counts[i]++
+	}
+	s.count++
+	s.sum += v
+}
+
+// Count returns the number of values observed by the series with the input
+// label values.
+func (h *Histogram) Count(values ...string) uint64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	s, ok := h.series[seriesKey(values)]
+	if !ok {
+		return 0
+	}
+
+	return s.count
+}
+
+// write implements Metric.
+func (h *Histogram) write(w io.Writer) {
+	writeHeader(w, h.name, h.help, "histogram")
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, key := range sortedKeys(h.series) {
+		s := h.series[key]
+
+		var cumulative uint64
+		for i, b := range h.buckets {
+			cumulative += s.counts[i]
+			writeSample(w, h.name+"_bucket", h.labels, s.values, "le", formatFloat(b), float64(cumulative))
+		}
+		writeSample(w, h.name+"_bucket", h.labels, s.values, "le", "+Inf", float64(s.count))
+		writeSample(w, h.name+"_sum", h.labels, s.values, "", "", s.sum)
+		writeSample(w, h.name+"_count", h.labels, s.values, "", "", float64(s.count))
+	}
+}
+
+// funcMetric is a metric whose single value is retrieved by a function each
+// time it is written.
+type funcMetric struct {
+	name string
+	help string
+	typ  string
+	fn   func() float64
+}
+
+// NewGaugeFunc creates a gauge Metric, whose value may increase or decrease,
+// and is retrieved using fn.
+func NewGaugeFunc(name string, help string, fn func() float64) Metric {
+	return &funcMetric{name: name, help: help, typ: "gauge", fn: fn}
+}
+
+// NewCounterFunc creates a counter Metric, whose value only increases, and is
+// retrieved using fn.
+func NewCounterFunc(name string, help string, fn func() float64) Metric {
+	return &funcMetric{name: name, help: help, typ: "counter", fn: fn}
+}
+
+// Name implements Metric.
+func (m *funcMetric) Name() string { return m.name }
+
+// write implements Metric.
+func (m *funcMetric) write(w io.Writer) {
+	writeHeader(w, m.name, m.help, m.typ)
+	writeSample(w, m.name, nil, nil, "", "", m.fn())
+}
+
+// checkLabels panics if the number of label values does not match the number
+// of label names, since this is always a programming error.
+func checkLabels(name string, labels []string, values []string) {
+	if len(labels) != len(values) {
+		panic(fmt.Sprintf("metrics: %s: expected %d label value(s), got %d", name, len(labels), len(values)))
+	}
+}
+
+// seriesKey returns a unique key for a set of label values.
+func seriesKey(values []string) string {
+	return strings.Join(values, "\xff")
+}
+
+// sortedKeys returns the keys of a map of series, sorted.
+func sortedKeys[T any](series map[string]T) []string {
+	keys := make([]string, 0, len(series))
+	for k := range series {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+// writeHeader writes the HELP and TYPE lines of a metric.
+func writeHeader(w io.Writer, name string, help string, typ string) {
+	help = strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(help)
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+// writeSample writes a single sample with the input labels, and an optional
+// extra label, such as a histogram bucket's upper bound.
+func writeSample(w io.Writer, name string, labels []string, values []string, extraLabel string, extraValue string, v float64) {
+	var pairs []string
+	for i, l := range labels {
+		pairs = append(pairs, l+`="`+escapeLabel(values[i])+`"`)
+	}
+	if extraLabel != "" {
+		pairs = append(pairs, extraLabel+`="`+extraValue+`"`)
+	}
+
+	if len(pairs) > 0 {
+		name += "{" + strings.Join(pairs, ",") + "}"
+	}
+
+	fmt.Fprintf(w, "%s %s\n", name, formatFloat(v))
+}
+
+// escapeLabel escapes a label value for the Prometheus text format.
+func escapeLabel(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
+
+// formatFloat formats a sample value for the Prometheus text format.
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// countWriter is an io.Writer which counts the bytes written to w, and stores
+// the first error which occurs.
+type countWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+// Write implements io.Writer.
+func (w *countWriter) Write(b []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+
+	n, err := w.w.Write(b)
+	w.n += int64(n)
+	w.err = err
+	return n, err
+}
@@ -0,0 +1,117 @@
+package metrics
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestRegistryWriteTo verifies that Registry writes its metrics in the
+// Prometheus text format, sorted by name.
+func TestRegistryWriteTo(t *testing.T) {
+	c := NewCounter("test_requests_total", "Number of requests.\nBy path.", "path")
+	c.Inc("/b")
+	c.Inc("/a")
+	c.Add(2, "/a")
+	c.Inc(`/"quoted"\`)
+
+	h := NewHistogram("test_duration_seconds", "Duration of requests.", []float64{0.1, 1}, "code")
+	h.Observe(0.05, "200")
+	h.Observe(0.5, "200")
+	h.Observe(5, "200")
+
+	g := NewGaugeFunc("test_goroutines", "Number of goroutines.", func() float64 {
+		return 42
+	})
+
+	reg := NewRegistry()
+	if err := reg.Register(c, h, g); err != nil {
+		t.Fatal(err)
+	}
+
+	var b bytes.Buffer
+	n, err := reg.WriteTo(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if int(n) != b.Len() {
+		t.Fatalf("unexpected number of bytes written: %d != %d", n, b.Len())
+	}
+
+	expected := strings.Join([]string{
+		"# HELP test_duration_seconds Duration of requests.",
+		"# TYPE test_duration_seconds histogram",
+		`test_duration_seconds_bucket{code="200",le="0.1"} 1`,
+		`test_duration_seconds_bucket{code="200",le="1"} 2`,
+		`test_duration_seconds_bucket{code="200",le="+Inf"} 3`,
+		`test_duration_seconds_sum{code="200"} 5.55`,
+		`test_duration_seconds_count{code="200"} 3`,
+		"# HELP test_goroutines Number of goroutines.",
+		"# TYPE test_goroutines gauge",
+		"test_goroutines 42",
+		`# HELP test_requests_total Number of requests.\nBy path.`,
+		"# TYPE test_requests_total counter",
+		`test_requests_total{path="/\"quoted\"\\"} 1`,
+		`test_requests_total{path="/a"} 3`,
+		`test_requests_total{path="/b"} 1`,
+		"",
+	}, "\n")
+
+	if got := b.String(); got != expected {
+		t.Fatalf("unexpected output:\n%s\nexpected:\n%s", got, expected)
+	}
+}
+
+// TestRegistryRegisterDuplicate verifies that Registry rejects metrics with
+// duplicate names.
+func TestRegistryRegisterDuplicate(t *testing.T) {
+	reg := NewRegistry()
+	if err := reg.Register(NewCounter("test_total", "Test.")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := reg.Register(NewCounter("test_total", "Test.")); err == nil {
+		t.Fatal("expected error, but none occurred")
+	}
+}
+
+// TestRegistryServeHTTP verifies that Registry serves its metrics over HTTP.
+func TestRegistryServeHTTP(t *testing.T) {
+	reg := NewRegistry()
+	if err := reg.Register(RuntimeMetrics()...); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, method := range []string{"GET", "HEAD"} {
+		r, err := http.NewRequest(method, "/metrics", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		w := httptest.NewRecorder()
+		reg.ServeHTTP(w, r)
+
+		if ct := w.Header().Get("Content-Type"); ct != contentType {
+			t.Fatalf("[%02d] unexpected Content-Type: %q", i, ct)
+		}
+
+		hasGoroutines := strings.Contains(w.Body.String(), "\ngo_goroutines ")
+		if hasGoroutines != (method == "GET") {
+			t.Fatalf("[%02d] unexpected body for %s:\n%s", i, method, w.Body.String())
+		}
+	}
+}
+
+// TestCounterLabelsPanic verifies that a Counter panics when used with the
+// wrong number of label values.
+func TestCounterLabelsPanic(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic, but none occurred")
+		}
+	}()
+
+	NewCounter("test_total", "Test.", "a", "b").Inc("a")
+}
@@ -0,0 +1,62 @@
+package metrics
+
+import (
+	"runtime"
+	"sync"
+	"time"
+)
+
+// memStatsMaxAge is the maximum age of the memory statistics reported by
+// RuntimeMetrics, so that they are read once per scrape.
+const memStatsMaxAge = 1 * time.Second
+
+// RuntimeMetrics returns Metrics which report the number of goroutines and
+// memory statistics of the Go runtime, named as in the Prometheus client.
+func RuntimeMetrics() []Metric {
+	ms := &memStats{}
+
+	return []Metric{
+		NewGaugeFunc("go_goroutines", "Number of goroutines that currently exist.", func() float64 {
+			return float64(runtime.NumGoroutine())
+		}),
+		NewGaugeFunc("go_memstats_alloc_bytes", "Number of bytes allocated and still in use.", func() float64 {
+			return float64(ms.read().Alloc)
+		}),
+		NewCounterFunc("go_memstats_alloc_bytes_total", "Total number of bytes allocated, even if freed.", func() float64 {
+			return float64(ms.read().TotalAlloc)
+		}),
+		NewGaugeFunc("go_memstats_sys_bytes", "Number of bytes obtained from system.", func() float64 {
+			return float64(ms.read().Sys)
+		}),
+		NewGaugeFunc("go_memstats_heap_inuse_bytes", "Number of heap bytes that are in use.", func() float64 {
+			return float64(ms.read().HeapInuse)
+		}),
+		NewGaugeFunc("go_memstats_heap_objects", "Number of allocated objects.", func() float64 {
+			return float64(ms.read().HeapObjects)
+		}),
+		NewCounterFunc("go_memstats_gc_total", "Number of completed garbage collection cycles.", func() float64 {
+			return float64(ms.read().NumGC)
+		}),
+	}
+}
+
+// memStats caches runtime.MemStats, since reading them stops the world.
+type memStats struct {
+	mu      sync.Mutex
+	updated time.Time
+	stats   runtime.MemStats
+}
+
+// read returns the cached runtime.MemStats, reading them again if they are
+// older than memStatsMaxAge.
+func (m *memStats) read() runtime.MemStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if time.Since(m.updated) > memStatsMaxAge {
+		runtime.ReadMemStats(&m.stats)
+		m.updated = time.Now()
+	}
+
+	return m.stats
+}
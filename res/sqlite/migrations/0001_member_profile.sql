@@ -0,0 +1,11 @@
+/* deltaiota sqlite migration: chapter-specific member profile fields */
+ALTER TABLE "users" ADD COLUMN "initiation_date" INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE "users" ADD COLUMN "pledge_class"       TEXT NOT NULL DEFAULT '';
+ALTER TABLE "users" ADD COLUMN "big_brother_id"  INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE "users" ADD COLUMN "instruments"        TEXT NOT NULL DEFAULT '';
+ALTER TABLE "users" ADD COLUMN "graduation_year" INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE "users" ADD COLUMN "status"             TEXT NOT NULL DEFAULT 'active';
+ALTER TABLE "users" ADD COLUMN "address"            TEXT NOT NULL DEFAULT '';
+ALTER TABLE "users" ADD COLUMN "bio"                TEXT NOT NULL DEFAULT '';
+CREATE INDEX "users_status" ON "users" ("status");
+CREATE INDEX "users_pledge_class" ON "users" ("pledge_class");
@@ -0,0 +1,2 @@
+/* deltaiota sqlite migration: user avatar content type */
+ALTER TABLE "users" ADD COLUMN "avatar" TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,22 @@
+/* deltaiota sqlite migration: officer positions and term history */
+/* positions */
+CREATE TABLE "positions" (
+	"id"            INTEGER PRIMARY KEY AUTOINCREMENT
+	, "name"           TEXT NOT NULL
+	, "description"    TEXT NOT NULL
+	, "rank"        INTEGER NOT NULL
+);
+CREATE UNIQUE INDEX "positions_unique_name" ON "positions" ("name");
+/* terms */
+CREATE TABLE "terms" (
+	"id"            INTEGER PRIMARY KEY AUTOINCREMENT
+	, "user_id"     INTEGER NOT NULL
+	, "position_id" INTEGER NOT NULL
+	, "start"       INTEGER NOT NULL
+	, "end"         INTEGER NOT NULL
+
+	, FOREIGN KEY(user_id) REFERENCES users(id)
+	, FOREIGN KEY(position_id) REFERENCES positions(id)
+);
+CREATE INDEX "terms_user_id" ON "terms" ("user_id");
+CREATE INDEX "terms_position_id" ON "terms" ("position_id");
@@ -0,0 +1,19 @@
+/* deltaiota sqlite migration: committees and committee membership */
+/* committees */
+CREATE TABLE "committees" (
+	"id"            INTEGER PRIMARY KEY AUTOINCREMENT
+	, "name"           TEXT NOT NULL
+	, "description"    TEXT NOT NULL
+);
+CREATE UNIQUE INDEX "committees_unique_name" ON "committees" ("name");
+/* committee_members */
+CREATE TABLE "committee_members" (
+	"committee_id"  INTEGER NOT NULL
+	, "user_id"     INTEGER NOT NULL
+	, "chair"       INTEGER NOT NULL
+
+	, PRIMARY KEY(committee_id, user_id)
+	, FOREIGN KEY(committee_id) REFERENCES committees(id)
+	, FOREIGN KEY(user_id) REFERENCES users(id)
+);
+CREATE INDEX "committee_members_user_id" ON "committee_members" ("user_id");
@@ -0,0 +1,30 @@
+/* deltaiota sqlite migration: dues ledger charges and payments */
+/* charges */
+CREATE TABLE "charges" (
+	"id"            INTEGER PRIMARY KEY AUTOINCREMENT
+	, "user_id"     INTEGER NOT NULL
+	, "kind"           TEXT NOT NULL
+	, "description"    TEXT NOT NULL
+	, "amount"      INTEGER NOT NULL
+	, "created"     INTEGER NOT NULL
+	, "due"         INTEGER NOT NULL
+	, "reminded"    INTEGER NOT NULL
+	, "overdue"     INTEGER NOT NULL
+
+	, FOREIGN KEY(user_id) REFERENCES users(id)
+);
+CREATE INDEX "charges_user_id" ON "charges" ("user_id");
+CREATE INDEX "charges_due" ON "charges" ("due");
+/* payments */
+CREATE TABLE "payments" (
+	"id"            INTEGER PRIMARY KEY AUTOINCREMENT
+	, "user_id"     INTEGER NOT NULL
+	, "amount"      INTEGER NOT NULL
+	, "method"         TEXT NOT NULL
+	, "reference"      TEXT NOT NULL
+	, "timestamp"   INTEGER NOT NULL
+	, "recorded_by" INTEGER NOT NULL
+
+	, FOREIGN KEY(user_id) REFERENCES users(id)
+);
+CREATE INDEX "payments_user_id" ON "payments" ("user_id");
@@ -0,0 +1,35 @@
+/* deltaiota sqlite migration: events, RSVPs, and calendar feed tokens */
+/* events */
+CREATE TABLE "events" (
+	"id"            INTEGER PRIMARY KEY AUTOINCREMENT
+	, "title"          TEXT NOT NULL
+	, "description"    TEXT NOT NULL
+	, "location"       TEXT NOT NULL
+	, "start"       INTEGER NOT NULL
+	, "end"         INTEGER NOT NULL
+	, "all_day"     INTEGER NOT NULL
+	, "created"     INTEGER NOT NULL
+	, "updated"     INTEGER NOT NULL
+);
+CREATE INDEX "events_start" ON "events" ("start");
+/* rsvps */
+CREATE TABLE "rsvps" (
+	"event_id"      INTEGER NOT NULL
+	, "user_id"     INTEGER NOT NULL
+	, "response"       TEXT NOT NULL
+	, "updated"     INTEGER NOT NULL
+
+	, PRIMARY KEY(event_id, user_id)
+	, FOREIGN KEY(event_id) REFERENCES events(id)
+	, FOREIGN KEY(user_id) REFERENCES users(id)
+);
+CREATE INDEX "rsvps_user_id" ON "rsvps" ("user_id");
+/* calendar_tokens */
+CREATE TABLE "calendar_tokens" (
+	"user_id"       INTEGER PRIMARY KEY
+	, "token"          TEXT NOT NULL
+	, "created"     INTEGER NOT NULL
+
+	, FOREIGN KEY(user_id) REFERENCES users(id)
+);
+CREATE UNIQUE INDEX "calendar_tokens_token" ON "calendar_tokens" ("token");
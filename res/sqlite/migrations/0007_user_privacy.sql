@@ -0,0 +1,3 @@
+/* deltaiota sqlite migration: user contact privacy settings */
+ALTER TABLE "users" ADD COLUMN "private_email" INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE "users" ADD COLUMN "private_phone" INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,13 @@
+/* deltaiota sqlite migration: account invitations */
+CREATE TABLE "invitations" (
+	"id"            INTEGER PRIMARY KEY AUTOINCREMENT
+	, "email"          TEXT NOT NULL
+	, "user_id"     INTEGER NOT NULL
+	, "token"          TEXT NOT NULL
+	, "created"     INTEGER NOT NULL
+	, "expire"      INTEGER NOT NULL
+	, "sent"        INTEGER NOT NULL
+);
+CREATE UNIQUE INDEX "invitations_token" ON "invitations" ("token");
+CREATE INDEX "invitations_user_id" ON "invitations" ("user_id");
+CREATE INDEX "invitations_sent" ON "invitations" ("sent");
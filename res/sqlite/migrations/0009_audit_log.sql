@@ -0,0 +1,25 @@
+/* deltaiota sqlite migration: audit log */
+CREATE TABLE "audit_log" (
+	"id"             INTEGER PRIMARY KEY AUTOINCREMENT
+	, "actor_id"     INTEGER NOT NULL
+	, "action"       TEXT NOT NULL
+	, "target_type"  TEXT NOT NULL
+	, "target_id"    INTEGER NOT NULL
+	, "before"       TEXT
+	, "after"        TEXT
+	, "remote_addr"  TEXT NOT NULL
+	, "created"      INTEGER NOT NULL
+);
+CREATE INDEX "audit_log_actor_id" ON "audit_log" ("actor_id");
+CREATE INDEX "audit_log_target" ON "audit_log" ("target_type", "target_id");
+CREATE INDEX "audit_log_created" ON "audit_log" ("created");
+
+/* The audit log is append-only */
+CREATE TRIGGER "audit_log_no_update" BEFORE UPDATE ON "audit_log"
+BEGIN
+	SELECT RAISE(ABORT, 'audit log is append-only');
+END;
+CREATE TRIGGER "audit_log_no_delete" BEFORE DELETE ON "audit_log"
+BEGIN
+	SELECT RAISE(ABORT, 'audit log is append-only');
+END;
@@ -0,0 +1,3 @@
+/* deltaiota sqlite migration: soft deletion of users */
+ALTER TABLE "users" ADD COLUMN "deleted_at" INTEGER NOT NULL DEFAULT 0;
+CREATE INDEX "users_deleted_at" ON "users" ("deleted_at");
@@ -0,0 +1,11 @@
+/* deltaiota sqlite migration: membership status transitions */
+CREATE TABLE "status_transitions" (
+	"id"            INTEGER PRIMARY KEY AUTOINCREMENT
+	, "user_id"     INTEGER NOT NULL
+	, "from_status"    TEXT NOT NULL
+	, "to_status"      TEXT NOT NULL
+	, "reason"         TEXT NOT NULL
+	, "actor_id"    INTEGER NOT NULL
+	, "created"     INTEGER NOT NULL
+);
+CREATE INDEX "status_transitions_user_id" ON "status_transitions" ("user_id");
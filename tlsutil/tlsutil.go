@@ -0,0 +1,160 @@
+// Package tlsutil provides TLS configuration for the Phi Mu Alpha Sinfonia -
+// Delta Iota chapter website, including certificates which are reloaded from
+// disk without restarting the server.
+package tlsutil
+
+import (
+	"crypto/tls"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// Config returns a tls.Config with modern defaults: only TLS 1.2 and newer,
+// forward-secret AEAD cipher suites, and support for HTTP/2.  Certificates are
+// retrieved from the input Reloader on each handshake.
+func Config(r *Reloader) *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+
+		// Cipher suites only apply to TLS 1.2, since TLS 1.3 suites are
+		// always secure
+		CipherSuites: []uint16{
+			tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+			tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+			tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305,
+			tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305,
+		},
+		CurvePreferences: []tls.CurveID{
+			tls.X25519,
+			tls.CurveP256,
+		},
+
+		NextProtos:     []string{"h2", "http/1.1"},
+		GetCertificate: r.GetCertificate,
+	}
+}
+
+// Reloader provides a TLS certificate and key loaded from files, which may be
+// replaced while the server is running.  Connections which are already
+// established are unaffected by a reload; new connections use the new
+// certificate.
+type Reloader struct {
+	certFile string
+	keyFile  string
+
+	mu       sync.RWMutex
+	cert     *tls.Certificate
+	modified time.Time
+}
+
+// NewReloader creates a Reloader, and loads its certificate and key from the
+// input files.
+func NewReloader(certFile string, keyFile string) (*Reloader, error) {
+	r := &Reloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+	}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Reload loads the certificate and key from their files.  If they cannot be
+// loaded, the current certificate continues to be used.
+func (r *Reloader) Reload() error {
+	modified, err := r.lastModified()
+	if err != nil {
+		return err
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	r.cert = &cert
+	r.modified = modified
+	r.mu.Unlock()
+
+	return nil
+}
+
+// GetCertificate returns the current certificate, and is used as the
+// GetCertificate function of a tls.Config.
+func (r *Reloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.cert, nil
+}
+
+// Watch checks the certificate and key files for changes at each interval,
+// reloading them when either is modified, until stop is closed.  Errors are
+// logged, so that a partially written file is retried on the next check.
+func (r *Reloader) Watch(interval time.Duration, stop <-chan struct{}) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-t.C:
+		}
+
+		changed, err := r.changed()
+		if err != nil {
+			log.Println("tlsutil: checking certificate:", err)
+			continue
+		}
+		if !changed {
+			continue
+		}
+
+		if err := r.Reload(); err != nil {
+			log.Println("tlsutil: reloading certificate:", err)
+			continue
+		}
+
+		log.Println("tlsutil: reloaded certificate:", r.certFile)
+	}
+}
+
+// changed returns whether or not the certificate or key file has been modified
+// since they were last loaded.
+func (r *Reloader) changed() (bool, error) {
+	modified, err := r.lastModified()
+	if err != nil {
+		return false, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return !modified.Equal(r.modified), nil
+}
+
+// lastModified returns the most recent modification time of the certificate
+// and key files.
+func (r *Reloader) lastModified() (time.Time, error) {
+	var modified time.Time
+	for _, f := range []string{r.certFile, r.keyFile} {
+		stat, err := os.Stat(f)
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		if stat.ModTime().After(modified) {
+			modified = stat.ModTime()
+		}
+	}
+
+	return modified, nil
+}
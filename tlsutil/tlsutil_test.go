@@ -0,0 +1,157 @@
+package tlsutil
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestReloader verifies that Reloader reloads its certificate when the files
+// change, and keeps the current certificate when they are invalid.
+func TestReloader(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tlsutil")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+
+	writeCert(t, certFile, keyFile, "first")
+	r, err := NewReloader(certFile, keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cn := commonName(t, r.GetCertificate); cn != "first" {
+		t.Fatalf("unexpected certificate: %q", cn)
+	}
+
+	// Unchanged files are not reloaded
+	if changed, err := r.changed(); err != nil || changed {
+		t.Fatalf("unexpected change: %v, %v", changed, err)
+	}
+
+	// Replaced files are reloaded
+	writeCert(t, certFile, keyFile, "second")
+	touch(t, certFile, time.Now().Add(1*time.Minute))
+	if changed, err := r.changed(); err != nil || !changed {
+		t.Fatalf("change not detected: %v, %v", changed, err)
+	}
+	if err := r.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if cn := commonName(t, r.GetCertificate); cn != "second" {
+		t.Fatalf("unexpected certificate: %q", cn)
+	}
+
+	// Invalid files are rejected, and the current certificate is kept
+	if err := ioutil.WriteFile(certFile, []byte("invalid"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Reload(); err == nil {
+		t.Fatal("expected error, but none occurred")
+	}
+	if cn := commonName(t, r.GetCertificate); cn != "second" {
+		t.Fatalf("unexpected certificate: %q", cn)
+	}
+}
+
+// TestConfig verifies that Config only permits modern TLS versions, and serves
+// the Reloader's certificate.
+func TestConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tlsutil")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	writeCert(t, certFile, keyFile, "config")
+
+	r, err := NewReloader(certFile, keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := Config(r)
+	if c.MinVersion != tls.VersionTLS12 {
+		t.Fatalf("unexpected minimum version: %x", c.MinVersion)
+	}
+
+	if cn := commonName(t, c.GetCertificate); cn != "config" {
+		t.Fatalf("unexpected certificate: %q", cn)
+	}
+}
+
+// commonName returns the common name of the certificate returned by the input
+// GetCertificate function.
+func commonName(t *testing.T, get func(*tls.ClientHelloInfo) (*tls.Certificate, error)) string {
+	cert, err := get(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return leaf.Subject.CommonName
+}
+
+// writeCert writes a self-signed certificate with the input common name, and
+// its key, to the input files.
+func writeCert(t *testing.T, certFile string, keyFile string, cn string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-1 * time.Hour),
+		NotAfter:     time.Now().Add(1 * time.Hour),
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, f := range []struct {
+		path  string
+		block *pem.Block
+	}{
+		{certFile, &pem.Block{Type: "CERTIFICATE", Bytes: der}},
+		{keyFile, &pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}},
+	} {
+		if err := ioutil.WriteFile(f.path, pem.EncodeToMemory(f.block), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// touch sets the modification time of the input file.
+func touch(t *testing.T, path string, modified time.Time) {
+	if err := os.Chtimes(path, modified, modified); err != nil {
+		t.Fatal(err)
+	}
+}
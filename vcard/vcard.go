@@ -0,0 +1,169 @@
+// Package vcard implements an encoder for vCard 4.0 data, as described in
+// RFC 6350.
+package vcard
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// ContentType is the MIME type of vCard data.
+	ContentType = "text/vcard; charset=utf-8"
+
+	// maxLineOctets is the maximum length of a content line, excluding the
+	// line break, before it must be folded.
+	maxLineOctets = 75
+)
+
+var (
+	// ErrNoFormattedName is returned when a Card has no formatted name.
+	ErrNoFormattedName = errors.New("vcard: card has no formatted name")
+)
+
+// Card is a single vCard, describing one person.
+type Card struct {
+	// UID is an optional globally unique and persistent identifier for the
+	// Card, such as a "urn:uuid:" URI.
+	UID string
+
+	// FormattedName is the display name of the person, and is required.
+	FormattedName string
+
+	// FamilyName and GivenName are the components of the person's name.
+	FamilyName string
+	GivenName  string
+
+	// Email, Phone, and Address are optional contact details.  Phone is
+	// encoded as free-form text, and Address as a free-form street address.
+	Email   string
+	Phone   string
+	Address string
+
+	// Org is an optional organization name, followed by any organizational
+	// units, from most to least general.
+	Org []string
+
+	// Categories are optional tags which apply to the person.
+	Categories []string
+
+	// Note is optional supplemental information about the person.
+	Note string
+}
+
+// An Encoder writes vCard data to an output stream.
+type Encoder struct {
+	w *bufio.Writer
+}
+
+// NewEncoder returns a new Encoder which writes to w.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{
+		w: bufio.NewWriter(w),
+	}
+}
+
+// Encode writes the vCard encoding of c to the stream.  Multiple Cards may be
+// written to the same stream by calling Encode repeatedly.
+func (e *Encoder) Encode(c *Card) error {
+	// Verify card before writing any output
+	if c.FormattedName == "" {
+		return ErrNoFormattedName
+	}
+
+	e.line("BEGIN", "", "VCARD")
+	e.line("VERSION", "", "4.0")
+	if c.UID != "" {
+		e.line("UID", "", c.UID)
+	}
+	e.line("FN", "", escape(c.FormattedName))
+
+	// N has five components: family, given, additional, prefixes, suffixes
+	e.line("N", "", structured(c.FamilyName, c.GivenName, "", "", ""))
+
+	if c.Email != "" {
+		e.line("EMAIL", "", escape(c.Email))
+	}
+	if c.Phone != "" {
+		e.line("TEL", ";VALUE=text", escape(c.Phone))
+	}
+
+	// ADR has seven components: PO box, extended address, street address,
+	// locality, region, postal code, and country
+	if c.Address != "" {
+		e.line("ADR", "", structured("", "", c.Address, "", "", "", ""))
+	}
+
+	if len(c.Org) > 0 {
+		e.line("ORG", "", structured(c.Org...))
+	}
+	if len(c.Categories) > 0 {
+		values := make([]string, 0, len(c.Categories))
+		for _, v := range c.Categories {
+			values = append(values, escape(v))
+		}
+		e.line("CATEGORIES", "", strings.Join(values, ","))
+	}
+	if c.Note != "" {
+		e.line("NOTE", "", escape(c.Note))
+	}
+
+	e.line("END", "", "VCARD")
+	return e.w.Flush()
+}
+
+// line writes a single content line, with optional parameters, folding the line
+// as needed.  Any write error is retained by the underlying bufio.Writer, and
+// reported when it is flushed.
+func (e *Encoder) line(name string, params string, value string) {
+	s := name + params + ":" + value
+
+	// Fold lines longer than the maximum, without splitting any UTF-8
+	// sequences; continuation lines begin with a single space
+	limit := maxLineOctets
+	for len(s) > limit {
+		i := limit
+		for i > 0 && !utf8.RuneStart(s[i]) {
+			i--
+		}
+
+		e.w.WriteString(s[:i])
+		e.w.WriteString("\r\n ")
+		s = s[i:]
+
+		// Leading space counts toward continuation line length
+		limit = maxLineOctets - 1
+	}
+
+	e.w.WriteString(s)
+	e.w.WriteString("\r\n")
+}
+
+// textEscaper escapes special characters in text values.
+var textEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	`;`, `\;`,
+	`,`, `\,`,
+	"\r\n", `\n`,
+	"\n", `\n`,
+	"\r", `\n`,
+)
+
+// escape escapes a text value, or a single component of a structured value,
+// for use in a content line.
+func escape(s string) string {
+	return textEscaper.Replace(s)
+}
+
+// structured escapes and joins the components of a structured value.
+func structured(components ...string) string {
+	escaped := make([]string, 0, len(components))
+	for _, c := range components {
+		escaped = append(escaped, escape(c))
+	}
+
+	return strings.Join(escaped, ";")
+}
@@ -0,0 +1,116 @@
+package vcard
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// TestEncoder verifies that Encoder produces the expected output for a
+// complete Card, and for a Card with only required fields.
+func TestEncoder(t *testing.T) {
+	var tests = []struct {
+		card *Card
+		want []string
+	}{
+		{
+			card: &Card{
+				FormattedName: "Jane Doe",
+			},
+			want: []string{
+				"BEGIN:VCARD",
+				"VERSION:4.0",
+				"FN:Jane Doe",
+				"N:;;;;",
+				"END:VCARD",
+			},
+		},
+		{
+			card: &Card{
+				UID:           "urn:uuid:f81d4fae-7dec-11d0-a765-00a0c91e6bf6",
+				FormattedName: "John Smith, Jr.",
+				FamilyName:    "Smith",
+				GivenName:     "John",
+				Email:         "john@example.com",
+				Phone:         "+1 555-0100",
+				Address:       "1 Main St; Apt 2\nSpringfield",
+				Org:           []string{"Phi Mu Alpha Sinfonia", "Delta Iota"},
+				Categories:    []string{"active", "Fall, 2014"},
+				Note:          `Plays trumpet \ piano`,
+			},
+			want: []string{
+				"BEGIN:VCARD",
+				"VERSION:4.0",
+				"UID:urn:uuid:f81d4fae-7dec-11d0-a765-00a0c91e6bf6",
+				`FN:John Smith\, Jr.`,
+				"N:Smith;John;;;",
+				"EMAIL:john@example.com",
+				"TEL;VALUE=text:+1 555-0100",
+				`ADR:;;1 Main St\; Apt 2\nSpringfield;;;;`,
+				"ORG:Phi Mu Alpha Sinfonia;Delta Iota",
+				`CATEGORIES:active,Fall\, 2014`,
+				`NOTE:Plays trumpet \\ piano`,
+				"END:VCARD",
+			},
+		},
+	}
+
+	for i, test := range tests {
+		buf := bytes.NewBuffer(nil)
+		if err := NewEncoder(buf).Encode(test.card); err != nil {
+			t.Fatal(err)
+		}
+
+		want := strings.Join(test.want, "\r\n") + "\r\n"
+		if got := buf.String(); got != want {
+			t.Fatalf("[%02d] unexpected output:\n%s\n!=\n%s", i, got, want)
+		}
+	}
+}
+
+// TestEncoderFolding verifies that Encoder folds long content lines without
+// splitting UTF-8 sequences.
+func TestEncoderFolding(t *testing.T) {
+	card := &Card{
+		FormattedName: "Jane Doe",
+		Note:          strings.Repeat("a", 70) + strings.Repeat("Δ", 60),
+	}
+
+	buf := bytes.NewBuffer(nil)
+	if err := NewEncoder(buf).Encode(card); err != nil {
+		t.Fatal(err)
+	}
+
+	var unfolded []string
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n") {
+		if len(line) > maxLineOctets {
+			t.Fatalf("line too long: %d > %d: %q", len(line), maxLineOctets, line)
+		}
+
+		if strings.HasPrefix(line, " ") {
+			unfolded[len(unfolded)-1] += line[1:]
+			continue
+		}
+		unfolded = append(unfolded, line)
+	}
+
+	want := "NOTE:" + card.Note
+	for _, line := range unfolded {
+		if line == want {
+			return
+		}
+	}
+
+	t.Fatalf("unfolded output missing %q:\n%v", want, unfolded)
+}
+
+// TestEncoderInvalid verifies that Encoder rejects a Card with no formatted name.
+func TestEncoderInvalid(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	if err := NewEncoder(buf).Encode(&Card{}); err != ErrNoFormattedName {
+		t.Fatalf("unexpected error: %v != %v", err, ErrNoFormattedName)
+	}
+	if buf.Len() != 0 {
+		t.Fatalf("unexpected output: %q", buf.String())
+	}
+}